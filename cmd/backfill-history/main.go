@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/services"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Report what would be backfilled without writing anything")
+	flag.Parse()
+
+	// Load configuration
+	config.LoadConfig()
+
+	// Initialize database connection
+	database.ConnectDB()
+
+	if *dryRun {
+		log.Println("Checking work order status history in dry-run mode, no changes will be applied...")
+	} else {
+		log.Println("Backfilling work order status history...")
+	}
+
+	service := services.HistoryBackfillService{}
+	report, err := service.Backfill(database.DB, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to backfill status history: %v", err)
+	}
+
+	for _, workOrder := range report.Backfilled {
+		log.Printf("  %s: %d history row(s) synthesized, current status %s",
+			workOrder.WorkOrderNumber, workOrder.Rows, workOrder.Status)
+	}
+	for _, workOrder := range report.Mismatched {
+		log.Printf("  %s: status is %s but the latest history row (%s) says %s, check manually",
+			workOrder.WorkOrderNumber, workOrder.Status, workOrder.HistoryAt.Format("2006-01-02 15:04:05"), workOrder.HistoryStatus)
+	}
+
+	if *dryRun {
+		log.Printf("%d work order(s) would be backfilled, %d work order(s) contradict their history", len(report.Backfilled), len(report.Mismatched))
+		return
+	}
+	log.Printf("Backfilled %d work order(s), %d work order(s) contradict their history", len(report.Backfilled), len(report.Mismatched))
+
+	// The daily counters are computed from the status history
+	if len(report.Backfilled) > 0 {
+		counters := services.DailyCounterService{}
+		count, err := counters.Rebuild(database.DB)
+		if err != nil {
+			log.Fatalf("Failed to rebuild daily production counters: %v", err)
+		}
+		log.Printf("Rebuilt %d daily production counter(s)", count)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Log the statements that would be executed without applying them")
+	verbose := flag.Bool("verbose", false, "Log the result of the constraint checks")
+	flag.Parse()
+
+	// Load configuration
+	config.LoadConfig()
+
+	// Initialize database connection
+	database.ConnectDB()
+
+	if err := database.Migrate(database.MigrateOptions{
+		DryRun:  *dryRun,
+		Verbose: *verbose,
+	}); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/services"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Log how many audit logs would be deleted without deleting them")
+	flag.Parse()
+
+	// Load configuration
+	config.LoadConfig()
+
+	// Never purge without an explicit retention window
+	retentionDays := config.AppConfig.AuditRetentionDays
+	if retentionDays <= 0 {
+		log.Println("AUDIT_RETENTION_DAYS is not set, audit log purging is disabled")
+		return
+	}
+
+	// Initialize database connection
+	database.ConnectDB()
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	if *dryRun {
+		log.Printf("Dry run: counting audit logs created before %s (retention %d days)...", cutoff.Format(time.RFC3339), retentionDays)
+	} else {
+		log.Printf("Purging audit logs created before %s (retention %d days)...", cutoff.Format(time.RFC3339), retentionDays)
+	}
+
+	service := services.AuditLogService{}
+	count, err := service.PurgeOlderThan(database.DB, cutoff, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to purge audit logs: %v", err)
+	}
+
+	if *dryRun {
+		log.Printf("%d audit log(s) would be deleted", count)
+	} else {
+		log.Printf("Deleted %d audit log(s)", count)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/services"
+)
+
+func main() {
+	// Load configuration
+	config.LoadConfig()
+
+	// Initialize database connection
+	database.ConnectDB()
+
+	// Make sure the counters table exists
+	if err := database.DB.AutoMigrate(&models.DailyProductionCounter{}); err != nil {
+		log.Fatalf("Failed to migrate daily production counters: %v", err)
+	}
+
+	log.Println("Rebuilding daily production counters...")
+
+	service := services.DailyCounterService{}
+	count, err := service.Rebuild(database.DB)
+	if err != nil {
+		log.Fatalf("Failed to rebuild daily production counters: %v", err)
+	}
+
+	log.Printf("Rebuilt %d daily production counter(s)", count)
+}
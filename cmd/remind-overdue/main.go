@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/services"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Log how many overdue work orders would be reminded without sending anything")
+	flag.Parse()
+
+	// Load configuration
+	config.LoadConfig()
+
+	// Initialize database connection
+	database.ConnectDB()
+
+	if *dryRun {
+		log.Println("Dry run: counting overdue work orders...")
+	} else {
+		log.Println("Sending overdue reminders...")
+	}
+
+	service := services.OverdueReminderService{
+		Notifications: &services.NotificationService{Sender: services.LogSender{}},
+	}
+	report, err := service.Remind(database.DB, time.Now(), *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to send overdue reminders: %v", err)
+	}
+
+	log.Printf("%d overdue work order(s), %d snoozed", report.Overdue, report.Snoozed)
+	if *dryRun {
+		log.Printf("%d work order(s) would be reminded", report.Overdue-report.Snoozed)
+	} else {
+		log.Printf("Sent %d overdue reminder(s)", report.Notified)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 )
 
 // Config stores all configuration of the application
@@ -16,11 +17,152 @@ type Config struct {
 	DBName         string
 	JWTSecret      string
 	TokenExpiresIn int
+
+	// ImpersonationTTL is how many minutes a manager's operator impersonation token is valid
+	ImpersonationTTL int
+
+	// JWT iss and aud claims set on issued tokens and required on incoming ones (empty disables the check)
+	JWTIssuer   string
+	JWTAudience string
+
+	// AppEnv is the deployment environment, e.g. development or production
+	AppEnv string
+
+	// AppTimezone is the IANA timezone of the server's local time, which day
+	// boundaries of reports and deadlines use (empty keeps the system timezone)
+	AppTimezone string
+
+	// MaxPageSize caps the limit of the paginated lists
+	MaxPageSize int
+
+	// GORM log level (silent/error/warn/info) and the duration in
+	// milliseconds above which a statement is logged as slow (0 disables it)
+	DBLogLevel           string
+	DBSlowQueryThreshold int
+
+	// Database connection pool limits, connection lifetime in seconds
+	// and the Postgres statement_timeout in seconds (0 disables it)
+	DBMaxOpenConns     int
+	DBMaxIdleConns     int
+	DBConnMaxLifetime  int
+	DBStatementTimeout int
+
+	// MaintenanceMode starts the server read-only, write requests are answered with 503
+	MaintenanceMode bool
+
+	// RequestTimeout is the per-request deadline in seconds (0 disables it)
+	RequestTimeout int
+
+	// ShutdownTimeout is how many seconds a shutdown waits for in-flight requests and queued events
+	ShutdownTimeout int
+
+	// MaxBodySize is the largest accepted request body in megabytes, it bounds CSV uploads
+	MaxBodySize int
+
+	// Response compression level (-1 disabled, 0 default, 1 best speed, 2 best compression)
+	// and the minimum response size in bytes before compressing
+	CompressLevel   int
+	CompressMinSize int
+
+	// Default sort column and direction (asc/desc) of the work order lists
+	WorkOrderSortBy    string
+	WorkOrderSortOrder string
+
+	// MaxActiveOrdersPerOperator caps in-progress work orders per operator (0 means unlimited)
+	MaxActiveOrdersPerOperator int
+
+	// PreventDuplicateActiveOrders rejects creating a work order while the operator has
+	// another non-completed one for the same product
+	PreventDuplicateActiveOrders bool
+
+	// MinProgressQuantity is the smallest quantity a progress entry may report
+	MinProgressQuantity int
+
+	// MonotonicProduced rejects status updates that lower the produced quantity
+	MonotonicProduced bool
+
+	// AutoStartOnProgress moves a pending work order to in_progress when progress is logged on it
+	AutoStartOnProgress bool
+
+	// BlockTargetChangeWithProgress rejects target quantity edits of work orders with logged progress
+	BlockTargetChangeWithProgress bool
+
+	// Production deadlines of created and edited work orders must be at least DeadlineMinLeadHours
+	// and at most DeadlineMaxHorizonDays ahead (0 disables either bound)
+	DeadlineMinLeadHours   int
+	DeadlineMaxHorizonDays int
+
+	// Password policy
+	PasswordMinLength     int
+	PasswordRequireDigit  bool
+	PasswordRequireUpper  bool
+	PasswordRequireSymbol bool
+
+	// Operator performance flags: minimum completed quantity per day (0 disables) and how many
+	// percent below their own trailing average an operator's throughput may drop
+	PerformanceMinThroughput float64
+	PerformanceDropPercent   int
+
+	// ForecastLookbackDays is how many days of completed orders the capacity forecast throughput is measured over
+	ForecastLookbackDays int
+
+	// CSV import limits: rows created per transaction and rows accepted per file
+	ImportBatchSize int
+	ImportMaxRows   int
+
+	// UpcomingLoadWeeks is how many weeks ahead the upcoming load report covers by default
+	UpcomingLoadWeeks int
+
+	// PerfProfiler records per-route timings in memory for the slowest routes report
+	PerfProfiler bool
+
+	// AuditReadAccess records who viewed audit logs and sensitive reports
+	AuditReadAccess bool
+
+	// AuditRetentionDays is how many days audit logs are kept by the purge tool (0 disables purging)
+	AuditRetentionDays int
+
+	// AuditReportSigningKey signs work order audit reports with HMAC-SHA256 (empty leaves them unsigned)
+	AuditReportSigningKey string
+
+	// Domain event bus: events queued per subscriber, workers per subscriber and
+	// the URL every event is posted to (empty disables the webhook)
+	EventBufferSize int
+	EventWorkers    int
+	WebhookURL      string
+
+	// Outbox worker: attempts per job, seconds before the first retry (doubled for
+	// every further one), seconds between polls and jobs claimed per poll
+	OutboxMaxAttempts  int
+	OutboxRetryBackoff int
+	OutboxPollInterval int
+	OutboxBatchSize    int
+
+	// FeatureFlags is a comma separated list of features enabled unless a manager switched them off
+	FeatureFlags string
+
+	// Business calendar: comma separated weekend day names and holiday dates (YYYY-MM-DD)
+	BusinessWeekend  string
+	BusinessHolidays string
 }
 
 // AppConfig holds the application configuration
 var AppConfig Config
 
+// redacted replaces secrets in logged configuration
+const redacted = "[redacted]"
+
+// Redacted returns a copy of the configuration with the secrets that are set masked, for logging.
+// Webhook URLs are masked as receivers often carry their token in the URL.
+func (c Config) Redacted() Config {
+	for _, secret := range []*string{&c.DBPassword, &c.JWTSecret, &c.AuditReportSigningKey, &c.WebhookURL} {
+		if *secret != "" {
+			*secret = redacted
+		}
+	}
+	return c
+}
+
 // LoadConfig reads configuration from environment variables or .env file
 func LoadConfig() {
 	// Try to load .env file (optional, mainly for local development)
@@ -35,9 +177,102 @@ func LoadConfig() {
 		DBName:         getEnv("DB_NAME", "workorder"),
 		JWTSecret:      getEnv("JWT_SECRET", "your-secret-key"),
 		TokenExpiresIn: getEnvAsInt("TOKEN_EXPIRES_IN", 24), // hours
+
+		ImpersonationTTL: getEnvAsInt("IMPERSONATION_TTL", 15), // minutes
+
+		// Unset by default so tokens issued before the checks existed stay valid
+		JWTIssuer:   getEnv("JWT_ISSUER", ""),
+		JWTAudience: getEnv("JWT_AUDIENCE", ""),
+
+		AppEnv:      getEnv("APP_ENV", "production"),
+		AppTimezone: getEnv("APP_TIMEZONE", ""),
+		MaxPageSize: getEnvAsInt("MAX_PAGE_SIZE", 100),
+
+		DBSlowQueryThreshold: getEnvAsInt("DB_SLOW_QUERY_THRESHOLD", 200), // milliseconds
+
+		DBMaxOpenConns:     getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:     getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
+		DBConnMaxLifetime:  getEnvAsInt("DB_CONN_MAX_LIFETIME", 1800), // seconds
+		DBStatementTimeout: getEnvAsInt("DB_STATEMENT_TIMEOUT", 60),   // seconds
+
+		MaintenanceMode: getEnvAsBool("MAINTENANCE_MODE", false),
+
+		RequestTimeout:  getEnvAsInt("REQUEST_TIMEOUT", 30),  // seconds
+		ShutdownTimeout: getEnvAsInt("SHUTDOWN_TIMEOUT", 10), // seconds
+		MaxBodySize:     getEnvAsInt("MAX_BODY_SIZE", 16),    // megabytes
+
+		CompressLevel:   getEnvAsInt("COMPRESS_LEVEL", 0),
+		CompressMinSize: getEnvAsInt("COMPRESS_MIN_SIZE", 1024), // bytes
+
+		WorkOrderSortBy:    getEnv("WORK_ORDER_SORT_BY", "work_order_number"),
+		WorkOrderSortOrder: getEnv("WORK_ORDER_SORT_ORDER", "desc"),
+
+		MaxActiveOrdersPerOperator: getEnvAsInt("MAX_ACTIVE_ORDERS_PER_OPERATOR", 0),
+
+		PreventDuplicateActiveOrders: getEnvAsBool("PREVENT_DUPLICATE_ACTIVE_ORDERS", false),
+
+		MinProgressQuantity: getEnvAsInt("MIN_PROGRESS_QUANTITY", 1),
+		MonotonicProduced:   getEnvAsBool("ENFORCE_MONOTONIC_PRODUCED", false),
+		AutoStartOnProgress: getEnvAsBool("AUTO_START_ON_PROGRESS", false),
+
+		BlockTargetChangeWithProgress: getEnvAsBool("BLOCK_TARGET_CHANGE_WITH_PROGRESS", false),
+
+		DeadlineMinLeadHours:   getEnvAsInt("DEADLINE_MIN_LEAD_HOURS", 0),
+		DeadlineMaxHorizonDays: getEnvAsInt("DEADLINE_MAX_HORIZON_DAYS", 0),
+
+		PasswordMinLength:     getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireDigit:  getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireUpper:  getEnvAsBool("PASSWORD_REQUIRE_UPPER", false),
+		PasswordRequireSymbol: getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+
+		PerformanceMinThroughput: getEnvAsFloat("PERFORMANCE_MIN_THROUGHPUT", 0),
+		PerformanceDropPercent:   getEnvAsInt("PERFORMANCE_DROP_PERCENT", 20),
+
+		ImportBatchSize: getEnvAsInt("IMPORT_BATCH_SIZE", 500),
+		ImportMaxRows:   getEnvAsInt("IMPORT_MAX_ROWS", 50000),
+
+		ForecastLookbackDays: getEnvAsInt("FORECAST_LOOKBACK_DAYS", 30),
+		UpcomingLoadWeeks:    getEnvAsInt("UPCOMING_LOAD_WEEKS", 8),
+
+		PerfProfiler: getEnvAsBool("PERF_PROFILER", true),
+
+		AuditReadAccess:    getEnvAsBool("AUDIT_READ_ACCESS", true),
+		AuditRetentionDays: getEnvAsInt("AUDIT_RETENTION_DAYS", 0),
+
+		AuditReportSigningKey: getEnv("AUDIT_REPORT_SIGNING_KEY", ""),
+
+		EventBufferSize: getEnvAsInt("EVENT_BUFFER_SIZE", 256),
+		EventWorkers:    getEnvAsInt("EVENT_WORKERS", 2),
+		WebhookURL:      getEnv("WEBHOOK_URL", ""),
+
+		OutboxMaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 5),
+		OutboxRetryBackoff: getEnvAsInt("OUTBOX_RETRY_BACKOFF", 30), // seconds
+		OutboxPollInterval: getEnvAsInt("OUTBOX_POLL_INTERVAL", 5),  // seconds
+		OutboxBatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 20),
+
+		FeatureFlags: getEnv("FEATURE_FLAGS", ""),
+
+		BusinessWeekend:  getEnv("BUSINESS_WEEKEND", "saturday,sunday"),
+		BusinessHolidays: getEnv("BUSINESS_HOLIDAYS", ""),
+	}
+
+	// Log every statement while developing, only warnings and slow queries otherwise
+	defaultDBLogLevel := "warn"
+	if AppConfig.AppEnv == "development" {
+		defaultDBLogLevel = "info"
 	}
+	AppConfig.DBLogLevel = getEnv("DB_LOG_LEVEL", defaultDBLogLevel)
 
-	log.Println(AppConfig)
+	// Use the configured timezone for the server's local time
+	if AppConfig.AppTimezone != "" {
+		location, err := time.LoadLocation(AppConfig.AppTimezone)
+		if err != nil {
+			log.Fatalf("Invalid APP_TIMEZONE %q: %v", AppConfig.AppTimezone, err)
+		}
+		time.Local = location
+	}
+
+	log.Println(AppConfig.Redacted())
 
 	// Validate critical configuration
 	if AppConfig.JWTSecret == "your-secret-key" {
@@ -65,3 +300,21 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// Helper function to read an environment variable as float or return a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// Helper function to read an environment variable as boolean or return a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedacted(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   Config
+	}{
+		{
+			name: "secrets are masked",
+			config: Config{
+				DBUser:                "postgres",
+				DBPassword:            "db-pass",
+				JWTSecret:             "jwt-secret",
+				AuditReportSigningKey: "signing-key",
+				WebhookURL:            "https://hooks.example.com/T0/secret-token",
+			},
+			want: Config{
+				DBUser:                "postgres",
+				DBPassword:            redacted,
+				JWTSecret:             redacted,
+				AuditReportSigningKey: redacted,
+				WebhookURL:            redacted,
+			},
+		},
+		{
+			name:   "unset secrets stay empty",
+			config: Config{DBUser: "postgres", JWTSecret: "jwt-secret"},
+			want:   Config{DBUser: "postgres", JWTSecret: redacted},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.config
+			got := tt.config.Redacted()
+			if got != tt.want {
+				t.Errorf("Redacted() = %+v, want %+v", got, tt.want)
+			}
+			if tt.config != original {
+				t.Errorf("Redacted() changed the configuration to %+v", tt.config)
+			}
+			logged := fmt.Sprint(got)
+			for _, secret := range []string{"db-pass", "jwt-secret", "signing-key", "secret-token"} {
+				if strings.Contains(logged, secret) {
+					t.Errorf("logged configuration %s contains %q", logged, secret)
+				}
+			}
+		})
+	}
+}
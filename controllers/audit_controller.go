@@ -1,31 +1,94 @@
 package controllers
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
 	"github.com/dawamr/work-order-system-go/database"
 	"github.com/dawamr/work-order-system-go/models"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
+// AuditLogListResponse represents a paginated list of audit logs
 type AuditLogListResponse struct {
-	Error     bool            `json:"error"`
-	AuditLogs []models.AuditLog `json:"audit_logs"`
-	Pagination Pagination     `json:"pagination"`
+	Error      bool          `json:"error"`
+	AuditLogs  []AuditLogDTO `json:"audit_logs"`
+	Pagination *Pagination   `json:"pagination,omitempty"`  // only set for offset paging
+	NextCursor string        `json:"next_cursor,omitempty"` // pass as ?after= to get the next page, empty on the last page
+}
+
+// FieldChange is the old and new value of one field in an audit log entry
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old" swaggertype:"object"` // null when the field had no previous value
+	New   json.RawMessage `json:"new" swaggertype:"object"` // null when the field was removed
+}
+
+// AuditLogDetailResponse represents a single audit log entry with its field diff
+type AuditLogDetailResponse struct {
+	Error    bool          `json:"error"`
+	AuditLog AuditLogDTO   `json:"audit_log"`
+	Changes  []FieldChange `json:"changes"`
+}
+
+// FieldHistoryEntry is one change of a field across the audit log of an entity
+type FieldHistoryEntry struct {
+	AuditLogID uint              `json:"audit_log_id"`
+	UserName   string            `json:"user_name"`
+	Action     models.ActionType `json:"action"`
+	Old        json.RawMessage   `json:"old" swaggertype:"object"`
+	New        json.RawMessage   `json:"new" swaggertype:"object"`
+	Note       string            `json:"note,omitempty"`
+	ChangedAt  time.Time         `json:"changed_at"`
+}
+
+// FieldHistoryResponse represents the chronological values of one work order field
+type FieldHistoryResponse struct {
+	Error   bool                `json:"error"`
+	Field   string              `json:"field"`
+	History []FieldHistoryEntry `json:"history"`
 }
 
 // GetAuditLogs returns a paginated list of audit logs
+// @Summary Get audit logs
+// @Description Get a paginated list of audit logs, newest first (Production Manager only)
+// @Tags audit-logs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1), ignored when after is given"
+// @Param limit query int false "Items per page (default: 10)"
+// @Param after query string false "Cursor from next_cursor; pass an empty value to start a cursor scan"
+// @Param entity_type query string false "Filter by entity type (e.g. WorkOrder)"
+// @Param entity_id query int false "Filter by entity ID"
+// @Param action query string false "Filter by action (create/update/delete/custom)"
+// @Success 200 {object} AuditLogListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /audit-logs [get]
 func GetAuditLogs(c *fiber.Ctx) error {
 	page := c.QueryInt("page", 1)
-	limit := c.QueryInt("limit", 10)
+	limit := pageLimit(c)
 	entityType := c.Query("entity_type")
 	entityID := c.QueryInt("entity_id", 0)
 	action := c.Query("action")
 
 	offset := (page - 1) * limit
 
-	// Build query with proper User preloading
-	query := database.DB.Model(&models.AuditLog{}).
-		Preload("User"). // Use Preload instead of Joins
-		Order("created_at DESC")
+	// Build query with proper User preloading, id breaks ties between rows created at the same time
+	query := preloadUnscoped(getDB(c).Model(&models.AuditLog{}), "User").
+		Order("created_at DESC, id DESC")
 
 	if entityType != "" {
 		query = query.Where("entity_type = ?", entityType)
@@ -37,21 +100,134 @@ func GetAuditLogs(c *fiber.Ctx) error {
 		query = query.Where("action = ?", action)
 	}
 
+	// Cursor paging continues after the last row of the previous page, so rows
+	// inserted during a scan neither shift nor repeat the following pages
+	var pagination *Pagination
+	if c.Context().QueryArgs().Has("after") {
+		if after := c.Query("after"); after != "" {
+			createdAt, id, err := decodeAuditCursor(after)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+					Error: true,
+					Msg:   "Invalid cursor",
+				})
+			}
+			query = query.Where("(created_at, id) < (?, ?)", createdAt, id)
+		}
+		offset = 0
+	} else {
+		var count int64
+		query.Count(&count)
+		pagination = &Pagination{
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
+		}
+	}
+
+	var auditLogs []models.AuditLog
+	if err := query.Offset(offset).Limit(limit).Find(&auditLogs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching audit logs",
+		})
+	}
+
+	// A full page may be followed by more rows
+	nextCursor := ""
+	if len(auditLogs) > 0 && len(auditLogs) == limit {
+		last := auditLogs[len(auditLogs)-1]
+		nextCursor = encodeAuditCursor(last.CreatedAt, last.ID)
+	}
+
+	logReadAccess(c, "Viewed audit logs")
+
+	return c.JSON(AuditLogListResponse{
+		Error:      false,
+		AuditLogs:  toAuditLogDTOs(auditLogs),
+		Pagination: pagination,
+		NextCursor: nextCursor,
+	})
+}
+
+// GetUserActions returns the audit logs of the actions a user performed
+// @Summary Get user actions
+// @Description Get a paginated list of the audit logs where the user is the actor, newest first, to review a person's changes (Production Manager only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param entity_type query string false "Filter by entity type (e.g. WorkOrder)"
+// @Param action query string false "Filter by action (create/update/delete/custom)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Success 200 {object} AuditLogListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/actions [get]
+func GetUserActions(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := pageLimit(c)
+	entityType := c.Query("entity_type")
+	action := c.Query("action")
+
+	offset := (page - 1) * limit
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	// The user has to be visible in the request's plant
+	var user models.User
+	result := getDB(c).First(&user, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching user",
+		})
+	}
+
+	query := preloadUnscoped(getDB(c).Model(&models.AuditLog{}), "User").
+		Where("user_id = ?", user.ID)
+	query = applyDateRange(query, "created_at", c.Query("start_date"), c.Query("end_date"))
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
 	var count int64
 	query.Count(&count)
 
 	var auditLogs []models.AuditLog
-	if err := query.Offset(offset).Limit(limit).Find(&auditLogs).Error; err != nil {
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&auditLogs).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Error fetching audit logs",
 		})
 	}
 
+	logReadAccess(c, fmt.Sprintf("Viewed actions of user %s", user.Username))
+
 	return c.JSON(AuditLogListResponse{
 		Error:     false,
-		AuditLogs: auditLogs,
-		Pagination: Pagination{
+		AuditLogs: toAuditLogDTOs(auditLogs),
+		Pagination: &Pagination{
 			Total: count,
 			Page:  page,
 			Limit: limit,
@@ -59,3 +235,221 @@ func GetAuditLogs(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// encodeAuditCursor builds the opaque cursor pointing after an audit log row
+func encodeAuditCursor(createdAt time.Time, id uint) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatUint(uint64(id), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeAuditCursor parses a cursor built by encodeAuditCursor
+func decodeAuditCursor(cursor string) (time.Time, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	createdAtStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("malformed cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	return createdAt, uint(id), nil
+}
+
+// logReadAccess records that the current user read a sensitive report when
+// AUDIT_READ_ACCESS is enabled. The entry is written in the background so a
+// logging failure never fails the read.
+func logReadAccess(c *fiber.Ctx, note string) {
+	if !config.AppConfig.AuditReadAccess {
+		return
+	}
+
+	userID := actorID(c)
+	if userID == 0 {
+		return
+	}
+
+	// Copy the request values, fiber reuses their memory once the handler returns
+	params := make(map[string]string)
+	for key, value := range c.Queries() {
+		params[strings.Clone(key)] = strings.Clone(value)
+	}
+	note = note + " (" + strings.Clone(c.Path()) + ")"
+
+	// Keep the request's plant but not its deadline, the request is over when the entry is written
+	db := database.DB.WithContext(context.WithoutCancel(c.UserContext()))
+	go func() {
+		if err := auditService.CreateAccessLog(db, userID, "Report", params, note); err != nil {
+			log.Printf("Error creating audit log: %v", err)
+		}
+	}()
+}
+
+// GetAuditLogByID returns a single audit log entry with a field-by-field diff
+// @Summary Get audit log entry
+// @Description Get an audit log entry with its old and new values aligned per field (Production Manager only)
+// @Tags audit-logs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Audit log ID"
+// @Success 200 {object} AuditLogDetailResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /audit-logs/{id} [get]
+func GetAuditLogByID(c *fiber.Ctx) error {
+	// Get audit log ID from URL
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	var auditLog models.AuditLog
+	result := preloadUnscoped(getDB(c), "User").First(&auditLog, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Audit log not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching audit log",
+		})
+	}
+
+	logReadAccess(c, "Viewed audit log entry")
+
+	return c.JSON(AuditLogDetailResponse{
+		Error:    false,
+		AuditLog: toAuditLogDTO(auditLog),
+		Changes:  auditLogChanges(auditLog),
+	})
+}
+
+// GetWorkOrderFieldHistory returns the values a work order field had over time
+// @Summary Get work order field history
+// @Description Get the chronological changes of a single work order field across its audit log (Production Manager only)
+// @Tags audit-logs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param field query string true "Field name as stored in the audit log (e.g. status)"
+// @Success 200 {object} FieldHistoryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/field-history [get]
+func GetWorkOrderFieldHistory(c *fiber.Ctx) error {
+	// Get work order ID from URL
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	field := c.Query("field")
+	if field == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "field is required",
+		})
+	}
+
+	// Only entries that recorded a value for the field
+	var auditLogs []models.AuditLog
+	if err := getDB(c).
+		Where("entity_type = ? AND entity_id = ?", "WorkOrder", id).
+		Where("(old_values -> ?) IS NOT NULL OR (new_values -> ?) IS NOT NULL", field, field).
+		Order("created_at ASC, id ASC").
+		Find(&auditLogs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching audit logs",
+		})
+	}
+
+	history := make([]FieldHistoryEntry, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		for _, change := range auditLogChanges(auditLog) {
+			if change.Field != field {
+				continue
+			}
+			history = append(history, FieldHistoryEntry{
+				AuditLogID: auditLog.ID,
+				UserName:   auditLog.UserName,
+				Action:     auditLog.Action,
+				Old:        change.Old,
+				New:        change.New,
+				Note:       auditLog.Note,
+				ChangedAt:  auditLog.CreatedAt,
+			})
+		}
+	}
+
+	return c.JSON(FieldHistoryResponse{
+		Error:   false,
+		Field:   field,
+		History: history,
+	})
+}
+
+// auditLogChanges aligns the old and new values of an audit log entry per field, sorted by field name
+func auditLogChanges(auditLog models.AuditLog) []FieldChange {
+	oldValues := decodeAuditValues(auditLog.OldValues)
+	newValues := decodeAuditValues(auditLog.NewValues)
+
+	fields := make([]string, 0, len(oldValues)+len(newValues))
+	for field := range oldValues {
+		fields = append(fields, field)
+	}
+	for field := range newValues {
+		if _, ok := oldValues[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := make([]FieldChange, 0, len(fields))
+	for _, field := range fields {
+		changes = append(changes, FieldChange{
+			Field: field,
+			Old:   jsonOrNull(oldValues[field]),
+			New:   jsonOrNull(newValues[field]),
+		})
+	}
+	return changes
+}
+
+// decodeAuditValues parses stored audit values into their fields, ignoring values that are not a JSON object
+func decodeAuditValues(values models.JSON) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if len(values) == 0 {
+		return fields
+	}
+	if err := json.Unmarshal(values, &fields); err != nil {
+		log.Printf("Error decoding audit log values: %v", err)
+		return map[string]json.RawMessage{}
+	}
+	return fields
+}
+
+// jsonOrNull returns value, or a JSON null when it is missing
+func jsonOrNull(value json.RawMessage) json.RawMessage {
+	if len(value) == 0 {
+		return json.RawMessage("null")
+	}
+	return value
+}
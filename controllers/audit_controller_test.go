@@ -0,0 +1,472 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// auditTables hold a manager of plant 1 and an audit log of their change to work order 1
+func auditTables() map[string]testTable {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	return map[string]testTable{
+		"users": {
+			columns: []string{"id", "plant_id", "username", "role"},
+			rows:    [][]driver.Value{{int64(1), int64(1), "manager", string(models.RoleProductionManager)}},
+		},
+		"audit_logs": {
+			columns: []string{"id", "plant_id", "user_id", "user_name", "action", "entity_type", "entity_id", "created_at"},
+			rows:    [][]driver.Value{{int64(5), int64(1), int64(1), "manager", string(models.ActionUpdate), "WorkOrder", int64(1), at}},
+		},
+		"work_orders": {
+			columns: []string{"id", "plant_id", "operator_id", "status"},
+			rows:    [][]driver.Value{{int64(1), int64(1), int64(2), string(models.StatusInProgress)}},
+		},
+	}
+}
+
+func TestAuditLogsAreScopedToThePlant(t *testing.T) {
+	useScriptedDB(t, storedRows(auditTables()))
+
+	ownPlant := testUser{1, models.RoleProductionManager, 1}
+	otherPlant := testUser{3, models.RoleProductionManager, 2}
+
+	tests := []struct {
+		name       string
+		route      string
+		handler    fiber.Handler
+		user       testUser
+		target     string
+		wantStatus int
+		wantLogs   int // length of audit_logs or logs in the response, -1 when there is none
+	}{
+		{"log of the plant", "/audit-logs/:id", GetAuditLogByID, ownPlant, "/audit-logs/5", fiber.StatusOK, -1},
+		{"log of another plant", "/audit-logs/:id", GetAuditLogByID, otherPlant, "/audit-logs/5", fiber.StatusNotFound, -1},
+		{"list of the plant", "/audit-logs", GetAuditLogs, ownPlant, "/audit-logs", fiber.StatusOK, 1},
+		{"list of another plant", "/audit-logs", GetAuditLogs, otherPlant, "/audit-logs", fiber.StatusOK, 0},
+		{"actions of a user of the plant", "/users/:id/actions", GetUserActions, ownPlant, "/users/1/actions", fiber.StatusOK, 1},
+		{"actions of a user of another plant", "/users/:id/actions", GetUserActions, otherPlant, "/users/1/actions", fiber.StatusNotFound, -1},
+		{"work order logs of the plant", "/work-orders/:id/logs", GetWorkOrderLogs, ownPlant, "/work-orders/1/logs", fiber.StatusOK, 1},
+		{"work order logs of another plant", "/work-orders/:id/logs", GetWorkOrderLogs, otherPlant, "/work-orders/1/logs", fiber.StatusNotFound, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := testRequest(t, tt.route, tt.handler, tt.user, fiber.MethodGet, tt.target)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if tt.wantLogs < 0 {
+				return
+			}
+			logs, ok := body["audit_logs"].([]interface{})
+			if !ok {
+				logs, _ = body["logs"].([]interface{})
+			}
+			if len(logs) != tt.wantLogs {
+				t.Errorf("got %d audit logs, want %d (%v)", len(logs), tt.wantLogs, body)
+			}
+		})
+	}
+}
+
+// auditLogTable is an audit_logs table written to while it is scanned
+type auditLogTable struct {
+	mu     sync.Mutex
+	rows   [][]driver.Value // id and created_at
+	nextID int64
+}
+
+// insert adds an audit log created at the time
+func (a *auditLogTable) insert(at time.Time) int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nextID++
+	a.rows = append(a.rows, []driver.Value{a.nextID, at})
+	return a.nextID
+}
+
+// queryLimit matches the page size of a query
+var queryLimit = regexp.MustCompile(`LIMIT (\d+)`)
+
+// respond answers the audit log page queries, newest first and after the cursor when given
+func (a *auditLogTable) respond(stmt dbtest.Statement) dbtest.Rows {
+	if !strings.HasPrefix(stmt.SQL, `SELECT * FROM "audit_logs"`) {
+		return dbtest.Rows{}
+	}
+	a.mu.Lock()
+	rows := append([][]driver.Value(nil), a.rows...)
+	a.mu.Unlock()
+
+	sort.Slice(rows, func(i, j int) bool {
+		ti, tj := rows[i][1].(time.Time), rows[j][1].(time.Time)
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return rows[i][0].(int64) > rows[j][0].(int64)
+	})
+
+	var page [][]driver.Value
+	for _, row := range rows {
+		if strings.Contains(stmt.SQL, "(created_at, id) < ($1, $2)") {
+			after, id := stmt.Args[0].(time.Time), stmt.Args[1].(int64)
+			at := row[1].(time.Time)
+			if at.After(after) || at.Equal(after) && row[0].(int64) >= id {
+				continue
+			}
+		}
+		page = append(page, row)
+	}
+	if match := queryLimit.FindStringSubmatch(stmt.SQL); match != nil {
+		if limit, _ := strconv.Atoi(match[1]); len(page) > limit {
+			page = page[:limit]
+		}
+	}
+	return dbtest.Rows{Columns: []string{"id", "created_at"}, Values: page}
+}
+
+func TestGetAuditLogsCursorScan(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		rows       int
+		limit      int
+		insertEach int  // audit logs written after every page
+		sameTime   bool // every row is created at the same time, the id breaks the tie
+	}{
+		{"no writes", 7, 3, 0, false},
+		{"writes during the scan", 7, 3, 2, false},
+		{"pages fill exactly", 6, 3, 4, false},
+		{"rows created at the same time", 7, 2, 1, true},
+		{"single page", 2, 10, 5, false},
+		{"empty table", 0, 3, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &auditLogTable{}
+			var want []int64
+			for i := 0; i < tt.rows; i++ {
+				at := start
+				if !tt.sameTime {
+					at = start.Add(time.Duration(i) * time.Minute)
+				}
+				want = append([]int64{table.insert(at)}, want...)
+			}
+			useScriptedDB(t, table.respond)
+
+			var scanned []int64
+			seen := map[int64]bool{}
+			cursor, writes := "", 0
+			for pages := 0; ; pages++ {
+				if pages > tt.rows+1 {
+					t.Fatalf("scan did not end after %d pages", pages)
+				}
+				target := "/audit-logs?limit=" + strconv.Itoa(tt.limit) + "&after=" + cursor
+				status, body := testRequest(t, "/audit-logs", GetAuditLogs, testUser{1, models.RoleProductionManager, 0}, fiber.MethodGet, target)
+				if status != fiber.StatusOK {
+					t.Fatalf("status = %d, want %d (%v)", status, fiber.StatusOK, body)
+				}
+				if body["pagination"] != nil {
+					t.Errorf("cursor page has offset pagination %v", body["pagination"])
+				}
+				logs, _ := body["audit_logs"].([]interface{})
+				for _, entry := range logs {
+					id := int64(entry.(map[string]interface{})["id"].(float64))
+					if seen[id] {
+						t.Errorf("audit log %d scanned twice", id)
+					}
+					seen[id] = true
+					scanned = append(scanned, id)
+				}
+
+				// Newer audit logs written meanwhile do not shift the following pages
+				for i := 0; i < tt.insertEach; i++ {
+					writes++
+					table.insert(start.Add(time.Duration(tt.rows+writes) * time.Minute))
+				}
+
+				next, _ := body["next_cursor"].(string)
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+
+			if len(scanned) != len(want) {
+				t.Fatalf("scanned %v, want %v", scanned, want)
+			}
+			for i := range want {
+				if scanned[i] != want[i] {
+					t.Fatalf("scanned %v, want %v", scanned, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeAuditCursor(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 123456789, time.UTC)
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := []struct {
+		name    string
+		cursor  string
+		wantAt  time.Time
+		wantID  uint
+		wantErr bool
+	}{
+		{"round trip", encodeAuditCursor(at, 42), at, 42, false},
+		{"other time zone", encodeAuditCursor(at.In(time.FixedZone("WIB", 7*60*60)), 42), at, 42, false},
+		{"not base64", "not a cursor!", time.Time{}, 0, true},
+		{"missing separator", encode("2026-03-02T09:00:00Z"), time.Time{}, 0, true},
+		{"invalid time", encode("yesterday|42"), time.Time{}, 0, true},
+		{"invalid id", encode("2026-03-02T09:00:00Z|-1"), time.Time{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAt, gotID, err := decodeAuditCursor(tt.cursor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if !gotAt.Equal(tt.wantAt) || gotID != tt.wantID {
+				t.Errorf("decoded %s, %d, want %s, %d", gotAt, gotID, tt.wantAt, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestGetAuditLogsInvalidCursor(t *testing.T) {
+	recorder := useScriptedDB(t, nil)
+
+	status, body := testRequest(t, "/audit-logs", GetAuditLogs, testUser{1, models.RoleProductionManager, 0}, fiber.MethodGet, "/audit-logs?after=bm90LWEtY3Vyc29y")
+	if status != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d (%v)", status, fiber.StatusBadRequest, body)
+	}
+	if queries := recorder.Find(`FROM "audit_logs"`); len(queries) > 0 {
+		t.Errorf("audit logs queried with an invalid cursor: %+v", queries)
+	}
+}
+
+// renamedUserTables is a database holding user 1 of plant 1, who can be renamed, and
+// the audit logs written to it
+type renamedUserTables struct {
+	mu       sync.Mutex
+	username string
+	logs     [][]driver.Value // id, plant_id, user_id, user_name, action, entity_type, entity_id
+}
+
+// insertColumns matches the column list of an INSERT statement
+var insertColumns = regexp.MustCompile(`^INSERT INTO "\w+" \(([^)]*)\)`)
+
+func (r *renamedUserTables) respond(stmt dbtest.Statement) dbtest.Rows {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case strings.HasPrefix(stmt.SQL, `INSERT INTO "audit_logs"`):
+		value := map[string]driver.Value{}
+		for i, column := range strings.Split(insertColumns.FindStringSubmatch(stmt.SQL)[1], ",") {
+			value[strings.Trim(column, `"`)] = stmt.Args[i]
+		}
+		id := int64(len(r.logs) + 1)
+		r.logs = append(r.logs, []driver.Value{id, value["plant_id"], value["user_id"], value["user_name"], value["action"], value["entity_type"], value["entity_id"]})
+		return dbtest.Rows{Columns: []string{"id"}, Values: [][]driver.Value{{id}}}
+	case strings.HasPrefix(stmt.SQL, `UPDATE "users" SET "username"=$1`):
+		r.username = stmt.Args[0].(string)
+		return dbtest.Rows{RowsAffected: 1}
+	case strings.HasPrefix(stmt.SQL, `SELECT * FROM "users"`):
+		return dbtest.Rows{
+			Columns: []string{"id", "plant_id", "username", "role"},
+			Values:  [][]driver.Value{{int64(1), int64(1), r.username, string(models.RoleProductionManager)}},
+		}
+	case strings.HasPrefix(stmt.SQL, `SELECT * FROM "audit_logs"`):
+		return dbtest.Rows{
+			Columns: []string{"id", "plant_id", "user_id", "user_name", "action", "entity_type", "entity_id"},
+			Values:  append([][]driver.Value(nil), r.logs...),
+		}
+	}
+	return dbtest.Rows{}
+}
+
+func TestAuditLogsKeepTheNameOfARenamedUser(t *testing.T) {
+	tables := &renamedUserTables{username: "manager"}
+	useScriptedDB(t, tables.respond)
+
+	if err := auditService.CreateLog(database.DB, 1, models.ActionUpdate, "WorkOrder", 1, nil, nil, "Target raised"); err != nil {
+		t.Fatalf("creating audit log: %v", err)
+	}
+	if err := database.DB.Model(&models.User{ID: 1}).Update("username", "plant-lead").Error; err != nil {
+		t.Fatalf("renaming user: %v", err)
+	}
+
+	manager := testUser{1, models.RoleProductionManager, 0}
+	status, body := testRequest(t, "/audit-logs/:id", GetAuditLogByID, manager, fiber.MethodGet, "/audit-logs/1")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", status, body)
+	}
+	auditLog, _ := body["audit_log"].(map[string]interface{})
+	if auditLog["user_name"] != "manager" {
+		t.Errorf("user_name = %v, want the name at the time of the action, manager", auditLog["user_name"])
+	}
+	// The user stays loaded to link to, under the current name
+	user, _ := auditLog["user"].(map[string]interface{})
+	if user["username"] != "plant-lead" {
+		t.Errorf("user.username = %v, want the current name plant-lead", user["username"])
+	}
+
+	status, body = testRequest(t, "/audit-logs", GetAuditLogs, manager, fiber.MethodGet, "/audit-logs")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200 (%v)", status, body)
+	}
+	logs, _ := body["audit_logs"].([]interface{})
+	if len(logs) != 1 || logs[0].(map[string]interface{})["user_name"] != "manager" {
+		t.Errorf("audit logs = %v, want the one by manager", logs)
+	}
+}
+
+func TestAuditLogPagesWithEqualTimestamps(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	columns := []string{"id", "user_id", "entity_type", "entity_id", "created_at"}
+	var logs [][]driver.Value
+	for id := int64(1); id <= 8; id++ {
+		logs = append(logs, []driver.Value{id, int64(1), "WorkOrder", int64(1), at})
+	}
+	stored := storedRows(auditTables())
+	useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+		switch {
+		case strings.HasPrefix(stmt.SQL, `SELECT count(*) FROM "audit_logs"`):
+			return dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(len(logs))}}}
+		case strings.HasPrefix(stmt.SQL, `SELECT * FROM "audit_logs"`):
+			return dbtest.Rows{Columns: columns, Values: orderedPage(columns, logs, stmt.SQL)}
+		}
+		return stored(stmt)
+	})
+
+	tests := []struct {
+		name    string
+		route   string
+		handler fiber.Handler
+		target  string
+		key     string
+	}{
+		{"audit logs", "/audit-logs", GetAuditLogs, "/audit-logs?limit=3&page=", "audit_logs"},
+		{"actions of a user", "/users/:id/actions", GetUserActions, "/users/1/actions?limit=3&page=", "audit_logs"},
+		{"work order logs", "/work-orders/:id/logs", GetWorkOrderLogs, "/work-orders/1/logs?limit=3&page=", "logs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Rows tied on created_at come back in any order, only the id keeps the pages apart
+			for scan := 0; scan < 10; scan++ {
+				var scanned []int64
+				for page := 1; page <= 3; page++ {
+					status, body := testRequest(t, tt.route, tt.handler, testUser{1, models.RoleProductionManager, 0}, fiber.MethodGet, tt.target+strconv.Itoa(page))
+					if status != fiber.StatusOK {
+						t.Fatalf("page %d: status = %d, want 200 (%v)", page, status, body)
+					}
+					entries, _ := body[tt.key].([]interface{})
+					for _, entry := range entries {
+						scanned = append(scanned, int64(entry.(map[string]interface{})["id"].(float64)))
+					}
+				}
+				want := []int64{8, 7, 6, 5, 4, 3, 2, 1}
+				if !slices.Equal(scanned, want) {
+					t.Fatalf("paged through %v, want %v without gaps or repeats", scanned, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditLogsInBothEnvelopes(t *testing.T) {
+	useScriptedDB(t, storedRows(auditTables()))
+	manager := testUser{1, models.RoleProductionManager, 1}
+
+	// serve answers through the envelope middleware as main.go mounts it
+	serve := func(target, accept string) (int, map[string]json.RawMessage) {
+		t.Helper()
+		app := fiber.New()
+		app.Use(middleware.Envelope())
+		app.Mount("/", testApp("/audit-logs/:id?", func(c *fiber.Ctx) error {
+			if c.Params("id") != "" {
+				return GetAuditLogByID(c)
+			}
+			return GetAuditLogs(c)
+		}, manager, fiber.MethodGet))
+
+		req := httptest.NewRequest(fiber.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set(fiber.HeaderAccept, accept)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("serving %s: %v", target, err)
+		}
+		defer resp.Body.Close()
+		var body map[string]json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decoding %s: %v", target, err)
+		}
+		return resp.StatusCode, body
+	}
+
+	status, legacy := serve("/audit-logs?limit=5", "")
+	if status != fiber.StatusOK || string(legacy["error"]) != "false" || legacy["audit_logs"] == nil || legacy["success"] != nil {
+		t.Fatalf("legacy list = %d %s", status, legacy)
+	}
+
+	for _, tt := range []struct{ name, target, accept string }{
+		{"query parameter", "/audit-logs?limit=5&envelope=v2", ""},
+		{"accept header", "/audit-logs?limit=5", middleware.EnvelopeV2MediaType},
+	} {
+		t.Run("list selected by "+tt.name, func(t *testing.T) {
+			status, enveloped := serve(tt.target, tt.accept)
+			if status != fiber.StatusOK || string(enveloped["success"]) != "true" || enveloped["error"] != nil {
+				t.Fatalf("enveloped list = %d %s", status, enveloped)
+			}
+			// The same list moves to data and its pagination to meta
+			if string(enveloped["data"]) != string(legacy["audit_logs"]) {
+				t.Errorf("data = %s, want the legacy audit_logs %s", enveloped["data"], legacy["audit_logs"])
+			}
+			var meta map[string]json.RawMessage
+			if err := json.Unmarshal(enveloped["meta"], &meta); err != nil || string(meta["pagination"]) != string(legacy["pagination"]) {
+				t.Errorf("meta = %s, want the legacy pagination %s", enveloped["meta"], legacy["pagination"])
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		legacyStatus, legacy := serve("/audit-logs/99", "")
+		status, enveloped := serve("/audit-logs/99?envelope=v2", "")
+		if legacyStatus != fiber.StatusNotFound || status != fiber.StatusNotFound {
+			t.Fatalf("status = %d legacy and %d enveloped, want 404", legacyStatus, status)
+		}
+		if string(legacy["error"]) != "true" || legacy["msg"] == nil {
+			t.Errorf("legacy error = %s", legacy)
+		}
+		var envelopeErr struct{ Code, Message string }
+		if err := json.Unmarshal(enveloped["error"], &envelopeErr); err != nil {
+			t.Fatalf("enveloped error = %s: %v", enveloped, err)
+		}
+		if string(enveloped["success"]) != "false" || envelopeErr.Code != "not_found" || `"`+envelopeErr.Message+`"` != string(legacy["msg"]) {
+			t.Errorf("enveloped error = %s, want not_found with the legacy msg %s", enveloped, legacy["msg"])
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/locale"
+	"github.com/dawamr/work-order-system-go/utils/pdf"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// AuditReport is the lifecycle of one work order as compiled for a dispute
+type AuditReport struct {
+	WorkOrder     WorkOrderDTO       `json:"work_order"`
+	StatusHistory []StatusHistoryDTO `json:"status_history"` // chronological
+	Progress      []ProgressDTO      `json:"progress"`       // chronological
+	AuditLogs     []AuditLogDTO      `json:"audit_logs"`     // chronological
+	GeneratedAt   time.Time          `json:"generated_at"`
+	GeneratedBy   uint               `json:"generated_by"`
+}
+
+// AuditReportResponse represents a work order audit report with its integrity check values
+type AuditReportResponse struct {
+	Error     bool        `json:"error"`
+	Report    AuditReport `json:"report"`
+	Checksum  string      `json:"checksum"`            // hex SHA-256 of the report as canonical JSON
+	Signature string      `json:"signature,omitempty"` // hex HMAC-SHA256 of the same bytes, when a signing key is configured
+}
+
+// @Summary Get work order audit report
+// @Description Get the tamper-evident lifecycle report of a work order: its audit logs, status history and progress entries. The checksum is the SHA-256 of the report as canonical JSON (object keys sorted, no whitespace, no HTML escaping); when AUDIT_REPORT_SIGNING_KEY is set, the signature is the HMAC-SHA256 of the same bytes. With format=pdf the report is downloaded as a PDF with the checksum and signature printed. (Production Manager only)
+// @Tags audit-logs
+// @Accept json
+// @Produce json,application/pdf
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param format query string false "json (default) or pdf"
+// @Param locale query string false "Date format of the PDF: iso (default), raw, en-US or id-ID; falls back to Accept-Language"
+// @Success 200 {object} AuditReportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/audit-report [get]
+func GetWorkOrderAuditReport(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	if !hasUser {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "pdf" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "format must be json or pdf",
+		})
+	}
+
+	var workOrder models.WorkOrder
+	result := preloadUnscoped(getDB(c), "Operator").Preload("Tags").First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	var history []models.WorkOrderStatusHistory
+	if err := getDB(c).Where("work_order_id = ?", workOrder.ID).Order("created_at ASC, id ASC").Find(&history).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching status history",
+		})
+	}
+
+	var progress []models.WorkOrderProgress
+	if err := getDB(c).Where("work_order_id = ?", workOrder.ID).Order("created_at ASC, id ASC").Find(&progress).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching progress entries",
+		})
+	}
+
+	var auditLogs []models.AuditLog
+	if err := getDB(c).Where("entity_type = ? AND entity_id = ?", "WorkOrder", workOrder.ID).
+		Order("created_at ASC, id ASC").Find(&auditLogs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching audit logs",
+		})
+	}
+
+	report := AuditReport{
+		WorkOrder:     toWorkOrderDTO(workOrder),
+		StatusHistory: toStatusHistoryDTOs(history),
+		Progress:      toProgressDTOs(progress),
+		AuditLogs:     toAuditLogDTOs(auditLogs),
+		GeneratedAt:   time.Now(),
+		GeneratedBy:   userID,
+	}
+	content, err := canonicalJSON(report)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error encoding audit report",
+		})
+	}
+	response := AuditReportResponse{Error: false, Report: report}
+	checksum := sha256.Sum256(content)
+	response.Checksum = hex.EncodeToString(checksum[:])
+	if key := config.AppConfig.AuditReportSigningKey; key != "" {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(content)
+		response.Signature = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	logReadAccess(c, "Exported work order audit report")
+
+	if format == "pdf" {
+		document := auditReportPDF(response, locale.FromRequest(c.Query("locale"), c.Get(fiber.HeaderAcceptLanguage)))
+		filename := fmt.Sprintf("%s-audit-report-%s.pdf", workOrder.WorkOrderNumber, report.GeneratedAt.Format(time.DateOnly))
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		return document.Write(c)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// canonicalJSON encodes a value as JSON with sorted object keys, without whitespace
+// and HTML escaping, so a client can reproduce the bytes from any decoded copy
+func canonicalJSON(v interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decoding into generic values sorts the object keys when encoding again,
+	// numbers are kept as written
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(generic); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// auditReportPDF renders an audit report as a text document ending with its checksum and signature
+func auditReportPDF(response AuditReportResponse, format locale.Locale) *pdf.Document {
+	report := response.Report
+	workOrder := report.WorkOrder
+	var document pdf.Document
+
+	document.AddLine("Work Order Audit Report")
+	document.AddLine("")
+	document.AddLine(fmt.Sprintf("Work Order: %s", workOrder.WorkOrderNumber))
+	document.AddLine(fmt.Sprintf("Product: %s", workOrder.ProductName))
+	document.AddLine(fmt.Sprintf("Quantity: %d of %d %s", workOrder.Quantity, workOrder.TargetQuantity, workOrder.Unit))
+	document.AddLine(fmt.Sprintf("Status: %s", workOrder.Status))
+	if workOrder.Operator != nil {
+		document.AddLine(fmt.Sprintf("Operator: %s", workOrder.Operator.Username))
+	}
+	document.AddLine(fmt.Sprintf("Production Deadline: %s", format.DateTime(workOrder.ProductionDeadline)))
+	document.AddLine(fmt.Sprintf("Created At: %s", format.DateTime(workOrder.CreatedAt)))
+	document.AddLine(fmt.Sprintf("Generated At: %s (user %d)", format.DateTime(report.GeneratedAt), report.GeneratedBy))
+
+	document.AddLine("")
+	document.AddLine("Status History")
+	if len(report.StatusHistory) == 0 {
+		document.AddLine("  none")
+	}
+	for _, entry := range report.StatusHistory {
+		line := fmt.Sprintf("  %s  %s, quantity %d", format.DateTime(entry.CreatedAt), entry.Status, entry.Quantity)
+		if entry.Note != "" {
+			line += ": " + entry.Note
+		}
+		document.AddLine(line)
+	}
+
+	document.AddLine("")
+	document.AddLine("Progress")
+	if len(report.Progress) == 0 {
+		document.AddLine("  none")
+	}
+	for _, entry := range report.Progress {
+		line := fmt.Sprintf("  %s  %d: %s", format.DateTime(entry.CreatedAt), entry.ProgressQuantity, entry.ProgressDesc)
+		if entry.ApprovedAt != nil {
+			line += fmt.Sprintf(" (approved %s)", format.DateTime(*entry.ApprovedAt))
+		}
+		document.AddLine(line)
+	}
+
+	document.AddLine("")
+	document.AddLine("Audit Log")
+	if len(report.AuditLogs) == 0 {
+		document.AddLine("  none")
+	}
+	for _, entry := range report.AuditLogs {
+		line := fmt.Sprintf("  %s  %s by %s", format.DateTime(entry.CreatedAt), entry.Action, entry.UserName)
+		if entry.Note != "" {
+			line += ": " + entry.Note
+		}
+		document.AddLine(line)
+
+		changes := auditLogChanges(models.AuditLog{OldValues: entry.OldValues, NewValues: entry.NewValues})
+		for _, change := range changes {
+			document.AddLine(fmt.Sprintf("      %s: %s -> %s", change.Field, change.Old, change.New))
+		}
+	}
+
+	document.AddLine("")
+	document.AddLine("Integrity")
+	document.AddLine("  Checksum (SHA-256 of the report as canonical JSON): " + response.Checksum)
+	signature := response.Signature
+	if signature == "" {
+		signature = "unsigned"
+	}
+	document.AddLine("  Signature (HMAC-SHA256): " + signature)
+	return &document
+}
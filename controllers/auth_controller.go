@@ -1,12 +1,16 @@
 package controllers
 
 import (
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/dawamr/work-order-system-go/database"
 	"github.com/dawamr/work-order-system-go/middleware"
 	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/validator"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // LoginRequest represents the login request body
@@ -20,16 +24,17 @@ type LoginResponse struct {
 	Error bool `json:"error"`
 	Token string `json:"token"`
 	User  struct {
-		ID       uint        `json:"id"`
-		Username string      `json:"username"`
-		Role     models.Role `json:"role"`
+		ID                 uint        `json:"id"`
+		Username           string      `json:"username"`
+		Role               models.Role `json:"role"`
+		MustChangePassword bool        `json:"must_change_password"`
 	} `json:"user"`
 }
 
 // RegisterRequest represents the register request body
 type RegisterRequest struct {
 	Username string      `json:"username" validate:"required,min=3,max=50"`
-	Password string      `json:"password" validate:"required,min=6"`
+	Password string      `json:"password" validate:"required"` // checked against the password policy
 	Role     models.Role `json:"role" validate:"required,oneof=production_manager operator"`
 }
 
@@ -44,10 +49,63 @@ type RegisterResponse struct {
 	} `json:"user"`
 }
 
+// ChangePasswordRequest represents the change password request body
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// MeResponse represents the current user response
+type MeResponse struct {
+	Error bool `json:"error"`
+	User  struct {
+		ID       uint        `json:"id"`
+		Username string      `json:"username"`
+		Role     models.Role `json:"role"`
+	} `json:"user"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 // ErrorResponse represents the error response
 type ErrorResponse struct {
 	Error bool   `json:"error"`
 	Msg   string `json:"msg"`
+	Code  string `json:"code,omitempty"` // machine readable error code, set for errors clients handle specifically
+}
+
+// Error codes returned in ErrorResponse.Code
+const (
+	CodeDuplicateWorkOrderNumber = "duplicate_work_order_number"
+	CodeUsernameTaken            = "username_taken"
+	CodeInvalidStatusTransition  = "invalid_status_transition"
+	CodeAlreadyCompleted         = "already_completed"
+	CodeDuplicateActiveOrder     = "duplicate_active_order"
+	CodeProgressNotApproved      = "progress_not_approved"
+	CodeInvalidID                = "invalid_id"
+	CodeOperatorNotFound         = "operator_not_found"
+	CodeTargetBelowProduced      = "target_below_produced"
+	CodeTargetChangeBlocked      = "target_change_blocked"
+	CodeDeadlineOutsideWindow    = "deadline_outside_window"
+)
+
+// ValidationErrorResponse represents an error response with per-field validation errors
+type ValidationErrorResponse struct {
+	Error  bool                   `json:"error"`
+	Msg    string                 `json:"msg"`
+	Errors []validator.FieldError `json:"errors"`
+}
+
+// ChangePasswordResponse represents a change password response
+type ChangePasswordResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+	Token   string `json:"token"` // fresh token without the password change restriction
+}
+
+// MessageResponse represents a simple success response
+type MessageResponse struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
 }
 
 // @Summary Login user
@@ -72,8 +130,7 @@ func Login(c *fiber.Ctx) error {
 
 	// Find user by username
 	var user models.User
-	result := database.DB.Where("username = ?", req.Username).First(&user)
-	log.Println(result.Error != nil)
+	result := getDB(c).Where("username = ?", req.Username).First(&user)
 	if result.Error != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
 			Error: true,
@@ -83,17 +140,17 @@ func Login(c *fiber.Ctx) error {
 
 	// Check password
 	if err := user.CheckPassword(req.Password); err != nil {
-		log.Println(err)
-		log.Println(user.Password)
 		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Invalid credentials",
 		})
 	}
 
+	// Record the login time without blocking or failing the login
+	go recordLastLogin(user.ID, time.Now())
+
 	// Generate JWT token
 	token, err := middleware.GenerateToken(&user)
-	log.Println(token)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
@@ -106,13 +163,15 @@ func Login(c *fiber.Ctx) error {
 		Error: false,
 		Token: token,
 		User: struct {
-			ID       uint        `json:"id"`
-			Username string      `json:"username"`
-			Role     models.Role `json:"role"`
+			ID                 uint        `json:"id"`
+			Username           string      `json:"username"`
+			Role               models.Role `json:"role"`
+			MustChangePassword bool        `json:"must_change_password"`
 		}{
-			ID:       user.ID,
-			Username: user.Username,
-			Role:     user.Role,
+			ID:                 user.ID,
+			Username:           user.Username,
+			Role:               user.Role,
+			MustChangePassword: user.MustChangePassword,
 		},
 	})
 }
@@ -125,6 +184,7 @@ func Login(c *fiber.Ctx) error {
 // @Param request body RegisterRequest true "Registration details"
 // @Success 201 {object} RegisterResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/register [post]
 func Register(c *fiber.Ctx) error {
@@ -137,13 +197,23 @@ func Register(c *fiber.Ctx) error {
 		})
 	}
 
+	// Check password against the password policy
+	if errs := validator.DefaultPasswordPolicy().Validate("password", req.Password); len(errs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ValidationErrorResponse{
+			Error:  true,
+			Msg:    "Password does not meet the password policy",
+			Errors: errs,
+		})
+	}
+
 	// Check if username already exists
 	var existingUser models.User
-	result := database.DB.Where("username = ?", req.Username).First(&existingUser)
+	result := getDB(c).Where("username = ?", req.Username).First(&existingUser)
 	if result.Error == nil {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Username already exists",
+			Code:  CodeUsernameTaken,
 		})
 	}
 
@@ -155,7 +225,15 @@ func Register(c *fiber.Ctx) error {
 	}
 
 	// Save user to database
-	if err := database.DB.Create(&user).Error; err != nil {
+	if err := getDB(c).Create(&user).Error; err != nil {
+		// Another registration may have taken the username after the check above
+		if database.IsUniqueViolation(err) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Username already exists",
+				Code:  CodeUsernameTaken,
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Error creating user",
@@ -186,3 +264,157 @@ func Register(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// @Summary Change password
+// @Description Change the password of the current user. Not allowed with an impersonation token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} ChangePasswordResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/password [put]
+func ChangePassword(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	// A manager impersonating the user acts as them but never takes over their credentials
+	if actorID(c) != userID {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Password can not be changed while impersonating a user",
+		})
+	}
+
+	// Parse request body
+	var req ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid request body",
+		})
+	}
+
+	// Find current user
+	var user models.User
+	if err := getDB(c).First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "User not found",
+		})
+	}
+
+	// Verify current password
+	if err := user.CheckPassword(req.CurrentPassword); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Current password is incorrect",
+		})
+	}
+
+	// Check new password against the password policy
+	if errs := validator.DefaultPasswordPolicy().Validate("new_password", req.NewPassword); len(errs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ValidationErrorResponse{
+			Error:  true,
+			Msg:    "Password does not meet the password policy",
+			Errors: errs,
+		})
+	}
+
+	// Password is re-hashed by the BeforeSave hook
+	user.Password = req.NewPassword
+	user.MustChangePassword = false
+	if err := getDB(c).Save(&user).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error updating password",
+		})
+	}
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		actorID(c),
+		models.ActionUpdate,
+		"User",
+		user.ID,
+		nil,
+		nil,
+		fmt.Sprintf("User %s changed password", user.Username),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	// Issue a new token so a forced password change takes effect without logging in again
+	token, err := middleware.GenerateToken(&user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error generating token",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ChangePasswordResponse{
+		Error:   false,
+		Message: "Password changed successfully",
+		Token:   token,
+	})
+}
+
+// recordLastLogin stores the last successful login time of a user
+func recordLastLogin(userID uint, loginAt time.Time) {
+	// UpdateColumn skips the BeforeSave hook so the password hash is untouched
+	if err := database.DB.Model(&models.User{}).
+		Where("id = ?", userID).
+		UpdateColumn("last_login_at", loginAt).Error; err != nil {
+		log.Printf("Error recording last login for user %d: %v", userID, err)
+	}
+}
+
+// @Summary Get current user
+// @Description Verify the token and return the current user and the token expiry
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} MeResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/me [get]
+func Me(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	// Re-check the database, the user may have been deleted since the token was issued
+	var user models.User
+	result := getDB(c).First(&user, userID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "User no longer exists",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching user",
+		})
+	}
+
+	var response MeResponse
+	response.User.ID = user.ID
+	response.User.Username = user.Username
+	response.User.Role = user.Role
+	if expiresAt, ok := c.Locals("token_expires_at").(time.Time); ok {
+		response.ExpiresAt = expiresAt
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// operatorUser is the operator a Production Manager impersonates in the tests
+var operatorUser = models.User{ID: 2, Username: "operator", Role: models.RoleOperator, PlantID: 1}
+
+// managerID is the Production Manager impersonating operatorUser
+const managerID uint = 1
+
+// useTestTokens signs the tokens of the test with a test secret
+func useTestTokens(t *testing.T) {
+	t.Helper()
+	previous := config.AppConfig
+	config.AppConfig.JWTSecret = "test-secret"
+	config.AppConfig.JWTIssuer = ""
+	config.AppConfig.JWTAudience = ""
+	config.AppConfig.TokenExpiresIn = 1
+	config.AppConfig.ImpersonationTTL = 15
+	t.Cleanup(func() { config.AppConfig = previous })
+}
+
+// operatorTokens returns the operator's own token and a manager's impersonation token for the operator
+func operatorTokens(t *testing.T) (own, impersonation string) {
+	t.Helper()
+	own, err := middleware.GenerateToken(&operatorUser)
+	if err != nil {
+		t.Fatalf("generating token: %v", err)
+	}
+	impersonation, _, err = middleware.GenerateImpersonationToken(&operatorUser, managerID)
+	if err != nil {
+		t.Fatalf("generating impersonation token: %v", err)
+	}
+	return own, impersonation
+}
+
+// userRows are the manager and the operator, with work order 1 assigned to the operator
+var userRows = storedRows(map[string]testTable{
+	"users": {
+		columns: []string{"id", "plant_id", "username", "role"},
+		rows: [][]driver.Value{
+			{int64(managerID), int64(1), "manager", string(models.RoleProductionManager)},
+			{int64(operatorUser.ID), int64(1), "operator", string(models.RoleOperator)},
+		},
+	},
+	"work_orders": {
+		columns: []string{"id", "plant_id", "operator_id", "status"},
+		rows:    [][]driver.Value{{int64(1), int64(1), int64(operatorUser.ID), string(models.StatusInProgress)}},
+	},
+})
+
+// serveWithToken serves one request through middleware.Protected and PlantScope
+func serveWithToken(t *testing.T, route string, handler fiber.Handler, method, target, token, body string) (int, string) {
+	t.Helper()
+	app := fiber.New()
+	app.Add(method, route, middleware.Protected(), middleware.PlantScope(), handler)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("serving %s %s: %v", method, target, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, resp.Header.Get("X-Impersonated-By")
+}
+
+func TestImpersonatedActionsAreAuditedAsTheManager(t *testing.T) {
+	useTestTokens(t)
+	own, impersonation := operatorTokens(t)
+
+	tests := []struct {
+		name             string
+		token            string
+		wantImpersonator string
+		wantActor        int64
+		wantActorName    string
+	}{
+		{"operator's own token", own, "", int64(operatorUser.ID), "operator"},
+		{"impersonation token", impersonation, "1", int64(managerID), "manager"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, userRows)
+
+			status, impersonator := serveWithToken(t, "/work-orders/:id/logs", CreateWorkOrderLog, fiber.MethodPost, "/work-orders/1/logs", tt.token, `{"note":"checked the line"}`)
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want %d", status, fiber.StatusOK)
+			}
+			if impersonator != tt.wantImpersonator {
+				t.Errorf("X-Impersonated-By = %q, want %q", impersonator, tt.wantImpersonator)
+			}
+
+			inserts := recorder.Find(`INSERT INTO "audit_logs"`)
+			if len(inserts) != 1 {
+				t.Fatalf("audit log not written: %+v", recorder.Statements())
+			}
+			if !hasArgs(inserts[0], tt.wantActor, tt.wantActorName) {
+				t.Errorf("audit log %v, want it attributed to user %d (%s)", inserts[0].Args, tt.wantActor, tt.wantActorName)
+			}
+		})
+	}
+}
+
+func TestChangePasswordWhileImpersonating(t *testing.T) {
+	useTestTokens(t)
+	own, impersonation := operatorTokens(t)
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+		wantLookup bool
+	}{
+		{"impersonation token is refused", impersonation, fiber.StatusForbidden, false},
+		{"operator's own token checks the password", own, fiber.StatusUnauthorized, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, userRows)
+
+			status, _ := serveWithToken(t, "/auth/password", ChangePassword, fiber.MethodPut, "/auth/password", tt.token, `{"current_password":"wrong","new_password":"N3w-Passw0rd!"}`)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if lookedUp := len(recorder.Find(`FROM "users"`)) > 0; lookedUp != tt.wantLookup {
+				t.Errorf("user looked up = %t, want %t", lookedUp, tt.wantLookup)
+			}
+			if updates := recorder.Find(`UPDATE "users"`); len(updates) > 0 {
+				t.Errorf("password changed: %+v", updates)
+			}
+		})
+	}
+}
+
+// duplicateInsert answers as a database holding operator 2 whose unique constraint
+// on the table is violated by the insert, as by a concurrent request
+func duplicateInsert(table string) dbtest.Responder {
+	operators := storedRows(map[string]testTable{
+		"users": {
+			columns: []string{"id", "plant_id", "username", "role", "active"},
+			rows:    [][]driver.Value{{int64(2), int64(1), "operator", string(models.RoleOperator), true}},
+		},
+	})
+	return func(stmt dbtest.Statement) dbtest.Rows {
+		switch {
+		case strings.HasPrefix(stmt.SQL, `INSERT INTO "`+table+`"`):
+			return dbtest.Rows{Err: &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}}
+		case strings.Contains(stmt.SQL, "username = $1"):
+			// The username check before the insert finds nothing
+			return dbtest.Rows{}
+		}
+		return operators(stmt)
+	}
+}
+
+func TestUniqueViolationIsAConflict(t *testing.T) {
+	deadline := time.Now().Add(48 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		route    string
+		handler  fiber.Handler
+		user     testUser
+		table    string
+		body     string
+		wantCode string
+	}{
+		{
+			name:     "work order number taken",
+			route:    "/work-orders",
+			handler:  CreateWorkOrder,
+			user:     testUser{1, models.RoleProductionManager, 0},
+			table:    "work_orders",
+			body:     `{"product_name":"Widget","target_quantity":10,"operator_id":2,"production_deadline":"` + deadline + `"}`,
+			wantCode: CodeDuplicateWorkOrderNumber,
+		},
+		{
+			name:     "username taken",
+			route:    "/auth/register",
+			handler:  Register,
+			table:    "users",
+			body:     `{"username":"operator","password":"Secure-pass1","role":"operator"}`,
+			wantCode: CodeUsernameTaken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.AppConfig
+			config.AppConfig.DeadlineMinLeadHours = 0
+			config.AppConfig.DeadlineMaxHorizonDays = 0
+			config.AppConfig.MaxActiveOrdersPerOperator = 0
+			config.AppConfig.PreventDuplicateActiveOrders = false
+			t.Cleanup(func() { config.AppConfig = previous })
+			useScriptedDB(t, duplicateInsert(tt.table))
+
+			status, body := testRequestBody(t, tt.route, tt.handler, tt.user, fiber.MethodPost, tt.route, tt.body)
+			if status != fiber.StatusConflict || body["code"] != tt.wantCode {
+				t.Errorf("response %d %v, want 409 with code %s", status, body, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFailedLoginLogsNoCredentials(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("Secret-Pass1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hashing password: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong password", "operator", "Wrong-Pass2"},
+		{"unknown user", "nobody", "Wrong-Pass2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+				if strings.HasPrefix(stmt.SQL, `SELECT * FROM "users"`) && hasArgs(stmt, "operator") {
+					return dbtest.Rows{
+						Columns: []string{"id", "plant_id", "username", "role", "password"},
+						Values:  [][]driver.Value{{int64(2), int64(1), "operator", string(models.RoleOperator), string(hash)}},
+					}
+				}
+				return dbtest.Rows{}
+			})
+
+			var logged bytes.Buffer
+			previous := log.Writer()
+			log.SetOutput(&logged)
+			t.Cleanup(func() { log.SetOutput(previous) })
+
+			body := `{"username":"` + tt.username + `","password":"` + tt.password + `"}`
+			if status, response := testRequestBody(t, "/auth/login", Login, testUser{}, fiber.MethodPost, "/auth/login", body); status != fiber.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401 (%v)", status, response)
+			}
+			for _, secret := range []string{string(hash), tt.password} {
+				if strings.Contains(logged.String(), secret) {
+					t.Errorf("log %q contains %q", logged.String(), secret)
+				}
+			}
+		})
+	}
+}
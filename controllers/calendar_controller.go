@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"log"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/utils/calendar"
+	"github.com/gofiber/fiber/v2"
+)
+
+// NextBusinessDayResponse represents a business day calculation response
+type NextBusinessDayResponse struct {
+	Error bool   `json:"error"`
+	Days  int    `json:"days"`
+	Date  string `json:"date"` // YYYY-MM-DD
+}
+
+// @Summary Get next business day
+// @Description Get the date that is N business days from today, skipping the configured weekend and holidays
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Number of business days to add (default: 1)"
+// @Success 200 {object} NextBusinessDayResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /calendar/next-business-day [get]
+func GetNextBusinessDay(c *fiber.Ctx) error {
+	days := c.QueryInt("days", 1)
+	if days < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Days must not be negative",
+		})
+	}
+
+	cal, err := calendar.Default()
+	if err != nil {
+		log.Printf("Error loading business calendar: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Business calendar is misconfigured",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(NextBusinessDayResponse{
+		Error: false,
+		Days:  days,
+		Date:  cal.AddBusinessDays(time.Now(), days).Format(time.DateOnly),
+	})
+}
@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserDTO is the public representation of a user
+type UserDTO struct {
+	ID          uint        `json:"id"`
+	Username    string      `json:"username"`
+	Role        models.Role `json:"role"`
+	Active      bool        `json:"active"`
+	Deleted     bool        `json:"deleted"` // the user was soft-deleted, e.g. a former employee still assigned to orders
+	PlantID     uint        `json:"plant_id"`
+	LastLoginAt *time.Time  `json:"last_login_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// WorkOrderDTO is the public representation of a work order
+type WorkOrderDTO struct {
+	ID                   uint                        `json:"id"`
+	WorkOrderNumber      string                      `json:"work_order_number"`
+	ProductName          string                      `json:"product_name"`
+	Quantity             int                         `json:"quantity"`
+	TargetQuantity       int                         `json:"target_quantity"`
+	Unit                 string                      `json:"unit"`
+	ProductionDeadline   time.Time                   `json:"production_deadline"`
+	Status               models.WorkOrderStatus      `json:"status"`
+	Priority             int                         `json:"priority"`
+	HoldReason           string                      `json:"hold_reason,omitempty"`
+	RemainingDisposition models.RemainingDisposition `json:"remaining_disposition,omitempty"`
+	CancellationReason   *string                     `json:"cancellation_reason,omitempty"`
+	RestockNote          *string                     `json:"restock_note,omitempty"`
+	OperatorID           uint                        `json:"operator_id"`
+	Operator             *UserDTO                    `json:"operator,omitempty"` // only set when the operator is loaded
+	AcknowledgedAt       *time.Time                  `json:"acknowledged_at"`
+	Acknowledged         bool                        `json:"acknowledged"`
+	OverdueSnoozedUntil  *time.Time                  `json:"overdue_snoozed_until"` // overdue reminders are snoozed until then
+	ParentID             *uint                       `json:"parent_id,omitempty"`
+	PlantID              uint                        `json:"plant_id"`
+	Children             []WorkOrderDTO              `json:"children,omitempty"`
+	Tags                 []TagDTO                    `json:"tags,omitempty"` // only set when the order has tags and they are loaded
+	CreatedAt            time.Time                   `json:"created_at"`
+	UpdatedAt            time.Time                   `json:"updated_at"`
+}
+
+// TagDTO is the public representation of a work order tag
+type TagDTO struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// ProgressDTO is the public representation of a work order progress entry
+type ProgressDTO struct {
+	ID               uint       `json:"id"`
+	WorkOrderID      uint       `json:"work_order_id"`
+	ProgressDesc     string     `json:"progress_desc"`
+	ProgressQuantity int        `json:"progress_quantity"`
+	ReportedByID     *uint      `json:"reported_by_id"`
+	ReportedBy       *UserDTO   `json:"reported_by,omitempty"` // only set when the reporter is loaded
+	ApprovedByID     *uint      `json:"approved_by_id"`
+	ApprovedAt       *time.Time `json:"approved_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// StatusHistoryDTO is the public representation of a work order status history entry
+type StatusHistoryDTO struct {
+	ID          uint                   `json:"id"`
+	WorkOrderID uint                   `json:"work_order_id"`
+	Status      models.WorkOrderStatus `json:"status"`
+	Quantity    int                    `json:"quantity"`
+	Note        string                 `json:"note,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+// AuditLogDTO is the public representation of an audit log entry
+type AuditLogDTO struct {
+	ID         uint              `json:"id"`
+	UserID     uint              `json:"user_id"`
+	User       *UserDTO          `json:"user,omitempty"` // only set when the user is loaded
+	UserName   string            `json:"user_name"`
+	Action     models.ActionType `json:"action"`
+	EntityID   uint              `json:"entity_id"`
+	EntityType string            `json:"entity_type"`
+	OldValues  models.JSON       `json:"old_values,omitempty" swaggertype:"object"`
+	NewValues  models.JSON       `json:"new_values,omitempty" swaggertype:"object"`
+	Note       string            `json:"note,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// NotificationPreferenceDTO is the public representation of a user's notification preferences
+type NotificationPreferenceDTO struct {
+	EmailOnAssignment bool `json:"email_on_assignment"`
+	EmailOnOverdue    bool `json:"email_on_overdue"`
+}
+
+// toUserDTO maps a user to its public representation
+func toUserDTO(user models.User) UserDTO {
+	return UserDTO{
+		ID:          user.ID,
+		Username:    user.Username,
+		Role:        user.Role,
+		Active:      user.Active,
+		Deleted:     user.DeletedAt.Valid,
+		PlantID:     user.PlantID,
+		LastLoginAt: user.LastLoginAt,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+}
+
+// toUserDTOs maps a list of users to their public representation
+func toUserDTOs(users []models.User) []UserDTO {
+	dtos := make([]UserDTO, 0, len(users))
+	for _, user := range users {
+		dtos = append(dtos, toUserDTO(user))
+	}
+	return dtos
+}
+
+// loadedUserDTO maps an associated user, returning nil when the association was not loaded
+func loadedUserDTO(user models.User) *UserDTO {
+	if user.ID == 0 {
+		return nil
+	}
+	dto := toUserDTO(user)
+	return &dto
+}
+
+// toWorkOrderDTO maps a work order to its public representation
+func toWorkOrderDTO(workOrder models.WorkOrder) WorkOrderDTO {
+	dto := WorkOrderDTO{
+		ID:                   workOrder.ID,
+		WorkOrderNumber:      workOrder.WorkOrderNumber,
+		ProductName:          workOrder.ProductName,
+		Quantity:             workOrder.Quantity,
+		TargetQuantity:       workOrder.TargetQuantity,
+		Unit:                 workOrder.Unit,
+		ProductionDeadline:   workOrder.ProductionDeadline,
+		Status:               workOrder.Status,
+		Priority:             workOrder.Priority,
+		HoldReason:           workOrder.HoldReason,
+		RemainingDisposition: workOrder.RemainingDisposition,
+		CancellationReason:   workOrder.CancellationReason,
+		RestockNote:          workOrder.RestockNote,
+		OperatorID:           workOrder.OperatorID,
+		Operator:             loadedUserDTO(workOrder.Operator),
+		AcknowledgedAt:       workOrder.AcknowledgedAt,
+		Acknowledged:         workOrder.AcknowledgedAt != nil,
+		OverdueSnoozedUntil:  workOrder.OverdueSnoozedUntil,
+		ParentID:             workOrder.ParentID,
+		PlantID:              workOrder.PlantID,
+		CreatedAt:            workOrder.CreatedAt,
+		UpdatedAt:            workOrder.UpdatedAt,
+	}
+	if len(workOrder.Children) > 0 {
+		dto.Children = toWorkOrderDTOs(workOrder.Children)
+	}
+	for _, tag := range workOrder.Tags {
+		dto.Tags = append(dto.Tags, TagDTO{ID: tag.ID, Name: tag.Name})
+	}
+	return dto
+}
+
+// toWorkOrderDTOs maps a list of work orders to their public representation
+func toWorkOrderDTOs(workOrders []models.WorkOrder) []WorkOrderDTO {
+	dtos := make([]WorkOrderDTO, 0, len(workOrders))
+	for _, workOrder := range workOrders {
+		dtos = append(dtos, toWorkOrderDTO(workOrder))
+	}
+	return dtos
+}
+
+// toProgressDTO maps a progress entry to its public representation
+func toProgressDTO(progress models.WorkOrderProgress) ProgressDTO {
+	dto := ProgressDTO{
+		ID:               progress.ID,
+		WorkOrderID:      progress.WorkOrderID,
+		ProgressDesc:     progress.ProgressDesc,
+		ProgressQuantity: progress.ProgressQuantity,
+		ReportedByID:     progress.ReportedByID,
+		ApprovedByID:     progress.ApprovedByID,
+		ApprovedAt:       progress.ApprovedAt,
+		CreatedAt:        progress.CreatedAt,
+		UpdatedAt:        progress.UpdatedAt,
+	}
+	if progress.ReportedBy != nil {
+		dto.ReportedBy = loadedUserDTO(*progress.ReportedBy)
+	}
+	return dto
+}
+
+// toProgressDTOs maps a list of progress entries to their public representation
+func toProgressDTOs(progress []models.WorkOrderProgress) []ProgressDTO {
+	dtos := make([]ProgressDTO, 0, len(progress))
+	for _, entry := range progress {
+		dtos = append(dtos, toProgressDTO(entry))
+	}
+	return dtos
+}
+
+// toStatusHistoryDTOs maps a list of status history entries to their public representation
+func toStatusHistoryDTOs(history []models.WorkOrderStatusHistory) []StatusHistoryDTO {
+	dtos := make([]StatusHistoryDTO, 0, len(history))
+	for _, entry := range history {
+		dtos = append(dtos, StatusHistoryDTO{
+			ID:          entry.ID,
+			WorkOrderID: entry.WorkOrderID,
+			Status:      entry.Status,
+			Quantity:    entry.Quantity,
+			Note:        entry.Note,
+			CreatedAt:   entry.CreatedAt,
+		})
+	}
+	return dtos
+}
+
+// toAuditLogDTO maps an audit log entry to its public representation
+func toAuditLogDTO(auditLog models.AuditLog) AuditLogDTO {
+	return AuditLogDTO{
+		ID:         auditLog.ID,
+		UserID:     auditLog.UserID,
+		User:       loadedUserDTO(auditLog.User),
+		UserName:   auditLog.UserName,
+		Action:     auditLog.Action,
+		EntityID:   auditLog.EntityID,
+		EntityType: auditLog.EntityType,
+		OldValues:  auditLog.OldValues,
+		NewValues:  auditLog.NewValues,
+		Note:       auditLog.Note,
+		CreatedAt:  auditLog.CreatedAt,
+	}
+}
+
+// toAuditLogDTOs maps a list of audit log entries to their public representation
+func toAuditLogDTOs(auditLogs []models.AuditLog) []AuditLogDTO {
+	dtos := make([]AuditLogDTO, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		dtos = append(dtos, toAuditLogDTO(auditLog))
+	}
+	return dtos
+}
+
+// toNotificationPreferenceDTO maps notification preferences to their public representation
+func toNotificationPreferenceDTO(preference models.NotificationPreference) NotificationPreferenceDTO {
+	return NotificationPreferenceDTO{
+		EmailOnAssignment: preference.EmailOnAssignment,
+		EmailOnOverdue:    preference.EmailOnOverdue,
+	}
+}
+
+// workOrderFields are the WorkOrderDTO fields a client can select with ?fields=
+var workOrderFields = jsonFieldNames(WorkOrderDTO{})
+
+// jsonFieldNames returns the JSON names of a struct's fields
+func jsonFieldNames(v interface{}) map[string]bool {
+	names := map[string]bool{}
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// parseWorkOrderFields parses the comma separated ?fields= selection, nil when it is absent
+func parseWorkOrderFields(c *fiber.Ctx) ([]string, error) {
+	value := c.Query("fields")
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !workOrderFields[field] {
+			allowed := make([]string, 0, len(workOrderFields))
+			for name := range workOrderFields {
+				allowed = append(allowed, name)
+			}
+			sort.Strings(allowed)
+			return nil, fmt.Errorf("Unknown field %q, fields must be among %s", field, strings.Join(allowed, ", "))
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must name at least one field")
+	}
+	return fields, nil
+}
+
+// sendWorkOrderFields answers with the response, keeping only the selected fields of the
+// work order or work orders under key. Without a selection the response is sent as is.
+func sendWorkOrderFields(c *fiber.Ctx, status int, response interface{}, key string, fields []string) error {
+	if len(fields) == 0 {
+		return c.Status(status).JSON(response)
+	}
+
+	var body map[string]json.RawMessage
+	encoded, err := json.Marshal(response)
+	if err == nil {
+		err = json.Unmarshal(encoded, &body)
+	}
+	if err == nil {
+		body[key], err = pickJSONFields(body[key], fields)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error selecting work order fields",
+		})
+	}
+	return c.Status(status).JSON(body)
+}
+
+// pickJSONFields keeps the selected fields of a JSON object, or of every object of a JSON array
+func pickJSONFields(value json.RawMessage, fields []string) (json.RawMessage, error) {
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(value, &objects); err == nil {
+		for i, object := range objects {
+			objects[i] = pickFields(object, fields)
+		}
+		return json.Marshal(objects)
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(value, &object); err != nil {
+		return nil, err
+	}
+	return json.Marshal(pickFields(object, fields))
+}
+
+// pickFields returns the selected fields of a decoded JSON object, fields it omitted stay omitted
+func pickFields(object map[string]json.RawMessage, fields []string) map[string]json.RawMessage {
+	picked := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := object[field]; ok {
+			picked[field] = value
+		}
+	}
+	return picked
+}
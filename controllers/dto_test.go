@@ -0,0 +1,340 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// jsonKeys returns the keys of v encoded as a JSON object
+func jsonKeys(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("encoding %T: %v", v, err)
+	}
+	var keys map[string]interface{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return keys
+}
+
+func TestToProgressDTO(t *testing.T) {
+	reporterID := uint(2)
+	reporter := models.User{ID: 2, Username: "operator", Password: "$2a$10$hash", Role: models.RoleOperator}
+
+	tests := []struct {
+		name           string
+		progress       models.WorkOrderProgress
+		wantReportedBy bool
+	}{
+		{"reporter loaded", models.WorkOrderProgress{ID: 1, WorkOrderID: 3, ReportedByID: &reporterID, ReportedBy: &reporter}, true},
+		{"reporter not loaded", models.WorkOrderProgress{ID: 1, WorkOrderID: 3, ReportedByID: &reporterID}, false},
+		{"no reporter recorded", models.WorkOrderProgress{ID: 1, WorkOrderID: 3}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := jsonKeys(t, toProgressDTO(tt.progress))
+			if _, ok := keys["work_order"]; ok {
+				t.Errorf("progress has a work order back-reference: %v", keys)
+			}
+			if keys["work_order_id"] != float64(3) {
+				t.Errorf("work_order_id = %v, want 3", keys["work_order_id"])
+			}
+			reportedBy, ok := keys["reported_by"].(map[string]interface{})
+			if ok != tt.wantReportedBy {
+				t.Fatalf("reported_by present = %t, want %t: %v", ok, tt.wantReportedBy, keys)
+			}
+			if _, leaked := reportedBy["password"]; leaked {
+				t.Errorf("reporter leaks the password hash: %v", reportedBy)
+			}
+		})
+	}
+}
+
+func TestToWorkOrderDTO(t *testing.T) {
+	operator := models.User{ID: 2, Username: "operator", Password: "$2a$10$hash", Role: models.RoleOperator}
+	acknowledged := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		workOrder        models.WorkOrder
+		wantOperator     bool
+		wantAcknowledged bool
+	}{
+		{"operator loaded", models.WorkOrder{ID: 1, OperatorID: 2, Operator: operator}, true, false},
+		{"operator not loaded", models.WorkOrder{ID: 1, OperatorID: 2}, false, false},
+		{"acknowledged", models.WorkOrder{ID: 1, OperatorID: 2, AcknowledgedAt: &acknowledged}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := jsonKeys(t, toWorkOrderDTO(tt.workOrder))
+			if _, ok := keys["deleted_at"]; ok {
+				t.Errorf("work order exposes deleted_at: %v", keys)
+			}
+			operator, ok := keys["operator"].(map[string]interface{})
+			if ok != tt.wantOperator {
+				t.Fatalf("operator present = %t, want %t: %v", ok, tt.wantOperator, keys)
+			}
+			if _, leaked := operator["password"]; leaked {
+				t.Errorf("operator leaks the password hash: %v", operator)
+			}
+			if keys["acknowledged"] != tt.wantAcknowledged {
+				t.Errorf("acknowledged = %v, want %t", keys["acknowledged"], tt.wantAcknowledged)
+			}
+		})
+	}
+}
+
+func TestGetWorkOrderProgressHasNoBackReference(t *testing.T) {
+	useScriptedDB(t, storedRows(map[string]testTable{
+		"work_orders": {
+			columns: []string{"id", "plant_id", "operator_id", "status"},
+			rows:    [][]driver.Value{{int64(1), int64(1), int64(2), string(models.StatusInProgress)}},
+		},
+		"work_order_progresses": {
+			columns: []string{"id", "work_order_id", "progress_desc", "progress_quantity", "reported_by_id"},
+			rows:    [][]driver.Value{{int64(5), int64(1), "first batch", int64(10), int64(2)}},
+		},
+		"users": {
+			columns: []string{"id", "plant_id", "username", "password", "role"},
+			rows:    [][]driver.Value{{int64(2), int64(1), "operator", "$2a$10$hash", string(models.RoleOperator)}},
+		},
+	}))
+
+	status, body := testRequest(t, "/work-orders/:id/progress", GetWorkOrderProgress, testUser{2, models.RoleOperator, 0}, fiber.MethodGet, "/work-orders/1/progress")
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d (%v)", status, fiber.StatusOK, body)
+	}
+	entries, _ := body["progress"].([]interface{})
+	if len(entries) != 1 {
+		t.Fatalf("progress = %v, want one entry", body["progress"])
+	}
+	entry := entries[0].(map[string]interface{})
+	if _, ok := entry["work_order"]; ok {
+		t.Errorf("progress entry has a work order back-reference: %v", entry)
+	}
+	reportedBy, _ := entry["reported_by"].(map[string]interface{})
+	if reportedBy["username"] != "operator" {
+		t.Errorf("reported_by = %v, want the operator", entry["reported_by"])
+	}
+	if _, leaked := reportedBy["password"]; leaked {
+		t.Errorf("reporter leaks the password hash: %v", reportedBy)
+	}
+}
+
+// nullPaths returns the paths of the null values in a decoded JSON response
+func nullPaths(path string, v interface{}) []string {
+	var paths []string
+	switch v := v.(type) {
+	case nil:
+		paths = append(paths, path)
+	case map[string]interface{}:
+		for key, value := range v {
+			paths = append(paths, nullPaths(path+"."+key, value)...)
+		}
+	case []interface{}:
+		for i, value := range v {
+			paths = append(paths, nullPaths(fmt.Sprintf("%s[%d]", path, i), value)...)
+		}
+	}
+	return paths
+}
+
+func TestListResponsesWithoutData(t *testing.T) {
+	manager := testUser{1, models.RoleProductionManager, 0}
+	operator := testUser{2, models.RoleOperator, 0}
+
+	tests := []struct {
+		name     string
+		route    string
+		handler  fiber.Handler
+		user     testUser
+		target   string
+		nullable []string // values that are not lists and may be null
+	}{
+		{"operators", "/operators", GetOperators, manager, "/operators", nil},
+		{"user actions", "/users/:id/actions", GetUserActions, manager, "/users/2/actions", nil},
+		{"work orders", "/work-orders", GetWorkOrders, manager, "/work-orders", nil},
+		{"assigned work orders", "/work-orders/assigned", GetAssignedWorkOrders, operator, "/work-orders/assigned", nil},
+		{"inbox", "/work-orders/inbox", GetWorkOrderInbox, manager, "/work-orders/inbox", nil},
+		{"work order progress", "/work-orders/:id/progress", GetWorkOrderProgress, manager, "/work-orders/1/progress", nil},
+		{"work order logs", "/work-orders/:id/logs", GetWorkOrderLogs, manager, "/work-orders/1/logs", nil},
+		{"status durations", "/work-orders/:id/status-durations", GetWorkOrderStatusDurations, manager, "/work-orders/1/status-durations", nil},
+		{"burndown", "/work-orders/:id/burndown", GetWorkOrderBurndown, manager, "/work-orders/1/burndown", nil},
+		{"field history", "/work-orders/:id/field-history", GetWorkOrderFieldHistory, manager, "/work-orders/1/field-history?field=status", nil},
+		{"audit report", "/work-orders/:id/audit-report", GetWorkOrderAuditReport, manager, "/work-orders/1/audit-report", []string{".report.work_order.acknowledged_at", ".report.work_order.overdue_snoozed_until", ".report.work_order.operator.last_login_at"}},
+		{"tags", "/tags", GetTags, manager, "/tags", nil},
+		{"progress feed", "/progress", GetProgressFeed, manager, "/progress", nil},
+		{"dashboard", "/reports/dashboard", GetWorkOrderDashboard, manager, "/reports/dashboard", nil},
+		{"kpis", "/reports/kpis", GetWorkOrderKPIs, manager, "/reports/kpis", nil},
+		{"aging", "/reports/aging", GetWorkOrderAging, manager, "/reports/aging", nil},
+		{"notification preferences", "/reports/notification-preferences", GetNotificationPreferenceSummary, manager, "/reports/notification-preferences", nil},
+		{"daily production", "/reports/daily", GetDailyProduction, manager, "/reports/daily", nil},
+		{"performance", "/reports/performance", GetOperatorPerformance, manager, "/reports/performance?start_date=2026-03-01&end_date=2026-03-31", nil},
+		{"lead time", "/reports/lead-time", GetLeadTimePercentiles, manager, "/reports/lead-time", nil},
+		{"upcoming load", "/reports/upcoming-load", GetUpcomingLoad, manager, "/reports/upcoming-load", nil},
+		{"status matrix", "/reports/operators/matrix", GetOperatorStatusMatrix, manager, "/reports/operators/matrix", nil},
+		{"summary", "/reports/summary", GetWorkOrderSummary, manager, "/reports/summary", nil},
+		{"summary comparison", "/reports/summary/compare", GetSummaryComparison, manager, "/reports/summary/compare", []string{".change.orders_created", ".change.orders_completed", ".change.completed_quantity"}},
+		{"summary product orders", "/reports/summary/product/:product_name/orders", GetSummaryProductWorkOrders, manager, "/reports/summary/product/Bracket/orders", nil},
+		{"operator summary", "/reports/summary/:operator_id", GetWorkOrderSummaryByOperator, manager, "/reports/summary/2", nil},
+		{"operator forecast", "/operators/:id/forecast", GetOperatorForecast, manager, "/operators/2/forecast?throughput=5", nil},
+		{"notification preferences of the user", "/me/notifications", GetMyNotificationPreferences, operator, "/me/notifications", nil},
+		{"perf stats", "/admin/perf", GetPerfStats, manager, "/admin/perf", nil},
+		{"enabled features", "/feature-flags/me", GetEnabledFeatures, operator, "/feature-flags/me", nil},
+		{"outbox jobs", "/admin/jobs", GetOutboxJobs, manager, "/admin/jobs", nil},
+		{"feature flags", "/feature-flags", GetFeatureFlags, manager, "/feature-flags", nil},
+		{"search", "/search", Search, manager, "/search?q=bracket", nil},
+		{"audit logs", "/audit-logs", GetAuditLogs, manager, "/audit-logs", nil},
+	}
+
+	previous := config.AppConfig
+	config.AppConfig.WorkOrderSortBy = "work_order_number"
+	config.AppConfig.WorkOrderSortOrder = "desc"
+	config.AppConfig.UpcomingLoadWeeks = 8
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Only the work order and the user looked up by ID exist, every list is empty
+			useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+				switch {
+				case strings.HasPrefix(stmt.SQL, `SELECT * FROM "work_orders"`) && strings.Contains(stmt.SQL, `"work_orders"."id" = $1`):
+					return dbtest.Rows{
+						Columns: []string{"id", "operator_id", "status", "target_quantity", "work_order_number"},
+						Values:  [][]driver.Value{{int64(1), int64(2), string(models.StatusPending), int64(100), "WO-20260301-001"}},
+					}
+				case strings.HasPrefix(stmt.SQL, `SELECT * FROM "users"`) && strings.Contains(stmt.SQL, `"users"."id" = $`):
+					return dbtest.Rows{
+						Columns: []string{"id", "username", "role"},
+						Values:  [][]driver.Value{{int64(2), "operator", string(models.RoleOperator)}},
+					}
+				}
+				return dbtest.Rows{}
+			})
+
+			status, body := testRequest(t, tt.route, tt.handler, tt.user, fiber.MethodGet, tt.target)
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", status, body)
+			}
+			for _, path := range nullPaths("", body) {
+				if !slices.Contains(tt.nullable, path) {
+					t.Errorf("null instead of [] at %s in %v", path, body)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkOrderFieldSelection(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.WorkOrderSortBy = "work_order_number"
+	config.AppConfig.WorkOrderSortOrder = "desc"
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	stored := storedRows(map[string]testTable{
+		"users": {
+			columns: []string{"id", "plant_id", "username", "role"},
+			rows:    [][]driver.Value{{int64(2), int64(1), "operator", string(models.RoleOperator)}},
+		},
+		"work_orders": {
+			columns: []string{"id", "plant_id", "operator_id", "status", "product_name", "quantity", "target_quantity", "work_order_number"},
+			rows:    [][]driver.Value{{int64(1), int64(1), int64(2), string(models.StatusPending), "Widget", int64(0), int64(100), "WO-20260301-001"}},
+		},
+	})
+	useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+		// The work order has no backorders
+		if strings.Contains(stmt.SQL, `"parent_id"`) {
+			return dbtest.Rows{}
+		}
+		return stored(stmt)
+	})
+	manager := testUser{1, models.RoleProductionManager, 0}
+	operator := testUser{2, models.RoleOperator, 0}
+
+	endpoints := []struct {
+		name    string
+		route   string
+		handler fiber.Handler
+		user    testUser
+		target  string
+		key     string
+	}{
+		{"work order", "/work-orders/:id", GetWorkOrderByID, manager, "/work-orders/1", "work_order"},
+		{"work orders", "/work-orders", GetWorkOrders, manager, "/work-orders", "work_orders"},
+		{"assigned work orders", "/work-orders/assigned", GetAssignedWorkOrders, operator, "/work-orders/assigned", "work_orders"},
+		{"inbox", "/work-orders/inbox", GetWorkOrderInbox, operator, "/work-orders/inbox", "work_orders"},
+	}
+
+	// workOrderObjects returns the work order, or the work orders of a list, under key
+	workOrderObjects := func(t *testing.T, body map[string]interface{}, key string) []map[string]interface{} {
+		t.Helper()
+		switch value := body[key].(type) {
+		case map[string]interface{}:
+			return []map[string]interface{}{value}
+		case []interface{}:
+			var objects []map[string]interface{}
+			for _, entry := range value {
+				objects = append(objects, entry.(map[string]interface{}))
+			}
+			return objects
+		}
+		t.Fatalf("no work orders under %s: %v", key, body)
+		return nil
+	}
+
+	for _, tt := range endpoints {
+		t.Run(tt.name+" subset", func(t *testing.T) {
+			status, body := testRequest(t, tt.route, tt.handler, tt.user, fiber.MethodGet, tt.target+"?fields=id,%20work_order_number,status")
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", status, body)
+			}
+			if body["error"] != false {
+				t.Errorf("error = %v, the fields of the response itself are kept", body["error"])
+			}
+			objects := workOrderObjects(t, body, tt.key)
+			if len(objects) != 1 {
+				t.Fatalf("got %d work orders, want 1", len(objects))
+			}
+			want := map[string]interface{}{"id": float64(1), "work_order_number": "WO-20260301-001", "status": string(models.StatusPending)}
+			if fmt.Sprint(objects[0]) != fmt.Sprint(want) {
+				t.Errorf("work order = %v, want only %v", objects[0], want)
+			}
+		})
+
+		t.Run(tt.name+" without a selection", func(t *testing.T) {
+			status, body := testRequest(t, tt.route, tt.handler, tt.user, fiber.MethodGet, tt.target)
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", status, body)
+			}
+			object := workOrderObjects(t, body, tt.key)[0]
+			for _, field := range []string{"id", "work_order_number", "status", "product_name", "quantity", "target_quantity", "unit", "operator_id", "priority"} {
+				if _, ok := object[field]; !ok {
+					t.Errorf("full work order misses %s: %v", field, object)
+				}
+			}
+		})
+
+		for _, fields := range []string{"id,cost", "operator.password", ","} {
+			t.Run(tt.name+" rejects "+fields, func(t *testing.T) {
+				status, body := testRequest(t, tt.route, tt.handler, tt.user, fiber.MethodGet, tt.target+"?fields="+fields)
+				if status != fiber.StatusBadRequest {
+					t.Errorf("status = %d, want 400 (%v)", status, body)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/features"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// featureFlagName is the accepted format of feature flag names, e.g. multi_operator
+var featureFlagName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// SetFeatureFlagRequest represents the set feature flag request body
+type SetFeatureFlagRequest struct {
+	Enabled     bool   `json:"enabled"`
+	Description string `json:"description"`
+}
+
+// FeatureFlagListResponse represents the stored feature flags
+type FeatureFlagListResponse struct {
+	Error    bool                 `json:"error"`
+	Flags    []models.FeatureFlag `json:"flags"`
+	Defaults []string             `json:"defaults"` // enabled through FEATURE_FLAGS unless stored as disabled
+}
+
+// FeatureFlagResponse represents a stored feature flag
+type FeatureFlagResponse struct {
+	Error bool               `json:"error"`
+	Flag  models.FeatureFlag `json:"flag"`
+}
+
+// EnabledFeaturesResponse lists the features switched on for clients
+type EnabledFeaturesResponse struct {
+	Error    bool     `json:"error"`
+	Features []string `json:"features"`
+}
+
+// @Summary Get enabled features
+// @Description Get the names of the switched on feature flags so clients can adapt
+// @Tags feature-flags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} EnabledFeaturesResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /feature-flags/me [get]
+func GetEnabledFeatures(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(EnabledFeaturesResponse{
+		Error:    false,
+		Features: features.EnabledNames(),
+	})
+}
+
+// @Summary Get feature flags
+// @Description Get the feature flags stored in the database and the ones enabled by default through FEATURE_FLAGS (Production Manager only)
+// @Tags feature-flags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} FeatureFlagListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /feature-flags [get]
+func GetFeatureFlags(c *fiber.Ctx) error {
+	flags := []models.FeatureFlag{}
+	if err := getDB(c).Order("name ASC").Find(&flags).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching feature flags",
+		})
+	}
+
+	defaults := []string{}
+	for name := range features.Defaults() {
+		defaults = append(defaults, name)
+	}
+	sort.Strings(defaults)
+
+	return c.Status(fiber.StatusOK).JSON(FeatureFlagListResponse{
+		Error:    false,
+		Flags:    flags,
+		Defaults: defaults,
+	})
+}
+
+// @Summary Set feature flag
+// @Description Switch a feature flag on or off, creating it if needed. The flag overrides FEATURE_FLAGS and reaches other instances within 30 seconds. (Production Manager only)
+// @Tags feature-flags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Feature flag name, e.g. multi_operator"
+// @Param request body SetFeatureFlagRequest true "Feature flag state"
+// @Success 200 {object} FeatureFlagResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /feature-flags/{name} [put]
+func SetFeatureFlag(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	name := c.Params("name")
+	if !featureFlagName.MatchString(name) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Feature flag name must be lowercase letters, digits and underscores, at most 50 characters",
+		})
+	}
+
+	var req SetFeatureFlagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid request body",
+		})
+	}
+
+	var flag models.FeatureFlag
+	result := getDB(c).Where("name = ?", name).First(&flag)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching feature flag",
+		})
+	}
+
+	oldFlag := flag
+	flag.Name = name
+	flag.Enabled = req.Enabled
+	if req.Description != "" {
+		flag.Description = req.Description
+	}
+	if err := getDB(c).Save(&flag).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error saving feature flag",
+		})
+	}
+	features.Invalidate()
+
+	state := "off"
+	if flag.Enabled {
+		state = "on"
+	}
+	if err := auditService.CreateLog(
+		getDB(c),
+		userID,
+		models.ActionUpdate,
+		"FeatureFlag",
+		flag.ID,
+		oldFlag,
+		flag,
+		fmt.Sprintf("Feature flag %s switched %s", flag.Name, state),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(FeatureFlagResponse{
+		Error: false,
+		Flag:  flag,
+	})
+}
+
+// @Summary Delete feature flag
+// @Description Delete a stored feature flag, it falls back to FEATURE_FLAGS (Production Manager only)
+// @Tags feature-flags
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Feature flag name"
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /feature-flags/{name} [delete]
+func DeleteFeatureFlag(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	var flag models.FeatureFlag
+	result := getDB(c).Where("name = ?", c.Params("name")).First(&flag)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Feature flag not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching feature flag",
+		})
+	}
+
+	if err := getDB(c).Delete(&flag).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error deleting feature flag",
+		})
+	}
+	features.Invalidate()
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		userID,
+		models.ActionDelete,
+		"FeatureFlag",
+		flag.ID,
+		nil,
+		nil,
+		fmt.Sprintf("Feature flag %s deleted", flag.Name),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{
+		Error:   false,
+		Message: "Feature flag deleted",
+	})
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ForecastOrder is an open work order in an operator's capacity forecast
+type ForecastOrder struct {
+	WorkOrderID         uint                   `json:"work_order_id"`
+	WorkOrderNumber     string                 `json:"work_order_number"`
+	ProductName         string                 `json:"product_name"`
+	Status              models.WorkOrderStatus `json:"status"`
+	ProductionDeadline  time.Time              `json:"production_deadline"`
+	RemainingQuantity   int                    `json:"remaining_quantity"`
+	CumulativeRemaining int                    `json:"cumulative_remaining"` // remaining quantity of this and all earlier-deadline orders
+	ProjectedCompletion *time.Time             `json:"projected_completion"` // nil when the operator has no throughput
+	AtRisk              bool                   `json:"at_risk"`              // the projected completion is after the deadline
+}
+
+// OperatorForecastResponse represents an operator's capacity forecast
+type OperatorForecastResponse struct {
+	Error            bool            `json:"error"`
+	OperatorID       uint            `json:"operator_id"`
+	Username         string          `json:"username"`
+	ThroughputPerDay float64         `json:"throughput_per_day"`
+	LookbackDays     int             `json:"lookback_days"` // days the historical throughput was measured over, 0 when overridden
+	AtRiskOrders     int             `json:"at_risk_orders"`
+	Orders           []ForecastOrder `json:"orders"`
+}
+
+// @Summary Get operator capacity forecast
+// @Description Get an operator's open work orders by deadline with the cumulative remaining quantity, flagging orders the operator is unlikely to finish in time at their historical throughput (Production Manager only)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Operator ID"
+// @Param throughput query number false "Quantity per day to forecast with instead of the historical throughput"
+// @Success 200 {object} OperatorForecastResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /operators/{id}/forecast [get]
+func GetOperatorForecast(c *fiber.Ctx) error {
+	// Get operator ID from URL
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	var operator models.User
+	result := getDB(c).Where("role = ?", models.RoleOperator).First(&operator, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Operator not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching operator",
+		})
+	}
+
+	now := time.Now()
+
+	// Use the given throughput, or measure it over the lookback period
+	lookbackDays := 0
+	var throughput float64
+	if value := c.Query("throughput"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "throughput must be a non-negative number",
+			})
+		}
+		throughput = parsed
+	} else {
+		lookbackDays = config.AppConfig.ForecastLookbackDays
+		if lookbackDays <= 0 {
+			lookbackDays = 30
+		}
+		measured, err := completedThroughput(getDB(c), operator.ID, now.AddDate(0, 0, -lookbackDays), lookbackDays)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error calculating operator throughput",
+			})
+		}
+		throughput = measured
+	}
+
+	// Get the open work orders, earliest deadline first
+	var workOrders []models.WorkOrder
+	if err := getDB(c).
+		Where("operator_id = ? AND status <> ?", operator.ID, models.StatusCompleted).
+		Order("production_deadline ASC, id ASC").
+		Find(&workOrders).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work orders",
+		})
+	}
+
+	response := OperatorForecastResponse{
+		Error:            false,
+		OperatorID:       operator.ID,
+		Username:         operator.Username,
+		ThroughputPerDay: roundTwoDecimals(throughput),
+		LookbackDays:     lookbackDays,
+		Orders:           make([]ForecastOrder, 0, len(workOrders)),
+	}
+
+	// Orders are worked in deadline order, so each one finishes once the
+	// remaining quantity of all earlier orders has been produced as well
+	cumulative := 0
+	for _, workOrder := range workOrders {
+		remaining := workOrder.TargetQuantity - workOrder.Quantity
+		if remaining < 0 {
+			remaining = 0
+		}
+		cumulative += remaining
+
+		order := ForecastOrder{
+			WorkOrderID:         workOrder.ID,
+			WorkOrderNumber:     workOrder.WorkOrderNumber,
+			ProductName:         workOrder.ProductName,
+			Status:              workOrder.Status,
+			ProductionDeadline:  workOrder.ProductionDeadline,
+			RemainingQuantity:   remaining,
+			CumulativeRemaining: cumulative,
+		}
+		if throughput > 0 {
+			days := float64(cumulative) / throughput
+			projected := now.Add(time.Duration(math.Ceil(days*24)) * time.Hour)
+			order.ProjectedCompletion = &projected
+			order.AtRisk = projected.After(workOrder.ProductionDeadline)
+		} else {
+			order.AtRisk = cumulative > 0
+		}
+		if order.AtRisk {
+			response.AtRiskOrders++
+		}
+
+		response.Orders = append(response.Orders, order)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// completedThroughput returns the quantity per day of the operator's work orders completed since the given time
+func completedThroughput(db *gorm.DB, operatorID uint, since time.Time, days int) (float64, error) {
+	var quantity int64
+	if err := db.Model(&models.WorkOrder{}).
+		Where("operator_id = ? AND status = ?", operatorID, models.StatusCompleted).
+		Where("EXISTS (SELECT 1 FROM work_order_status_histories h WHERE h.work_order_id = work_orders.id AND h.status = ? AND h.created_at >= ?)",
+			models.StatusCompleted, since).
+		Select("COALESCE(SUM(quantity), 0)").
+		Row().Scan(&quantity); err != nil {
+		return 0, err
+	}
+	return float64(quantity) / float64(days), nil
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"log"
+
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReadinessResponse represents the readiness check response
+type ReadinessResponse struct {
+	Status   string                 `json:"status"`   // ready or not_ready
+	Database string                 `json:"database"` // ok or unavailable
+	Schema   *database.SchemaStatus `json:"schema,omitempty"`
+	// Maintenance is true while writes are blocked, reads are still served so the instance stays ready
+	Maintenance bool `json:"maintenance"`
+}
+
+// GetReadiness reports whether the database is reachable and every migrated
+// table exists, answering 503 until the instance can serve traffic. Unlike the
+// liveness checks it is registered outside the API, next to them in main.go.
+func GetReadiness(c *fiber.Ctx) error {
+	// Check the database connection
+	sqlDB, err := database.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(c.UserContext())
+	}
+	if err != nil {
+		log.Printf("Readiness check: database unavailable: %v", err)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(ReadinessResponse{
+			Status:   "not_ready",
+			Database: "unavailable",
+		})
+	}
+
+	// Check that the migrations created every table
+	schema, err := database.CheckSchema(getDB(c))
+	if err != nil {
+		log.Printf("Readiness check: error checking schema: %v", err)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(ReadinessResponse{
+			Status:   "not_ready",
+			Database: "ok",
+		})
+	}
+	if !schema.Migrated {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(ReadinessResponse{
+			Status:      "not_ready",
+			Database:    "ok",
+			Schema:      &schema,
+			Maintenance: middleware.MaintenanceMode(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ReadinessResponse{
+		Status:      "ready",
+		Database:    "ok",
+		Schema:      &schema,
+		Maintenance: middleware.MaintenanceMode(),
+	})
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/services"
+	"github.com/dawamr/work-order-system-go/utils/events"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// getDB returns the database handle bound to the request context, so queries
+// are cancelled when the request deadline set by middleware.Timeout passes.
+// On routes registered with middleware.Transaction it returns the request
+// transaction instead, so every write of the handler commits or rolls back together.
+func getDB(c *fiber.Ctx) *gorm.DB {
+	if tx, ok := middleware.RequestTransaction(c); ok {
+		return tx
+	}
+	return database.DB.WithContext(c.UserContext())
+}
+
+// eventBus receives the domain events published by the handlers, set at startup by SetEventBus
+var eventBus *events.Bus
+
+// SetEventBus sets the bus the handlers publish their domain events to
+func SetEventBus(bus *events.Bus) {
+	eventBus = bus
+}
+
+// eventSubscribers store the outbox jobs of the domain events, set at startup by SetEventSubscribers
+var eventSubscribers []services.EventSubscriber
+
+// SetEventSubscribers sets the subscribers whose outbox jobs are stored with every domain event
+func SetEventSubscribers(subscribers ...services.EventSubscriber) {
+	eventSubscribers = subscribers
+}
+
+// newEvent returns a domain event about the work order, caused by the request's actor
+func newEvent(c *fiber.Ctx, eventType events.Type, workOrder models.WorkOrder, oldStatus models.WorkOrderStatus) events.Event {
+	return events.Event{
+		Type:       eventType,
+		WorkOrder:  workOrder,
+		OldStatus:  oldStatus,
+		ActorID:    actorID(c),
+		OccurredAt: time.Now(),
+	}
+}
+
+// enqueueEvent stores the outbox jobs of the event's subscribers with db, so they
+// commit or roll back together with the change the event describes
+func enqueueEvent(db *gorm.DB, event events.Event) error {
+	for _, subscriber := range eventSubscribers {
+		if err := subscriber.Enqueue(db, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// announceEvent hands the event to the in-memory bus once the request's changes
+// are committed, so its subscribers never hear of rolled back changes
+func announceEvent(c *fiber.Ctx, event events.Event) {
+	if eventBus == nil {
+		return
+	}
+	middleware.AfterCommit(c, func() {
+		eventBus.Publish(event)
+	})
+}
+
+// publishEvent publishes a domain event about the work order: its outbox jobs are
+// stored in the request's transaction and the bus hears of it after the commit
+func publishEvent(c *fiber.Ctx, eventType events.Type, workOrder models.WorkOrder, oldStatus models.WorkOrderStatus) error {
+	event := newEvent(c, eventType, workOrder, oldStatus)
+	if err := enqueueEvent(getDB(c), event); err != nil {
+		return err
+	}
+	announceEvent(c, event)
+	return nil
+}
+
+// eventError responds to a domain event whose outbox jobs could not be stored
+func eventError(c *fiber.Ctx, err error) error {
+	log.Printf("Error queueing work order event: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+		Error: true,
+		Msg:   "Error queueing work order event",
+	})
+}
+
+// preloadUnscoped preloads a user association including soft-deleted users,
+// so orders and logs of former employees still show who they belonged to;
+// the returned UserDTO marks those users as deleted
+func preloadUnscoped(query *gorm.DB, association string) *gorm.DB {
+	return query.Preload(association, func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	})
+}
+
+// getUserID returns the ID of the authenticated user set by middleware.Protected.
+// It is false when the route is served without the middleware.
+func getUserID(c *fiber.Ctx) (uint, bool) {
+	userID, ok := c.Locals("user_id").(uint)
+	return userID, ok
+}
+
+// actorID returns the user to record as the actor of the request's audit logs and
+// events: the Production Manager impersonating the authenticated operator, or the
+// authenticated user itself. Handlers operators can reach use it for their audit logs.
+func actorID(c *fiber.Ctx) uint {
+	if managerID, ok := c.Locals("impersonated_by").(uint); ok && managerID != 0 {
+		return managerID
+	}
+	userID, _ := getUserID(c)
+	return userID
+}
+
+// getRole returns the role of the authenticated user set by middleware.Protected.
+// It is false when the route is served without the middleware.
+func getRole(c *fiber.Ctx) (models.Role, bool) {
+	role, ok := c.Locals("role").(models.Role)
+	return role, ok
+}
+
+// pageLimit reads the limit query parameter of a paginated list, 10 by default
+// and at most MAX_PAGE_SIZE
+func pageLimit(c *fiber.Ctx) int {
+	limit := c.QueryInt("limit", 10)
+	if limit < 1 {
+		limit = 10
+	}
+	if config.AppConfig.MaxPageSize > 0 && limit > config.AppConfig.MaxPageSize {
+		limit = config.AppConfig.MaxPageSize
+	}
+	return limit
+}
+
+// idParam parses a numeric path parameter such as id or operator_id.
+// It is false when the value is not a positive integer.
+func idParam(c *fiber.Ctx, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Params(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// invalidIDError responds to a path parameter that is not a positive integer,
+// before the value reaches the database
+func invalidIDError(c *fiber.Ctx, name string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+		Error: true,
+		Msg:   fmt.Sprintf("%s must be a positive integer", name),
+		Code:  CodeInvalidID,
+	})
+}
+
+// unauthorizedError responds to a request that lacks the authenticated user
+func unauthorizedError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+		Error: true,
+		Msg:   "Unauthorized",
+	})
+}
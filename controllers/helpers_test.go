@@ -0,0 +1,431 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/services"
+	"github.com/dawamr/work-order-system-go/utils/events"
+	"github.com/gofiber/fiber/v2"
+)
+
+// useScriptedDB points database.DB at a scripted database answering with respond
+// for the duration of the test, with the plant scope registered
+func useScriptedDB(t *testing.T, respond dbtest.Responder) *dbtest.Recorder {
+	t.Helper()
+	db, recorder := dbtest.Open(t, respond)
+	if err := database.RegisterPlantScope(db); err != nil {
+		t.Fatalf("registering plant scope: %v", err)
+	}
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+	return recorder
+}
+
+// testUser is the authenticated user of a test request, the zero value sends none
+type testUser struct {
+	id    uint
+	role  models.Role
+	plant uint
+}
+
+// testApp serves handler at route, as the user middleware.Protected would have authenticated
+func testApp(route string, handler fiber.Handler, user testUser, method string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if user.id != 0 {
+			c.Locals("user_id", user.id)
+			c.Locals("role", user.role)
+		}
+		if user.plant != 0 {
+			c.Locals("plant_id", user.plant)
+			c.SetUserContext(database.WithPlant(c.UserContext(), user.plant))
+		}
+		return c.Next()
+	})
+	app.Add(method, route, handler)
+	return app
+}
+
+// jsonRequest builds a request with a JSON body, none when body is empty
+func jsonRequest(method, target, body string) *http.Request {
+	if body == "" {
+		return httptest.NewRequest(method, target, nil)
+	}
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	return req
+}
+
+// testRequest serves one request with handler registered at route, as the user
+// middleware.Protected would have authenticated, and decodes the JSON response
+func testRequest(t *testing.T, route string, handler fiber.Handler, user testUser, method, target string) (int, map[string]interface{}) {
+	t.Helper()
+	return testRequestBody(t, route, handler, user, method, target, "")
+}
+
+// testRequestBody is testRequest sending a JSON body
+func testRequestBody(t *testing.T, route string, handler fiber.Handler, user testUser, method, target, payload string) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := testApp(route, handler, user, method).Test(jsonRequest(method, target, payload))
+	if err != nil {
+		t.Fatalf("serving %s %s: %v", method, target, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	var decoded map[string]interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Fatalf("decoding response %q: %v", body, err)
+		}
+	}
+	return resp.StatusCode, decoded
+}
+
+func TestPublishEventStoresJobsWithTheChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		wantEnd       string
+		wantAnnounced int
+	}{
+		{"committed change", fiber.StatusOK, "COMMIT", 1},
+		{"rolled back change", fiber.StatusConflict, "ROLLBACK", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, nil)
+
+			outbox := &services.OutboxService{MaxAttempts: 3}
+			SetEventSubscribers(&services.NotificationSubscriber{Outbox: outbox})
+			t.Cleanup(func() { SetEventSubscribers() })
+
+			bus := events.NewBus(10)
+			var announced atomic.Int32
+			bus.Subscribe("test", 1, func(events.Event) { announced.Add(1) })
+			SetEventBus(bus)
+			t.Cleanup(func() { SetEventBus(nil) })
+
+			app := fiber.New()
+			app.Post("/work-orders", middleware.Transaction(), func(c *fiber.Ctx) error {
+				if err := publishEvent(c, events.WorkOrderCreated, models.WorkOrder{ID: 1, PlantID: 1}, ""); err != nil {
+					return eventError(c, err)
+				}
+				return c.SendStatus(tt.status)
+			})
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/work-orders", nil))
+			if err != nil {
+				t.Fatalf("serving request: %v", err)
+			}
+			resp.Body.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := bus.Close(ctx); err != nil {
+				t.Fatalf("closing bus: %v", err)
+			}
+
+			var sequence []string
+			for _, stmt := range recorder.Statements() {
+				sequence = append(sequence, strings.SplitN(stmt.SQL, " (", 2)[0])
+			}
+			want := []string{"BEGIN", `INSERT INTO "outbox_jobs"`, tt.wantEnd}
+			if strings.Join(sequence, "; ") != strings.Join(want, "; ") {
+				t.Errorf("statements %q, want %q", sequence, want)
+			}
+			if got := int(announced.Load()); got != tt.wantAnnounced {
+				t.Errorf("bus heard of %d events, want %d", got, tt.wantAnnounced)
+			}
+		})
+	}
+}
+
+// testTable holds the rows of a table, their first columns are id and plant_id
+type testTable struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// tableFilter matches a column compared to a bind variable, such as "audit_logs"."plant_id" = $2
+var tableFilter = regexp.MustCompile(`"(\w+)"\."(id|plant_id)" = \$(\d+)`)
+
+// storedRows answers the queries of the tables with their rows, filtered by the
+// id and the plant the query is scoped to, so lookups in another plant find nothing
+func storedRows(tables map[string]testTable) dbtest.Responder {
+	return func(stmt dbtest.Statement) dbtest.Rows {
+		var table string
+		for name := range tables {
+			if strings.Contains(stmt.SQL, `FROM "`+name+`"`) {
+				table = name
+			}
+		}
+		if table == "" {
+			return dbtest.Rows{}
+		}
+
+		var found [][]driver.Value
+		for _, row := range tables[table].rows {
+			matches := true
+			for _, filter := range tableFilter.FindAllStringSubmatch(stmt.SQL, -1) {
+				if filter[1] != table {
+					continue
+				}
+				column := 0
+				if filter[2] == "plant_id" {
+					column = 1
+				}
+				arg, _ := strconv.Atoi(filter[3])
+				if arg > len(stmt.Args) || stmt.Args[arg-1] != row[column] {
+					matches = false
+				}
+			}
+			if matches {
+				found = append(found, row)
+			}
+		}
+
+		if strings.HasPrefix(stmt.SQL, "SELECT count(*)") {
+			return dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{int64(len(found))}}}
+		}
+		return dbtest.Rows{Columns: tables[table].columns, Values: found}
+	}
+}
+
+// hasArgs reports whether the statement was run with every one of the values
+func hasArgs(stmt dbtest.Statement, values ...driver.Value) bool {
+	for _, value := range values {
+		found := false
+		for _, arg := range stmt.Args {
+			if arg == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// orderByTerm matches one term of an ORDER BY clause, such as created_at DESC
+var orderByTerm = regexp.MustCompile(`(\w+) (ASC|DESC)`)
+
+// queryOffset matches the offset of a page query
+var queryOffset = regexp.MustCompile(`OFFSET (\d+)`)
+
+// orderedPage answers a query of rows the way the database would: sorted by the terms
+// of its ORDER BY clause with the rows it leaves tied in any order, then cut to its
+// OFFSET and LIMIT
+func orderedPage(columns []string, rows [][]driver.Value, sql string) [][]driver.Value {
+	sorted := slices.Clone(rows)
+	rand.Shuffle(len(sorted), func(i, j int) { sorted[i], sorted[j] = sorted[j], sorted[i] })
+
+	var terms [][]string
+	if _, orderBy, found := strings.Cut(sql, "ORDER BY "); found {
+		terms = orderByTerm.FindAllStringSubmatch(orderBy, -1)
+	}
+	slices.SortStableFunc(sorted, func(a, b []driver.Value) int {
+		for _, term := range terms {
+			column := slices.Index(columns, term[1])
+			var order int
+			switch value := a[column].(type) {
+			case int64:
+				order = cmp.Compare(value, b[column].(int64))
+			case string:
+				order = strings.Compare(value, b[column].(string))
+			case time.Time:
+				order = value.Compare(b[column].(time.Time))
+			}
+			if term[2] == "DESC" {
+				order = -order
+			}
+			if order != 0 {
+				return order
+			}
+		}
+		return 0
+	})
+
+	if match := queryOffset.FindStringSubmatch(sql); match != nil {
+		offset, _ := strconv.Atoi(match[1])
+		sorted = sorted[min(offset, len(sorted)):]
+	}
+	if match := queryLimit.FindStringSubmatch(sql); match != nil {
+		limit, _ := strconv.Atoi(match[1])
+		sorted = sorted[:min(limit, len(sorted))]
+	}
+	return sorted
+}
+
+func TestIDParam(t *testing.T) {
+	tests := []struct {
+		param  string
+		wantID uint
+		wantOK bool
+	}{
+		{"1", 1, true},
+		{"42", 42, true},
+		{"0", 0, false},
+		{"-1", 0, false},
+		{"abc", 0, false},
+		{"1.5", 0, false},
+		{"1e3", 0, false},
+		{"99999999999999999999", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.param, func(t *testing.T) {
+			_, body := testRequest(t, "/items/:id", func(c *fiber.Ctx) error {
+				id, ok := idParam(c, "id")
+				return c.JSON(fiber.Map{"id": id, "ok": ok})
+			}, testUser{}, fiber.MethodGet, "/items/"+tt.param)
+			if body["id"] != float64(tt.wantID) || body["ok"] != tt.wantOK {
+				t.Errorf("idParam(%q) = %v, %v, want %d, %t", tt.param, body["id"], body["ok"], tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestInvalidIDIsRejectedBeforeTheDatabase(t *testing.T) {
+	// database.DB is not set, a handler reaching the database would panic
+	manager := testUser{id: 1, role: models.RoleProductionManager}
+
+	tests := []struct {
+		name    string
+		route   string
+		handler fiber.Handler
+		method  string
+		target  string
+	}{
+		{"work order", "/work-orders/:id", GetWorkOrderByID, fiber.MethodGet, "/work-orders/abc"},
+		{"status update", "/work-orders/:id/status", UpdateWorkOrderStatus, fiber.MethodPut, "/work-orders/0/status"},
+		{"progress entry", "/work-orders/:id/progress", CreateWorkOrderProgress, fiber.MethodPost, "/work-orders/-3/progress"},
+		{"audit log", "/audit-logs/:id", GetAuditLogByID, fiber.MethodGet, "/audit-logs/1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := testRequest(t, tt.route, tt.handler, manager, tt.method, tt.target)
+			if status != fiber.StatusBadRequest || body["code"] != CodeInvalidID {
+				t.Errorf("response %d %v, want 400 with code %s", status, body, CodeInvalidID)
+			}
+		})
+	}
+}
+
+func TestPageLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		maxPageSize int
+		want        int
+	}{
+		{"default", "", 100, 10},
+		{"requested limit", "?limit=25", 100, 25},
+		{"zero", "?limit=0", 100, 10},
+		{"negative", "?limit=-5", 100, 10},
+		{"not a number", "?limit=all", 100, 10},
+		{"at the maximum", "?limit=100", 100, 100},
+		{"above the maximum", "?limit=5000", 100, 100},
+		{"no maximum", "?limit=5000", 0, 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.AppConfig
+			config.AppConfig.MaxPageSize = tt.maxPageSize
+			t.Cleanup(func() { config.AppConfig = previous })
+
+			_, body := testRequest(t, "/items", func(c *fiber.Ctx) error {
+				return c.JSON(fiber.Map{"limit": pageLimit(c)})
+			}, testUser{}, fiber.MethodGet, "/items"+tt.query)
+			if body["limit"] != float64(tt.want) {
+				t.Errorf("pageLimit() = %v, want %d", body["limit"], tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlersWithoutAuthContext(t *testing.T) {
+	useScriptedDB(t, nil)
+
+	// Handlers mounted without Protected have no user in the context
+	handlers := map[string]fiber.Handler{
+		"GetWorkOrderAuditReport":         GetWorkOrderAuditReport,
+		"ChangePassword":                  ChangePassword,
+		"Me":                              Me,
+		"SetFeatureFlag":                  SetFeatureFlag,
+		"DeleteFeatureFlag":               DeleteFeatureFlag,
+		"ImpersonateOperator":             ImpersonateOperator,
+		"SetMaintenanceMode":              SetMaintenanceMode,
+		"GetMyNotificationPreferences":    GetMyNotificationPreferences,
+		"UpdateMyNotificationPreferences": UpdateMyNotificationPreferences,
+		"SnoozeOverdue":                   SnoozeOverdue,
+		"GetWorkOrderDashboard":           GetWorkOrderDashboard,
+		"GetWorkOrderKPIs":                GetWorkOrderKPIs,
+		"GetWorkOrderAging":               GetWorkOrderAging,
+		"GetOperatorPerformance":          GetOperatorPerformance,
+		"GetWorkOrderSummary":             GetWorkOrderSummary,
+		"GetWorkOrderSummaryByOperator":   GetWorkOrderSummaryByOperator,
+		"Search":                          Search,
+		"CreateTag":                       CreateTag,
+		"DeleteTag":                       DeleteTag,
+		"AddWorkOrderTags":                AddWorkOrderTags,
+		"RemoveWorkOrderTag":              RemoveWorkOrderTag,
+		"ResetUserPassword":               ResetUserPassword,
+		"GetWorkOrderBurndown":            GetWorkOrderBurndown,
+		"CreateWorkOrder":                 CreateWorkOrder,
+		"GetAssignedWorkOrders":           GetAssignedWorkOrders,
+		"GetWorkOrderInbox":               GetWorkOrderInbox,
+		"GetNextWorkOrder":                GetNextWorkOrder,
+		"BatchGetWorkOrders":              BatchGetWorkOrders,
+		"UpdateWorkOrder":                 UpdateWorkOrder,
+		"UpdateWorkOrderStatus":           UpdateWorkOrderStatus,
+		"GetWorkOrderTransitions":         GetWorkOrderTransitions,
+		"AcknowledgeWorkOrder":            AcknowledgeWorkOrder,
+		"AcknowledgeAllWorkOrders":        AcknowledgeAllWorkOrders,
+		"DeleteWorkOrder":                 DeleteWorkOrder,
+		"GetWorkOrderLogs":                GetWorkOrderLogs,
+		"CreateWorkOrderLog":              CreateWorkOrderLog,
+		"ImportWorkOrders":                ImportWorkOrders,
+		"CreateWorkOrderProgress":         CreateWorkOrderProgress,
+		"ApproveWorkOrderProgress":        ApproveWorkOrderProgress,
+		"GetWorkOrderProgress":            GetWorkOrderProgress,
+		"GetWorkOrderStatusHistory":       GetWorkOrderStatusHistory,
+		"GetWorkOrderStatusDurations":     GetWorkOrderStatusDurations,
+	}
+
+	for name, handler := range handlers {
+		t.Run(name, func(t *testing.T) {
+			status, body := testRequestBody(t, "/items/:id/:name", handler, testUser{}, fiber.MethodPost, "/items/1/urgent", `{}`)
+			if status != fiber.StatusUnauthorized {
+				t.Errorf("status = %d, want 401 (%v)", status, body)
+			}
+		})
+	}
+}
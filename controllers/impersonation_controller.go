@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ImpersonationResponse represents an operator impersonation token
+type ImpersonationResponse struct {
+	Error     bool      `json:"error"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Operator  UserDTO   `json:"operator"`
+}
+
+// @Summary Impersonate operator
+// @Description Issue a short-lived token to see and act as an operator for support, valid for IMPERSONATION_TTL minutes. Requests made with it are served as the operator and answered with an X-Impersonated-By header naming the manager, who is recorded as the actor of their audit logs. Impersonation ends by discarding the token. (Production Manager only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param operator_id path int true "Operator ID"
+// @Success 200 {object} ImpersonationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/impersonate/{operator_id} [post]
+func ImpersonateOperator(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	operatorID, ok := idParam(c, "operator_id")
+	if !ok {
+		return invalidIDError(c, "operator_id")
+	}
+
+	// Only active operators of the manager's plant can be impersonated
+	operator, err := findAssignableOperator(getDB(c), operatorID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Operator not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching operator",
+		})
+	}
+
+	token, expiresAt, err := middleware.GenerateImpersonationToken(&operator, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error generating token",
+		})
+	}
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		userID,
+		models.ActionCustom,
+		"User",
+		operator.ID,
+		nil,
+		nil,
+		fmt.Sprintf("Impersonation of operator %s started, valid until %s", operator.Username, expiresAt.Format(time.RFC3339)),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ImpersonationResponse{
+		Error:     false,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		Operator:  toUserDTO(operator),
+	})
+}
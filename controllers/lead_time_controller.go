@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// leadTimeGroupColumns maps the accepted group_by values to the column the lead times are grouped by
+var leadTimeGroupColumns = map[string]string{
+	"product":  "work_orders.product_name",
+	"operator": "users.username",
+}
+
+// LeadTimeStats represents the lead time distribution of a group of completed work orders
+type LeadTimeStats struct {
+	Group        string  `json:"group,omitempty"` // product name or operator username, empty for all orders
+	Orders       int     `json:"orders"`
+	AverageHours float64 `json:"average_hours"`
+	P50Hours     float64 `json:"p50_hours"`
+	P90Hours     float64 `json:"p90_hours"`
+	P99Hours     float64 `json:"p99_hours"`
+}
+
+// LeadTimeResponse represents a lead time percentiles response
+type LeadTimeResponse struct {
+	Error   bool            `json:"error"`
+	GroupBy string          `json:"group_by,omitempty"`
+	Overall LeadTimeStats   `json:"overall"`
+	Groups  []LeadTimeStats `json:"groups"` // empty without group_by
+}
+
+// leadTimeRow is the lead time of one completed work order
+type leadTimeRow struct {
+	Group string
+	Hours float64
+}
+
+// @Summary Get lead time percentiles
+// @Description Get the average and the p50, p90 and p99 lead time in hours from creating a work order to completing it, taken from the status history. Orders are dated by their completion and can be grouped by product or operator. Completed orders without a completion in the status history are left out. (Production Manager only)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Completed on or after this day (YYYY-MM-DD)"
+// @Param end_date query string false "Completed on or before this day (YYYY-MM-DD)"
+// @Param group_by query string false "Group by product or operator"
+// @Success 200 {object} LeadTimeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/lead-time [get]
+func GetLeadTimePercentiles(c *fiber.Ctx) error {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	groupBy := c.Query("group_by")
+
+	groupColumn := "''"
+	if groupBy != "" {
+		column, ok := leadTimeGroupColumns[groupBy]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "group_by must be product or operator",
+			})
+		}
+		groupColumn = column
+	}
+
+	// Lead time of every completed order up to its last completion in the status history
+	query := getDB(c).Model(&models.WorkOrder{}).
+		Select(groupColumn+" AS \"group\", EXTRACT(EPOCH FROM (h.completed_at - work_orders.created_at)) / 3600 AS hours").
+		Joins("JOIN (SELECT work_order_id, MAX(created_at) AS completed_at FROM work_order_status_histories WHERE status = ? GROUP BY work_order_id) h ON h.work_order_id = work_orders.id",
+			models.StatusCompleted).
+		Where("work_orders.status = ?", models.StatusCompleted)
+	if groupBy == "operator" {
+		query = query.Joins("JOIN users ON users.id = work_orders.operator_id")
+	}
+
+	var rows []leadTimeRow
+	if err := applyDateRange(query, "h.completed_at", startDate, endDate).
+		Scan(&rows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching lead times",
+		})
+	}
+
+	all := make([]float64, 0, len(rows))
+	grouped := map[string][]float64{}
+	for _, row := range rows {
+		all = append(all, row.Hours)
+		if groupBy != "" {
+			grouped[row.Group] = append(grouped[row.Group], row.Hours)
+		}
+	}
+
+	response := LeadTimeResponse{
+		Error:   false,
+		GroupBy: groupBy,
+		Overall: leadTimeStats("", all),
+		Groups:  make([]LeadTimeStats, 0, len(grouped)),
+	}
+	for group, hours := range grouped {
+		response.Groups = append(response.Groups, leadTimeStats(group, hours))
+	}
+	// Slowest groups first
+	sort.Slice(response.Groups, func(i, j int) bool {
+		if response.Groups[i].P90Hours != response.Groups[j].P90Hours {
+			return response.Groups[i].P90Hours > response.Groups[j].P90Hours
+		}
+		return response.Groups[i].Group < response.Groups[j].Group
+	})
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// leadTimeStats summarizes the lead times of a group of work orders
+func leadTimeStats(group string, hours []float64) LeadTimeStats {
+	stats := LeadTimeStats{Group: group, Orders: len(hours)}
+	if len(hours) == 0 {
+		return stats
+	}
+
+	sort.Float64s(hours)
+	total := 0.0
+	for _, h := range hours {
+		total += h
+	}
+	stats.AverageHours = roundTwoDecimals(total / float64(len(hours)))
+	stats.P50Hours = roundTwoDecimals(percentile(hours, 0.5))
+	stats.P90Hours = roundTwoDecimals(percentile(hours, 0.9))
+	stats.P99Hours = roundTwoDecimals(percentile(hours, 0.99))
+	return stats
+}
+
+// percentile returns the p-th percentile of the sorted values, interpolating
+// linearly between the closest ranks like Postgres' percentile_cont
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
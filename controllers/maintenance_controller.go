@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"log"
+
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MaintenanceModeRequest represents the maintenance mode toggle request body
+type MaintenanceModeRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// MaintenanceModeResponse represents the maintenance mode state
+type MaintenanceModeResponse struct {
+	Error       bool `json:"error"`
+	Maintenance bool `json:"maintenance"`
+}
+
+// @Summary Get maintenance mode
+// @Description Get whether the read-only maintenance mode is on
+// @Tags maintenance
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} MaintenanceModeResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /maintenance [get]
+func GetMaintenanceMode(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(MaintenanceModeResponse{
+		Error:       false,
+		Maintenance: middleware.MaintenanceMode(),
+	})
+}
+
+// @Summary Set maintenance mode
+// @Description Turn the read-only maintenance mode on or off. While it is on, write requests are answered with 503 and reads keep working. The mode is kept in memory, a restart falls back to MAINTENANCE_MODE. (Production Manager only)
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MaintenanceModeRequest true "Maintenance mode"
+// @Success 200 {object} MaintenanceModeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /maintenance [put]
+func SetMaintenanceMode(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	var req MaintenanceModeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid request body",
+		})
+	}
+
+	middleware.SetMaintenanceMode(req.Enabled)
+
+	note := "Maintenance mode turned off"
+	if req.Enabled {
+		note = "Maintenance mode turned on"
+	}
+	log.Printf("%s by user %d", note, userID)
+
+	// The audit log is written even while writes are blocked, it records who blocked them
+	if err := auditService.CreateLog(getDB(c), userID, models.ActionCustom, "Maintenance", 0, nil, nil, note); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MaintenanceModeResponse{
+		Error:       false,
+		Maintenance: req.Enabled,
+	})
+}
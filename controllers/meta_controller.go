@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// EnumsResponse lists the valid values of the enumerations clients offer in forms and filters
+type EnumsResponse struct {
+	Error                 bool                          `json:"error"`
+	WorkOrderStatuses     []models.WorkOrderStatus      `json:"work_order_statuses"`
+	RemainingDispositions []models.RemainingDisposition `json:"remaining_dispositions"`
+	Roles                 []models.Role                 `json:"roles"`
+	ActionTypes           []models.ActionType           `json:"action_types"`
+}
+
+// ServerTimeResponse tells clients the server's clock and the settings they align with
+type ServerTimeResponse struct {
+	Error               bool      `json:"error"`
+	ServerTime          time.Time `json:"server_time"`        // current time in UTC
+	Timezone            string    `json:"timezone"`           // APP_TIMEZONE, or the system timezone when unset
+	UTCOffsetSeconds    int       `json:"utc_offset_seconds"` // current offset of the timezone
+	TokenExpiresInHours int       `json:"token_expires_in_hours"`
+	MaxPageSize         int       `json:"max_page_size"` // largest accepted limit of the paginated lists
+}
+
+// @Summary Get enum values
+// @Description Get the valid work order statuses, remaining dispositions, user roles and audit log action types, taken from the backend constants. Public, the values are not sensitive.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} EnumsResponse
+// @Router /meta/enums [get]
+func GetEnums(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(EnumsResponse{
+		Error:                 false,
+		WorkOrderStatuses:     models.WorkOrderStatuses,
+		RemainingDispositions: models.RemainingDispositions,
+		Roles:                 models.Roles,
+		ActionTypes:           models.ActionTypes,
+	})
+}
+
+// @Summary Get server time
+// @Description Get the server's current UTC time, its timezone and offset, the token lifetime and the largest page size, so clients can align date pickers and countdowns with the backend. Public, the values are not sensitive.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} ServerTimeResponse
+// @Router /meta/time [get]
+func GetServerTime(c *fiber.Ctx) error {
+	now := time.Now()
+	timezone := config.AppConfig.AppTimezone
+	if timezone == "" {
+		timezone = now.Location().String()
+	}
+	_, offset := now.Zone()
+
+	return c.Status(fiber.StatusOK).JSON(ServerTimeResponse{
+		Error:               false,
+		ServerTime:          now.UTC(),
+		Timezone:            timezone,
+		UTCOffsetSeconds:    offset,
+		TokenExpiresInHours: config.AppConfig.TokenExpiresIn,
+		MaxPageSize:         config.AppConfig.MaxPageSize,
+	})
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestGetServerTime(t *testing.T) {
+	_, localOffset := time.Now().Zone()
+
+	tests := []struct {
+		name         string
+		timezone     string
+		wantTimezone string
+	}{
+		{"configured timezone", "Asia/Jakarta", "Asia/Jakarta"},
+		{"system timezone", "", time.Local.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.AppConfig
+			config.AppConfig.AppTimezone = tt.timezone
+			config.AppConfig.TokenExpiresIn = 12
+			config.AppConfig.MaxPageSize = 50
+			config.AppConfig.JWTSecret = "jwt-secret"
+			config.AppConfig.DBPassword = "db-pass"
+			t.Cleanup(func() { config.AppConfig = previous })
+
+			app := fiber.New()
+			app.Get("/meta/time", GetServerTime)
+			before := time.Now()
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/meta/time", nil))
+			if err != nil {
+				t.Fatalf("serving request: %v", err)
+			}
+			defer resp.Body.Close()
+			raw, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("reading response: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(raw, &body); err != nil {
+				t.Fatalf("decoding response %q: %v", raw, err)
+			}
+			serverTime, _ := body["server_time"].(string)
+			if !strings.HasSuffix(serverTime, "Z") {
+				t.Errorf("server_time %q is not in UTC", serverTime)
+			}
+			if at, err := time.Parse(time.RFC3339Nano, serverTime); err != nil || at.Before(before.Add(-time.Second)) || at.After(time.Now().Add(time.Second)) {
+				t.Errorf("server_time %q is not the current time", serverTime)
+			}
+			if body["timezone"] != tt.wantTimezone {
+				t.Errorf("timezone = %v, want %s", body["timezone"], tt.wantTimezone)
+			}
+			if body["utc_offset_seconds"] != float64(localOffset) {
+				t.Errorf("utc_offset_seconds = %v, want %d", body["utc_offset_seconds"], localOffset)
+			}
+			if body["token_expires_in_hours"] != float64(12) || body["max_page_size"] != float64(50) {
+				t.Errorf("settings %v, want a 12 hour token lifetime and a page size of 50", body)
+			}
+
+			// The endpoint is public, it reports the listed settings only
+			var fields []string
+			for field := range body {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			want := []string{"error", "max_page_size", "server_time", "timezone", "token_expires_in_hours", "utc_offset_seconds"}
+			if strings.Join(fields, ",") != strings.Join(want, ",") {
+				t.Errorf("fields %v, want %v", fields, want)
+			}
+			for _, secret := range []string{"jwt-secret", "db-pass"} {
+				if strings.Contains(string(raw), secret) {
+					t.Errorf("response %s contains %q", raw, secret)
+				}
+			}
+		})
+	}
+}
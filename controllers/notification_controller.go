@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+var notificationService = services.NotificationService{Sender: services.LogSender{}}
+
+// UpdateNotificationPreferencesRequest represents the update notification preferences request body.
+// Omitted fields keep their current value.
+type UpdateNotificationPreferencesRequest struct {
+	EmailOnAssignment *bool `json:"email_on_assignment"`
+	EmailOnOverdue    *bool `json:"email_on_overdue"`
+}
+
+// NotificationPreferencesResponse represents a notification preferences response
+type NotificationPreferencesResponse struct {
+	Error       bool                      `json:"error"`
+	Preferences NotificationPreferenceDTO `json:"preferences"`
+}
+
+// NotificationPreferenceSummary counts how many users opted in to each notification
+type NotificationPreferenceSummary struct {
+	Users             int64 `json:"users"`
+	EmailOnAssignment int64 `json:"email_on_assignment"`
+	EmailOnOverdue    int64 `json:"email_on_overdue"`
+}
+
+// NotificationPreferenceSummaryResponse represents a notification preference summary response
+type NotificationPreferenceSummaryResponse struct {
+	Error   bool                          `json:"error"`
+	Summary NotificationPreferenceSummary `json:"summary"`
+}
+
+// @Summary Get my notification preferences
+// @Description Get the notification preferences of the current user
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} NotificationPreferencesResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /me/notifications [get]
+func GetMyNotificationPreferences(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	preference, err := notificationService.Preferences(getDB(c), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching notification preferences",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(NotificationPreferencesResponse{
+		Error:       false,
+		Preferences: toNotificationPreferenceDTO(preference),
+	})
+}
+
+// @Summary Update my notification preferences
+// @Description Opt in or out of notifications for the current user
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateNotificationPreferencesRequest true "Notification preferences"
+// @Success 200 {object} NotificationPreferencesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /me/notifications [put]
+func UpdateMyNotificationPreferences(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	// Parse request body
+	var req UpdateNotificationPreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid request body",
+		})
+	}
+
+	preference, err := notificationService.Preferences(getDB(c), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching notification preferences",
+		})
+	}
+
+	if req.EmailOnAssignment != nil {
+		preference.EmailOnAssignment = *req.EmailOnAssignment
+	}
+	if req.EmailOnOverdue != nil {
+		preference.EmailOnOverdue = *req.EmailOnOverdue
+	}
+
+	if err := notificationService.SavePreferences(getDB(c), &preference); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error saving notification preferences",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(NotificationPreferencesResponse{
+		Error:       false,
+		Preferences: toNotificationPreferenceDTO(preference),
+	})
+}
+
+// @Summary Get notification preference summary
+// @Description Get how many users opted in to each notification (Production Manager only)
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} NotificationPreferenceSummaryResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /reports/notification-preferences [get]
+func GetNotificationPreferenceSummary(c *fiber.Ctx) error {
+	var summary NotificationPreferenceSummary
+	if err := getDB(c).Model(&models.User{}).Count(&summary.Users).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching users",
+		})
+	}
+
+	// Users without stored preferences count with the defaults
+	var preferences []models.NotificationPreference
+	if err := getDB(c).
+		Joins("JOIN users ON users.id = notification_preferences.user_id AND users.deleted_at IS NULL").
+		Find(&preferences).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching notification preferences",
+		})
+	}
+
+	defaults := models.DefaultNotificationPreference(0)
+	withoutPreferences := summary.Users - int64(len(preferences))
+	if defaults.EmailOnAssignment {
+		summary.EmailOnAssignment = withoutPreferences
+	}
+	if defaults.EmailOnOverdue {
+		summary.EmailOnOverdue = withoutPreferences
+	}
+	for _, preference := range preferences {
+		if preference.EmailOnAssignment {
+			summary.EmailOnAssignment++
+		}
+		if preference.EmailOnOverdue {
+			summary.EmailOnOverdue++
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(NotificationPreferenceSummaryResponse{
+		Error:   false,
+		Summary: summary,
+	})
+}
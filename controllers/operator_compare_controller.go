@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxComparedOperators caps how many operators one comparison may list
+const maxComparedOperators = 5
+
+// OperatorComparison represents one operator's metrics in a side by side comparison
+type OperatorComparison struct {
+	OperatorPerformance
+	AverageLeadTimeHours float64 `json:"average_lead_time_hours"` // from creation to completion of the completed orders
+	OnTimeRate           int64   `json:"on_time_rate"`            // percentage of completed orders finished by their deadline
+}
+
+// OperatorComparisonResponse represents an operator comparison response
+type OperatorComparisonResponse struct {
+	Error       bool                        `json:"error"`
+	OperatorIDs []uint                      `json:"operator_ids"` // in the requested order
+	Operators   map[uint]OperatorComparison `json:"operators"`
+}
+
+// completionStatsRow is the lead time and on-time count of an operator's completed orders
+type completionStatsRow struct {
+	OperatorID           uint
+	Completed            int64
+	OnTime               int64
+	AverageLeadTimeHours float64
+}
+
+// @Summary Compare operators
+// @Description Get the performance metrics of the listed operators side by side, keyed by operator ID: assigned, completed and quantity as in the performance report, the average lead time and the on-time rate of the completed orders. Orders are selected by their production deadline like the performance report, completions are taken from the status history. (Production Manager only)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param operators query string true "Comma separated operator IDs, 2 to 5"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} OperatorComparisonResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/compare [get]
+func GetOperatorComparison(c *fiber.Ctx) error {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	// Parse the operator IDs, keeping their order and dropping repeats
+	var operatorIDs []uint
+	seen := map[uint]bool{}
+	for _, value := range strings.Split(c.Query("operators"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   fmt.Sprintf("Invalid operator ID %q", value),
+			})
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			operatorIDs = append(operatorIDs, uint(id))
+		}
+	}
+	if len(operatorIDs) < 2 || len(operatorIDs) > maxComparedOperators {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   fmt.Sprintf("operators must list 2 to %d operator IDs", maxComparedOperators),
+		})
+	}
+
+	var operators []models.User
+	if err := getDB(c).Where("role = ? AND id IN ?", models.RoleOperator, operatorIDs).Find(&operators).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching operators",
+		})
+	}
+	operatorsByID := make(map[uint]models.User, len(operators))
+	for _, operator := range operators {
+		operatorsByID[operator.ID] = operator
+	}
+	for _, id := range operatorIDs {
+		if _, ok := operatorsByID[id]; !ok {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   fmt.Sprintf("Operator %d not found", id),
+			})
+		}
+	}
+
+	// Measure the throughput over the same period as the performance report
+	periodStart, periodEnd, err := performancePeriod(getDB(c), startDate, endDate)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error calculating report period",
+		})
+	}
+
+	// Lead time and on-time count of the completed orders, up to their last completion
+	// in the status history or the last update for orders completed before it was recorded
+	var stats []completionStatsRow
+	if err := applyDateRange(getDB(c).Model(&models.WorkOrder{}), "production_deadline", startDate, endDate).
+		Select("operator_id, COUNT(*) AS completed, "+
+			"COUNT(*) FILTER (WHERE completed_at <= production_deadline) AS on_time, "+
+			"COALESCE(AVG(EXTRACT(EPOCH FROM (completed_at - created_at)) / 3600), 0) AS average_lead_time_hours").
+		Table("(?) AS work_orders", completedWorkOrders(getDB(c)).Where("operator_id IN ?", operatorIDs)).
+		Group("operator_id").
+		Scan(&stats).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error calculating lead times",
+		})
+	}
+	statsByOperator := make(map[uint]completionStatsRow, len(stats))
+	for _, row := range stats {
+		statsByOperator[row.OperatorID] = row
+	}
+
+	comparison := make(map[uint]OperatorComparison, len(operatorIDs))
+	for _, id := range operatorIDs {
+		entry := OperatorComparison{
+			OperatorPerformance: operatorPerformance(getDB(c), operatorsByID[id], startDate, endDate, periodStart, periodEnd),
+		}
+		if row, ok := statsByOperator[id]; ok && row.Completed > 0 {
+			entry.AverageLeadTimeHours = roundTwoDecimals(row.AverageLeadTimeHours)
+			entry.OnTimeRate = row.OnTime * 100 / row.Completed
+		}
+		comparison[id] = entry
+	}
+
+	logReadAccess(c, "Viewed operator comparison report")
+
+	return c.Status(fiber.StatusOK).JSON(OperatorComparisonResponse{
+		Error:       false,
+		OperatorIDs: operatorIDs,
+		Operators:   comparison,
+	})
+}
+
+// completedWorkOrders selects the completed work orders with the time they were completed as completed_at:
+// their last completion in the status history, or the last update for orders completed before it was recorded
+func completedWorkOrders(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.WorkOrder{}).
+		Select("work_orders.*, COALESCE((SELECT MAX(h.created_at) FROM work_order_status_histories h WHERE h.work_order_id = work_orders.id AND h.status = ?), work_orders.updated_at) AS completed_at",
+			models.StatusCompleted).
+		Where("work_orders.status = ?", models.StatusCompleted)
+}
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/locale"
+	"github.com/dawamr/work-order-system-go/utils/xlsx"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// xlsxContentType is the content type of XLSX downloads
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// completedOrderRow is a completed work order with the time it was completed
+type completedOrderRow struct {
+	WorkOrderNumber    string
+	ProductName        string
+	Quantity           int
+	TargetQuantity     int
+	Unit               string
+	ProductionDeadline time.Time
+	CreatedAt          time.Time
+	CompletedAt        time.Time
+}
+
+// monthlyPerformance accumulates an operator's completed orders of one month
+type monthlyPerformance struct {
+	month         time.Time
+	orders        int
+	quantity      map[string]int // per unit
+	onTime        int
+	leadTimeHours float64
+}
+
+// @Summary Export operator performance history
+// @Description Download an XLSX workbook with the operator's completed work orders in the date range, their quantities, lead times and on-time rate, and a month-by-month breakdown. Orders are dated by their completion, the range defaults to the current year. (Production Manager only)
+// @Tags reports
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path int true "Operator ID"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param locale query string false "Date format: iso (default), raw, en-US or id-ID; falls back to Accept-Language"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /operators/{id}/report/export [get]
+func ExportOperatorReport(c *fiber.Ctx) error {
+	// Get operator ID from URL
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	format := locale.FromRequest(c.Query("locale"), c.Get(fiber.HeaderAcceptLanguage))
+
+	var operator models.User
+	result := getDB(c).Where("role = ?", models.RoleOperator).First(&operator, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Operator not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching operator",
+		})
+	}
+
+	// Completed orders dated by their last completion in the status history,
+	// falling back to the last update for orders completed before it was recorded
+	completed := getDB(c).Model(&models.WorkOrder{}).
+		Select("work_order_number, product_name, quantity, target_quantity, unit, production_deadline, created_at, "+
+			"COALESCE((SELECT MAX(h.created_at) FROM work_order_status_histories h WHERE h.work_order_id = work_orders.id AND h.status = ?), updated_at) AS completed_at",
+			models.StatusCompleted).
+		Where("operator_id = ? AND status = ?", operator.ID, models.StatusCompleted)
+
+	var orders []completedOrderRow
+	if err := applySummaryDateRange(getDB(c).Table("(?) AS completed", completed), "completed_at", startDate, endDate).
+		Order("completed_at ASC").
+		Scan(&orders).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching completed work orders",
+		})
+	}
+
+	// Orders sheet, accumulating the totals and months on the way
+	orderRows := [][]interface{}{{
+		"Work Order Number", "Product", "Target Quantity", "Quantity", "Unit", "Created", "Deadline", "Completed", "Lead Time (days)", "On Time",
+	}}
+	// Quantities are totalled per unit, different units never add up
+	totalQuantity := map[string]int{}
+	var onTime int
+	var totalLeadTimeHours float64
+	var months []*monthlyPerformance
+	for _, order := range orders {
+		leadTimeHours := order.CompletedAt.Sub(order.CreatedAt).Hours()
+		orderOnTime := !order.CompletedAt.After(order.ProductionDeadline)
+
+		totalQuantity[order.Unit] += order.Quantity
+		totalLeadTimeHours += leadTimeHours
+		onTimeLabel := "No"
+		if orderOnTime {
+			onTime++
+			onTimeLabel = "Yes"
+		}
+
+		// Orders are sorted by completion, so a new month always starts a new entry
+		month := time.Date(order.CompletedAt.Year(), order.CompletedAt.Month(), 1, 0, 0, 0, 0, order.CompletedAt.Location())
+		if len(months) == 0 || !months[len(months)-1].month.Equal(month) {
+			months = append(months, &monthlyPerformance{month: month, quantity: map[string]int{}})
+		}
+		current := months[len(months)-1]
+		current.orders++
+		current.quantity[order.Unit] += order.Quantity
+		current.leadTimeHours += leadTimeHours
+		if orderOnTime {
+			current.onTime++
+		}
+
+		orderRows = append(orderRows, []interface{}{
+			order.WorkOrderNumber,
+			order.ProductName,
+			order.TargetQuantity,
+			order.Quantity,
+			order.Unit,
+			format.DateTime(order.CreatedAt),
+			format.DateTime(order.ProductionDeadline),
+			format.DateTime(order.CompletedAt),
+			roundTwoDecimals(leadTimeHours / 24),
+			onTimeLabel,
+		})
+	}
+
+	// Month-by-month breakdown
+	monthRows := [][]interface{}{{
+		"Month", "Completed Orders", "Quantity", "On Time Orders", "On Time Rate (%)", "Average Lead Time (days)",
+	}}
+	for _, month := range months {
+		monthRows = append(monthRows, []interface{}{
+			month.month.Format("2006-01"),
+			month.orders,
+			formatUnitQuantities(month.quantity),
+			month.onTime,
+			roundTwoDecimals(float64(month.onTime) / float64(month.orders) * 100),
+			roundTwoDecimals(month.leadTimeHours / 24 / float64(month.orders)),
+		})
+	}
+
+	// Summary of the whole range, showing the current year defaults of missing dates
+	now := time.Now()
+	if startDate == "" {
+		startDate = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()).Format(time.DateOnly)
+	}
+	if endDate == "" {
+		endDate = time.Date(now.Year(), 12, 31, 0, 0, 0, 0, now.Location()).Format(time.DateOnly)
+	}
+	onTimeRate, averageLeadTime := 0.0, 0.0
+	if len(orders) > 0 {
+		onTimeRate = roundTwoDecimals(float64(onTime) / float64(len(orders)) * 100)
+		averageLeadTime = roundTwoDecimals(totalLeadTimeHours / 24 / float64(len(orders)))
+	}
+	summaryRows := [][]interface{}{
+		{"Operator", operator.Username},
+		{"Start Date", startDate},
+		{"End Date", endDate},
+		{"Completed Orders", len(orders)},
+	}
+	if len(totalQuantity) == 0 {
+		summaryRows = append(summaryRows, []interface{}{"Total Quantity", 0})
+	}
+	for _, unit := range sortedUnits(totalQuantity) {
+		summaryRows = append(summaryRows, []interface{}{fmt.Sprintf("Total Quantity (%s)", unit), totalQuantity[unit]})
+	}
+	summaryRows = append(summaryRows,
+		[]interface{}{"On Time Orders", onTime},
+		[]interface{}{"On Time Rate (%)", onTimeRate},
+		[]interface{}{"Average Lead Time (days)", averageLeadTime},
+		[]interface{}{"Generated At", format.DateTime(now)},
+	)
+
+	var workbook xlsx.Workbook
+	workbook.AddSheet("Summary", summaryRows)
+	workbook.AddSheet("Orders", orderRows)
+	workbook.AddSheet("Monthly", monthRows)
+
+	logReadAccess(c, "Exported operator performance report")
+
+	filename := fmt.Sprintf("operator-%s-performance-%s.xlsx", operator.Username, now.Format(time.DateOnly))
+	c.Set(fiber.HeaderContentType, xlsxContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return workbook.Write(c)
+}
+
+// sortedUnits returns the units of a per-unit quantity map in alphabetical order
+func sortedUnits(quantities map[string]int) []string {
+	units := make([]string, 0, len(quantities))
+	for unit := range quantities {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+	return units
+}
+
+// formatUnitQuantities formats per-unit quantities as one cell, e.g. "120 pcs, 35 kg"
+func formatUnitQuantities(quantities map[string]int) string {
+	parts := make([]string, 0, len(quantities))
+	for _, unit := range sortedUnits(quantities) {
+		parts = append(parts, fmt.Sprintf("%d %s", quantities[unit], unit))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OutboxJobListResponse represents a paginated list of outbox jobs
+type OutboxJobListResponse struct {
+	Error      bool               `json:"error"`
+	Jobs       []models.OutboxJob `json:"jobs"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+// @Summary Get outbox jobs
+// @Description Get the background jobs of notifications and webhook deliveries of the plant, newest first. By default the dead-lettered ones, which failed on every attempt, with their last error. (Production Manager only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, running, done or dead (default)"
+// @Param kind query string false "Job kind, e.g. webhook or notification.assignment"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Success 200 {object} OutboxJobListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/jobs [get]
+func GetOutboxJobs(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := pageLimit(c)
+	status := models.OutboxJobStatus(c.Query("status", string(models.JobDead)))
+	kind := c.Query("kind")
+
+	valid := false
+	for _, s := range models.OutboxJobStatuses {
+		valid = valid || s == status
+	}
+	if !valid {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   fmt.Sprintf("status must be one of %v", models.OutboxJobStatuses),
+		})
+	}
+
+	query := getDB(c).Model(&models.OutboxJob{}).Where("status = ?", status)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var count int64
+	query.Count(&count)
+
+	jobs := []models.OutboxJob{}
+	if err := query.Order("updated_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&jobs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching outbox jobs",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(OutboxJobListResponse{
+		Error: false,
+		Jobs:  jobs,
+		Pagination: Pagination{
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
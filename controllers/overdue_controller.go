@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// maxOverdueSnoozeHours caps how long overdue reminders can be snoozed at once
+const maxOverdueSnoozeHours = 30 * 24
+
+// SnoozeOverdueRequest represents the snooze overdue reminders request body
+type SnoozeOverdueRequest struct {
+	DurationHours int    `json:"duration_hours" validate:"required,min=1"`
+	Note          string `json:"note"`
+}
+
+// @Summary Snooze overdue reminders
+// @Description Acknowledge that a work order is overdue and stop its overdue reminders for the given number of hours, at most 30 days (Production Manager only)
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param request body SnoozeOverdueRequest true "Snooze duration"
+// @Success 200 {object} WorkOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/snooze-overdue [post]
+func SnoozeOverdue(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	// Parse request body
+	var req SnoozeOverdueRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid request body",
+		})
+	}
+	if req.DurationHours < 1 || req.DurationHours > maxOverdueSnoozeHours {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   fmt.Sprintf("duration_hours must be between 1 and %d", maxOverdueSnoozeHours),
+		})
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	// Get work order from database
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	// Only overdue work orders have reminders to snooze
+	now := time.Now()
+	var overdue int64
+	if err := getDB(c).Model(&models.WorkOrder{}).
+		Scopes(models.ScopeOverdue(now)).
+		Where("id = ?", workOrder.ID).
+		Count(&overdue).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error checking overdue work order",
+		})
+	}
+	if overdue == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Work order is not overdue",
+		})
+	}
+
+	oldWorkOrder := workOrder
+	until := now.Add(time.Duration(req.DurationHours) * time.Hour)
+	workOrder.OverdueSnoozedUntil = &until
+
+	// Record the acknowledgement and keep the current snooze on the work order
+	err := getDB(c).Transaction(func(tx *gorm.DB) error {
+		acknowledgement := models.OverdueAcknowledgement{
+			WorkOrderID:      workOrder.ID,
+			AcknowledgedByID: userID,
+			Until:            until,
+			Note:             req.Note,
+		}
+		if err := tx.Create(&acknowledgement).Error; err != nil {
+			return err
+		}
+		return tx.Model(&workOrder).Update("overdue_snoozed_until", until).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error snoozing overdue reminders",
+		})
+	}
+
+	note := fmt.Sprintf("Overdue reminders of work order %s snoozed until %s", workOrder.WorkOrderNumber, until.Format(time.RFC3339))
+	if req.Note != "" {
+		note += ": " + req.Note
+	}
+	if err := auditService.CreateLog(
+		getDB(c),
+		userID,
+		models.ActionCustom,
+		"WorkOrder",
+		workOrder.ID,
+		oldWorkOrder,
+		workOrder,
+		note,
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
+		Error:     false,
+		WorkOrder: toWorkOrderDTO(workOrder),
+	})
+}
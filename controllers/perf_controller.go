@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PerfResponse represents the slowest routes report
+type PerfResponse struct {
+	Error   bool                     `json:"error"`
+	Enabled bool                     `json:"enabled"` // PERF_PROFILER, nothing is recorded when false
+	Since   time.Time                `json:"since"`   // startup or the last reset
+	Routes  []middleware.RouteTiming `json:"routes"`  // slowest p95 first
+}
+
+// @Summary Get slowest routes
+// @Description Get the routes with the slowest p95 response time recorded in memory by this instance since startup or the last reset, with their call count, mean, p50, p95 and maximum in milliseconds. Percentiles are estimated from a latency histogram. (Production Manager only)
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of routes (default: 20)"
+// @Param reset query bool false "Clear the recorded timings after reading them"
+// @Success 200 {object} PerfResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /admin/perf [get]
+func GetPerfStats(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 20)
+	routes, since := middleware.RouteTimings(c.QueryBool("reset", false))
+	if limit > 0 && len(routes) > limit {
+		routes = routes[:limit]
+	}
+
+	return c.Status(fiber.StatusOK).JSON(PerfResponse{
+		Error:   false,
+		Enabled: config.AppConfig.PerfProfiler,
+		Since:   since,
+		Routes:  routes,
+	})
+}
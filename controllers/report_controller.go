@@ -1,17 +1,23 @@
 package controllers
 
 import (
+	"fmt"
 	"log"
+	"math"
+	"net/url"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/config"
 	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/services"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
+var dailyCounterService = services.DailyCounterService{}
+
 // WorkOrderDashboard represents a summary of work orders by status
 type WorkOrderDashboard struct {
 	Status    models.WorkOrderStatus `json:"status"`
@@ -21,6 +27,8 @@ type WorkOrderDashboard struct {
 type WorkOrderSummary struct {
 	WorkOrderNumber string `json:"work_order_number"`
 	ProductName     string `json:"product_name"`
+	Unit            string `json:"unit"`                  // unit of the quantities, empty on a total row with mixed units
+	MixedUnits      bool   `json:"mixed_units,omitempty"` // set on the total row when products use different units, its quantities are then left at zero
 	TotalWO         int64  `json:"total_wo"`
 	Percentage      int64  `json:"percentage"`
 	TargetQty       int64  `json:"target_qty"`
@@ -28,18 +36,77 @@ type WorkOrderSummary struct {
 	Achievement     int64  `json:"achievement"`
 	Pending         int64  `json:"pending"`
 	InProgress      int64  `json:"in_progress"`
+	OnHold          int64  `json:"on_hold"`
 	Completed       int64  `json:"completed"`
 	Cancelled       int64  `json:"cancelled"`
+	// Completed orders whose remaining quantity was cancelled, the cancelled quantity and how many noted a restock
+	PartiallyCancelled int64 `json:"partially_cancelled"`
+	CancelledQty       int64 `json:"cancelled_qty"`
+	Restocked          int64 `json:"restocked"`
 }
 
 // OperatorPerformance represents an operator's performance metrics
 type OperatorPerformance struct {
-	OperatorID   uint   `json:"operator_id"`
-	Username     string `json:"username"`
-	Assigned     int64  `json:"assigned"`
-	InProgress   int64  `json:"in_progress"`
-	Completed    int64  `json:"completed"`
-	TotalQuantity int64 `json:"total_quantity"`
+	OperatorID               uint    `json:"operator_id"`
+	Username                 string  `json:"username"`
+	Assigned                 int64   `json:"assigned"`
+	InProgress               int64   `json:"in_progress"`
+	Completed                int64   `json:"completed"`
+	TotalQuantity            int64   `json:"total_quantity"`
+	ThroughputPerDay         float64 `json:"throughput_per_day"`          // completed quantity per day in the range
+	TrailingThroughputPerDay float64 `json:"trailing_throughput_per_day"` // same metric over the preceding period of equal length
+	Flagged                  bool    `json:"flagged"`
+}
+
+// OperatorStatusMatrixRow represents one operator's work order counts per status
+type OperatorStatusMatrixRow struct {
+	OperatorID       uint   `json:"operator_id"`
+	Username         string `json:"username"`
+	Pending          int64  `json:"pending"`
+	InProgress       int64  `json:"in_progress"`
+	OnHold           int64  `json:"on_hold"`
+	Completed        int64  `json:"completed"`
+	Total            int64  `json:"total"`
+	ProducedQuantity int64  `json:"produced_quantity"`
+}
+
+// OperatorStatusMatrixResponse represents the operator by status matrix
+type OperatorStatusMatrixResponse struct {
+	Error     bool                      `json:"error"`
+	Operators []OperatorStatusMatrixRow `json:"operators"`
+}
+
+// WorkOrderKPIs represents the headline numbers of the dashboard
+type WorkOrderKPIs struct {
+	TotalOrders     int64 `json:"total_orders"`
+	CompletedOrders int64 `json:"completed_orders"`
+	CompletionRate  int64 `json:"completion_rate"` // percentage of completed orders
+	OverdueOrders   int64 `json:"overdue_orders"`
+	ActiveOperators int64 `json:"active_operators"` // operators holding in-progress orders
+}
+
+// AgingBucket is the number of open work orders whose age since creation falls in a range
+type AgingBucket struct {
+	Bucket string `json:"bucket"` // 0-1d, 1-3d, 3-7d or 7+d
+	Orders int64  `json:"orders"`
+}
+
+// AgingResponse represents the open work order aging report
+type AgingResponse struct {
+	Error   bool          `json:"error"`
+	Buckets []AgingBucket `json:"buckets"` // youngest first
+}
+
+// KPIResponse represents a dashboard KPI response
+type KPIResponse struct {
+	Error bool          `json:"error"`
+	KPIs  WorkOrderKPIs `json:"kpis"`
+}
+
+// DailyProductionResponse represents a daily production counters response
+type DailyProductionResponse struct {
+	Error    bool                            `json:"error"`
+	Counters []models.DailyProductionCounter `json:"counters"`
 }
 
 // SummaryResponse represents a work order summary response
@@ -53,6 +120,23 @@ type SummaryResponse struct {
 	Summary []WorkOrderSummary `json:"summary"`
 }
 
+// WeeklyLoad represents the open work orders due in one week
+type WeeklyLoad struct {
+	WeekStart           string `json:"week_start"` // Monday, YYYY-MM-DD
+	Week                string `json:"week"`       // ISO week, e.g. 2026-W42
+	Orders              int64  `json:"orders"`
+	TotalTargetQuantity int64  `json:"total_target_quantity"`
+}
+
+// UpcomingLoadResponse represents the upcoming load report
+type UpcomingLoadResponse struct {
+	Error bool         `json:"error"`
+	Weeks []WeeklyLoad `json:"weeks"`
+}
+
+// maxUpcomingLoadWeeks caps how many weeks ahead the upcoming load report may look
+const maxUpcomingLoadWeeks = 52
+
 // PerformanceResponse represents an operator performance response
 type PerformanceResponse struct {
 	Error        bool                  `json:"error"`
@@ -72,50 +156,44 @@ type PerformanceResponse struct {
 // @Failure 403 {object} ErrorResponse
 // @Router /reports/dashboard [get]
 func GetWorkOrderDashboard(c *fiber.Ctx) error {
-	role := c.Locals("role").(models.Role)
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
 
 	// Build base query
-	baseQuery := database.DB.Model(&models.WorkOrder{})
+	baseQuery := getDB(c).Model(&models.WorkOrder{})
 
 	// Apply date filters if provided
-	if startDate != "" {
-		startTime, err := time.Parse(time.DateOnly, startDate)
-		if err == nil {
-			baseQuery = baseQuery.Where("created_at >= ?", startTime)
-		}
-	}
-	if endDate != "" {
-		endTime, err := time.Parse(time.DateOnly, endDate)
-		if err == nil {
-			// Add one day to include the end date
-			endTime = endTime.Add(24 * time.Hour)
-			baseQuery = baseQuery.Where("created_at < ?", endTime)
-		}
-	}
+	baseQuery = applyDateRange(baseQuery, "created_at", startDate, endDate)
 	if role != models.RoleProductionManager {
-		baseQuery = baseQuery.Where("operator_id = ?", c.Locals("user_id").(uint))
+		baseQuery = baseQuery.Where("operator_id = ?", userID)
 	}
 
 	// Get summary by status using cloned queries
 	var pendingSummary WorkOrderDashboard
 	var inProgressSummary WorkOrderDashboard
+	var onHoldSummary WorkOrderDashboard
 	var completedSummary WorkOrderDashboard
 	var totalWorkOrderCount WorkOrderDashboard
 
 	// Clone base query for each status count
 	baseQuery.Session(&gorm.Session{}).Where("status = ?", models.StatusPending).Count(&pendingSummary.Count)
 	baseQuery.Session(&gorm.Session{}).Where("status = ?", models.StatusInProgress).Count(&inProgressSummary.Count)
+	baseQuery.Session(&gorm.Session{}).Where("status = ?", models.StatusOnHold).Count(&onHoldSummary.Count)
 	baseQuery.Session(&gorm.Session{}).Where("status = ?", models.StatusCompleted).Count(&completedSummary.Count)
 	baseQuery.Session(&gorm.Session{}).Count(&totalWorkOrderCount.Count)
 
 	pendingSummary.Status = models.StatusPending
 	inProgressSummary.Status = models.StatusInProgress
+	onHoldSummary.Status = models.StatusOnHold
 	completedSummary.Status = models.StatusCompleted
 	totalWorkOrderCount.Status = "total"
 
-	summaries := []WorkOrderDashboard{pendingSummary, inProgressSummary, completedSummary, totalWorkOrderCount}
+	summaries := []WorkOrderDashboard{pendingSummary, inProgressSummary, onHoldSummary, completedSummary, totalWorkOrderCount}
 
 	return c.Status(fiber.StatusOK).JSON(DashboardResponse{
 		Error:   false,
@@ -123,6 +201,232 @@ func GetWorkOrderDashboard(c *fiber.Ctx) error {
 	})
 }
 
+// @Summary Get dashboard KPIs
+// @Description Get total orders, completion rate, overdue count and active operators in one call. Operators only see their own orders.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} KPIResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /reports/kpis [get]
+func GetWorkOrderKPIs(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	// Build base query with the same scope as the dashboard
+	baseQuery := getDB(c).Model(&models.WorkOrder{})
+	baseQuery = applyDateRange(baseQuery, "created_at", startDate, endDate)
+	if role != models.RoleProductionManager {
+		baseQuery = baseQuery.Where("operator_id = ?", userID)
+	}
+
+	var kpis WorkOrderKPIs
+	baseQuery.Session(&gorm.Session{}).Count(&kpis.TotalOrders)
+	baseQuery.Session(&gorm.Session{}).Where("status = ?", models.StatusCompleted).Count(&kpis.CompletedOrders)
+	baseQuery.Session(&gorm.Session{}).Scopes(models.ScopeOverdue(time.Now())).Count(&kpis.OverdueOrders)
+	baseQuery.Session(&gorm.Session{}).
+		Where("status = ?", models.StatusInProgress).
+		Distinct("operator_id").
+		Count(&kpis.ActiveOperators)
+
+	if kpis.TotalOrders > 0 {
+		kpis.CompletionRate = int64(float64(kpis.CompletedOrders) / float64(kpis.TotalOrders) * 100)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(KPIResponse{
+		Error: false,
+		KPIs:  kpis,
+	})
+}
+
+// @Summary Get work order aging
+// @Description Get the number of non-completed work orders by age since creation: 0-1d, 1-3d, 3-7d and 7+d. Operators only see their own orders.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} AgingResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/aging [get]
+func GetWorkOrderAging(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	query := getDB(c).Model(&models.WorkOrder{}).Where("status <> ?", models.StatusCompleted)
+	if role != models.RoleProductionManager {
+		query = query.Where("operator_id = ?", userID)
+	}
+
+	// All buckets in one pass, each bucket includes its lower bound
+	now := time.Now()
+	day := 24 * time.Hour
+	var counts struct {
+		UpToOneDay    int64
+		UpToThreeDays int64
+		UpToSevenDays int64
+		OverSevenDays int64
+	}
+	if err := query.Select("COUNT(*) FILTER (WHERE created_at > ?) AS up_to_one_day, "+
+		"COUNT(*) FILTER (WHERE created_at <= ? AND created_at > ?) AS up_to_three_days, "+
+		"COUNT(*) FILTER (WHERE created_at <= ? AND created_at > ?) AS up_to_seven_days, "+
+		"COUNT(*) FILTER (WHERE created_at <= ?) AS over_seven_days",
+		now.Add(-day),
+		now.Add(-day), now.Add(-3*day),
+		now.Add(-3*day), now.Add(-7*day),
+		now.Add(-7*day)).
+		Scan(&counts).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error calculating work order aging",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(AgingResponse{
+		Error: false,
+		Buckets: []AgingBucket{
+			{Bucket: "0-1d", Orders: counts.UpToOneDay},
+			{Bucket: "1-3d", Orders: counts.UpToThreeDays},
+			{Bucket: "3-7d", Orders: counts.UpToSevenDays},
+			{Bucket: "7+d", Orders: counts.OverSevenDays},
+		},
+	})
+}
+
+// @Summary Get work orders of a summary product
+// @Description Get the paginated work orders behind a product row of the work order summary, using the same date range (Production Manager only)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param product_name path string true "Product name"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param min_quantity query int false "Minimum ordered (target) quantity"
+// @Param max_quantity query int false "Maximum ordered (target) quantity"
+// @Param unit query string false "Unit of the summary row, e.g. pcs"
+// @Param status query string false "Filter by status (pending/in_progress/on_hold/completed)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Success 200 {object} WorkOrderListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /reports/summary/product/{product_name}/orders [get]
+func GetSummaryProductWorkOrders(c *fiber.Ctx) error {
+	productName, err := url.PathUnescape(c.Params("product_name"))
+	if err != nil || productName == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid product name",
+		})
+	}
+
+	// Get query parameters
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	status := c.Query("status")
+	page := c.QueryInt("page", 1)
+	limit := pageLimit(c)
+
+	minQuantity, maxQuantity, err := parseQuantityRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Build query with the same scope as the summary
+	query := preloadUnscoped(getDB(c).Model(&models.WorkOrder{}), "Operator").
+		Where("product_name = ?", productName)
+	query = applySummaryDateRange(query, "production_deadline", startDate, endDate)
+	query = applyQuantityRange(query, minQuantity, maxQuantity)
+	if unit := c.Query("unit"); unit != "" {
+		query = query.Where("unit = ?", unit)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Get total count
+	var count int64
+	query.Count(&count)
+
+	// Get work orders with pagination
+	var workOrders []models.WorkOrder
+	if err := query.Offset(offset).Limit(limit).Order("work_order_number DESC").Find(&workOrders).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work orders",
+		})
+	}
+
+	// Return work orders with pagination info
+	return c.Status(fiber.StatusOK).JSON(WorkOrderListResponse{
+		Error:      false,
+		WorkOrders: toWorkOrderDTOs(workOrders),
+		Pagination: Pagination{
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// @Summary Get daily production
+// @Description Get the precomputed daily production counters per product (Production Manager only)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param product_name query string false "Filter by product name"
+// @Success 200 {object} DailyProductionResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /reports/daily [get]
+func GetDailyProduction(c *fiber.Ctx) error {
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+	productName := c.Query("product_name")
+
+	query := getDB(c).Model(&models.DailyProductionCounter{})
+	query = applyDateRange(query, "date", startDate, endDate)
+	if productName != "" {
+		query = query.Where("product_name = ?", productName)
+	}
+
+	counters := []models.DailyProductionCounter{}
+	if err := query.Order("date DESC, product_name ASC").Find(&counters).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching daily production",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(DailyProductionResponse{
+		Error:    false,
+		Counters: counters,
+	})
+}
+
 // @Summary Get operator performance
 // @Description Get performance metrics for operators (Production Manager only)
 // @Tags reports
@@ -137,7 +441,10 @@ func GetWorkOrderDashboard(c *fiber.Ctx) error {
 // @Router /reports/performance [get]
 func GetOperatorPerformance(c *fiber.Ctx) error {
 	// Only Production Manager can view reports
-	role := c.Locals("role").(models.Role)
+	role, ok := getRole(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
 	if role != models.RoleProductionManager {
 		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Error: true,
@@ -151,65 +458,27 @@ func GetOperatorPerformance(c *fiber.Ctx) error {
 
 	// Get all operators
 	var operators []models.User
-	if err := database.DB.Where("role = ?", models.RoleOperator).Find(&operators).Error; err != nil {
+	if err := getDB(c).Where("role = ?", models.RoleOperator).Find(&operators).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Error fetching operators",
 		})
 	}
 
-	// Prepare performance data
-	var performances []OperatorPerformance
-
-	for _, operator := range operators {
-		performance := OperatorPerformance{
-			OperatorID: operator.ID,
-			Username:   operator.Username,
-		}
-
-		// Build base query for this operator
-		baseQuery := database.DB.Model(&models.WorkOrder{}).Where("operator_id = ?", operator.ID)
-
-		// Apply date filters if provided
-		if startDate != "" {
-			startTime, err := time.Parse(time.DateOnly, startDate)
-			if err == nil {
-				baseQuery = baseQuery.Where("production_deadline >= ?", startTime)
-			}
-		}
-		if endDate != "" {
-			endTime, err := time.Parse(time.DateOnly, endDate)
-			if err == nil {
-				// Add one day to include the end date
-				endTime = endTime.Add(24 * time.Hour)
-				baseQuery = baseQuery.Where("production_deadline < ?", endTime)
-			}
-		}
-
-		// Get total assigned work orders
-		baseQuery.Session(&gorm.Session{}).Count(&performance.Assigned)
-
-		// Get work orders in progress
-		baseQuery.Session(&gorm.Session{}).
-			Where("status = ?", models.StatusInProgress).
-			Count(&performance.InProgress)
+	// Work out the period the throughput is measured over
+	periodStart, periodEnd, err := performancePeriod(getDB(c), startDate, endDate)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error calculating report period",
+		})
+	}
 
-		// Get completed work orders
-		baseQuery.Session(&gorm.Session{}).
-			Where("status = ?", models.StatusCompleted).
-			Count(&performance.Completed)
+	// Prepare performance data, an empty list rather than null without operators
+	performances := make([]OperatorPerformance, 0, len(operators))
 
-		// Get total quantity of completed work orders
-		var totalQuantity int64
-		if err := baseQuery.Session(&gorm.Session{}).
-			Where("status = ?", models.StatusCompleted).
-			Select("COALESCE(SUM(quantity), 0)").
-			Row().Scan(&totalQuantity); err != nil {
-			log.Printf("Error calculating total quantity for operator %d: %v", operator.ID, err)
-		}
-		performance.TotalQuantity = totalQuantity
-
-		performances = append(performances, performance)
+	for _, operator := range operators {
+		performances = append(performances, operatorPerformance(getDB(c), operator, startDate, endDate, periodStart, periodEnd))
 	}
 
 	// performance sort by completed descending
@@ -218,12 +487,79 @@ func GetOperatorPerformance(c *fiber.Ctx) error {
 	})
 
 	// Return performance data
+	logReadAccess(c, "Viewed operator performance report")
+
 	return c.Status(fiber.StatusOK).JSON(PerformanceResponse{
 		Error:        false,
 		Performances: performances,
 	})
 }
 
+// @Summary Get upcoming load
+// @Description Get the number and total target quantity of non-completed work orders due in each of the next weeks, starting with the current ISO week. Weeks without orders are included with zero counts. (Production Manager only)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param weeks query int false "Number of weeks including the current one, at most 52 (default: UPCOMING_LOAD_WEEKS)"
+// @Success 200 {object} UpcomingLoadResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/upcoming-load [get]
+func GetUpcomingLoad(c *fiber.Ctx) error {
+	weeks := c.QueryInt("weeks", config.AppConfig.UpcomingLoadWeeks)
+	if weeks < 1 || weeks > maxUpcomingLoadWeeks {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "weeks must be between 1 and 52",
+		})
+	}
+
+	// ISO weeks start on Monday, like date_trunc('week', ...)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	end := start.AddDate(0, 0, 7*weeks)
+
+	var rows []struct {
+		WeekStart           string
+		Orders              int64
+		TotalTargetQuantity int64
+	}
+	if err := getDB(c).Model(&models.WorkOrder{}).
+		Select("to_char(date_trunc('week', production_deadline), 'YYYY-MM-DD') AS week_start, COUNT(*) AS orders, COALESCE(SUM(target_quantity), 0) AS total_target_quantity").
+		Where("status <> ? AND production_deadline >= ? AND production_deadline < ?", models.StatusCompleted, start, end).
+		Group("week_start").
+		Scan(&rows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching upcoming load",
+		})
+	}
+
+	byWeek := make(map[string]WeeklyLoad, len(rows))
+	for _, row := range rows {
+		byWeek[row.WeekStart] = WeeklyLoad{Orders: row.Orders, TotalTargetQuantity: row.TotalTargetQuantity}
+	}
+
+	// Every week of the range, empty weeks included
+	load := make([]WeeklyLoad, 0, weeks)
+	for week := start; week.Before(end); week = week.AddDate(0, 0, 7) {
+		entry := byWeek[week.Format(time.DateOnly)]
+		year, number := week.ISOWeek()
+		entry.WeekStart = week.Format(time.DateOnly)
+		entry.Week = fmt.Sprintf("%d-W%02d", year, number)
+		load = append(load, entry)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(UpcomingLoadResponse{
+		Error: false,
+		Weeks: load,
+	})
+}
+
 // @Summary Get work order summary
 // @Description Get a summary report of work orders by status (Production Manager only)
 // @Tags reports
@@ -232,12 +568,18 @@ func GetOperatorPerformance(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
 // @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param min_quantity query int false "Minimum ordered (target) quantity"
+// @Param max_quantity query int false "Maximum ordered (target) quantity"
 // @Success 200 {object} SummaryResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Router /reports/summary [get]
 func GetWorkOrderSummary(c *fiber.Ctx) error {
-	role := c.Locals("role").(models.Role)
+	role, ok := getRole(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
 	if role != models.RoleProductionManager {
 		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Error: true,
@@ -250,33 +592,27 @@ func GetWorkOrderSummary(c *fiber.Ctx) error {
 	endDate := c.Query("end_date")
 
 	// Build base query
-	baseQuery := database.DB.Model(&models.WorkOrder{})
+	baseQuery := getDB(c).Model(&models.WorkOrder{})
 
-	// Apply date filters if provided
-	if startDate != "" {
-		startTime, err := time.Parse(time.DateOnly, startDate)
-		if err == nil {
-			baseQuery = baseQuery.Where("production_deadline >= ?", startTime)
-		}
-	} else {
-		baseQuery = baseQuery.Where("production_deadline >= ?", time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.Now().Location()))
-	}
-	if endDate != "" {
-		endTime, err := time.Parse(time.DateOnly, endDate)
-		if err == nil {
-			// Add one day to include the end date
-			endTime = endTime.Add(24 * time.Hour)
-			baseQuery = baseQuery.Where("production_deadline < ?", endTime)
-		}
-	} else {
-		baseQuery = baseQuery.Where("production_deadline < ?", time.Date(time.Now().Year(), 12, 31, 23, 59, 59, 0, time.Now().Location()))
+	// Apply date filters, defaulting to the current year
+	baseQuery = applySummaryDateRange(baseQuery, "production_deadline", startDate, endDate)
+
+	// Apply ordered quantity filters if provided
+	minQuantity, maxQuantity, err := parseQuantityRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
 	}
+	baseQuery = applyQuantityRange(baseQuery, minQuantity, maxQuantity)
 
-	// Get distinct product names
-	var productNames []string
+	// Get distinct products, quantities in different units are never added up
+	var products []summaryProduct
 	if err := baseQuery.Session(&gorm.Session{}).
-		Distinct("product_name").
-		Pluck("product_name", &productNames).Error; err != nil {
+		Distinct("product_name", "unit").
+		Order("product_name, unit").
+		Scan(&products).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Error fetching product names",
@@ -291,18 +627,20 @@ func GetWorkOrderSummary(c *fiber.Ctx) error {
 	summaries := []WorkOrderSummary{}
 
 	// For each product, calculate metrics
-	for _, productName := range productNames {
+	for _, product := range products {
+		productName, unit := product.ProductName, product.Unit
 		summary := WorkOrderSummary{
 			ProductName: productName,
+			Unit:        unit,
 		}
 
 		// Get all work order numbers for this product
 		var workOrderNumbers []string
 		if err := baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ?", productName).
+			Where("product_name = ? AND unit = ?", productName, unit).
 			Distinct("work_order_number").
 			Pluck("work_order_number", &workOrderNumbers).Error; err != nil {
-			log.Printf("Error fetching work order numbers for %s: %v", productName, err)
+			log.Printf("Error fetching work order numbers for %s (%s): %v", productName, unit, err)
 			workOrderNumbers = []string{}
 		}
 
@@ -311,7 +649,7 @@ func GetWorkOrderSummary(c *fiber.Ctx) error {
 
 		// Count total work orders for this product
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ?", productName).
+			Where("product_name = ? AND unit = ?", productName, unit).
 			Count(&summary.TotalWO)
 
 		// Calculate percentage of total work orders
@@ -319,15 +657,16 @@ func GetWorkOrderSummary(c *fiber.Ctx) error {
 			summary.Percentage = int64(float64(summary.TotalWO) / float64(totalWorkOrders) * 100)
 		}
 
-		// Get target quantity
+		// Get target quantity. Backorders carry part of their parent's target,
+		// so only root work orders count towards it.
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ?", productName).
+			Where("product_name = ? AND unit = ? AND parent_id IS NULL", productName, unit).
 			Select("COALESCE(SUM(target_quantity), 0)").
 			Row().Scan(&summary.TargetQty)
 
 		// Get achieved quantity (completed work orders)
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND status = ?", productName, models.StatusCompleted).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusCompleted).
 			Select("COALESCE(SUM(quantity), 0)").
 			Row().Scan(&summary.AchievedQty)
 
@@ -338,24 +677,35 @@ func GetWorkOrderSummary(c *fiber.Ctx) error {
 
 		// Count work orders by status
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND status = ?", productName, models.StatusPending).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusPending).
 			Count(&summary.Pending)
 
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND status = ?", productName, models.StatusInProgress).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusInProgress).
 			Count(&summary.InProgress)
 
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND status = ?", productName, models.StatusCompleted).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusOnHold).
+			Count(&summary.OnHold)
+
+		baseQuery.Session(&gorm.Session{}).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusCompleted).
 			Count(&summary.Completed)
 
 		// For cancelled, we need to check if there's a "cancelled" status in your system
 		// Since it's not defined in the models, we'll use a placeholder query
 		// You might need to adjust this based on how cancelled orders are tracked
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND deleted_at IS NOT NULL", productName).
+			Where("product_name = ? AND unit = ? AND deleted_at IS NOT NULL", productName, unit).
 			Count(&summary.Cancelled)
 
+		// Completed orders whose remainder was cancelled instead of backordered
+		baseQuery.Session(&gorm.Session{}).
+			Where("product_name = ? AND unit = ? AND status = ? AND remaining_disposition = ?",
+				productName, unit, models.StatusCompleted, models.DispositionCancelled).
+			Select("COUNT(*), COALESCE(SUM(target_quantity - quantity), 0), COUNT(restock_note)").
+			Row().Scan(&summary.PartiallyCancelled, &summary.CancelledQty, &summary.Restocked)
+
 		summaries = append(summaries, summary)
 	}
 
@@ -373,8 +723,20 @@ func GetWorkOrderSummary(c *fiber.Ctx) error {
 			totalSummary.AchievedQty += summary.AchievedQty
 			totalSummary.Pending += summary.Pending
 			totalSummary.InProgress += summary.InProgress
+			totalSummary.OnHold += summary.OnHold
 			totalSummary.Completed += summary.Completed
 			totalSummary.Cancelled += summary.Cancelled
+			totalSummary.PartiallyCancelled += summary.PartiallyCancelled
+			totalSummary.CancelledQty += summary.CancelledQty
+			totalSummary.Restocked += summary.Restocked
+		}
+
+		// Quantities are only totalled when every product shares one unit
+		totalSummary.Unit, totalSummary.MixedUnits = summaryUnit(summaries)
+		if totalSummary.MixedUnits {
+			totalSummary.TargetQty = 0
+			totalSummary.AchievedQty = 0
+			totalSummary.CancelledQty = 0
 		}
 
 		// Calculate overall achievement percentage
@@ -391,6 +753,90 @@ func GetWorkOrderSummary(c *fiber.Ctx) error {
 	})
 }
 
+// @Summary Get operator status matrix
+// @Description Get every operator with their work order counts per status and produced quantity in the date range, the overview behind the per-operator summaries (Production Manager only)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} OperatorStatusMatrixResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/operators/matrix [get]
+func GetOperatorStatusMatrix(c *fiber.Ctx) error {
+	// Get query parameters for date range
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	// Get all operators so those without work orders are listed too
+	var operators []models.User
+	if err := getDB(c).Where("role = ?", models.RoleOperator).Order("username ASC").Find(&operators).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching operators",
+		})
+	}
+
+	// Count work orders and produced quantity per operator and status in one query,
+	// using the same date range as the per-operator summary
+	query := getDB(c).Model(&models.WorkOrder{}).
+		Select("operator_id, status, COUNT(*) AS count, COALESCE(SUM(quantity), 0) AS quantity").
+		Group("operator_id, status")
+	query = applySummaryDateRange(query, "created_at", startDate, endDate)
+
+	var cells []struct {
+		OperatorID uint
+		Status     models.WorkOrderStatus
+		Count      int64
+		Quantity   int64
+	}
+	if err := query.Scan(&cells).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order counts",
+		})
+	}
+
+	rows := make([]OperatorStatusMatrixRow, 0, len(operators))
+	index := make(map[uint]int, len(operators))
+	for _, operator := range operators {
+		index[operator.ID] = len(rows)
+		rows = append(rows, OperatorStatusMatrixRow{
+			OperatorID: operator.ID,
+			Username:   operator.Username,
+		})
+	}
+
+	for _, cell := range cells {
+		i, ok := index[cell.OperatorID]
+		if !ok {
+			// Orders of deleted users or users that are no longer operators
+			continue
+		}
+		row := &rows[i]
+		switch cell.Status {
+		case models.StatusPending:
+			row.Pending = cell.Count
+		case models.StatusInProgress:
+			row.InProgress = cell.Count
+		case models.StatusOnHold:
+			row.OnHold = cell.Count
+		case models.StatusCompleted:
+			row.Completed = cell.Count
+		}
+		row.Total += cell.Count
+		row.ProducedQuantity += cell.Quantity
+	}
+
+	return c.Status(fiber.StatusOK).JSON(OperatorStatusMatrixResponse{
+		Error:     false,
+		Operators: rows,
+	})
+}
+
 // @Summary Get work order summary by operator
 // @Description Get a summary report of work orders by status for a specific operator (Production Manager only)
 // @Tags reports
@@ -401,11 +847,15 @@ func GetWorkOrderSummary(c *fiber.Ctx) error {
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
 // @Param end_date query string false "End date (YYYY-MM-DD)"
 // @Success 200 {object} SummaryResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Router /reports/summary/{operator_id} [get]
 func GetWorkOrderSummaryByOperator(c *fiber.Ctx) error {
-	role := c.Locals("role").(models.Role)
+	role, ok := getRole(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
 	if role != models.RoleProductionManager {
 		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Error: true,
@@ -414,38 +864,25 @@ func GetWorkOrderSummaryByOperator(c *fiber.Ctx) error {
 	}
 
 	// Get operator ID from path parameter
-	operatorID := c.Params("operator_id")
+	operatorID, ok := idParam(c, "operator_id")
+	if !ok {
+		return invalidIDError(c, "operator_id")
+	}
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
 
 	// Build base query
-	baseQuery := database.DB.Model(&models.WorkOrder{}).Where("operator_id = ?", operatorID)
+	baseQuery := getDB(c).Model(&models.WorkOrder{}).Where("operator_id = ?", operatorID)
 
-	// Apply date filters if provided
-	if startDate != "" {
-		startTime, err := time.Parse(time.DateOnly, startDate)
-		if err == nil {
-			baseQuery = baseQuery.Where("created_at >= ?", startTime)
-		}
-	} else {
-		baseQuery = baseQuery.Where("created_at >= ?", time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.Now().Location()))
-	}
-	if endDate != "" {
-		endTime, err := time.Parse(time.DateOnly, endDate)
-		if err == nil {
-			// Add one day to include the end date
-			endTime = endTime.Add(24 * time.Hour)
-			baseQuery = baseQuery.Where("created_at < ?", endTime)
-		}
-	} else {
-		baseQuery = baseQuery.Where("created_at < ?", time.Date(time.Now().Year(), 12, 31, 23, 59, 59, 0, time.Now().Location()))
-	}
+	// Apply date filters, defaulting to the current year
+	baseQuery = applySummaryDateRange(baseQuery, "created_at", startDate, endDate)
 
-	// Get distinct product names
-	var productNames []string
+	// Get distinct products, quantities in different units are never added up
+	var products []summaryProduct
 	if err := baseQuery.Session(&gorm.Session{}).
-		Distinct("product_name").
-		Pluck("product_name", &productNames).Error; err != nil {
+		Distinct("product_name", "unit").
+		Order("product_name, unit").
+		Scan(&products).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Error fetching product names",
@@ -460,18 +897,20 @@ func GetWorkOrderSummaryByOperator(c *fiber.Ctx) error {
 	summaries := []WorkOrderSummary{}
 
 	// For each product, calculate metrics
-	for _, productName := range productNames {
+	for _, product := range products {
+		productName, unit := product.ProductName, product.Unit
 		summary := WorkOrderSummary{
 			ProductName: productName,
+			Unit:        unit,
 		}
 
 		// Get all work order numbers for this product
 		var workOrderNumbers []string
 		if err := baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ?", productName).
+			Where("product_name = ? AND unit = ?", productName, unit).
 			Distinct("work_order_number").
 			Pluck("work_order_number", &workOrderNumbers).Error; err != nil {
-			log.Printf("Error fetching work order numbers for %s: %v", productName, err)
+			log.Printf("Error fetching work order numbers for %s (%s): %v", productName, unit, err)
 			workOrderNumbers = []string{}
 		}
 
@@ -480,7 +919,7 @@ func GetWorkOrderSummaryByOperator(c *fiber.Ctx) error {
 
 		// Count total work orders for this product
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ?", productName).
+			Where("product_name = ? AND unit = ?", productName, unit).
 			Count(&summary.TotalWO)
 
 		// Calculate percentage of total work orders
@@ -488,15 +927,16 @@ func GetWorkOrderSummaryByOperator(c *fiber.Ctx) error {
 			summary.Percentage = int64(float64(summary.TotalWO) / float64(totalWorkOrders) * 100)
 		}
 
-		// Get target quantity
+		// Get target quantity. Backorders carry part of their parent's target,
+		// so only root work orders count towards it.
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ?", productName).
+			Where("product_name = ? AND unit = ? AND parent_id IS NULL", productName, unit).
 			Select("COALESCE(SUM(target_quantity), 0)").
 			Row().Scan(&summary.TargetQty)
 
 		// Get achieved quantity (completed work orders)
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND status = ?", productName, models.StatusCompleted).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusCompleted).
 			Select("COALESCE(SUM(quantity), 0)").
 			Row().Scan(&summary.AchievedQty)
 
@@ -507,22 +947,33 @@ func GetWorkOrderSummaryByOperator(c *fiber.Ctx) error {
 
 		// Count work orders by status
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND status = ?", productName, models.StatusPending).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusPending).
 			Count(&summary.Pending)
 
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND status = ?", productName, models.StatusInProgress).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusInProgress).
 			Count(&summary.InProgress)
 
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND status = ?", productName, models.StatusCompleted).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusOnHold).
+			Count(&summary.OnHold)
+
+		baseQuery.Session(&gorm.Session{}).
+			Where("product_name = ? AND unit = ? AND status = ?", productName, unit, models.StatusCompleted).
 			Count(&summary.Completed)
 
 		// For cancelled, we need to check if there's a "cancelled" status in your system
 		baseQuery.Session(&gorm.Session{}).
-			Where("product_name = ? AND deleted_at IS NOT NULL", productName).
+			Where("product_name = ? AND unit = ? AND deleted_at IS NOT NULL", productName, unit).
 			Count(&summary.Cancelled)
 
+		// Completed orders whose remainder was cancelled instead of backordered
+		baseQuery.Session(&gorm.Session{}).
+			Where("product_name = ? AND unit = ? AND status = ? AND remaining_disposition = ?",
+				productName, unit, models.StatusCompleted, models.DispositionCancelled).
+			Select("COUNT(*), COALESCE(SUM(target_quantity - quantity), 0), COUNT(restock_note)").
+			Row().Scan(&summary.PartiallyCancelled, &summary.CancelledQty, &summary.Restocked)
+
 		summaries = append(summaries, summary)
 	}
 
@@ -540,8 +991,20 @@ func GetWorkOrderSummaryByOperator(c *fiber.Ctx) error {
 			totalSummary.AchievedQty += summary.AchievedQty
 			totalSummary.Pending += summary.Pending
 			totalSummary.InProgress += summary.InProgress
+			totalSummary.OnHold += summary.OnHold
 			totalSummary.Completed += summary.Completed
 			totalSummary.Cancelled += summary.Cancelled
+			totalSummary.PartiallyCancelled += summary.PartiallyCancelled
+			totalSummary.CancelledQty += summary.CancelledQty
+			totalSummary.Restocked += summary.Restocked
+		}
+
+		// Quantities are only totalled when every product shares one unit
+		totalSummary.Unit, totalSummary.MixedUnits = summaryUnit(summaries)
+		if totalSummary.MixedUnits {
+			totalSummary.TargetQty = 0
+			totalSummary.AchievedQty = 0
+			totalSummary.CancelledQty = 0
 		}
 
 		// Calculate overall achievement percentage
@@ -557,3 +1020,186 @@ func GetWorkOrderSummaryByOperator(c *fiber.Ctx) error {
 		Summary: summaries,
 	})
 }
+
+// summaryProduct is a product and unit pair the summary reports on
+type summaryProduct struct {
+	ProductName string
+	Unit        string
+}
+
+// summaryUnit returns the unit shared by the summary rows, or reports that they mix units
+func summaryUnit(summaries []WorkOrderSummary) (string, bool) {
+	unit := ""
+	for i, summary := range summaries {
+		if i == 0 {
+			unit = summary.Unit
+		} else if summary.Unit != unit {
+			return "", true
+		}
+	}
+	return unit, false
+}
+
+// applyDateRange filters the query on column between the start and end dates (YYYY-MM-DD), both inclusive.
+// Empty or malformed dates are ignored.
+func applyDateRange(query *gorm.DB, column, startDate, endDate string) *gorm.DB {
+	if startDate != "" {
+		startTime, err := time.Parse(time.DateOnly, startDate)
+		if err == nil {
+			query = query.Where(column+" >= ?", startTime)
+		}
+	}
+	if endDate != "" {
+		endTime, err := time.Parse(time.DateOnly, endDate)
+		if err == nil {
+			// Add one day to include the end date
+			endTime = endTime.Add(24 * time.Hour)
+			query = query.Where(column+" < ?", endTime)
+		}
+	}
+	return query
+}
+
+// refreshDailyCounter recomputes the daily production counter affected by an event at the given time.
+// Failures are only logged, the counters can always be rebuilt from source data.
+func refreshDailyCounter(db *gorm.DB, productName string, at time.Time) {
+	if err := dailyCounterService.RecomputeDay(db, productName, at); err != nil {
+		log.Printf("Error refreshing daily production counter: %v", err)
+	}
+}
+
+// operatorPerformance computes an operator's performance metrics for the orders due in the
+// date range, measuring the throughput over the [periodStart, periodEnd) period
+func operatorPerformance(db *gorm.DB, operator models.User, startDate, endDate string, periodStart, periodEnd time.Time) OperatorPerformance {
+	performance := OperatorPerformance{
+		OperatorID: operator.ID,
+		Username:   operator.Username,
+	}
+	periodDays := periodEnd.Sub(periodStart).Hours() / 24
+
+	// Build base query for this operator
+	baseQuery := db.Model(&models.WorkOrder{}).Where("operator_id = ?", operator.ID)
+
+	// Apply date filters if provided
+	if startDate != "" {
+		startTime, err := time.Parse(time.DateOnly, startDate)
+		if err == nil {
+			baseQuery = baseQuery.Where("production_deadline >= ?", startTime)
+		}
+	}
+	if endDate != "" {
+		endTime, err := time.Parse(time.DateOnly, endDate)
+		if err == nil {
+			// Add one day to include the end date
+			endTime = endTime.Add(24 * time.Hour)
+			baseQuery = baseQuery.Where("production_deadline < ?", endTime)
+		}
+	}
+
+	// Get total assigned work orders
+	baseQuery.Session(&gorm.Session{}).Count(&performance.Assigned)
+
+	// Get work orders in progress
+	baseQuery.Session(&gorm.Session{}).
+		Where("status = ?", models.StatusInProgress).
+		Count(&performance.InProgress)
+
+	// Get completed work orders
+	baseQuery.Session(&gorm.Session{}).
+		Where("status = ?", models.StatusCompleted).
+		Count(&performance.Completed)
+
+	// Get total quantity of completed work orders
+	var totalQuantity int64
+	if err := baseQuery.Session(&gorm.Session{}).
+		Where("status = ?", models.StatusCompleted).
+		Select("COALESCE(SUM(quantity), 0)").
+		Row().Scan(&totalQuantity); err != nil {
+		log.Printf("Error calculating total quantity for operator %d: %v", operator.ID, err)
+	}
+	performance.TotalQuantity = totalQuantity
+
+	// Compare the throughput with the preceding period of the same length
+	var trailingQuantity int64
+	if err := db.Model(&models.WorkOrder{}).
+		Where("operator_id = ? AND status = ?", operator.ID, models.StatusCompleted).
+		Where("production_deadline >= ? AND production_deadline < ?", periodStart.Add(-periodEnd.Sub(periodStart)), periodStart).
+		Select("COALESCE(SUM(quantity), 0)").
+		Row().Scan(&trailingQuantity); err != nil {
+		log.Printf("Error calculating trailing quantity for operator %d: %v", operator.ID, err)
+	}
+
+	performance.ThroughputPerDay = roundTwoDecimals(float64(totalQuantity) / periodDays)
+	performance.TrailingThroughputPerDay = roundTwoDecimals(float64(trailingQuantity) / periodDays)
+	performance.Flagged = throughputFlagged(performance.ThroughputPerDay, performance.TrailingThroughputPerDay)
+
+	return performance
+}
+
+// performancePeriod returns the [start, end) period of the performance report.
+// Without explicit dates it spans the production deadlines of all work orders up to today.
+func performancePeriod(db *gorm.DB, startDate, endDate string) (time.Time, time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start, end := today, today.Add(24*time.Hour)
+
+	if startDate == "" || endDate == "" {
+		var earliest, latest *time.Time
+		if err := db.Model(&models.WorkOrder{}).
+			Select("MIN(production_deadline), MAX(production_deadline)").
+			Row().Scan(&earliest, &latest); err != nil {
+			return start, end, err
+		}
+		if earliest != nil && earliest.Before(start) {
+			start = time.Date(earliest.Year(), earliest.Month(), earliest.Day(), 0, 0, 0, 0, now.Location())
+		}
+		if latest != nil && latest.After(end) {
+			end = time.Date(latest.Year(), latest.Month(), latest.Day(), 0, 0, 0, 0, now.Location()).Add(24 * time.Hour)
+		}
+	}
+
+	if startTime, err := time.Parse(time.DateOnly, startDate); err == nil {
+		start = startTime
+	}
+	if endTime, err := time.Parse(time.DateOnly, endDate); err == nil {
+		// Add one day to include the end date
+		end = endTime.Add(24 * time.Hour)
+	}
+
+	// Always measure over at least one day
+	if !end.After(start) {
+		end = start.Add(24 * time.Hour)
+	}
+	return start, end, nil
+}
+
+// throughputFlagged reports whether a throughput is below the configured minimum
+// or dropped more than PERFORMANCE_DROP_PERCENT below the trailing throughput
+func throughputFlagged(throughput, trailing float64) bool {
+	if minimum := config.AppConfig.PerformanceMinThroughput; minimum > 0 && throughput < minimum {
+		return true
+	}
+	if trailing > 0 {
+		allowed := trailing * float64(100-config.AppConfig.PerformanceDropPercent) / 100
+		return throughput < allowed
+	}
+	return false
+}
+
+// roundTwoDecimals rounds v to two decimal places
+func roundTwoDecimals(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// applySummaryDateRange filters on column like applyDateRange, but defaults
+// a missing start or end date to the start or end of the current year
+func applySummaryDateRange(query *gorm.DB, column, startDate, endDate string) *gorm.DB {
+	now := time.Now()
+	if startDate == "" {
+		query = query.Where(column+" >= ?", time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()))
+	}
+	if endDate == "" {
+		query = query.Where(column+" < ?", time.Date(now.Year(), 12, 31, 23, 59, 59, 0, now.Location()))
+	}
+	return applyDateRange(query, column, startDate, endDate)
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// summaryProductRow is a product of the summary with its root target and completed quantity
+type summaryProductRow struct {
+	product, unit    string
+	target, achieved int64
+}
+
+// summaryRows answers the summary queries for the products, one work order each
+func summaryRows(products []summaryProductRow) dbtest.Responder {
+	return func(stmt dbtest.Statement) dbtest.Rows {
+		switch {
+		case strings.HasPrefix(stmt.SQL, `SELECT DISTINCT "product_name","unit"`):
+			rows := dbtest.Rows{Columns: []string{"product_name", "unit"}}
+			for _, p := range products {
+				rows.Values = append(rows.Values, []driver.Value{p.product, p.unit})
+			}
+			return rows
+		case strings.HasPrefix(stmt.SQL, "SELECT count(*)"):
+			count := int64(len(products))
+			if strings.Contains(stmt.SQL, "product_name = $") {
+				count = 1
+			}
+			return dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{count}}}
+		}
+		for _, p := range products {
+			if !hasArgs(stmt, p.product, p.unit) {
+				continue
+			}
+			switch {
+			case strings.Contains(stmt.SQL, "COALESCE(SUM(target_quantity), 0)"):
+				return dbtest.Rows{Columns: []string{"sum"}, Values: [][]driver.Value{{p.target}}}
+			case strings.Contains(stmt.SQL, "COALESCE(SUM(quantity), 0)"):
+				return dbtest.Rows{Columns: []string{"sum"}, Values: [][]driver.Value{{p.achieved}}}
+			}
+		}
+		return dbtest.Rows{}
+	}
+}
+
+func TestWorkOrderSummaryUnits(t *testing.T) {
+	tests := []struct {
+		name         string
+		products     []summaryProductRow
+		wantUnit     string
+		wantMixed    bool
+		wantTarget   float64
+		wantAchieved float64
+	}{
+		{
+			"one unit",
+			[]summaryProductRow{{"Bracket", "pcs", 100, 40}, {"Widget", "pcs", 50, 50}},
+			"pcs", false, 150, 90,
+		},
+		{
+			"products in different units",
+			[]summaryProductRow{{"Steel", "kg", 500, 200}, {"Widget", "pcs", 50, 50}},
+			"", true, 0, 0,
+		},
+		{
+			"one product in two units",
+			[]summaryProductRow{{"Cable", "m", 300, 100}, {"Cable", "pcs", 10, 10}},
+			"", true, 0, 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useScriptedDB(t, summaryRows(tt.products))
+
+			status, body := testRequest(t, "/reports/summary", GetWorkOrderSummary, testUser{1, models.RoleProductionManager, 0}, fiber.MethodGet, "/reports/summary")
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", status, body)
+			}
+			rows, _ := body["summary"].([]interface{})
+			if len(rows) != len(tt.products)+1 {
+				t.Fatalf("got %d summary rows, want one per product and unit and the total: %v", len(rows), rows)
+			}
+
+			// Every product and unit keeps its own quantities
+			for i, p := range tt.products {
+				row := rows[i].(map[string]interface{})
+				if row["product_name"] != p.product || row["unit"] != p.unit || row["target_qty"] != float64(p.target) || row["achieved_qty"] != float64(p.achieved) {
+					t.Errorf("row %d = %v, want %s in %s with target %d and achieved %d", i, row, p.product, p.unit, p.target, p.achieved)
+				}
+				if row["mixed_units"] != nil {
+					t.Errorf("product row %d flagged as mixed units", i)
+				}
+			}
+
+			total := rows[len(rows)-1].(map[string]interface{})
+			mixed, _ := total["mixed_units"].(bool)
+			if total["product_name"] != "Total" || total["unit"] != tt.wantUnit || mixed != tt.wantMixed {
+				t.Errorf("total = %v, want unit %q and mixed units %t", total, tt.wantUnit, tt.wantMixed)
+			}
+			// Quantities in different units are never added up
+			if total["target_qty"] != tt.wantTarget || total["achieved_qty"] != tt.wantAchieved {
+				t.Errorf("total target %v and achieved %v, want %v and %v", total["target_qty"], total["achieved_qty"], tt.wantTarget, tt.wantAchieved)
+			}
+			if total["total_wo"] != float64(len(tt.products)) {
+				t.Errorf("total work orders = %v, want %d", total["total_wo"], len(tt.products))
+			}
+		})
+	}
+}
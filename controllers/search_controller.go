@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// searchGroupLimit caps how many matches each group of a global search returns
+const searchGroupLimit = 5
+
+// WorkOrderSearchGroup holds the work orders matching a global search
+type WorkOrderSearchGroup struct {
+	Total int64          `json:"total"` // all matches, more than the returned items when capped
+	Items []WorkOrderDTO `json:"items"`
+}
+
+// OperatorSearchGroup holds the operators matching a global search
+type OperatorSearchGroup struct {
+	Total int64     `json:"total"`
+	Items []UserDTO `json:"items"`
+}
+
+// AuditLogSearchGroup holds the most recent audit log entries whose note matches a global search
+type AuditLogSearchGroup struct {
+	Total int64         `json:"total"`
+	Items []AuditLogDTO `json:"items"`
+}
+
+// SearchResponse represents the grouped results of a global search
+type SearchResponse struct {
+	Error      bool                 `json:"error"`
+	Query      string               `json:"query"`
+	WorkOrders WorkOrderSearchGroup `json:"work_orders"`
+	Operators  OperatorSearchGroup  `json:"operators"`
+	AuditLogs  *AuditLogSearchGroup `json:"audit_logs,omitempty"` // only for Production Managers
+}
+
+// @Summary Global search
+// @Description Search work orders by number or product name, operators by username and, for Production Managers, audit log notes. Each group returns at most 5 items with the total number of matches. Operators only find their own work orders.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search term"
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /search [get]
+func Search(c *fiber.Ctx) error {
+	// Get user ID and role from context
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	term := strings.TrimSpace(c.Query("q"))
+	if term == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Search term q is required",
+		})
+	}
+	pattern := "%" + strings.ToUpper(term) + "%"
+
+	response := SearchResponse{Error: false, Query: term}
+
+	// Work orders by number or product name, operators only see their own
+	workOrderQuery := getDB(c).Model(&models.WorkOrder{}).
+		Where("UPPER(work_order_number) LIKE ? OR UPPER(product_name) LIKE ?", pattern, pattern)
+	if role == models.RoleOperator {
+		workOrderQuery = workOrderQuery.Where("operator_id = ?", userID)
+	}
+	if err := workOrderQuery.Count(&response.WorkOrders.Total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error searching work orders",
+		})
+	}
+	var workOrders []models.WorkOrder
+	if err := preloadUnscoped(workOrderQuery, "Operator").
+		Limit(searchGroupLimit).
+		Order("created_at DESC, id DESC").
+		Find(&workOrders).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error searching work orders",
+		})
+	}
+	response.WorkOrders.Items = toWorkOrderDTOs(workOrders)
+
+	// Operators by username, like the operator list
+	operatorQuery := getDB(c).Model(&models.User{}).
+		Where("role = ? AND UPPER(username) LIKE ?", models.RoleOperator, pattern)
+	if err := operatorQuery.Count(&response.Operators.Total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error searching operators",
+		})
+	}
+	var operators []models.User
+	if err := operatorQuery.Limit(searchGroupLimit).Order("username ASC").Find(&operators).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error searching operators",
+		})
+	}
+	response.Operators.Items = toUserDTOs(operators)
+
+	// Audit log notes are restricted to Production Managers like the audit log list
+	if role == models.RoleProductionManager {
+		auditLogs := &AuditLogSearchGroup{}
+		auditQuery := getDB(c).Model(&models.AuditLog{}).Where("UPPER(note) LIKE ?", pattern)
+		if err := auditQuery.Count(&auditLogs.Total).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error searching audit logs",
+			})
+		}
+		var logs []models.AuditLog
+		if err := preloadUnscoped(auditQuery, "User").
+			Limit(searchGroupLimit).
+			Order("created_at DESC, id DESC").
+			Find(&logs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error searching audit logs",
+			})
+		}
+		auditLogs.Items = toAuditLogDTOs(logs)
+		response.AuditLogs = auditLogs
+
+		logReadAccess(c, "Searched audit logs")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// summaryCompareTTL is how long a period comparison is served from the cache
+const summaryCompareTTL = time.Minute
+
+// comparePeriods are the accepted period values
+var comparePeriods = []string{"week", "month", "quarter", "year"}
+
+// PeriodMetrics represents the key work order metrics of one time window
+type PeriodMetrics struct {
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"` // exclusive
+	OrdersCreated     int64     `json:"orders_created"`
+	OrdersCompleted   int64     `json:"orders_completed"`
+	CompletedQuantity int64     `json:"completed_quantity"`
+	OnTimeRate        float64   `json:"on_time_rate"` // percentage of the completed orders finished by their deadline
+}
+
+// PeriodChange is the change from the previous to the current period, nil where the previous value is zero
+type PeriodChange struct {
+	OrdersCreated     *float64 `json:"orders_created"`     // percent
+	OrdersCompleted   *float64 `json:"orders_completed"`   // percent
+	CompletedQuantity *float64 `json:"completed_quantity"` // percent
+	OnTimeRate        float64  `json:"on_time_rate"`       // percentage points
+}
+
+// SummaryCompareResponse represents a comparison of the current period with the previous one
+type SummaryCompareResponse struct {
+	Error       bool          `json:"error"`
+	Period      string        `json:"period"`
+	Current     PeriodMetrics `json:"current"`
+	Previous    PeriodMetrics `json:"previous"`
+	Change      PeriodChange  `json:"change"`
+	GeneratedAt time.Time     `json:"generated_at"`
+}
+
+// summaryCompareEntry is a cached comparison
+type summaryCompareEntry struct {
+	response  SummaryCompareResponse
+	expiresAt time.Time
+}
+
+// summaryCompareCache holds the comparisons per plant and period
+var summaryCompareCache = struct {
+	sync.Mutex
+	entries map[string]summaryCompareEntry
+}{entries: map[string]summaryCompareEntry{}}
+
+// periodBucketRow is a metric of the current or the previous window
+type periodBucketRow struct {
+	Bucket   string
+	Orders   int64
+	Quantity int64
+	OnTime   int64
+}
+
+// @Summary Compare summary with the previous period
+// @Description Get the orders created, the orders completed, their quantity and on-time rate of the current week, month, quarter or year so far next to the same span of the previous period, e.g. the 1st to the 15th of this and of last month, with the change between them. Completions are taken from the status history. Results are cached for a minute. (Production Manager only)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "week, month (default), quarter or year"
+// @Success 200 {object} SummaryCompareResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /reports/summary/compare [get]
+func GetSummaryComparison(c *fiber.Ctx) error {
+	period := c.Query("period", "month")
+	now := time.Now()
+	currentStart, ok := periodStart(period, now)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   fmt.Sprintf("period must be one of %v", comparePeriods),
+		})
+	}
+
+	// Managers only see their own plant, so each plant has its own comparison
+	plantID, _ := database.PlantFromContext(c.UserContext())
+	key := fmt.Sprintf("%d:%s", plantID, period)
+	summaryCompareCache.Lock()
+	entry, cached := summaryCompareCache.entries[key]
+	summaryCompareCache.Unlock()
+	if cached && now.Before(entry.expiresAt) {
+		return c.Status(fiber.StatusOK).JSON(entry.response)
+	}
+
+	// The previous window covers as much of the previous period as has passed of the current one
+	previousStart := previousPeriodStart(period, currentStart)
+	previousEnd := previousStart.Add(now.Sub(currentStart))
+	if previousEnd.After(currentStart) {
+		previousEnd = currentStart
+	}
+
+	response := SummaryCompareResponse{
+		Error:       false,
+		Period:      period,
+		Current:     PeriodMetrics{Start: currentStart, End: now},
+		Previous:    PeriodMetrics{Start: previousStart, End: previousEnd},
+		GeneratedAt: now,
+	}
+	windows := "(%[1]s >= ? AND %[1]s < ?) OR (%[1]s >= ? AND %[1]s < ?)"
+
+	// Orders created in either window
+	var created []periodBucketRow
+	if err := getDB(c).Model(&models.WorkOrder{}).
+		Select("CASE WHEN created_at >= ? THEN 'current' ELSE 'previous' END AS bucket, COUNT(*) AS orders", currentStart).
+		Where(fmt.Sprintf(windows, "created_at"), previousStart, previousEnd, currentStart, now).
+		Group("bucket").
+		Scan(&created).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error counting created work orders",
+		})
+	}
+
+	// Orders completed in either window
+	var completed []periodBucketRow
+	if err := getDB(c).Model(&models.WorkOrder{}).
+		Table("(?) AS work_orders", completedWorkOrders(getDB(c))).
+		Select("CASE WHEN completed_at >= ? THEN 'current' ELSE 'previous' END AS bucket, COUNT(*) AS orders, "+
+			"COALESCE(SUM(quantity), 0) AS quantity, COUNT(*) FILTER (WHERE completed_at <= production_deadline) AS on_time", currentStart).
+		Where(fmt.Sprintf(windows, "completed_at"), previousStart, previousEnd, currentStart, now).
+		Group("bucket").
+		Scan(&completed).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error counting completed work orders",
+		})
+	}
+
+	metrics := map[string]*PeriodMetrics{"current": &response.Current, "previous": &response.Previous}
+	for _, row := range created {
+		metrics[row.Bucket].OrdersCreated = row.Orders
+	}
+	for _, row := range completed {
+		window := metrics[row.Bucket]
+		window.OrdersCompleted = row.Orders
+		window.CompletedQuantity = row.Quantity
+		if row.Orders > 0 {
+			window.OnTimeRate = roundTwoDecimals(float64(row.OnTime) / float64(row.Orders) * 100)
+		}
+	}
+
+	response.Change = PeriodChange{
+		OrdersCreated:     percentChange(response.Previous.OrdersCreated, response.Current.OrdersCreated),
+		OrdersCompleted:   percentChange(response.Previous.OrdersCompleted, response.Current.OrdersCompleted),
+		CompletedQuantity: percentChange(response.Previous.CompletedQuantity, response.Current.CompletedQuantity),
+		OnTimeRate:        roundTwoDecimals(response.Current.OnTimeRate - response.Previous.OnTimeRate),
+	}
+
+	summaryCompareCache.Lock()
+	summaryCompareCache.entries[key] = summaryCompareEntry{response: response, expiresAt: now.Add(summaryCompareTTL)}
+	summaryCompareCache.Unlock()
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// periodStart returns the start of the week (Monday), month, quarter or year containing t
+func periodStart(period string, t time.Time) (time.Time, bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case "week":
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7), true
+	case "month":
+		return day.AddDate(0, 0, 1-day.Day()), true
+	case "quarter":
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, t.Location()), true
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location()), true
+	}
+	return time.Time{}, false
+}
+
+// previousPeriodStart returns the start of the period before the one starting at start
+func previousPeriodStart(period string, start time.Time) time.Time {
+	switch period {
+	case "week":
+		return start.AddDate(0, 0, -7)
+	case "quarter":
+		return start.AddDate(0, -3, 0)
+	case "year":
+		return start.AddDate(-1, 0, 0)
+	default:
+		return start.AddDate(0, -1, 0)
+	}
+}
+
+// percentChange returns the change from previous to current in percent, nil when previous is zero
+func percentChange(previous, current int64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := roundTwoDecimals(float64(current-previous) / float64(previous) * 100)
+	return &change
+}
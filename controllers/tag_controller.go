@@ -0,0 +1,470 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// tagName is the accepted format of tag names, e.g. rework or customer-x
+var tagName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// CreateTagRequest represents the create tag request body
+type CreateTagRequest struct {
+	Name string `json:"name"`
+}
+
+// WorkOrderTagsRequest represents the tags to add to a work order
+type WorkOrderTagsRequest struct {
+	Tags []string `json:"tags"` // names of existing tags
+}
+
+// TagResponse represents a tag
+type TagResponse struct {
+	Error bool   `json:"error"`
+	Tag   TagDTO `json:"tag"`
+}
+
+// TagListResponse represents the tags of the plant
+type TagListResponse struct {
+	Error bool     `json:"error"`
+	Tags  []TagDTO `json:"tags"`
+}
+
+// @Summary Get tags
+// @Description Get the tags that can be put on work orders, sorted by name
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} TagListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags [get]
+func GetTags(c *fiber.Ctx) error {
+	var tags []models.Tag
+	if err := getDB(c).Order("name ASC").Find(&tags).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching tags",
+		})
+	}
+
+	dtos := make([]TagDTO, 0, len(tags))
+	for _, tag := range tags {
+		dtos = append(dtos, TagDTO{ID: tag.ID, Name: tag.Name})
+	}
+	return c.Status(fiber.StatusOK).JSON(TagListResponse{
+		Error: false,
+		Tags:  dtos,
+	})
+}
+
+// @Summary Create tag
+// @Description Add a tag operators and managers can put on work orders. Names are lowercased. (Production Manager only)
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateTagRequest true "Tag"
+// @Success 201 {object} TagResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags [post]
+func CreateTag(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	var req CreateTagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid request body",
+		})
+	}
+
+	name := normalizeTagName(req.Name)
+	if !tagName.MatchString(name) {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Tag name must be letters, digits, dashes and underscores, at most 50 characters",
+		})
+	}
+
+	tag := models.Tag{Name: name}
+	if err := getDB(c).Create(&tag).Error; err != nil {
+		if database.IsUniqueViolation(err) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error: true,
+				Msg:   fmt.Sprintf("Tag %s already exists", name),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error creating tag",
+		})
+	}
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		userID,
+		models.ActionCreate,
+		"Tag",
+		tag.ID,
+		nil,
+		tag,
+		fmt.Sprintf("Tag %s created", tag.Name),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(TagResponse{
+		Error: false,
+		Tag:   TagDTO{ID: tag.ID, Name: tag.Name},
+	})
+}
+
+// @Summary Delete tag
+// @Description Delete a tag and take it off every work order carrying it (Production Manager only)
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags/{id} [delete]
+func DeleteTag(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	var tag models.Tag
+	result := getDB(c).First(&tag, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Tag not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching tag",
+		})
+	}
+
+	// The route runs in a transaction, so the tag never stays half removed
+	if err := getDB(c).Exec("DELETE FROM work_order_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error removing tag from work orders",
+		})
+	}
+	if err := getDB(c).Delete(&tag).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error deleting tag",
+		})
+	}
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		userID,
+		models.ActionDelete,
+		"Tag",
+		tag.ID,
+		tag,
+		nil,
+		fmt.Sprintf("Tag %s deleted", tag.Name),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(MessageResponse{
+		Error:   false,
+		Message: "Tag deleted",
+	})
+}
+
+// @Summary Add tags to work order
+// @Description Put existing tags on a work order, tags it already carries are kept. Operators can only tag the work orders assigned to them.
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param request body WorkOrderTagsRequest true "Tag names"
+// @Success 200 {object} WorkOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/tags [post]
+func AddWorkOrderTags(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	var req WorkOrderTagsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid request body",
+		})
+	}
+	names := normalizeTagNames(req.Tags)
+	if len(names) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "tags must list at least one tag name",
+		})
+	}
+
+	workOrder, ok, err := fetchTaggableWorkOrder(c, id, userID, role)
+	if !ok {
+		return err
+	}
+
+	// Only existing tags can be applied, the vocabulary is kept by the managers
+	var tags []models.Tag
+	if err := getDB(c).Where("name IN ?", names).Find(&tags).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching tags",
+		})
+	}
+	if len(tags) < len(names) {
+		found := map[string]bool{}
+		for _, tag := range tags {
+			found[tag.Name] = true
+		}
+		var unknown []string
+		for _, name := range names {
+			if !found[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   fmt.Sprintf("Unknown tags: %s", strings.Join(unknown, ", ")),
+		})
+	}
+
+	tagIDs := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	if err := getDB(c).Exec("INSERT INTO work_order_tags (work_order_id, tag_id) SELECT ?, id FROM tags WHERE id IN ? ON CONFLICT DO NOTHING",
+		workOrder.ID, tagIDs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error adding tags",
+		})
+	}
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		actorID(c),
+		models.ActionUpdate,
+		"WorkOrder",
+		workOrder.ID,
+		nil,
+		nil,
+		fmt.Sprintf("Tags %s added to work order %s", strings.Join(names, ", "), workOrder.WorkOrderNumber),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return sendTaggedWorkOrder(c, workOrder)
+}
+
+// @Summary Remove tag from work order
+// @Description Take a tag off a work order. Operators can only untag the work orders assigned to them.
+// @Tags work-orders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param tag_id path int true "Tag ID"
+// @Success 200 {object} WorkOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/tags/{tag_id} [delete]
+func RemoveWorkOrderTag(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+	tagID, ok := idParam(c, "tag_id")
+	if !ok {
+		return invalidIDError(c, "tag_id")
+	}
+
+	workOrder, ok, err := fetchTaggableWorkOrder(c, id, userID, role)
+	if !ok {
+		return err
+	}
+
+	var tag models.Tag
+	if err := getDB(c).First(&tag, tagID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Tag not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching tag",
+		})
+	}
+
+	result := getDB(c).Exec("DELETE FROM work_order_tags WHERE work_order_id = ? AND tag_id = ?", workOrder.ID, tag.ID)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error removing tag",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: true,
+			Msg:   fmt.Sprintf("Work order does not carry tag %s", tag.Name),
+		})
+	}
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		actorID(c),
+		models.ActionUpdate,
+		"WorkOrder",
+		workOrder.ID,
+		nil,
+		nil,
+		fmt.Sprintf("Tag %s removed from work order %s", tag.Name, workOrder.WorkOrderNumber),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return sendTaggedWorkOrder(c, workOrder)
+}
+
+// fetchTaggableWorkOrder loads a work order the user may tag. When it is not ok the
+// error response was sent and its result is returned.
+func fetchTaggableWorkOrder(c *fiber.Ctx, id, userID uint, role models.Role) (models.WorkOrder, bool, error) {
+	var workOrder models.WorkOrder
+	if err := getDB(c).First(&workOrder, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return workOrder, false, c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return workOrder, false, c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	if role == models.RoleOperator && workOrder.OperatorID != userID {
+		return workOrder, false, c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "You are not assigned to this work order",
+		})
+	}
+	return workOrder, true, nil
+}
+
+// sendTaggedWorkOrder responds with the work order and its current tags
+func sendTaggedWorkOrder(c *fiber.Ctx, workOrder models.WorkOrder) error {
+	if err := getDB(c).Model(&workOrder).Order("name ASC").Association("Tags").Find(&workOrder.Tags); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching tags",
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
+		Error:     false,
+		WorkOrder: toWorkOrderDTO(workOrder),
+	})
+}
+
+// parseTagFilter reads the optional tags and tags_match query parameters
+func parseTagFilter(c *fiber.Ctx) ([]string, bool, error) {
+	names := normalizeTagNames(strings.Split(c.Query("tags"), ","))
+	switch c.Query("tags_match", "any") {
+	case "any":
+		return names, false, nil
+	case "all":
+		return names, true, nil
+	}
+	return nil, false, fmt.Errorf("Invalid tags_match, expected any or all")
+}
+
+// applyTagFilter keeps the work orders carrying any, or with matchAll all, of the named tags,
+// skipping an empty list. The outer work order query keeps the filter within the plant.
+func applyTagFilter(query *gorm.DB, names []string, matchAll bool) *gorm.DB {
+	if len(names) == 0 {
+		return query
+	}
+	tagged := "SELECT wot.work_order_id FROM work_order_tags wot JOIN tags t ON t.id = wot.tag_id WHERE t.name IN ?"
+	if matchAll {
+		return query.Where("work_orders.id IN ("+tagged+" GROUP BY wot.work_order_id HAVING COUNT(DISTINCT t.name) = ?)", names, len(names))
+	}
+	return query.Where("work_orders.id IN ("+tagged+")", names)
+}
+
+// normalizeTagName trims and lowercases a tag name
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTagNames normalizes tag names, dropping empty ones and repeats
+func normalizeTagNames(names []string) []string {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized
+}
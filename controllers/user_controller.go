@@ -1,31 +1,96 @@
 package controllers
 
 import (
-	"github.com/dawamr/work-order-system-go/database"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
 	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/validator"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // OperatorResponse represents a response containing a list of operators
 type OperatorResponse struct {
-	Error     bool          `json:"error"`
-	Operators []models.User `json:"operators"`
+	Error      bool       `json:"error"`
+	Operators  []UserDTO  `json:"operators"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// UserResponse represents a single user response
+type UserResponse struct {
+	Error bool    `json:"error"`
+	User  UserDTO `json:"user"`
+}
+
+// ResetPasswordRequest represents the reset password request body
+type ResetPasswordRequest struct {
+	Password           string `json:"password"`             // optional, a random password is generated when empty
+	MustChangePassword *bool  `json:"must_change_password"` // defaults to true
+}
+
+// ResetPasswordResponse represents a reset password response
+type ResetPasswordResponse struct {
+	Error              bool   `json:"error"`
+	Message            string `json:"message"`
+	Password           string `json:"password,omitempty"` // only returned when the password was generated
+	MustChangePassword bool   `json:"must_change_password"`
 }
 
 // @Summary Get all operators
-// @Description Get a list of all operators in the system
+// @Description Get a paginated list of operators in the system
 // @Tags operators
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param search query string false "Filter by username substring"
+// @Param active query bool false "Filter by active status"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
 // @Success 200 {object} OperatorResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /operators [get]
 func GetOperators(c *fiber.Ctx) error {
-	// Fetch all users with operator role from database
+	// Get query parameters
+	search := c.Query("search")
+	active := c.Query("active")
+	page := c.QueryInt("page", 1)
+	limit := pageLimit(c)
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Build query for users with operator role
+	query := getDB(c).Model(&models.User{}).Where("role = ?", models.RoleOperator)
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("UPPER(username) LIKE ?", "%"+strings.ToUpper(search)+"%")
+	}
+
+	// Apply active filter if provided
+	if active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Invalid active filter, expected true or false",
+			})
+		}
+		query = query.Where("active = ?", isActive)
+	}
+
+	// Get total count
+	var count int64
+	query.Count(&count)
+
+	// Fetch operators with pagination
 	var operators []models.User
-	result := database.DB.Where("role = ?", models.RoleOperator).Find(&operators)
+	result := query.Offset(offset).Limit(limit).Order("username ASC").Find(&operators)
 
 	if result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
@@ -37,6 +102,174 @@ func GetOperators(c *fiber.Ctx) error {
 	// Return operators list
 	return c.Status(fiber.StatusOK).JSON(OperatorResponse{
 		Error:     false,
-		Operators: operators,
+		Operators: toUserDTOs(operators),
+		Pagination: Pagination{
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
+		},
 	})
 }
+
+// @Summary Get user by ID
+// @Description Get the details of a user, including the last login time (Production Manager only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id} [get]
+func GetUserByID(c *fiber.Ctx) error {
+	// Get user ID from URL
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	// Get user from database
+	var user models.User
+	result := getDB(c).First(&user, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching user",
+		})
+	}
+
+	// Return user
+	return c.Status(fiber.StatusOK).JSON(UserResponse{
+		Error: false,
+		User:  toUserDTO(user),
+	})
+}
+
+// @Summary Reset user password
+// @Description Set a new password for an operator, or generate one when none is given. The generated password is only returned once. Other Production Managers' passwords can not be reset (403). (Production Manager only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body ResetPasswordRequest false "New password"
+// @Success 200 {object} ResetPasswordResponse
+// @Failure 400 {object} ValidationErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /users/{id}/reset-password [post]
+func ResetUserPassword(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	// Parse request body, an empty body generates a password
+	var req ResetPasswordRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Invalid request body",
+			})
+		}
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	// Get user from database
+	var user models.User
+	result := getDB(c).First(&user, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching user",
+		})
+	}
+
+	// Only operator passwords are reset, a manager's account is not taken over by another manager
+	if user.Role != models.RoleOperator {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Only the passwords of operators can be reset",
+		})
+	}
+
+	policy := validator.DefaultPasswordPolicy()
+	password := req.Password
+	generated := password == ""
+	if generated {
+		var err error
+		if password, err = policy.Generate(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error generating password",
+			})
+		}
+	} else if errs := policy.Validate("password", password); len(errs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ValidationErrorResponse{
+			Error:  true,
+			Msg:    "Password does not meet the password policy",
+			Errors: errs,
+		})
+	}
+
+	mustChange := true
+	if req.MustChangePassword != nil {
+		mustChange = *req.MustChangePassword
+	}
+
+	// Password is re-hashed by the BeforeSave hook
+	user.Password = password
+	user.MustChangePassword = mustChange
+	if err := getDB(c).Save(&user).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error resetting password",
+		})
+	}
+
+	// Never log the password itself
+	if err := auditService.CreateLog(
+		getDB(c),
+		userID,
+		models.ActionUpdate,
+		"User",
+		user.ID,
+		nil,
+		nil,
+		fmt.Sprintf("Password of user %s reset (must change password: %t)", user.Username, mustChange),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	response := ResetPasswordResponse{
+		Error:              false,
+		Message:            "Password reset successfully",
+		MustChangePassword: mustChange,
+	}
+	if generated {
+		response.Password = password
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/middleware"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// updatedValue returns the value an UPDATE statement sets the column to
+func updatedValue(stmt dbtest.Statement, column string) (driver.Value, bool) {
+	match := regexp.MustCompile(`"` + column + `"=\$(\d+)`).FindStringSubmatch(stmt.SQL)
+	if match == nil {
+		return nil, false
+	}
+	arg, _ := strconv.Atoi(match[1])
+	return stmt.Args[arg-1], true
+}
+
+func TestResetUserPasswordTargets(t *testing.T) {
+	manager := testUser{1, models.RoleProductionManager, 0}
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{"operator", "/users/2/reset-password", fiber.StatusOK},
+		{"another manager", "/users/3/reset-password", fiber.StatusForbidden},
+		{"own password", "/users/1/reset-password", fiber.StatusForbidden},
+		{"unknown user", "/users/9/reset-password", fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, storedRows(map[string]testTable{
+				"users": {
+					columns: []string{"id", "plant_id", "username", "role"},
+					rows: [][]driver.Value{
+						{int64(1), int64(1), "manager", string(models.RoleProductionManager)},
+						{int64(2), int64(1), "operator", string(models.RoleOperator)},
+						{int64(3), int64(1), "other-manager", string(models.RoleProductionManager)},
+					},
+				},
+			}))
+
+			status, body := testRequestBody(t, "/users/:id/reset-password", ResetUserPassword, manager, fiber.MethodPost, tt.target, `{"password":"Reset-Pass1"}`)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if updates := recorder.Find(`UPDATE "users"`); (len(updates) > 0) != (status == fiber.StatusOK) {
+				t.Errorf("password updated %d times, answered %d", len(updates), status)
+			}
+		})
+	}
+}
+
+func TestResetPasswordForcesAChange(t *testing.T) {
+	useTestTokens(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"must change by default", `{"password":"Reset-Pass1"}`, fiber.StatusForbidden},
+		{"must change", `{"password":"Reset-Pass1","must_change_password":true}`, fiber.StatusForbidden},
+		{"may keep the password", `{"password":"Reset-Pass1","must_change_password":false}`, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, userRows)
+			status, body := testRequestBody(t, "/users/:id/reset-password", ResetUserPassword, testUser{managerID, models.RoleProductionManager, 0}, fiber.MethodPost, "/users/2/reset-password", tt.body)
+			if status != fiber.StatusOK {
+				t.Fatalf("reset answered %d (%v)", status, body)
+			}
+
+			// The operator signs in with the stored password and flag
+			updates := recorder.Find(`UPDATE "users"`)
+			if len(updates) != 1 {
+				t.Fatalf("password updated %d times, want once", len(updates))
+			}
+			hash, _ := updatedValue(updates[0], "password")
+			mustChange, _ := updatedValue(updates[0], "must_change_password")
+			user := operatorUser
+			user.Password, _ = hash.(string)
+			user.MustChangePassword, _ = mustChange.(bool)
+			if err := user.CheckPassword("Reset-Pass1"); err != nil {
+				t.Fatalf("reset password does not sign in: %v", err)
+			}
+			token, err := middleware.GenerateToken(&user)
+			if err != nil {
+				t.Fatalf("generating token: %v", err)
+			}
+
+			app := fiber.New()
+			app.Get("/work-orders", middleware.Protected(), middleware.PasswordChangeGate(), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+			req := httptest.NewRequest(fiber.MethodGet, "/work-orders", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("serving request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("gated request answered %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// BurndownPoint is the produced and the ideal quantity of a work order at one moment
+type BurndownPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Produced  int       `json:"produced"` // cumulative progress quantity
+	Ideal     float64   `json:"ideal"`    // quantity due by then on a straight line from creation to the deadline
+}
+
+// BurndownResponse represents a work order burndown
+type BurndownResponse struct {
+	Error              bool            `json:"error"`
+	WorkOrderNumber    string          `json:"work_order_number"`
+	TargetQuantity     int             `json:"target_quantity"`
+	CreatedAt          time.Time       `json:"created_at"`
+	ProductionDeadline time.Time       `json:"production_deadline"`
+	Points             []BurndownPoint `json:"points"` // chronological
+}
+
+// @Summary Get work order burndown
+// @Description Get how the produced quantity approached the target over the work order's life: a point at creation, one per progress entry and one at the production deadline, each with the cumulative progress quantity and the ideal quantity of a straight line from zero at creation to the target at the deadline
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Success 200 {object} BurndownResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/burndown [get]
+func GetWorkOrderBurndown(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	// Check if user is the assigned operator or a production manager
+	if role == models.RoleOperator && workOrder.OperatorID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "You are not assigned to this work order",
+		})
+	}
+
+	var progress []models.WorkOrderProgress
+	if err := getDB(c).Where("work_order_id = ?", workOrder.ID).Order("created_at ASC, id ASC").Find(&progress).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching progress entries",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(BurndownResponse{
+		Error:              false,
+		WorkOrderNumber:    workOrder.WorkOrderNumber,
+		TargetQuantity:     workOrder.TargetQuantity,
+		CreatedAt:          workOrder.CreatedAt,
+		ProductionDeadline: workOrder.ProductionDeadline,
+		Points:             burndownPoints(workOrder, progress),
+	})
+}
+
+// burndownPoints builds the burndown series of a work order from its chronological progress entries
+func burndownPoints(workOrder models.WorkOrder, progress []models.WorkOrderProgress) []BurndownPoint {
+	points := make([]BurndownPoint, 0, len(progress)+2)
+	points = append(points, BurndownPoint{Timestamp: workOrder.CreatedAt})
+
+	produced := 0
+	for _, entry := range progress {
+		produced += entry.ProgressQuantity
+		points = append(points, BurndownPoint{Timestamp: entry.CreatedAt, Produced: produced})
+	}
+
+	// The deadline point shows where the ideal line reaches the target, with the
+	// quantity produced by then
+	if workOrder.ProductionDeadline.After(workOrder.CreatedAt) {
+		deadline := BurndownPoint{Timestamp: workOrder.ProductionDeadline}
+		for _, entry := range progress {
+			if entry.CreatedAt.After(workOrder.ProductionDeadline) {
+				break
+			}
+			deadline.Produced += entry.ProgressQuantity
+		}
+		points = append(points, deadline)
+	}
+
+	// Progress logged after the deadline keeps its place after the deadline point
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
+	for i := range points {
+		points[i].Ideal = idealBurndownQuantity(workOrder, points[i].Timestamp)
+	}
+	return points
+}
+
+// idealBurndownQuantity is the quantity due at the given time when producing at a
+// constant rate from zero at creation to the target at the production deadline
+func idealBurndownQuantity(workOrder models.WorkOrder, at time.Time) float64 {
+	total := workOrder.ProductionDeadline.Sub(workOrder.CreatedAt)
+	if total <= 0 || !at.Before(workOrder.ProductionDeadline) {
+		return float64(workOrder.TargetQuantity)
+	}
+	if !at.After(workOrder.CreatedAt) {
+		return 0
+	}
+	return roundTwoDecimals(float64(workOrder.TargetQuantity) * float64(at.Sub(workOrder.CreatedAt)) / float64(total))
+}
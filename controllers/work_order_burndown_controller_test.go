@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+)
+
+func TestBurndownPoints(t *testing.T) {
+	created := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return created.Add(time.Duration(hours) * time.Hour) }
+	progress := func(hours, quantity int) models.WorkOrderProgress {
+		return models.WorkOrderProgress{ProgressQuantity: quantity, CreatedAt: at(hours)}
+	}
+	workOrder := models.WorkOrder{TargetQuantity: 100, CreatedAt: created, ProductionDeadline: at(10)}
+
+	tests := []struct {
+		name      string
+		workOrder models.WorkOrder
+		progress  []models.WorkOrderProgress
+		want      []BurndownPoint
+	}{
+		{
+			name:      "no progress",
+			workOrder: workOrder,
+			want: []BurndownPoint{
+				{Timestamp: at(0), Produced: 0, Ideal: 0},
+				{Timestamp: at(10), Produced: 0, Ideal: 100},
+			},
+		},
+		{
+			name:      "progress before the deadline",
+			workOrder: workOrder,
+			progress:  []models.WorkOrderProgress{progress(2, 10), progress(5, 40)},
+			want: []BurndownPoint{
+				{Timestamp: at(0), Produced: 0, Ideal: 0},
+				{Timestamp: at(2), Produced: 10, Ideal: 20},
+				{Timestamp: at(5), Produced: 50, Ideal: 50},
+				{Timestamp: at(10), Produced: 50, Ideal: 100},
+			},
+		},
+		{
+			name:      "progress after the deadline follows the deadline point",
+			workOrder: workOrder,
+			progress:  []models.WorkOrderProgress{progress(4, 30), progress(10, 20), progress(12, 50)},
+			want: []BurndownPoint{
+				{Timestamp: at(0), Produced: 0, Ideal: 0},
+				{Timestamp: at(4), Produced: 30, Ideal: 40},
+				{Timestamp: at(10), Produced: 50, Ideal: 100},
+				{Timestamp: at(10), Produced: 50, Ideal: 100},
+				{Timestamp: at(12), Produced: 100, Ideal: 100},
+			},
+		},
+		{
+			name:      "ideal quantity is rounded to two decimals",
+			workOrder: models.WorkOrder{TargetQuantity: 10, CreatedAt: created, ProductionDeadline: at(3)},
+			progress:  []models.WorkOrderProgress{progress(1, 3)},
+			want: []BurndownPoint{
+				{Timestamp: at(0), Produced: 0, Ideal: 0},
+				{Timestamp: at(1), Produced: 3, Ideal: 3.33},
+				{Timestamp: at(3), Produced: 3, Ideal: 10},
+			},
+		},
+		{
+			name:      "deadline not after creation has no deadline point",
+			workOrder: models.WorkOrder{TargetQuantity: 100, CreatedAt: created, ProductionDeadline: created},
+			progress:  []models.WorkOrderProgress{progress(1, 60)},
+			want: []BurndownPoint{
+				{Timestamp: at(0), Produced: 0, Ideal: 100},
+				{Timestamp: at(1), Produced: 60, Ideal: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := burndownPoints(tt.workOrder, tt.progress); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("burndownPoints() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
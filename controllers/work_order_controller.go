@@ -1,16 +1,22 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dawamr/work-order-system-go/config"
 	"github.com/dawamr/work-order-system-go/database"
 	"github.com/dawamr/work-order-system-go/models"
 	"github.com/dawamr/work-order-system-go/services"
+	"github.com/dawamr/work-order-system-go/utils/events"
+	"github.com/dawamr/work-order-system-go/utils/features"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var auditService = services.AuditLogService{}
@@ -20,43 +26,188 @@ type CreateWorkOrderRequest struct {
 	ProductName        string    `json:"product_name" validate:"required"`
 	Quantity           int       `json:"quantity" validate:"required,min=0"`
 	TargetQuantity     int       `json:"target_quantity" validate:"required,min=1"`
+	Unit               string    `json:"unit" validate:"omitempty,max=20"` // unit of measure of the quantities, defaults to pcs
 	ProductionDeadline time.Time `json:"production_deadline" validate:"required"`
 	OperatorID         uint      `json:"operator_id" validate:"required"`
+	Priority           int       `json:"priority" validate:"min=0,max=9"` // higher is started first, 0 by default
+	Force              bool      `json:"force"`                           // override the operator's active order limit and the duplicate active order check
+	AllowPastDeadline  bool      `json:"allow_past_deadline"`             // accept a deadline in the past, e.g. for data imports
+	// AllowDeadlineOutsideWindow accepts a deadline outside DEADLINE_MIN_LEAD_HOURS..DEADLINE_MAX_HORIZON_DAYS, audit logged
+	AllowDeadlineOutsideWindow bool `json:"allow_deadline_outside_window"`
 }
 
+// deadlineGracePeriod is how far in the past a new work order deadline may be,
+// to absorb clock skew between the client and the server
+const deadlineGracePeriod = 5 * time.Minute
+
 // UpdateWorkOrderRequest represents the update work order request body
 type UpdateWorkOrderRequest struct {
-	ProductName        string             `json:"product_name"`
-	Quantity           int                `json:"quantity" validate:"omitempty,min=0"`
-	TargetQuantity     int                `json:"target_quantity" validate:"omitempty,min=1"`
-	ProductionDeadline time.Time          `json:"production_deadline"`
+	ProductName        string                 `json:"product_name"`
+	TargetQuantity     int                    `json:"target_quantity" validate:"omitempty,min=1"`
+	Unit               string                 `json:"unit" validate:"omitempty,max=20"`
+	ProductionDeadline time.Time              `json:"production_deadline"`
 	Status             models.WorkOrderStatus `json:"status"`
-	OperatorID         uint               `json:"operator_id"`
+	OperatorID         uint                   `json:"operator_id"`
+	Priority           *int                   `json:"priority" validate:"omitempty,min=0,max=9"`
+	Force              bool                   `json:"force"` // override the operator's active order limit
+	// AllowDeadlineOutsideWindow accepts a deadline outside DEADLINE_MIN_LEAD_HOURS..DEADLINE_MAX_HORIZON_DAYS, audit logged
+	AllowDeadlineOutsideWindow bool `json:"allow_deadline_outside_window"`
+	StatusChangeDetails
+}
+
+// StatusChangeDetails are the details a status change may require, accepted by every request that changes a status
+type StatusChangeDetails struct {
+	HoldReason string `json:"hold_reason"` // required when moving to on_hold
+	// RemainingDisposition is required when completing below the target quantity
+	RemainingDisposition models.RemainingDisposition `json:"remaining_disposition" validate:"omitempty,oneof=cancelled backorder"`
+	// CancellationReason is required when the remaining quantity is cancelled
+	CancellationReason string `json:"cancellation_reason"`
+	// RestockNote records the materials of a cancelled remainder returned to stock
+	RestockNote string `json:"restock_note"`
+	// BackorderDeadline is the deadline of the spawned backorder, defaults to the original deadline
+	BackorderDeadline *time.Time `json:"backorder_deadline"`
 }
 
 // UpdateWorkOrderStatusRequest represents the update work order status request body
 type UpdateWorkOrderStatusRequest struct {
-	Status   models.WorkOrderStatus `json:"status" validate:"required,oneof=pending in_progress completed"`
-	Quantity int                `json:"quantity" validate:"omitempty,min=0"`
-	Description string             `json:"description"`
+	Status models.WorkOrderStatus `json:"status" validate:"required,oneof=pending in_progress on_hold completed"`
+	// Quantity is the produced quantity; the target quantity can only be changed by a Production Manager through PUT /work-orders/{id}
+	Quantity    int    `json:"quantity" validate:"omitempty,min=0"`
+	Description string `json:"description"`
+	StatusChangeDetails
+	// Force lets a Production Manager override the operator's active order limit
+	Force bool `json:"force"`
+	// Reason is required when a Production Manager changes the status on behalf of the assigned operator
+	Reason string `json:"reason"`
+}
+
+// BatchGetWorkOrdersRequest represents the batch get work orders request body
+type BatchGetWorkOrdersRequest struct {
+	IDs []uint `json:"ids" validate:"required"`
+}
+
+// maxBatchGetIDs caps how many work orders can be fetched in one batch request
+const maxBatchGetIDs = 100
+
+// BatchGetWorkOrdersResponse represents the work orders found for a batch of IDs
+type BatchGetWorkOrdersResponse struct {
+	Error      bool           `json:"error"`
+	WorkOrders []WorkOrderDTO `json:"work_orders"` // in the order the IDs were requested
+	MissingIDs []uint         `json:"missing_ids"` // requested IDs that were not found or are not visible to the user
+}
+
+// AcknowledgeAllRequest represents the bulk acknowledge request body
+type AcknowledgeAllRequest struct {
+	OperatorID uint `json:"operator_id"` // required for Production Managers, operators always acknowledge their own orders
+}
+
+// AcknowledgeAllResponse represents the work orders acknowledged at once
+type AcknowledgeAllResponse struct {
+	Error        bool   `json:"error"`
+	Acknowledged int    `json:"acknowledged"`
+	WorkOrderIDs []uint `json:"work_order_ids"`
 }
 
 // WorkOrderResponse represents a work order response
 type WorkOrderResponse struct {
 	Error     bool         `json:"error"`
-	WorkOrder models.WorkOrder `json:"work_order"`
+	WorkOrder WorkOrderDTO `json:"work_order"`
+}
+
+// NextWorkOrderResponse represents the work order an operator should start next
+type NextWorkOrderResponse struct {
+	Error     bool         `json:"error"`
+	WorkOrder WorkOrderDTO `json:"work_order"`
+	Overdue   bool         `json:"overdue"` // past its production deadline
 }
 
 // WorkOrderListResponse represents a paginated list of work orders
 type WorkOrderListResponse struct {
-	Error      bool             `json:"error"`
-	WorkOrders []models.WorkOrder `json:"work_orders"`
+	Error      bool           `json:"error"`
+	WorkOrders []WorkOrderDTO `json:"work_orders"`
 	Pagination struct {
 		Total int64 `json:"total"`
 		Page  int   `json:"page"`
 		Limit int   `json:"limit"`
 		Pages int64 `json:"pages"`
 	} `json:"pagination"`
+	Matches []SearchMatch `json:"matches,omitempty"` // only populated when a search term is given
+}
+
+// SearchMatch describes where the search term matched a work order in a list result
+type SearchMatch struct {
+	WorkOrderID  uint          `json:"work_order_id"`
+	MatchedField string        `json:"matched_field"` // product_name or work_order_number
+	Offsets      []MatchOffset `json:"offsets"`
+}
+
+// MatchOffset is a matched substring as [start, end) character offsets into the field value
+type MatchOffset struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// CapacityErrorResponse represents an error response when an operator holds too many active orders
+type CapacityErrorResponse struct {
+	Error        bool   `json:"error"`
+	Msg          string `json:"msg"`
+	ActiveOrders int64  `json:"active_orders"`
+	Limit        int    `json:"limit"`
+}
+
+// DeadlineWindow is the range production deadlines have to fall in, inclusive
+type DeadlineWindow struct {
+	Earliest *time.Time `json:"earliest"` // nil without a minimum lead time
+	Latest   *time.Time `json:"latest"`   // nil without a maximum horizon
+}
+
+// DeadlineWindowErrorResponse represents an error response for a deadline outside the allowed window
+type DeadlineWindowErrorResponse struct {
+	Error  bool           `json:"error"`
+	Msg    string         `json:"msg"`
+	Code   string         `json:"code"`
+	Window DeadlineWindow `json:"allowed_window"`
+}
+
+// TargetChange is a target quantity edit of a work order that already has production
+type TargetChange struct {
+	OldTarget int   `json:"old_target"`
+	NewTarget int   `json:"new_target"`
+	Produced  int   `json:"produced"`         // produced quantity the new target applies to
+	Progress  int64 `json:"progress_entries"` // progress entries logged against the old target
+}
+
+// TargetChangeErrorResponse represents an error response for a rejected target quantity edit
+type TargetChangeErrorResponse struct {
+	Error        bool         `json:"error"`
+	Msg          string       `json:"msg"`
+	Code         string       `json:"code"`
+	TargetChange TargetChange `json:"target_change"`
+}
+
+// UpdateWorkOrderResponse represents an updated work order, warning about a target
+// quantity changed after progress was logged
+type UpdateWorkOrderResponse struct {
+	Error        bool          `json:"error"`
+	WorkOrder    WorkOrderDTO  `json:"work_order"`
+	Warning      string        `json:"warning,omitempty"`
+	TargetChange *TargetChange `json:"target_change,omitempty"`
+}
+
+// DuplicateActiveOrderErrorResponse represents an error response when the operator already has an active order for the product
+type DuplicateActiveOrderErrorResponse struct {
+	Error                   bool   `json:"error"`
+	Msg                     string `json:"msg"`
+	Code                    string `json:"code"`
+	ExistingWorkOrderNumber string `json:"existing_work_order_number"`
+}
+
+// AlreadyCompletedErrorResponse represents an error response when a work order was already completed
+type AlreadyCompletedErrorResponse struct {
+	Error         bool   `json:"error"`
+	Msg           string `json:"msg"`
+	Code          string `json:"code"`
+	FinalQuantity int    `json:"final_quantity"` // quantity recorded by the completion that went through
 }
 
 // Pagination represents pagination information
@@ -69,18 +220,51 @@ type Pagination struct {
 
 // CreateWorkOrderLogRequest represents the request body for creating a work order log
 type CreateWorkOrderLogRequest struct {
-	Note   string              `json:"note" validate:"required"`
+	Note   string                 `json:"note" validate:"required"`
 	Status models.WorkOrderStatus `json:"status,omitempty"`
+	// StatusChangeDetails complete a status change, the hold reason defaults to the note
+	StatusChangeDetails
+}
+
+// StatusTransitionsResponse lists the statuses a work order may move to
+type StatusTransitionsResponse struct {
+	Error       bool                     `json:"error"`
+	Status      models.WorkOrderStatus   `json:"status"`
+	Transitions []models.WorkOrderStatus `json:"transitions"`
+}
+
+// WorkOrderLogsResponse represents a paginated list of the audit log entries of a work order
+type WorkOrderLogsResponse struct {
+	Error      bool          `json:"error"`
+	Logs       []AuditLogDTO `json:"logs"`
+	Pagination Pagination    `json:"pagination"`
+}
+
+// WorkOrderLogResponse represents a created work order log response
+type WorkOrderLogResponse struct {
+	Error     bool         `json:"error"`
+	Message   string       `json:"message"`
+	WorkOrder WorkOrderDTO `json:"work_order"`
+}
+
+// workOrderUnit returns the requested unit of measure, or the default unit when none is given
+func workOrderUnit(unit string) string {
+	if unit = strings.TrimSpace(unit); unit != "" {
+		return unit
+	}
+	return models.DefaultUnit
 }
 
-// GenerateWorkOrderNumber generates a unique work order number
-func GenerateWorkOrderNumber() string {
+// generateWorkOrderNumber generates the next work order number as seen by db,
+// so numbers generated inside a transaction follow the ones it already created
+func generateWorkOrderNumber(db *gorm.DB) string {
 	// Format: WO-YYYYMMDD-XXX
 	date := time.Now().Format("20060102")
 
-	// Get the latest work order number for today
+	// Get the latest work order number for today, including deleted work orders
+	// and those of other plants since their numbers stay taken by the unique index
 	var latestWorkOrder models.WorkOrder
-	result := database.DB.Where("work_order_number LIKE ?", fmt.Sprintf("WO-%s-%%", date)).
+	result := db.WithContext(database.WithoutPlant(db.Statement.Context)).Unscoped().Where("work_order_number LIKE ?", fmt.Sprintf("WO-%s-%%", date)).
 		Order("work_order_number DESC").
 		First(&latestWorkOrder)
 
@@ -99,21 +283,26 @@ func GenerateWorkOrderNumber() string {
 }
 
 // @Summary Create work order
-// @Description Create a new work order (Production Manager only)
+// @Description Create a new work order (Production Manager only). With PREVENT_DUPLICATE_ACTIVE_ORDERS, an operator's second non-completed order for the same product is rejected with 409 unless force is set. The production deadline must fall within DEADLINE_MIN_LEAD_HOURS and DEADLINE_MAX_HORIZON_DAYS unless allow_deadline_outside_window is set, which is audit logged.
 // @Tags work-orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body CreateWorkOrderRequest true "Work order details"
 // @Success 201 {object} WorkOrderResponse
-// @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} DeadlineWindowErrorResponse "Invalid request or deadline outside the allowed window"
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} DuplicateActiveOrderErrorResponse "Work order number taken, operator at capacity or a duplicate active order"
 // @Router /work-orders [post]
 func CreateWorkOrder(c *fiber.Ctx) error {
 
 	// Only Production Manager can create work orders
-	role := c.Locals("role").(models.Role)
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
 	if role != models.RoleProductionManager {
 		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Error: true,
@@ -123,9 +312,6 @@ func CreateWorkOrder(c *fiber.Ctx) error {
 
 	// Parse request body
 	var req CreateWorkOrderRequest
-
-	fmt.Println(c.BodyParser(&req))
-	fmt.Println(req)
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Error: true,
@@ -133,19 +319,66 @@ func CreateWorkOrder(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.Priority < 0 || req.Priority > models.MaxPriority {
+		return priorityError(c)
+	}
 
-	// Check if operator exists
-	var operator models.User
-	result := database.DB.Where("id = ? AND role = ?", req.OperatorID, models.RoleOperator).First(&operator)
-	if result.Error != nil {
+	// The production deadline must be in the future unless explicitly allowed
+	if !req.AllowPastDeadline && !req.ProductionDeadline.After(time.Now().Add(-deadlineGracePeriod)) {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Error: true,
-			Msg:   "Operator not found",
+			Msg:   "Production deadline must be in the future, set allow_past_deadline to import past work orders",
+		})
+	}
+
+	// The deadline must respect the minimum lead time and the maximum horizon unless
+	// overridden; a past deadline that is explicitly allowed skips the lead time
+	window := deadlineWindow(time.Now())
+	if req.AllowPastDeadline {
+		window.Earliest = nil
+	}
+	deadlineOverride := !window.contains(req.ProductionDeadline)
+	if deadlineOverride && !req.AllowDeadlineOutsideWindow {
+		return deadlineWindowError(c, window)
+	}
+
+	// Check if operator exists
+	operator, err := findAssignableOperator(getDB(c), req.OperatorID)
+	if err != nil {
+		return operatorNotFoundError(c, err)
+	}
+
+	// Check the operator's active order limit
+	activeOrders, atCapacity, err := operatorAtCapacity(getDB(c), req.OperatorID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error checking operator active orders",
+		})
+	}
+	if atCapacity && !req.Force {
+		return capacityError(c, activeOrders)
+	}
+
+	// Check for an identical order the operator is still working on
+	duplicate, err := findDuplicateActiveOrder(getDB(c), req.ProductName, req.OperatorID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error checking duplicate work orders",
+		})
+	}
+	if duplicate != nil && !req.Force {
+		return c.Status(fiber.StatusConflict).JSON(DuplicateActiveOrderErrorResponse{
+			Error:                   true,
+			Msg:                     fmt.Sprintf("Operator already has active work order %s for %s, set force to create another one", duplicate.WorkOrderNumber, duplicate.ProductName),
+			Code:                    CodeDuplicateActiveOrder,
+			ExistingWorkOrderNumber: duplicate.WorkOrderNumber,
 		})
 	}
 
 	// Generate work order number
-	workOrderNumber := GenerateWorkOrderNumber()
+	workOrderNumber := generateWorkOrderNumber(getDB(c))
 
 	// Create work order
 	workOrder := models.WorkOrder{
@@ -153,13 +386,23 @@ func CreateWorkOrder(c *fiber.Ctx) error {
 		ProductName:        req.ProductName,
 		Quantity:           req.Quantity,
 		TargetQuantity:     req.TargetQuantity,
+		Unit:               workOrderUnit(req.Unit),
 		ProductionDeadline: req.ProductionDeadline,
 		Status:             models.StatusPending,
+		Priority:           req.Priority,
 		OperatorID:         req.OperatorID,
 	}
 
 	// Save work order to database
-	if err := database.DB.Create(&workOrder).Error; err != nil {
+	if err := getDB(c).Create(&workOrder).Error; err != nil {
+		// A concurrent create may have generated the same work order number
+		if database.IsUniqueViolation(err) {
+			return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order number already exists, please retry",
+				Code:  CodeDuplicateWorkOrderNumber,
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Error creating work order",
@@ -173,17 +416,32 @@ func CreateWorkOrder(c *fiber.Ctx) error {
 		Quantity:    0,
 	}
 
-	if err := database.DB.Create(&statusHistory).Error; err != nil {
+	if err := getDB(c).Create(&statusHistory).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Error creating status history",
 		})
 	}
+	refreshDailyCounter(getDB(c), workOrder.ProductName, statusHistory.CreatedAt)
+
+	if atCapacity {
+		logCapacityOverride(getDB(c), userID, workOrder, operator.Username, activeOrders)
+	}
+	if duplicate != nil {
+		logDuplicateOverride(getDB(c), userID, workOrder, *duplicate, operator.Username)
+	}
+	if deadlineOverride {
+		logDeadlineOverride(getDB(c), userID, workOrder, window)
+	}
+
+	if err := publishEvent(c, events.WorkOrderCreated, workOrder, ""); err != nil {
+		return eventError(c, err)
+	}
 
 	// Return work order
 	return c.Status(fiber.StatusCreated).JSON(WorkOrderResponse{
 		Error:     false,
-		WorkOrder: workOrder,
+		WorkOrder: toWorkOrderDTO(workOrder),
 	})
 }
 
@@ -195,52 +453,69 @@ func CreateWorkOrder(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 10)"
-// @Param status query string false "Filter by status (pending/in_progress/completed)"
+// @Param status query string false "Filter by status (pending/in_progress/on_hold/completed)"
+// @Param search query string false "Search by work order number (WO- prefix) or product name"
+// @Param deadline query string false "Production deadline on this day (YYYY-MM-DD or RFC 3339)"
+// @Param deadline_from query string false "Production deadline on or after this day (YYYY-MM-DD or RFC 3339)"
+// @Param deadline_to query string false "Production deadline on or before this day (YYYY-MM-DD or RFC 3339)"
+// @Param acknowledged query bool false "Filter by whether the assigned operator acknowledged the order"
+// @Param min_quantity query int false "Minimum ordered (target) quantity"
+// @Param max_quantity query int false "Maximum ordered (target) quantity"
+// @Param sort_by query string false "Sort by work_order_number, production_deadline, created_at, target_quantity, quantity or status (default: WORK_ORDER_SORT_BY)"
+// @Param order query string false "Sort order asc or desc (default: WORK_ORDER_SORT_ORDER)"
+// @Param fields query string false "Comma separated work order fields to return, e.g. id,work_order_number,status (default: all)"
+// @Param tags query string false "Comma separated tag names, e.g. rework,customer-x"
+// @Param tags_match query string false "Match orders with any (default) or all of the tags"
 // @Success 200 {object} WorkOrderListResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Router /work-orders [get]
 func GetWorkOrders(c *fiber.Ctx) error {
 	// Get query parameters
-	status := c.Query("status")
 	page := c.QueryInt("page", 1)
-	limit := c.QueryInt("limit", 10)
+	limit := pageLimit(c)
 	operatorID := c.QueryInt("operator_id", 0) // filter by work_orders.operator_id
-	search := c.Query("search") // search by work_orders.work_order_number, work_orders.product_name
-	deadline := c.Query("deadline") // filter by work_orders.production_deadline
+
+	// Filter by status, search, deadline, quantity, acknowledgement and tags
+	filter, err := parseWorkOrderListFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Sort by sort_by and order
+	orderBy, err := parseWorkOrderSort(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Trim the work orders to the selected fields if requested
+	fields, err := parseWorkOrderFields(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
 
 	// Calculate offset
 	offset := (page - 1) * limit
 
 	// Build query
-	query := database.DB.Model(&models.WorkOrder{}).Preload("Operator")
-
-	// Apply status filter if provided
-	if status != "" {
-		query = query.Where("status = ?", status)
-	}
+	query := preloadUnscoped(getDB(c).Model(&models.WorkOrder{}), "Operator").Preload("Tags")
 
 	// Apply operator filter if provided
 	if operatorID > 0 {
 		query = query.Where("operator_id = ?", operatorID)
 	}
 
-	// Apply search if provided
-	if search != "" {
-		// search with UPPERCASE
-		search = strings.ToUpper(search)
-		if strings.HasPrefix(search, "WO-") {
-			query = query.Where("UPPER(work_order_number) LIKE ?", "%"+search+"%")
-		} else {
-			query = query.Where("UPPER(product_name) LIKE ?", "%"+search+"%")
-		}
-	}
-
-	// Apply deadline filter if provided
-	if deadline != "" {
-		// Assume deadline is in YYYY-MM-DD format
-		query = query.Where("DATE(production_deadline) = ?", deadline)
-	}
+	query = applyWorkOrderListFilter(query, filter)
 
 	// Get total count
 	var count int64
@@ -248,7 +523,7 @@ func GetWorkOrders(c *fiber.Ctx) error {
 
 	// Get work orders with pagination
 	var workOrders []models.WorkOrder
-	result := query.Offset(offset).Limit(limit).Order("work_order_number DESC").Find(&workOrders)
+	result := query.Offset(offset).Limit(limit).Order(orderBy).Find(&workOrders)
 	if result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
@@ -257,16 +532,17 @@ func GetWorkOrders(c *fiber.Ctx) error {
 	}
 
 	// Return work orders with pagination info
-	return c.Status(fiber.StatusOK).JSON(WorkOrderListResponse{
+	return sendWorkOrderFields(c, fiber.StatusOK, WorkOrderListResponse{
 		Error:      false,
-		WorkOrders: workOrders,
+		WorkOrders: toWorkOrderDTOs(workOrders),
 		Pagination: Pagination{
-			Total:  count,
-			Page:   page,
-			Limit:  limit,
-			Pages:  (count + int64(limit) - 1) / int64(limit),
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
 		},
-	})
+		Matches: searchMatches(workOrders, filter.Search),
+	}, "work_orders", fields)
 }
 
 // @Summary Get assigned work orders
@@ -277,50 +553,173 @@ func GetWorkOrders(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 10)"
-// @Param status query string false "Filter by status (pending/in_progress/completed)"
+// @Param status query string false "Filter by status (pending/in_progress/on_hold/completed)"
+// @Param search query string false "Search by work order number (WO- prefix) or product name"
+// @Param deadline query string false "Production deadline on this day (YYYY-MM-DD or RFC 3339)"
+// @Param deadline_from query string false "Production deadline on or after this day (YYYY-MM-DD or RFC 3339)"
+// @Param deadline_to query string false "Production deadline on or before this day (YYYY-MM-DD or RFC 3339)"
+// @Param acknowledged query bool false "Filter by whether the order was acknowledged"
+// @Param min_quantity query int false "Minimum ordered (target) quantity"
+// @Param max_quantity query int false "Maximum ordered (target) quantity"
+// @Param sort_by query string false "Sort by work_order_number, production_deadline, created_at, target_quantity, quantity or status (default: WORK_ORDER_SORT_BY)"
+// @Param order query string false "Sort order asc or desc (default: WORK_ORDER_SORT_ORDER)"
+// @Param fields query string false "Comma separated work order fields to return, e.g. id,work_order_number,status (default: all)"
+// @Param tags query string false "Comma separated tag names, e.g. rework,customer-x"
+// @Param tags_match query string false "Match orders with any (default) or all of the tags"
 // @Success 200 {object} WorkOrderListResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Router /work-orders/assigned [get]
 func GetAssignedWorkOrders(c *fiber.Ctx) error {
 	// Get user ID from context
-	userID := c.Locals("user_id").(uint)
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
 
 	// Get query parameters
-	status := c.Query("status")
 	page := c.QueryInt("page", 1)
-	limit := c.QueryInt("limit", 10)
-	search := c.Query("search") // search by work_orders.work_order_number, work_orders.product_name
-	deadline := c.Query("deadline") // filter by work_orders.production_deadline
+	limit := pageLimit(c)
+
+	// Filter by status, search, deadline, quantity, acknowledgement and tags
+	filter, err := parseWorkOrderListFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Sort by sort_by and order
+	orderBy, err := parseWorkOrderSort(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Trim the work orders to the selected fields if requested
+	fields, err := parseWorkOrderFields(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
 
 	// Calculate offset
 	offset := (page - 1) * limit
 
 	// Build query - Perbaikan: gunakan Where setelah Model
-	query := database.DB.Model(&models.WorkOrder{}).
-		Preload("Operator").
+	query := preloadUnscoped(getDB(c).Model(&models.WorkOrder{}), "Operator").Preload("Tags").
 		Where("operator_id = ?", userID) // Hanya sekali filter operator_id
+	query = applyWorkOrderListFilter(query, filter)
 
-	// Apply status filter if provided
-	if status != "" {
-		query = query.Where("status = ?", status)
+	// Get total count
+	var count int64
+	query.Count(&count)
+
+	// Get work orders with pagination
+	var workOrders []models.WorkOrder
+	result := query.Offset(offset).Limit(limit).Order(orderBy).Find(&workOrders)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work orders",
+		})
 	}
 
-	// Apply search if provided
-	if search != "" {
-		// search with UPPERCASE
-		search = strings.ToUpper(search)
-		if strings.HasPrefix(search, "WO-") {
-			query = query.Where("UPPER(work_order_number) LIKE ?", "%"+search+"%")
-		} else {
-			query = query.Where("UPPER(product_name) LIKE ?", "%"+search+"%")
-		}
+	// Return work orders with pagination info
+	return sendWorkOrderFields(c, fiber.StatusOK, WorkOrderListResponse{
+		Error:      false,
+		WorkOrders: toWorkOrderDTOs(workOrders),
+		Pagination: Pagination{
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
+		},
+		Matches: searchMatches(workOrders, filter.Search),
+	}, "work_orders", fields)
+}
+
+// @Summary Get work order inbox
+// @Description Get the work orders the current user should look at first. Operators get their pending and in-progress orders by deadline, Production Managers get open orders with unacknowledged or overdue ones first.
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Param status query string false "Filter by status (pending/in_progress/on_hold/completed)"
+// @Param search query string false "Search by work order number (WO- prefix) or product name"
+// @Param deadline query string false "Production deadline on this day (YYYY-MM-DD or RFC 3339)"
+// @Param deadline_from query string false "Production deadline on or after this day (YYYY-MM-DD or RFC 3339)"
+// @Param deadline_to query string false "Production deadline on or before this day (YYYY-MM-DD or RFC 3339)"
+// @Param fields query string false "Comma separated work order fields to return, e.g. id,work_order_number,status (default: all)"
+// @Success 200 {object} WorkOrderListResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /work-orders/inbox [get]
+func GetWorkOrderInbox(c *fiber.Ctx) error {
+	// Get user ID and role from context
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
 	}
 
-	// Apply deadline filter if provided
-	if deadline != "" {
-		// Assume deadline is in YYYY-MM-DD format
-		query = query.Where("DATE(production_deadline) = ?", deadline)
+	// Get query parameters
+	status := c.Query("status")
+	page := c.QueryInt("page", 1)
+	limit := pageLimit(c)
+	search := c.Query("search")
+	deadline, err := parseDeadlineRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Trim the work orders to the selected fields if requested
+	fields, err := parseWorkOrderFields(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Build query
+	query := preloadUnscoped(getDB(c).Model(&models.WorkOrder{}), "Operator").Preload("Tags")
+	query = applyWorkOrderFilters(query, status, search, deadline)
+
+	var order clause.Expression
+	switch role {
+	case models.RoleOperator:
+		// Operators work on their own open orders, the most urgent deadline first
+		query = query.Where("operator_id = ? AND status IN ?", userID,
+			[]models.WorkOrderStatus{models.StatusPending, models.StatusInProgress})
+		order = clause.Expr{SQL: "production_deadline, id", WithoutParentheses: true}
+	case models.RoleProductionManager:
+		// Managers chase open orders that nobody acknowledged yet or that are overdue
+		query = query.Where("status <> ?", models.StatusCompleted)
+		order = clause.Expr{
+			SQL:                "CASE WHEN acknowledged_at IS NULL OR (production_deadline < ? AND status IN (?)) THEN 0 ELSE 1 END, production_deadline, id",
+			Vars:               []interface{}{time.Now(), models.OverdueStatuses},
+			WithoutParentheses: true,
+		}
+	default:
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Unauthorized to view the work order inbox",
+		})
 	}
 
 	// Get total count
@@ -329,7 +728,7 @@ func GetAssignedWorkOrders(c *fiber.Ctx) error {
 
 	// Get work orders with pagination
 	var workOrders []models.WorkOrder
-	result := query.Offset(offset).Limit(limit).Order("work_order_number DESC").Find(&workOrders)
+	result := query.Clauses(clause.OrderBy{Expression: order}).Offset(offset).Limit(limit).Find(&workOrders)
 	if result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
@@ -338,86 +737,83 @@ func GetAssignedWorkOrders(c *fiber.Ctx) error {
 	}
 
 	// Return work orders with pagination info
-	return c.Status(fiber.StatusOK).JSON(WorkOrderListResponse{
+	return sendWorkOrderFields(c, fiber.StatusOK, WorkOrderListResponse{
 		Error:      false,
-		WorkOrders: workOrders,
+		WorkOrders: toWorkOrderDTOs(workOrders),
 		Pagination: Pagination{
-			Total:  count,
-			Page:   page,
-			Limit:  limit,
-			Pages:  (count + int64(limit) - 1) / int64(limit),
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
 		},
-	})
+		Matches: searchMatches(workOrders, search),
+	}, "work_orders", fields)
 }
 
-// @Summary Get work order by ID
-// @Description Get a work order by its ID
+// @Summary Get next work order
+// @Description Get the pending work order the current operator should start next: the highest priority first, then the earliest production deadline, so overdue orders come before the others of their priority, then the oldest order. Answers 204 without a body when the operator has no pending orders. (Operator only)
 // @Tags work-orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param id path int true "Work order ID"
-// @Success 200 {object} WorkOrderResponse
+// @Success 200 {object} NextWorkOrderResponse
+// @Success 204 "No pending work orders"
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Router /work-orders/{id} [get]
-func GetWorkOrderByID(c *fiber.Ctx) error {
-	// Get work order ID from URL
-	id := c.Params("id")
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/next [get]
+func GetNextWorkOrder(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	if !hasUser {
+		return unauthorizedError(c)
+	}
 
-	// Get work order from database
-	var workOrder models.WorkOrder
-	result := database.DB.Preload("Operator").First(&workOrder, id)
+	// The highest priority comes first, then the earliest deadline so overdue orders lead
+	var workOrders []models.WorkOrder
+	result := preloadUnscoped(getDB(c).Model(&models.WorkOrder{}), "Operator").Preload("Tags").
+		Where("operator_id = ? AND status = ?", userID, models.StatusPending).
+		Order("priority DESC, production_deadline ASC, created_at ASC, id ASC").
+		Limit(1).
+		Find(&workOrders)
 	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-				Error: true,
-				Msg:   "Work order not found",
-			})
-		}
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
-			Msg:   "Error fetching work order",
+			Msg:   "Error fetching work orders",
 		})
 	}
+	if len(workOrders) == 0 {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
 
-	// Return work order
-	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
+	return c.Status(fiber.StatusOK).JSON(NextWorkOrderResponse{
 		Error:     false,
-		WorkOrder: workOrder,
+		WorkOrder: toWorkOrderDTO(workOrders[0]),
+		Overdue:   workOrders[0].ProductionDeadline.Before(time.Now()),
 	})
 }
 
-// @Summary Update work order
-// @Description Update a work order (Production Manager only)
+// @Summary Batch get work orders
+// @Description Get the work orders matching a list of IDs, at most 100 per request. Operators only get work orders assigned to them.
 // @Tags work-orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param id path int true "Work order ID"
-// @Param request body UpdateWorkOrderRequest true "Work order update details"
-// @Success 200 {object} WorkOrderResponse
+// @Param request body BatchGetWorkOrdersRequest true "Work order IDs"
+// @Success 200 {object} BatchGetWorkOrdersResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
-// @Failure 403 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
-// @Router /work-orders/{id} [put]
-func UpdateWorkOrder(c *fiber.Ctx) error {
-	// Only Production Manager can update work orders
-	role := c.Locals("role").(models.Role)
-	if role != models.RoleProductionManager {
-		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
-			Error: true,
-			Msg:   "Only Production Manager can update work orders",
-		})
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/batch-get [post]
+func BatchGetWorkOrders(c *fiber.Ctx) error {
+	// Get user ID and role from context
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
 	}
 
-	// Get work order ID from URL
-	id := c.Params("id")
-
 	// Parse request body
-	var req UpdateWorkOrderRequest
+	var req BatchGetWorkOrdersRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Error: true,
@@ -425,122 +821,152 @@ func UpdateWorkOrder(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get work order from database
-	var oldWorkOrder models.WorkOrder
-	result := database.DB.First(&oldWorkOrder, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-				Error: true,
-				Msg:   "Work order not found",
-			})
+	// Drop duplicate IDs, keeping the requested order
+	ids := make([]uint, 0, len(req.IDs))
+	seen := make(map[uint]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+	}
+	if len(ids) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Error: true,
-			Msg:   "Error fetching work order",
+			Msg:   "At least one work order ID is required",
+		})
+	}
+	if len(ids) > maxBatchGetIDs {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   fmt.Sprintf("At most %d work order IDs can be requested at once", maxBatchGetIDs),
 		})
 	}
 
-	// Buat salinan untuk audit log
-	workOrder := oldWorkOrder
-
-	// Update work order fields if provided
-	if req.ProductName != "" {
-		workOrder.ProductName = req.ProductName
-	}
-	if req.Quantity > 0 {
-		workOrder.Quantity = req.Quantity
-	}
-	if req.TargetQuantity > 0 {
-		workOrder.TargetQuantity = req.TargetQuantity
-	}
-	if !req.ProductionDeadline.IsZero() {
-		workOrder.ProductionDeadline = req.ProductionDeadline
-	}
-	if req.Status != "" {
-		workOrder.Status = req.Status
-	}
-	if req.OperatorID != 0 {
-		workOrder.OperatorID = req.OperatorID
+	// Operators only see the work orders assigned to them
+	query := preloadUnscoped(getDB(c), "Operator").Preload("Tags").Where("id IN ?", ids)
+	if role == models.RoleOperator {
+		query = query.Where("operator_id = ?", userID)
 	}
 
-	// Save work order to database
-	if err := database.DB.Save(&workOrder).Error; err != nil {
+	var workOrders []models.WorkOrder
+	if err := query.Find(&workOrders).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
-			Msg:   "Error updating work order",
+			Msg:   "Error fetching work orders",
 		})
 	}
 
-	// Create audit log after successful update
-	userID := c.Locals("user_id").(uint)
-	if err := auditService.CreateLog(
-		userID,
-		models.ActionUpdate,
-		"WorkOrder",
-		workOrder.ID,
-		oldWorkOrder,  // old values
-		workOrder,     // new values
-		fmt.Sprintf("Work order %s updated", workOrder.WorkOrderNumber),
-	); err != nil {
-		log.Printf("Error creating audit log: %v", err)
+	found := make(map[uint]models.WorkOrder, len(workOrders))
+	for _, workOrder := range workOrders {
+		found[workOrder.ID] = workOrder
 	}
 
-	// Return updated work order
-	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
-		Error:     false,
-		WorkOrder: workOrder,
-	})
+	response := BatchGetWorkOrdersResponse{
+		Error:      false,
+		WorkOrders: make([]WorkOrderDTO, 0, len(workOrders)),
+		MissingIDs: []uint{},
+	}
+	for _, id := range ids {
+		if workOrder, ok := found[id]; ok {
+			response.WorkOrders = append(response.WorkOrders, toWorkOrderDTO(workOrder))
+		} else {
+			response.MissingIDs = append(response.MissingIDs, id)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
 }
 
-// @Summary Update work order status
-// @Description Update a work order status (Operator only)
+// @Summary Get work order by ID
+// @Description Get a work order by its ID
 // @Tags work-orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Work order ID"
-// @Param request body UpdateWorkOrderStatusRequest true "Status update details"
+// @Param fields query string false "Comma separated work order fields to return, e.g. id,work_order_number,status (default: all)"
 // @Success 200 {object} WorkOrderResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /work-orders/{id}/status [put]
-func UpdateWorkOrderStatus(c *fiber.Ctx) error {
-	// Get user ID and role from context
-	userID := c.Locals("user_id").(uint)
-	role := c.Locals("role").(models.Role)
+// @Router /work-orders/{id} [get]
+func GetWorkOrderByID(c *fiber.Ctx) error {
+	// Get work order ID from URL
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
 
-	// Tambahkan validasi role
-	if role == models.RoleOperator {
-		// Check if user is the assigned operator
-		var workOrder models.WorkOrder
-		if err := database.DB.First(&workOrder, c.Params("id")).Error; err != nil {
+	// Trim the work order to the selected fields if requested
+	fields, err := parseWorkOrderFields(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Get work order from database
+	var workOrder models.WorkOrder
+	result := preloadUnscoped(getDB(c), "Operator").Preload("Children").Preload("Tags").First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
 				Error: true,
 				Msg:   "Work order not found",
 			})
 		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
 
-		if workOrder.OperatorID != userID {
-			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
-				Error: true,
-				Msg:   "You are not assigned to this work order",
-			})
-		}
-	} else if role != models.RoleProductionManager {
+	// Return work order
+	return sendWorkOrderFields(c, fiber.StatusOK, WorkOrderResponse{
+		Error:     false,
+		WorkOrder: toWorkOrderDTO(workOrder),
+	}, "work_order", fields)
+}
+
+// @Summary Update work order
+// @Description Update a work order (Production Manager only). The target quantity can not go below the produced quantity. Changing the target of an order with logged progress is answered with a warning and the produced quantity, or rejected with 409 when BLOCK_TARGET_CHANGE_WITH_PROGRESS is set. A status change needs the same details as through the status endpoint. A changed production deadline must fall within DEADLINE_MIN_LEAD_HOURS and DEADLINE_MAX_HORIZON_DAYS unless allow_deadline_outside_window is set, which is audit logged.
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param request body UpdateWorkOrderRequest true "Work order update details"
+// @Success 200 {object} UpdateWorkOrderResponse
+// @Failure 400 {object} TargetChangeErrorResponse "Invalid request or target below the produced quantity"
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} TargetChangeErrorResponse "Target change blocked by BLOCK_TARGET_CHANGE_WITH_PROGRESS, or the work order completed or changed concurrently"
+// @Router /work-orders/{id} [put]
+func UpdateWorkOrder(c *fiber.Ctx) error {
+	// Only Production Manager can update work orders
+	role, ok := getRole(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+	if role != models.RoleProductionManager {
 		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Error: true,
-			Msg:   "Unauthorized to update work order status",
+			Msg:   "Only Production Manager can update work orders",
 		})
 	}
 
 	// Get work order ID from URL
-	id := c.Params("id")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
 
 	// Parse request body
-	var req UpdateWorkOrderStatusRequest
+	var req UpdateWorkOrderRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Error: true,
@@ -550,7 +976,7 @@ func UpdateWorkOrderStatus(c *fiber.Ctx) error {
 
 	// Get work order from database
 	var oldWorkOrder models.WorkOrder
-	result := database.DB.First(&oldWorkOrder, id)
+	result := getDB(c).First(&oldWorkOrder, id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
@@ -564,103 +990,243 @@ func UpdateWorkOrderStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	// Buat salinan untuk update
-	workOrder := oldWorkOrder
+	if req.Priority != nil && (*req.Priority < 0 || *req.Priority > models.MaxPriority) {
+		return priorityError(c)
+	}
 
-	// Update work order status
-	workOrder.Status = req.Status
-	if req.Quantity > 0 {
-		workOrder.Quantity = req.Quantity
+	// A changed deadline must respect the minimum lead time and the maximum horizon unless overridden
+	window := deadlineWindow(time.Now())
+	deadlineOverride := false
+	if !req.ProductionDeadline.IsZero() && !req.ProductionDeadline.Equal(oldWorkOrder.ProductionDeadline) &&
+		!window.contains(req.ProductionDeadline) {
+		if !req.AllowDeadlineOutsideWindow {
+			return deadlineWindowError(c, window)
+		}
+		deadlineOverride = true
 	}
 
-	// Save work order to database
-	if err := database.DB.Save(&workOrder).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error: true,
-			Msg:   "Error updating work order status",
-		})
+	// Check the new operator exists and their active order limit when reassigning
+	reassignOverride := false
+	var reassignActiveOrders int64
+	var newOperator models.User
+	if req.OperatorID != 0 && req.OperatorID != oldWorkOrder.OperatorID {
+		operator, err := findAssignableOperator(getDB(c), req.OperatorID)
+		if err != nil {
+			return operatorNotFoundError(c, err)
+		}
+		newOperator = operator
+
+		activeOrders, atCapacity, err := operatorAtCapacity(getDB(c), req.OperatorID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error checking operator active orders",
+			})
+		}
+		if atCapacity && !req.Force {
+			return capacityError(c, activeOrders)
+		}
+		reassignOverride = atCapacity
+		reassignActiveOrders = activeOrders
 	}
 
+	// Buat salinan untuk audit log
+	workOrder := oldWorkOrder
 
-	workOrderProgress := models.WorkOrderProgress{
-		WorkOrderID: workOrder.ID,
-		ProgressDesc: req.Description,
-		ProgressQuantity: req.Quantity,
+	// Update work order fields if provided
+	if req.ProductName != "" {
+		workOrder.ProductName = req.ProductName
 	}
-	if err := database.DB.Create(&workOrderProgress).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error: true,
-			Msg:   "Error creating work order progress",
-		})
+	if req.TargetQuantity > 0 {
+		workOrder.TargetQuantity = req.TargetQuantity
+	}
+	if unit := strings.TrimSpace(req.Unit); unit != "" {
+		workOrder.Unit = unit
+	}
+	if !req.ProductionDeadline.IsZero() {
+		workOrder.ProductionDeadline = req.ProductionDeadline
+	}
+	if req.Priority != nil {
+		workOrder.Priority = *req.Priority
+	}
+	if req.OperatorID != 0 {
+		if req.OperatorID != workOrder.OperatorID {
+			// The new assignee has to acknowledge the order again
+			workOrder.AcknowledgedAt = nil
+		}
+		workOrder.OperatorID = req.OperatorID
 	}
 
+	// A target changed mid-run may invalidate the progress reported against the old one
+	var targetChange *TargetChange
+	if workOrder.TargetQuantity != oldWorkOrder.TargetQuantity {
+		var progressEntries int64
+		if err := getDB(c).Model(&models.WorkOrderProgress{}).Where("work_order_id = ?", oldWorkOrder.ID).Count(&progressEntries).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error checking work order progress",
+			})
+		}
+		change := TargetChange{
+			OldTarget: oldWorkOrder.TargetQuantity,
+			NewTarget: workOrder.TargetQuantity,
+			Produced:  workOrder.Quantity,
+			Progress:  progressEntries,
+		}
+		if change.NewTarget < change.Produced {
+			return c.Status(fiber.StatusBadRequest).JSON(TargetChangeErrorResponse{
+				Error:        true,
+				Msg:          fmt.Sprintf("Target quantity can not go below the %d already produced", change.Produced),
+				Code:         CodeTargetBelowProduced,
+				TargetChange: change,
+			})
+		}
+		if progressEntries > 0 {
+			if config.AppConfig.BlockTargetChangeWithProgress {
+				return c.Status(fiber.StatusConflict).JSON(TargetChangeErrorResponse{
+					Error:        true,
+					Msg:          fmt.Sprintf("Target quantity can not be changed, %d progress entries were logged against it", progressEntries),
+					Code:         CodeTargetChangeBlocked,
+					TargetChange: change,
+				})
+			}
+			targetChange = &change
+		}
+	}
 
+	// Only write the edited columns: the produced quantity is changed by progress
+	// entries and status updates alone, so a concurrent increment is never overwritten
+	columns := []string{"product_name", "target_quantity", "unit", "production_deadline", "priority", "operator_id", "acknowledged_at"}
+	change := statusChange{old: oldWorkOrder}
+	if req.Status != "" && req.Status != oldWorkOrder.Status {
+		// A status change needs the same details as through the status endpoint
+		var ok bool
+		var err error
+		if change, ok, err = prepareStatusChange(c, oldWorkOrder, &workOrder, req.Status, role, req.Force, req.StatusChangeDetails); !ok {
+			return err
+		}
+	}
+	// Every edit is written under the status it was read with, so an edit racing a
+	// completion can neither complete the order twice nor reopen it with a stale status
+	if ok, err := saveStatusChange(c, change, &workOrder, columns...); !ok {
+		return err
+	}
+	statusNote := ""
+	if workOrder.Status != oldWorkOrder.Status {
+		statusNote = statusChangeNote(workOrder.WorkOrderNumber, oldWorkOrder.Status, workOrder.Status, change.note)
+	}
 
 	// Create audit log after successful update
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+	note := fmt.Sprintf("Work order %s updated", workOrder.WorkOrderNumber)
+	if targetChange != nil {
+		note = fmt.Sprintf("%s: target quantity changed from %d to %d with %d already produced in %d progress entries",
+			note, targetChange.OldTarget, targetChange.NewTarget, targetChange.Produced, targetChange.Progress)
+	}
+	if statusNote != "" {
+		note = fmt.Sprintf("%s; %s", note, statusNote)
+	}
 	if err := auditService.CreateLog(
+		getDB(c),
 		userID,
 		models.ActionUpdate,
 		"WorkOrder",
 		workOrder.ID,
-		oldWorkOrder,  // old values
-		workOrder,     // new values
-		fmt.Sprintf("Work order %s status updated from %s to %s",
-			workOrder.WorkOrderNumber,
-			oldWorkOrder.Status,
-			workOrder.Status),
+		oldWorkOrder, // old values
+		workOrder,    // new values
+		note,
 	); err != nil {
 		log.Printf("Error creating audit log: %v", err)
 	}
 
-	// audit log work order progress
-	if err := auditService.CreateLog(
-		userID,
-		models.ActionCreate,
-		"WorkOrderProgress",
-		workOrderProgress.ID,
-		nil,
-		workOrderProgress,
-		fmt.Sprintf("Work order %s progress created", workOrder.WorkOrderNumber),
-	); err != nil {
-		log.Printf("Error creating audit log: %v", err)
+	if workOrder.OperatorID != oldWorkOrder.OperatorID {
+		if err := auditService.CreateLog(
+			getDB(c),
+			userID,
+			models.ActionUpdate,
+			"WorkOrder",
+			workOrder.ID,
+			map[string]uint{"operator_id": oldWorkOrder.OperatorID},
+			map[string]uint{"operator_id": workOrder.OperatorID},
+			fmt.Sprintf("Work order %s reassigned from operator #%d to %s (#%d)",
+				workOrder.WorkOrderNumber, oldWorkOrder.OperatorID, newOperator.Username, newOperator.ID),
+		); err != nil {
+			log.Printf("Error creating audit log: %v", err)
+		}
+	}
+
+	if reassignOverride {
+		logCapacityOverride(getDB(c), userID, workOrder, fmt.Sprintf("#%d", workOrder.OperatorID), reassignActiveOrders)
+	}
+	if deadlineOverride {
+		logDeadlineOverride(getDB(c), userID, workOrder, window)
 	}
 
+	if err := publishEvent(c, events.WorkOrderUpdated, workOrder, ""); err != nil {
+		return eventError(c, err)
+	}
+	if workOrder.OperatorID != oldWorkOrder.OperatorID {
+		if err := publishEvent(c, events.WorkOrderReassigned, workOrder, ""); err != nil {
+			return eventError(c, err)
+		}
+	}
 
 	// Return updated work order
-	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
-		Error:     false,
-		WorkOrder: workOrder,
-	})
+	response := UpdateWorkOrderResponse{
+		Error:        false,
+		WorkOrder:    toWorkOrderDTO(workOrder),
+		TargetChange: targetChange,
+	}
+	if targetChange != nil {
+		response.Warning = fmt.Sprintf("Target quantity changed from %d to %d after %d of %d were produced",
+			targetChange.OldTarget, targetChange.NewTarget, targetChange.Produced, targetChange.OldTarget)
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
 }
 
-// @Summary Delete work order
-// @Description Delete a work order (Production Manager only)
+// @Summary Update work order status
+// @Description Update a work order status and produced quantity (assigned Operator, or Production Manager with a reason). Operators setting order fields such as target_quantity or product_name get 403.
 // @Tags work-orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Work order ID"
+// @Param request body UpdateWorkOrderStatusRequest true "Status update details"
 // @Success 200 {object} WorkOrderResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /work-orders/{id} [delete]
-func DeleteWorkOrder(c *fiber.Ctx) error {
-	// Only Production Manager can delete work orders
-	role := c.Locals("role").(models.Role)
-	if role != models.RoleProductionManager {
+// @Failure 409 {object} AlreadyCompletedErrorResponse "Work order already completed or changed concurrently"
+// @Router /work-orders/{id}/status [put]
+func UpdateWorkOrderStatus(c *fiber.Ctx) error {
+	// Get user ID and role from context
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	// Only operators and production managers can update a status
+	if role != models.RoleOperator && role != models.RoleProductionManager {
 		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Error: true,
-			Msg:   "Only Production Manager can delete work orders",
+			Msg:   "Unauthorized to update work order status",
 		})
 	}
 
 	// Get work order ID from URL
-	id := c.Params("id")
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
 
 	// Get work order from database
-	var workOrder models.WorkOrder
-	result := database.DB.First(&workOrder, id)
+	var oldWorkOrder models.WorkOrder
+	result := getDB(c).First(&oldWorkOrder, id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
@@ -674,62 +1240,16 @@ func DeleteWorkOrder(c *fiber.Ctx) error {
 		})
 	}
 
-	// Delete work order from database
-	if err := database.DB.Delete(&workOrder).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error: true,
-			Msg:   "Error deleting work order",
-		})
-	}
-
-	// Create audit log after successful delete
-	userID := c.Locals("user_id").(uint)
-	if err := auditService.CreateLog(
-		userID,
-		models.ActionDelete,
-		"WorkOrder",
-		workOrder.ID,
-		workOrder,  // capture state before deletion
-		nil,        // no new values for deletion
-		fmt.Sprintf("Work order %s deleted", workOrder.WorkOrderNumber),
-	); err != nil {
-		log.Printf("Error creating audit log: %v", err)
-	}
-
-	// Return success response
-	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
-		Error:     false,
-		WorkOrder: workOrder,
-	})
-}
-
-func GetWorkOrderLogs(c *fiber.Ctx) error {
-	id := c.Params("id")
-
-	var logs []models.AuditLog
-	if err := database.DB.
-		Preload("User"). // Add preload for User
-		Where("entity_type = ? AND entity_id = ?", "WorkOrder", id).
-		Order("created_at DESC").
-		Find(&logs).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+	// Operators can only update work orders assigned to them
+	if role == models.RoleOperator && oldWorkOrder.OperatorID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 			Error: true,
-			Msg:   "Error fetching audit logs",
+			Msg:   "You are not assigned to this work order",
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"error": false,
-		"logs":  logs,
-	})
-}
-
-
-// CreateWorkOrderLog creates a custom log entry for a work order
-func CreateWorkOrderLog(c *fiber.Ctx) error {
-	id := c.Params("id")
-
-	var req CreateWorkOrderLogRequest
+	// Parse request body
+	var req UpdateWorkOrderStatusRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Error: true,
@@ -737,105 +1257,1385 @@ func CreateWorkOrderLog(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate required fields
-	if req.Note == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Error: true,
-			Msg:   "Note is required",
-		})
-	}
-
-	// Get work order from database
-	var workOrder models.WorkOrder
-	result := database.DB.First(&workOrder, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+	// Operators only report the status and produced quantity, the order itself belongs to the manager
+	if role == models.RoleOperator {
+		if field := managerOnlyField(c.Body()); field != "" {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
 				Error: true,
-				Msg:   "Work order not found",
+				Msg:   fmt.Sprintf("Operators cannot change %s of a work order", field),
 			})
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Error: true,
-			Msg:   "Error fetching work order",
-		})
 	}
 
-	// If status is provided, validate the transition
-	if req.Status != "" {
-		if !isValidStatusTransition(workOrder.Status, req.Status) {
-			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-				Error: true,
-				Msg:   "Invalid status transition",
-			})
-		}
-
-		// Create a copy of work order for new values
-		newWorkOrder := workOrder
-		newWorkOrder.Status = req.Status
+	// Buat salinan untuk update
+	workOrder := oldWorkOrder
+	if req.Quantity > 0 {
+		workOrder.Quantity = req.Quantity
+	}
+	change, ok, err := prepareStatusChange(c, oldWorkOrder, &workOrder, req.Status, role, req.Force, req.StatusChangeDetails)
+	if !ok {
+		return err
+	}
 
-		// Create audit log with status change
-		userID := c.Locals("user_id").(uint)
-		if err := auditService.CreateLog(
-			userID,
-			models.ActionCustom,
-			"WorkOrder",
-			workOrder.ID,
-			workOrder,     // old state
-			newWorkOrder,  // new state with updated status
-			req.Note,      // use provided note
-		); err != nil {
-			log.Printf("Error creating audit log: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+	// A manager changing the status on behalf of the operator has to justify it
+	overrideReason := ""
+	if role == models.RoleProductionManager && oldWorkOrder.OperatorID != userID && req.Status != oldWorkOrder.Status {
+		overrideReason = strings.TrimSpace(req.Reason)
+		if overrideReason == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 				Error: true,
-				Msg:   "Error creating work order log",
+				Msg:   "Reason is required when changing the status on behalf of the assigned operator",
 			})
 		}
+	}
+
+	// The produced quantity may not exceed the target quantity
+	if req.Quantity > oldWorkOrder.TargetQuantity {
+		return progressLimitError(c, oldWorkOrder.TargetQuantity-oldWorkOrder.Quantity)
+	}
+
+	// With ENFORCE_MONOTONIC_PRODUCED the produced quantity only goes up
+	if config.AppConfig.MonotonicProduced && req.Quantity > 0 && req.Quantity < oldWorkOrder.Quantity {
+		return progressQuantityError(c, fmt.Sprintf("Produced quantity can not go below the %d already produced", oldWorkOrder.Quantity),
+			oldWorkOrder.TargetQuantity-oldWorkOrder.Quantity)
+	}
+
+	// Record why the manager overrode the status
+	if overrideReason != "" {
+		if change.note != "" {
+			change.note += "; "
+		}
+		change.note += "Manager override: " + overrideReason
+	}
+
+	// Only write the produced quantity when reported so a concurrent
+	// progress increment is not overwritten with a stale value
+	var columns []string
+	if req.Quantity > 0 {
+		columns = append(columns, "quantity")
+	}
+	if ok, err := saveStatusChange(c, change, &workOrder, columns...); !ok {
+		return err
+	}
+
+	// Create audit log after successful update
+	if err := auditService.CreateLog(
+		getDB(c),
+		actorID(c),
+		models.ActionUpdate,
+		"WorkOrder",
+		workOrder.ID,
+		oldWorkOrder, // old values
+		workOrder,    // new values
+		statusChangeNote(workOrder.WorkOrderNumber, oldWorkOrder.Status, workOrder.Status, change.note),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
 
-		// Update work order status
-		workOrder.Status = req.Status
-		if err := database.DB.Save(&workOrder).Error; err != nil {
+	// Record the quantity produced since the last update as a progress entry
+	if workOrderProgress, ok := statusProgressEntry(workOrder, oldWorkOrder.Quantity, req.Description, userID); ok {
+		if err := getDB(c).Create(&workOrderProgress).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 				Error: true,
-				Msg:   "Error updating work order status",
+				Msg:   "Error creating work order progress",
 			})
 		}
-	} else {
-		// Create audit log without status change
-		userID := c.Locals("user_id").(uint)
+		refreshDailyCounter(getDB(c), workOrder.ProductName, workOrderProgress.CreatedAt)
+
 		if err := auditService.CreateLog(
-			userID,
-			models.ActionCustom,
-			"WorkOrder",
-			workOrder.ID,
-			nil,         // no old values
-			nil,         // no new values
-			req.Note,    // use provided note
+			getDB(c),
+			actorID(c),
+			models.ActionCreate,
+			"WorkOrderProgress",
+			workOrderProgress.ID,
+			nil,
+			workOrderProgress,
+			fmt.Sprintf("Work order %s progress created", workOrder.WorkOrderNumber),
 		); err != nil {
 			log.Printf("Error creating audit log: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+		}
+	}
+
+	// Return updated work order
+	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
+		Error:     false,
+		WorkOrder: toWorkOrderDTO(workOrder),
+	})
+}
+
+// @Summary Get work order status transitions
+// @Description Get the statuses the current user may move a work order to
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Success 200 {object} StatusTransitionsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /work-orders/{id}/transitions [get]
+func GetWorkOrderTransitions(c *fiber.Ctx) error {
+	// Get user ID and role from context
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	// Get work order ID from URL
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	// Get work order from database
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
 				Error: true,
-				Msg:   "Error creating work order log",
+				Msg:   "Work order not found",
 			})
 		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
 	}
 
-	// Return success response
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"error": false,
-		"message": "Work order log created successfully",
-		"work_order": workOrder,
+	// Operators can only change work orders assigned to them
+	transitions := []models.WorkOrderStatus{}
+	if role != models.RoleOperator || workOrder.OperatorID == userID {
+		transitions = models.AllowedTransitions(workOrder.Status, role)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(StatusTransitionsResponse{
+		Error:       false,
+		Status:      workOrder.Status,
+		Transitions: transitions,
+	})
+}
+
+// @Summary Acknowledge work order
+// @Description Confirm that the assigned operator has seen the work order (assigned Operator only)
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Success 200 {object} WorkOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /work-orders/{id}/acknowledge [post]
+func AcknowledgeWorkOrder(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	// Get work order from database
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	// Only the assigned operator can acknowledge
+	if workOrder.OperatorID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "You are not assigned to this work order",
+		})
+	}
+
+	// The acknowledgment is only recorded once
+	if workOrder.AcknowledgedAt != nil {
+		return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
+			Error:     false,
+			WorkOrder: toWorkOrderDTO(workOrder),
+		})
+	}
+
+	oldWorkOrder := workOrder
+	now := time.Now()
+	workOrder.AcknowledgedAt = &now
+
+	if err := getDB(c).Model(&workOrder).Update("acknowledged_at", now).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error acknowledging work order",
+		})
+	}
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		actorID(c),
+		models.ActionCustom,
+		"WorkOrder",
+		workOrder.ID,
+		oldWorkOrder,
+		workOrder,
+		fmt.Sprintf("Work order %s acknowledged", workOrder.WorkOrderNumber),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
+		Error:     false,
+		WorkOrder: toWorkOrderDTO(workOrder),
 	})
 }
 
-// Helper function to validate status transitions
-func isValidStatusTransition(from, to models.WorkOrderStatus) bool {
-	switch from {
-	case models.StatusPending:
-		return to == models.StatusInProgress
-	case models.StatusInProgress:
-		return to == models.StatusCompleted
+// @Summary Acknowledge all assigned work orders
+// @Description Acknowledge all unacknowledged, non-completed work orders assigned to an operator at once, e.g. at the start of a shift. Operators acknowledge their own orders, Production Managers name the operator with operator_id. The batch is recorded as one audit log entry.
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AcknowledgeAllRequest false "Operator to acknowledge for (Production Manager only)"
+// @Success 200 {object} AcknowledgeAllResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/acknowledge-all [post]
+func AcknowledgeAllWorkOrders(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	// The body is optional for operators
+	var req AcknowledgeAllRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Invalid request body",
+			})
+		}
+	}
+
+	operatorID := userID
+	switch role {
+	case models.RoleOperator:
+		if req.OperatorID != 0 && req.OperatorID != userID {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Operators can only acknowledge their own work orders",
+			})
+		}
+	case models.RoleProductionManager:
+		if req.OperatorID == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "operator_id is required",
+			})
+		}
+		var operator models.User
+		if err := getDB(c).Where("id = ? AND role = ?", req.OperatorID, models.RoleOperator).First(&operator).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+					Error: true,
+					Msg:   "Operator not found",
+				})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error fetching operator",
+			})
+		}
+		operatorID = operator.ID
 	default:
-		return false
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Unauthorized to acknowledge work orders",
+		})
+	}
+
+	// Lock the unacknowledged orders so a concurrent acknowledgment does not record them twice
+	var workOrders []models.WorkOrder
+	if err := getDB(c).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("operator_id = ? AND acknowledged_at IS NULL AND status <> ?", operatorID, models.StatusCompleted).
+		Order("id").
+		Find(&workOrders).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work orders",
+		})
+	}
+
+	response := AcknowledgeAllResponse{
+		Error:        false,
+		WorkOrderIDs: make([]uint, 0, len(workOrders)),
+	}
+	if len(workOrders) == 0 {
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+
+	numbers := make([]string, 0, len(workOrders))
+	for _, workOrder := range workOrders {
+		response.WorkOrderIDs = append(response.WorkOrderIDs, workOrder.ID)
+		numbers = append(numbers, workOrder.WorkOrderNumber)
+	}
+
+	now := time.Now()
+	if err := getDB(c).Model(&models.WorkOrder{}).
+		Where("id IN ?", response.WorkOrderIDs).
+		Update("acknowledged_at", now).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error acknowledging work orders",
+		})
+	}
+	response.Acknowledged = len(workOrders)
+
+	if err := auditService.CreateLog(
+		getDB(c),
+		actorID(c),
+		models.ActionCustom,
+		"WorkOrder",
+		0,
+		nil,
+		map[string]interface{}{"operator_id": operatorID, "work_order_ids": response.WorkOrderIDs, "acknowledged_at": now},
+		fmt.Sprintf("%d work orders of operator %d acknowledged: %s", len(workOrders), operatorID, strings.Join(numbers, ", ")),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// @Summary Delete work order
+// @Description Delete a work order (Production Manager only)
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Success 200 {object} WorkOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /work-orders/{id} [delete]
+func DeleteWorkOrder(c *fiber.Ctx) error {
+	// Only Production Manager can delete work orders
+	role, ok := getRole(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+	if role != models.RoleProductionManager {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Only Production Manager can delete work orders",
+		})
+	}
+
+	// Get work order ID from URL
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	// Get work order from database
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	// Delete work order from database
+	if err := getDB(c).Delete(&workOrder).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error deleting work order",
+		})
+	}
+
+	// Create audit log after successful delete
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+	if err := auditService.CreateLog(
+		getDB(c),
+		userID,
+		models.ActionDelete,
+		"WorkOrder",
+		workOrder.ID,
+		workOrder, // capture state before deletion
+		nil,       // no new values for deletion
+		fmt.Sprintf("Work order %s deleted", workOrder.WorkOrderNumber),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+
+	// Return success response
+	return c.Status(fiber.StatusOK).JSON(WorkOrderResponse{
+		Error:     false,
+		WorkOrder: toWorkOrderDTO(workOrder),
+	})
+}
+
+// @Summary Get work order logs
+// @Description Get a paginated list of the audit log entries of a work order, newest first. Operators only see the logs of work orders assigned to them.
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Success 200 {object} WorkOrderLogsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/logs [get]
+func GetWorkOrderLogs(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	// Check if user is the assigned operator or a production manager
+	if role == models.RoleOperator && workOrder.OperatorID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "You are not assigned to this work order",
+		})
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := pageLimit(c)
+	offset := (page - 1) * limit
+
+	// id breaks ties between rows created at the same time
+	query := preloadUnscoped(getDB(c).Model(&models.AuditLog{}), "User").
+		Where("entity_type = ? AND entity_id = ?", "WorkOrder", workOrder.ID).
+		Order("created_at DESC, id DESC")
+
+	var count int64
+	query.Count(&count)
+
+	var logs []models.AuditLog
+	if err := query.Offset(offset).Limit(limit).Find(&logs).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching audit logs",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(WorkOrderLogsResponse{
+		Error: false,
+		Logs:  toAuditLogDTOs(logs),
+		Pagination: Pagination{
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// CreateWorkOrderLog creates a custom log entry for a work order
+// @Summary Create work order log
+// @Description Add a note to the work order log, optionally changing its status with the same details as the status endpoint (assigned Operator or Production Manager)
+// @Tags work-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param request body CreateWorkOrderLogRequest true "Log note and optional status"
+// @Success 200 {object} WorkOrderLogResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} AlreadyCompletedErrorResponse "Work order completed or changed concurrently"
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/logs [post]
+func CreateWorkOrderLog(c *fiber.Ctx) error {
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	var req CreateWorkOrderLogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Invalid request body",
+		})
+	}
+
+	// Validate required fields
+	if req.Note == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Note is required",
+		})
+	}
+
+	// Get work order from database
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	// A status change goes through the same checks as through the status endpoint
+	if req.Status != "" {
+		// Operators can only change the status of work orders assigned to them
+		if role == models.RoleOperator && workOrder.OperatorID != userID {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "You are not assigned to this work order",
+			})
+		}
+		// Logging the current status again is no transition
+		if req.Status == workOrder.Status && req.Status != models.StatusCompleted {
+			return invalidTransitionError(c, workOrder.Status, req.Status)
+		}
+
+		oldWorkOrder := workOrder
+		details := req.StatusChangeDetails
+		if details.HoldReason == "" {
+			details.HoldReason = req.Note
+		}
+		change, ok, err := prepareStatusChange(c, oldWorkOrder, &workOrder, req.Status, role, false, details)
+		if !ok {
+			return err
+		}
+
+		// The note leads the status history, followed by the details of the change
+		if change.note != "" && change.note != req.Note {
+			change.note = req.Note + "; " + change.note
+		} else {
+			change.note = req.Note
+		}
+		if ok, err := saveStatusChange(c, change, &workOrder); !ok {
+			return err
+		}
+
+		// Create audit log with status change
+		if err := auditService.CreateLog(
+			getDB(c),
+			actorID(c),
+			models.ActionCustom,
+			"WorkOrder",
+			workOrder.ID,
+			oldWorkOrder, // old state
+			workOrder,    // new state with updated status
+			req.Note,     // use provided note
+		); err != nil {
+			log.Printf("Error creating audit log: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error creating work order log",
+			})
+		}
+	} else {
+		// Create audit log without status change
+		if err := auditService.CreateLog(
+			getDB(c),
+			actorID(c),
+			models.ActionCustom,
+			"WorkOrder",
+			workOrder.ID,
+			nil,      // no old values
+			nil,      // no new values
+			req.Note, // use provided note
+		); err != nil {
+			log.Printf("Error creating audit log: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error creating work order log",
+			})
+		}
+	}
+
+	// Return success response
+	return c.Status(fiber.StatusOK).JSON(WorkOrderLogResponse{
+		Error:     false,
+		Message:   "Work order log created successfully",
+		WorkOrder: toWorkOrderDTO(workOrder),
+	})
+}
+
+// managerOnlyWorkOrderFields are the work order fields only a Production Manager may change
+var managerOnlyWorkOrderFields = []string{"target_quantity", "unit", "product_name", "production_deadline", "priority", "operator_id", "plant_id", "parent_id"}
+
+// managerOnlyField returns the first manager-only work order field set in a JSON request body,
+// or an empty string if there is none or the body is not a JSON object
+func managerOnlyField(body []byte) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	for _, field := range managerOnlyWorkOrderFields {
+		if _, ok := fields[field]; ok {
+			return field
+		}
+	}
+	return ""
+}
+
+// applyWorkOrderFilters applies the status, search and deadline list filters shared by the work order lists
+func applyWorkOrderFilters(query *gorm.DB, status, search string, deadline deadlineRange) *gorm.DB {
+	// Apply status filter if provided
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Apply search if provided
+	if search != "" {
+		// search with UPPERCASE
+		search = strings.ToUpper(search)
+		if strings.HasPrefix(search, "WO-") {
+			query = query.Where("UPPER(work_order_number) LIKE ?", "%"+search+"%")
+		} else {
+			query = query.Where("UPPER(product_name) LIKE ?", "%"+search+"%")
+		}
+	}
+
+	// Apply deadline filter if provided
+	if !deadline.From.IsZero() {
+		query = query.Where("production_deadline >= ?", deadline.From)
+	}
+	if !deadline.To.IsZero() {
+		query = query.Where("production_deadline < ?", deadline.To)
+	}
+
+	return query
+}
+
+// workOrderListFilter holds the filters of the work order lists that are read from the query,
+// shared by GetWorkOrders and GetAssignedWorkOrders
+type workOrderListFilter struct {
+	Status       string
+	Search       string // work order number (WO- prefix) or product name
+	Deadline     deadlineRange
+	MinQuantity  int   // -1 when not set
+	MaxQuantity  int   // -1 when not set
+	Acknowledged *bool // nil when not set
+	Tags         []string
+	MatchAllTags bool
+}
+
+// parseWorkOrderListFilter reads the status, search, deadline, quantity, acknowledged and tag
+// query parameters of the work order lists
+func parseWorkOrderListFilter(c *fiber.Ctx) (workOrderListFilter, error) {
+	filter := workOrderListFilter{
+		Status: c.Query("status"),
+		Search: c.Query("search"),
+	}
+
+	var err error
+	if filter.Deadline, err = parseDeadlineRange(c); err != nil {
+		return workOrderListFilter{}, err
+	}
+	if filter.MinQuantity, filter.MaxQuantity, err = parseQuantityRange(c); err != nil {
+		return workOrderListFilter{}, err
+	}
+	if filter.Tags, filter.MatchAllTags, err = parseTagFilter(c); err != nil {
+		return workOrderListFilter{}, err
+	}
+
+	if acknowledged := c.Query("acknowledged"); acknowledged != "" {
+		isAcknowledged, err := strconv.ParseBool(acknowledged)
+		if err != nil {
+			return workOrderListFilter{}, fmt.Errorf("Invalid acknowledged filter, expected true or false")
+		}
+		filter.Acknowledged = &isAcknowledged
+	}
+
+	return filter, nil
+}
+
+// applyWorkOrderListFilter applies the filters read by parseWorkOrderListFilter
+func applyWorkOrderListFilter(query *gorm.DB, filter workOrderListFilter) *gorm.DB {
+	query = applyWorkOrderFilters(query, filter.Status, filter.Search, filter.Deadline)
+	query = applyQuantityRange(query, filter.MinQuantity, filter.MaxQuantity)
+
+	// Filter on whether the assigned operator acknowledged the work order
+	if filter.Acknowledged != nil {
+		if *filter.Acknowledged {
+			query = query.Where("acknowledged_at IS NOT NULL")
+		} else {
+			query = query.Where("acknowledged_at IS NULL")
+		}
+	}
+
+	return applyTagFilter(query, filter.Tags, filter.MatchAllTags)
+}
+
+// deadlineRange is a production deadline filter from From up to but excluding To, zero bounds are open
+type deadlineRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// parseDeadlineRange reads the optional deadline, deadline_from and deadline_to query parameters.
+// Each is a YYYY-MM-DD date or an RFC 3339 timestamp normalized to its date, and days are inclusive.
+// deadline matches a single day and cannot be combined with the range parameters.
+func parseDeadlineRange(c *fiber.Ctx) (deadlineRange, error) {
+	days := map[string]time.Time{}
+	for _, name := range []string{"deadline", "deadline_from", "deadline_to"} {
+		value := strings.TrimSpace(c.Query(name))
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			timestamp, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return deadlineRange{}, fmt.Errorf("%s must be a date in YYYY-MM-DD format", name)
+			}
+			day = time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		days[name] = day
+	}
+
+	if day, ok := days["deadline"]; ok {
+		if len(days) > 1 {
+			return deadlineRange{}, fmt.Errorf("deadline cannot be combined with deadline_from or deadline_to")
+		}
+		return deadlineRange{From: day, To: day.AddDate(0, 0, 1)}, nil
+	}
+
+	var deadline deadlineRange
+	if from, ok := days["deadline_from"]; ok {
+		deadline.From = from
+	}
+	if to, ok := days["deadline_to"]; ok {
+		// Add one day to include the end date
+		deadline.To = to.AddDate(0, 0, 1)
+	}
+	if !deadline.From.IsZero() && !deadline.To.IsZero() && !deadline.From.Before(deadline.To) {
+		return deadlineRange{}, fmt.Errorf("deadline_from must not be after deadline_to")
+	}
+	return deadline, nil
+}
+
+// parseQuantityRange reads the optional min_quantity and max_quantity query parameters.
+// A missing bound is returned as -1.
+func parseQuantityRange(c *fiber.Ctx) (int, int, error) {
+	bounds := []int{-1, -1}
+	for i, name := range []string{"min_quantity", "max_quantity"} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		quantity, err := strconv.Atoi(value)
+		if err != nil || quantity < 0 {
+			return 0, 0, fmt.Errorf("%s must be a non-negative integer", name)
+		}
+		bounds[i] = quantity
+	}
+
+	if bounds[0] >= 0 && bounds[1] >= 0 && bounds[0] > bounds[1] {
+		return 0, 0, fmt.Errorf("min_quantity must not be greater than max_quantity")
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// applyQuantityRange filters on the ordered quantity, skipping bounds that are -1
+func applyQuantityRange(query *gorm.DB, minQuantity, maxQuantity int) *gorm.DB {
+	if minQuantity >= 0 {
+		query = query.Where("target_quantity >= ?", minQuantity)
+	}
+	if maxQuantity >= 0 {
+		query = query.Where("target_quantity <= ?", maxQuantity)
+	}
+	return query
+}
+
+// workOrderSortColumns are the columns the work order lists can be sorted by
+var workOrderSortColumns = []string{"work_order_number", "production_deadline", "created_at", "target_quantity", "quantity", "status"}
+
+// parseWorkOrderSort reads the optional sort_by and order query parameters into an ORDER BY clause.
+// They default to WORK_ORDER_SORT_BY and WORK_ORDER_SORT_ORDER, the newest work order number first.
+// The unique work order number breaks ties of the other columns so pages never overlap.
+func parseWorkOrderSort(c *fiber.Ctx) (string, error) {
+	sortBy := c.Query("sort_by", config.AppConfig.WorkOrderSortBy)
+	order := strings.ToUpper(c.Query("order", config.AppConfig.WorkOrderSortOrder))
+	if order != "ASC" && order != "DESC" {
+		return "", fmt.Errorf("order must be asc or desc")
+	}
+
+	for _, column := range workOrderSortColumns {
+		if column != sortBy {
+			continue
+		}
+		if column == "work_order_number" {
+			return column + " " + order, nil
+		}
+		return column + " " + order + ", work_order_number DESC", nil
+	}
+	return "", fmt.Errorf("sort_by must be one of %s", strings.Join(workOrderSortColumns, ", "))
+}
+
+// priorityError responds to a priority outside 0..models.MaxPriority
+func priorityError(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+		Error: true,
+		Msg:   fmt.Sprintf("priority must be between 0 and %d", models.MaxPriority),
+	})
+}
+
+// invalidTransitionError responds to a status change that is not in models.StatusTransitions
+func invalidTransitionError(c *fiber.Ctx, from, to models.WorkOrderStatus) error {
+	return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+		Error: true,
+		Msg:   fmt.Sprintf("Invalid status transition from %s to %s", from, to),
+		Code:  CodeInvalidStatusTransition,
+	})
+}
+
+// alreadyCompletedError responds to completing a work order that is already completed
+func alreadyCompletedError(c *fiber.Ctx, finalQuantity int) error {
+	return c.Status(fiber.StatusConflict).JSON(AlreadyCompletedErrorResponse{
+		Error:         true,
+		Msg:           "Work order is already completed",
+		Code:          CodeAlreadyCompleted,
+		FinalQuantity: finalQuantity,
+	})
+}
+
+// statusConflictError responds to a status update that lost the race against a
+// concurrent one, reporting the final quantity when that one completed the order
+func statusConflictError(c *fiber.Ctx, id uint) error {
+	var current models.WorkOrder
+	if err := getDB(c).First(&current, id).Error; err == nil && current.Status == models.StatusCompleted {
+		return alreadyCompletedError(c, current.Quantity)
+	}
+	return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+		Error: true,
+		Msg:   "Work order status was changed by another request, please reload and retry",
+	})
+}
+
+// createStatusHistory records the current status of a work order in its status history
+func createStatusHistory(db *gorm.DB, workOrder models.WorkOrder, note string) error {
+	statusHistory := models.WorkOrderStatusHistory{
+		WorkOrderID: workOrder.ID,
+		Status:      workOrder.Status,
+		Quantity:    workOrder.Quantity,
+		Note:        note,
+	}
+	return db.Create(&statusHistory).Error
+}
+
+// statusProgressEntry returns the progress entry recording the quantity a status update
+// produced beyond oldQuantity, and false when the quantity did not go up
+func statusProgressEntry(workOrder models.WorkOrder, oldQuantity int, description string, reportedByID uint) (models.WorkOrderProgress, bool) {
+	produced := workOrder.Quantity - oldQuantity
+	if produced <= 0 {
+		return models.WorkOrderProgress{}, false
+	}
+	return models.WorkOrderProgress{
+		WorkOrderID:      workOrder.ID,
+		ProgressDesc:     description,
+		ProgressQuantity: produced,
+		ReportedByID:     &reportedByID,
+	}, true
+}
+
+// findAssignableOperator returns the operator work orders can be assigned to: an
+// existing, active user with the operator role. It returns gorm.ErrRecordNotFound otherwise.
+func findAssignableOperator(db *gorm.DB, operatorID uint) (models.User, error) {
+	var operator models.User
+	err := db.Where("id = ? AND role = ? AND active = ?", operatorID, models.RoleOperator, true).First(&operator).Error
+	return operator, err
+}
+
+// operatorNotFoundError responds to an assignment to a user that is not an active operator
+func operatorNotFoundError(c *fiber.Ctx, err error) error {
+	if err != gorm.ErrRecordNotFound {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching operator",
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+		Error: true,
+		Msg:   "Operator not found or not active",
+		Code:  CodeOperatorNotFound,
+	})
+}
+
+// operatorAtCapacity returns how many in-progress work orders the operator holds
+// and whether that reaches MAX_ACTIVE_ORDERS_PER_OPERATOR (0 means unlimited)
+func operatorAtCapacity(db *gorm.DB, operatorID uint) (int64, bool, error) {
+	limit := config.AppConfig.MaxActiveOrdersPerOperator
+	if limit <= 0 {
+		return 0, false, nil
+	}
+
+	var activeOrders int64
+	if err := db.Model(&models.WorkOrder{}).
+		Where("operator_id = ? AND status = ?", operatorID, models.StatusInProgress).
+		Count(&activeOrders).Error; err != nil {
+		return 0, false, err
+	}
+
+	return activeOrders, activeOrders >= int64(limit), nil
+}
+
+// capacityError responds with 409 and the operator's current active order count
+func capacityError(c *fiber.Ctx, activeOrders int64) error {
+	return c.Status(fiber.StatusConflict).JSON(CapacityErrorResponse{
+		Error:        true,
+		Msg:          "Operator has reached the maximum number of active work orders",
+		ActiveOrders: activeOrders,
+		Limit:        config.AppConfig.MaxActiveOrdersPerOperator,
+	})
+}
+
+// logCapacityOverride writes an audit log entry for a forced active order limit override
+func logCapacityOverride(db *gorm.DB, userID uint, workOrder models.WorkOrder, operator string, activeOrders int64) {
+	if err := auditService.CreateLog(
+		db,
+		userID,
+		models.ActionCustom,
+		"WorkOrder",
+		workOrder.ID,
+		nil,
+		nil,
+		fmt.Sprintf("Active order limit overridden for work order %s: operator %s holds %d of %d in-progress work orders",
+			workOrder.WorkOrderNumber, operator, activeOrders, config.AppConfig.MaxActiveOrdersPerOperator),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+}
+
+// findDuplicateActiveOrder returns the operator's oldest non-completed work order for the product,
+// or nil if there is none or PREVENT_DUPLICATE_ACTIVE_ORDERS is disabled
+func findDuplicateActiveOrder(db *gorm.DB, productName string, operatorID uint) (*models.WorkOrder, error) {
+	if !config.AppConfig.PreventDuplicateActiveOrders {
+		return nil, nil
+	}
+
+	var workOrders []models.WorkOrder
+	if err := db.Where("operator_id = ? AND status <> ? AND UPPER(product_name) = UPPER(?)", operatorID, models.StatusCompleted, strings.TrimSpace(productName)).
+		Order("created_at ASC, id ASC").
+		Limit(1).
+		Find(&workOrders).Error; err != nil {
+		return nil, err
+	}
+	if len(workOrders) == 0 {
+		return nil, nil
+	}
+	return &workOrders[0], nil
+}
+
+// logDuplicateOverride writes an audit log entry for a work order forced past the duplicate active order check
+func logDuplicateOverride(db *gorm.DB, userID uint, workOrder, duplicate models.WorkOrder, operator string) {
+	if err := auditService.CreateLog(
+		db,
+		userID,
+		models.ActionCustom,
+		"WorkOrder",
+		workOrder.ID,
+		nil,
+		nil,
+		fmt.Sprintf("Duplicate active order forced for work order %s: operator %s already has active work order %s for %s",
+			workOrder.WorkOrderNumber, operator, duplicate.WorkOrderNumber, duplicate.ProductName),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+}
+
+// deadlineWindow returns the production deadlines allowed at now by DEADLINE_MIN_LEAD_HOURS
+// and DEADLINE_MAX_HORIZON_DAYS. The earliest deadline absorbs the same clock skew as the
+// future deadline check.
+func deadlineWindow(now time.Time) DeadlineWindow {
+	var window DeadlineWindow
+	if hours := config.AppConfig.DeadlineMinLeadHours; hours > 0 {
+		earliest := now.Add(time.Duration(hours)*time.Hour - deadlineGracePeriod)
+		window.Earliest = &earliest
+	}
+	if days := config.AppConfig.DeadlineMaxHorizonDays; days > 0 {
+		latest := now.AddDate(0, 0, days)
+		window.Latest = &latest
+	}
+	return window
+}
+
+// contains reports whether the deadline falls in the window
+func (w DeadlineWindow) contains(deadline time.Time) bool {
+	if w.Earliest != nil && deadline.Before(*w.Earliest) {
+		return false
+	}
+	if w.Latest != nil && deadline.After(*w.Latest) {
+		return false
+	}
+	return true
+}
+
+// deadlineWindowError responds with 400 and the allowed deadline window
+func deadlineWindowError(c *fiber.Ctx, window DeadlineWindow) error {
+	msg := "Production deadline is outside the allowed window"
+	switch {
+	case window.Earliest != nil && window.Latest != nil:
+		msg = fmt.Sprintf("Production deadline must be between %s and %s", window.Earliest.Format(time.RFC3339), window.Latest.Format(time.RFC3339))
+	case window.Earliest != nil:
+		msg = fmt.Sprintf("Production deadline must be on or after %s", window.Earliest.Format(time.RFC3339))
+	case window.Latest != nil:
+		msg = fmt.Sprintf("Production deadline must be on or before %s", window.Latest.Format(time.RFC3339))
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(DeadlineWindowErrorResponse{
+		Error:  true,
+		Msg:    msg + ", set allow_deadline_outside_window to override",
+		Code:   CodeDeadlineOutsideWindow,
+		Window: window,
+	})
+}
+
+// logDeadlineOverride writes an audit log entry for a work order deadline accepted outside the allowed window
+func logDeadlineOverride(db *gorm.DB, userID uint, workOrder models.WorkOrder, window DeadlineWindow) {
+	if err := auditService.CreateLog(
+		db,
+		userID,
+		models.ActionCustom,
+		"WorkOrder",
+		workOrder.ID,
+		nil,
+		nil,
+		fmt.Sprintf("Deadline window overridden for work order %s: deadline %s is outside the allowed window",
+			workOrder.WorkOrderNumber, workOrder.ProductionDeadline.Format(time.RFC3339)),
+	); err != nil {
+		log.Printf("Error creating audit log: %v", err)
+	}
+}
+
+// createBackorder creates a pending follow-up work order for the unproduced quantity of parent
+func createBackorder(db *gorm.DB, parent models.WorkOrder, quantity int, deadline time.Time) (models.WorkOrder, error) {
+	backorder := models.WorkOrder{
+		WorkOrderNumber:    generateWorkOrderNumber(db),
+		ProductName:        parent.ProductName,
+		Quantity:           0,
+		TargetQuantity:     quantity,
+		Unit:               parent.Unit,
+		ProductionDeadline: deadline,
+		Status:             models.StatusPending,
+		OperatorID:         parent.OperatorID,
+		ParentID:           &parent.ID,
+		PlantID:            parent.PlantID,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&backorder).Error; err != nil {
+			return err
+		}
+		return createStatusHistory(tx, backorder, fmt.Sprintf("Backorder of %s", parent.WorkOrderNumber))
+	})
+	return backorder, err
+}
+
+// statusChangeNote builds the audit log note for a status change
+func statusChangeNote(workOrderNumber string, from, to models.WorkOrderStatus, reason string) string {
+	note := fmt.Sprintf("Work order %s status updated from %s to %s", workOrderNumber, from, to)
+	if reason != "" {
+		note = fmt.Sprintf("%s: %s", note, reason)
+	}
+	return note
+}
+
+// statusChange is a status change checked by prepareStatusChange, to be written by saveStatusChange
+type statusChange struct {
+	old               models.WorkOrder
+	note              string // recorded with the status history
+	shortfall         int    // unproduced quantity of a completion below the target
+	backorderDeadline time.Time
+	startOverride     bool  // a Production Manager forced the start past the active order limit
+	activeOrders      int64 // in-progress work orders of the operator when starting
+}
+
+// prepareStatusChange moves workOrder, a copy of old carrying the other edits of the request,
+// to status. It requires every progress entry approved to complete when progress_approval
+// is switched on, a hold reason for on_hold, room under the operator's active order limit
+// to start, which only a Production Manager may force, and for a completion below the target the
+// disposition of the remainder. When it is not ok the error response was sent and its result is returned.
+func prepareStatusChange(c *fiber.Ctx, old models.WorkOrder, workOrder *models.WorkOrder, status models.WorkOrderStatus, role models.Role, force bool, details StatusChangeDetails) (statusChange, bool, error) {
+	change := statusChange{old: old, backorderDeadline: old.ProductionDeadline}
+
+	// A work order is completed only once, repeating it must not overwrite the final quantity
+	if status == models.StatusCompleted && old.Status == models.StatusCompleted {
+		return change, false, alreadyCompletedError(c, old.Quantity)
+	}
+
+	// Status changes must follow the allowed transitions
+	if status != old.Status && !models.CanTransition(old.Status, status, role) {
+		return change, false, invalidTransitionError(c, old.Status, status)
+	}
+
+	// With progress approval switched on, every progress entry needs a manager's sign-off first
+	if status == models.StatusCompleted && features.Enabled(features.ProgressApproval) {
+		var unapproved int64
+		if err := getDB(c).Model(&models.WorkOrderProgress{}).
+			Where("work_order_id = ? AND approved_at IS NULL", old.ID).
+			Count(&unapproved).Error; err != nil {
+			return change, false, c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error checking progress approvals",
+			})
+		}
+		if unapproved > 0 {
+			return change, false, c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+				Error: true,
+				Msg:   fmt.Sprintf("%d progress entries are waiting for a manager's approval", unapproved),
+				Code:  CodeProgressNotApproved,
+			})
+		}
+	}
+
+	// Starting work counts against the operator's active order limit
+	if status == models.StatusInProgress && old.Status != models.StatusInProgress {
+		activeOrders, atCapacity, err := operatorAtCapacity(getDB(c), workOrder.OperatorID)
+		if err != nil {
+			return change, false, c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error checking operator active orders",
+			})
+		}
+		if atCapacity && !(force && role == models.RoleProductionManager) {
+			return change, false, capacityError(c, activeOrders)
+		}
+		change.startOverride = atCapacity
+		change.activeOrders = activeOrders
+	}
+
+	workOrder.Status = status
+	if status == models.StatusOnHold {
+		if old.Status != models.StatusOnHold && strings.TrimSpace(details.HoldReason) == "" {
+			return change, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Hold reason is required when putting a work order on hold",
+			})
+		}
+		if details.HoldReason != "" {
+			workOrder.HoldReason = details.HoldReason
+		}
+		change.note = workOrder.HoldReason
+	} else {
+		workOrder.HoldReason = ""
+	}
+
+	// Completing below the target requires deciding what happens to the remainder
+	if status != models.StatusCompleted || old.Status == models.StatusCompleted {
+		return change, true, nil
+	}
+	change.shortfall = workOrder.TargetQuantity - workOrder.Quantity
+	if change.shortfall <= 0 {
+		return change, true, nil
+	}
+	if details.RemainingDisposition != models.DispositionCancelled && details.RemainingDisposition != models.DispositionBackorder {
+		return change, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   fmt.Sprintf("Produced quantity is %d short of the target, remaining_disposition (cancelled/backorder) is required", change.shortfall),
+		})
+	}
+	workOrder.RemainingDisposition = details.RemainingDisposition
+	change.note = fmt.Sprintf("Completed %d of %d, remaining %d %s",
+		workOrder.Quantity, workOrder.TargetQuantity, change.shortfall, details.RemainingDisposition)
+	if details.BackorderDeadline != nil {
+		change.backorderDeadline = *details.BackorderDeadline
+	}
+
+	// A cancelled remainder needs a reason, materials already issued for it can be noted as restocked
+	if details.RemainingDisposition == models.DispositionCancelled {
+		reason := strings.TrimSpace(details.CancellationReason)
+		if reason == "" {
+			return change, false, c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "cancellation_reason is required when the remaining quantity is cancelled",
+			})
+		}
+		workOrder.CancellationReason = &reason
+		change.note += ": " + reason
+		if note := strings.TrimSpace(details.RestockNote); note != "" {
+			workOrder.RestockNote = &note
+			change.note += "; Restock: " + note
+		}
+	}
+	return change, true, nil
+}
+
+// saveStatusChange writes the status columns and the other columns of workOrder, but only while
+// it still has the status it was read with so of two concurrent completions only the first one
+// goes through. A changed status is recorded in the status history, and a backordered remainder
+// spawns its follow-up work order. When it is not ok the error response was sent and its result is returned.
+func saveStatusChange(c *fiber.Ctx, change statusChange, workOrder *models.WorkOrder, columns ...string) (bool, error) {
+	columns = append(columns, "status", "hold_reason", "remaining_disposition", "cancellation_reason", "restock_note")
+	result := getDB(c).Model(workOrder).Where("status = ?", change.old.Status).Select(columns).Updates(workOrder)
+	if result.Error != nil {
+		return false, c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error updating work order status",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return false, statusConflictError(c, workOrder.ID)
+	}
+
+	if workOrder.Status != change.old.Status {
+		if err := createStatusHistory(getDB(c), *workOrder, change.note); err != nil {
+			return false, c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error creating status history",
+			})
+		}
+		refreshDailyCounter(getDB(c), workOrder.ProductName, time.Now())
+		if err := publishEvent(c, events.StatusChanged, *workOrder, change.old.Status); err != nil {
+			return false, eventError(c, err)
+		}
+	}
+
+	// Spawn a follow-up work order for the backordered remainder
+	if change.shortfall > 0 && workOrder.RemainingDisposition == models.DispositionBackorder {
+		backorder, err := createBackorder(getDB(c), *workOrder, change.shortfall, change.backorderDeadline)
+		if err != nil {
+			return false, c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error creating backorder work order",
+			})
+		}
+		workOrder.Children = append(workOrder.Children, backorder)
+
+		if err := auditService.CreateLog(
+			getDB(c),
+			actorID(c),
+			models.ActionCreate,
+			"WorkOrder",
+			backorder.ID,
+			nil,
+			backorder,
+			fmt.Sprintf("Work order %s created as backorder of %s", backorder.WorkOrderNumber, workOrder.WorkOrderNumber),
+		); err != nil {
+			log.Printf("Error creating audit log: %v", err)
+		}
+	}
+
+	if change.startOverride {
+		logCapacityOverride(getDB(c), actorID(c), *workOrder, fmt.Sprintf("#%d", workOrder.OperatorID), change.activeOrders)
+	}
+	return true, nil
+}
+
+// searchMatches reports which field matched the search term for each work order and where.
+// The field follows the list search: work_order_number for WO- terms, product_name otherwise.
+func searchMatches(workOrders []models.WorkOrder, search string) []SearchMatch {
+	if search == "" {
+		return nil
+	}
+
+	matches := []SearchMatch{}
+	for _, workOrder := range workOrders {
+		field, value := "product_name", workOrder.ProductName
+		if strings.HasPrefix(strings.ToUpper(search), "WO-") {
+			field, value = "work_order_number", workOrder.WorkOrderNumber
+		}
+
+		offsets := matchOffsets(value, search)
+		if len(offsets) == 0 {
+			continue
+		}
+		matches = append(matches, SearchMatch{
+			WorkOrderID:  workOrder.ID,
+			MatchedField: field,
+			Offsets:      offsets,
+		})
+	}
+	return matches
+}
+
+// matchOffsets returns the non-overlapping case-insensitive occurrences of term in value
+func matchOffsets(value, term string) []MatchOffset {
+	// Compare rune by rune so offsets stay aligned with the original value
+	haystack := []rune(strings.ToUpper(value))
+	needle := []rune(strings.ToUpper(term))
+	if len(needle) == 0 || len(haystack) != len([]rune(value)) {
+		return nil
+	}
+
+	var offsets []MatchOffset
+	for i := 0; i+len(needle) <= len(haystack); {
+		if string(haystack[i:i+len(needle)]) == string(needle) {
+			offsets = append(offsets, MatchOffset{Start: i, End: i + len(needle)})
+			i += len(needle)
+			continue
+		}
+		i++
 	}
+	return offsets
 }
@@ -0,0 +1,1481 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/features"
+	"github.com/gofiber/fiber/v2"
+)
+
+// workOrderRows answers the lookup of work order 1, assigned to operator 2, and finds nothing else
+func workOrderRows(stmt dbtest.Statement) dbtest.Rows {
+	if strings.HasPrefix(stmt.SQL, `SELECT * FROM "work_orders"`) && len(stmt.Args) > 0 && stmt.Args[0] == int64(1) {
+		return dbtest.Rows{Columns: []string{"id", "operator_id", "status"}, Values: [][]driver.Value{{int64(1), int64(2), "in_progress"}}}
+	}
+	return dbtest.Rows{}
+}
+
+func TestGetWorkOrderLogs(t *testing.T) {
+	useScriptedDB(t, workOrderRows)
+
+	tests := []struct {
+		name       string
+		user       testUser
+		target     string
+		wantStatus int
+	}{
+		{"without a user", testUser{}, "/work-orders/1/logs", fiber.StatusUnauthorized},
+		{"invalid id", testUser{1, models.RoleProductionManager, 0}, "/work-orders/abc/logs", fiber.StatusBadRequest},
+		{"work order not found", testUser{1, models.RoleProductionManager, 0}, "/work-orders/9/logs", fiber.StatusNotFound},
+		{"operator not assigned", testUser{3, models.RoleOperator, 0}, "/work-orders/1/logs", fiber.StatusForbidden},
+		{"assigned operator", testUser{2, models.RoleOperator, 0}, "/work-orders/1/logs", fiber.StatusOK},
+		{"production manager", testUser{1, models.RoleProductionManager, 0}, "/work-orders/1/logs", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := testRequest(t, "/work-orders/:id/logs", GetWorkOrderLogs, tt.user, fiber.MethodGet, tt.target)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+		})
+	}
+}
+
+func TestStatusProgressEntry(t *testing.T) {
+	tests := []struct {
+		name         string
+		quantity     int
+		oldQuantity  int
+		wantEntry    bool
+		wantProduced int
+	}{
+		{"quantity went up", 30, 10, true, 20},
+		{"first quantity", 5, 0, true, 5},
+		{"quantity unchanged", 10, 10, false, 0},
+		{"status change only", 0, 0, false, 0},
+		{"quantity corrected down", 8, 10, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workOrder := models.WorkOrder{ID: 3, Quantity: tt.quantity}
+			entry, ok := statusProgressEntry(workOrder, tt.oldQuantity, "shift 2", 7)
+			if ok != tt.wantEntry {
+				t.Fatalf("ok = %t, want %t", ok, tt.wantEntry)
+			}
+			if !ok {
+				return
+			}
+			if entry.ProgressQuantity != tt.wantProduced {
+				t.Errorf("ProgressQuantity = %d, want %d", entry.ProgressQuantity, tt.wantProduced)
+			}
+			if entry.WorkOrderID != 3 || entry.ProgressDesc != "shift 2" || entry.ReportedByID == nil || *entry.ReportedByID != 7 {
+				t.Errorf("entry = %+v, want work order 3 reported by 7 with the description", entry)
+			}
+		})
+	}
+}
+
+func TestWorkOrderListFilters(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.WorkOrderSortBy = "work_order_number"
+	config.AppConfig.WorkOrderSortOrder = "desc"
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	lists := []struct {
+		name    string
+		route   string
+		handler fiber.Handler
+		user    testUser
+	}{
+		{"all work orders", "/work-orders", GetWorkOrders, testUser{1, models.RoleProductionManager, 0}},
+		{"assigned work orders", "/work-orders/assigned", GetAssignedWorkOrders, testUser{2, models.RoleOperator, 0}},
+	}
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantSQL    []string
+	}{
+		{"status and search", "?status=pending&search=wo-2026", fiber.StatusOK, []string{"status = $", "UPPER(work_order_number) LIKE $"}},
+		{"quantity range", "?min_quantity=10&max_quantity=20", fiber.StatusOK, []string{"target_quantity >= $", "target_quantity <= $"}},
+		{"not acknowledged", "?acknowledged=false", fiber.StatusOK, []string{"acknowledged_at IS NULL"}},
+		{"any of the tags", "?tags=rework,customer-x", fiber.StatusOK, []string{"WHERE t.name IN ($"}},
+		{"all of the tags", "?tags=rework,customer-x&tags_match=all", fiber.StatusOK, []string{"HAVING COUNT(DISTINCT t.name) = $"}},
+		{"invalid acknowledged", "?acknowledged=maybe", fiber.StatusBadRequest, nil},
+		{"invalid tags_match", "?tags=rework&tags_match=some", fiber.StatusBadRequest, nil},
+		{"inverted quantity range", "?min_quantity=20&max_quantity=10", fiber.StatusBadRequest, nil},
+		{"invalid deadline", "?deadline=tomorrow", fiber.StatusBadRequest, nil},
+	}
+
+	for _, list := range lists {
+		for _, tt := range tests {
+			t.Run(list.name+"/"+tt.name, func(t *testing.T) {
+				recorder := useScriptedDB(t, nil)
+
+				status, body := testRequest(t, list.route, list.handler, list.user, fiber.MethodGet, list.route+tt.query)
+				if status != tt.wantStatus {
+					t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+				}
+				selects := recorder.Find(`FROM "work_orders"`)
+				if tt.wantSQL == nil {
+					if len(selects) > 0 {
+						t.Errorf("rejected filter queried work orders: %+v", selects)
+					}
+					return
+				}
+				if len(selects) == 0 {
+					t.Fatalf("work orders not queried: %+v", recorder.Statements())
+				}
+				for _, want := range tt.wantSQL {
+					if !strings.Contains(selects[0].SQL, want) {
+						t.Errorf("query lacks %q: %s", want, selects[0].SQL)
+					}
+				}
+			})
+		}
+	}
+}
+
+// statusUpdateRows answers as a database holding manager 1 and work order 1 with the
+// status, assigned to operator 2 who has activeOrders work orders in progress
+func statusUpdateRows(status models.WorkOrderStatus, activeOrders int64) dbtest.Responder {
+	stored := storedRows(map[string]testTable{
+		"users": {
+			columns: []string{"id", "plant_id", "username", "role"},
+			rows: [][]driver.Value{
+				{int64(1), int64(1), "manager", string(models.RoleProductionManager)},
+				{int64(2), int64(1), "operator", string(models.RoleOperator)},
+			},
+		},
+		"work_orders": {
+			columns: []string{"id", "plant_id", "operator_id", "status", "quantity", "target_quantity", "work_order_number"},
+			rows:    [][]driver.Value{{int64(1), int64(1), int64(2), string(status), int64(0), int64(100), "WO-20260301-001"}},
+		},
+	})
+	return func(stmt dbtest.Statement) dbtest.Rows {
+		switch {
+		case strings.HasPrefix(stmt.SQL, "SELECT count(*)") && strings.Contains(stmt.SQL, "operator_id = $1 AND status = $2"):
+			return dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{activeOrders}}}
+		case strings.HasPrefix(stmt.SQL, `UPDATE "work_orders"`):
+			return dbtest.Rows{RowsAffected: 1}
+		}
+		return stored(stmt)
+	}
+}
+
+func TestUpdateWorkOrderStatusActiveOrderLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		user         testUser
+		limit        int
+		activeOrders int64
+		body         string
+		wantStatus   int
+		wantOverride bool
+	}{
+		{"no limit", testUser{2, models.RoleOperator, 0}, 0, 10, `{"status":"in_progress"}`, fiber.StatusOK, false},
+		{"below the limit", testUser{2, models.RoleOperator, 0}, 3, 2, `{"status":"in_progress"}`, fiber.StatusOK, false},
+		{"at the limit", testUser{2, models.RoleOperator, 0}, 3, 3, `{"status":"in_progress"}`, fiber.StatusConflict, false},
+		{"over the limit", testUser{2, models.RoleOperator, 0}, 3, 4, `{"status":"in_progress"}`, fiber.StatusConflict, false},
+		{"operators can not force", testUser{2, models.RoleOperator, 0}, 3, 3, `{"status":"in_progress","force":true}`, fiber.StatusConflict, false},
+		{"manager without force", testUser{1, models.RoleProductionManager, 0}, 3, 3, `{"status":"in_progress","reason":"rush order"}`, fiber.StatusConflict, false},
+		{"manager forcing is audit logged", testUser{1, models.RoleProductionManager, 0}, 3, 3, `{"status":"in_progress","reason":"rush order","force":true}`, fiber.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.AppConfig
+			config.AppConfig.MaxActiveOrdersPerOperator = tt.limit
+			t.Cleanup(func() { config.AppConfig = previous })
+			recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, tt.activeOrders))
+
+			status, body := testRequestBody(t, "/work-orders/:id/status", UpdateWorkOrderStatus, tt.user, fiber.MethodPut, "/work-orders/1/status", tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if status == fiber.StatusConflict && (body["active_orders"] != float64(tt.activeOrders) || body["limit"] != float64(tt.limit)) {
+				t.Errorf("response %v lacks the active orders %d and limit %d", body, tt.activeOrders, tt.limit)
+			}
+
+			overridden := false
+			for _, insert := range recorder.Find(`INSERT INTO "audit_logs"`) {
+				for _, arg := range insert.Args {
+					if note, ok := arg.(string); ok && strings.HasPrefix(note, "Active order limit overridden") {
+						overridden = true
+					}
+				}
+			}
+			if overridden != tt.wantOverride {
+				t.Errorf("override audit logged = %t, want %t", overridden, tt.wantOverride)
+			}
+		})
+	}
+}
+
+func TestUpdateWorkOrderStatusAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       testUser
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{"missing work order for a manager", testUser{1, models.RoleProductionManager, 0}, "/work-orders/9/status", `{"status":"in_progress","reason":"rush order"}`, fiber.StatusNotFound},
+		{"missing work order for an operator", testUser{3, models.RoleOperator, 0}, "/work-orders/9/status", `{"status":"in_progress"}`, fiber.StatusNotFound},
+		{"operator of another work order", testUser{3, models.RoleOperator, 0}, "/work-orders/1/status", `{"status":"in_progress"}`, fiber.StatusForbidden},
+		{"assigned operator", testUser{2, models.RoleOperator, 0}, "/work-orders/1/status", `{"status":"in_progress"}`, fiber.StatusOK},
+		{"manager", testUser{1, models.RoleProductionManager, 0}, "/work-orders/1/status", `{"status":"in_progress","reason":"rush order"}`, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, 0))
+
+			status, body := testRequestBody(t, "/work-orders/:id/status", UpdateWorkOrderStatus, tt.user, fiber.MethodPut, tt.target, tt.body)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if lookups := recorder.Find(`SELECT * FROM "work_orders"`); len(lookups) != 1 {
+				t.Errorf("work order looked up %d times, want once: %+v", len(lookups), lookups)
+			}
+		})
+	}
+}
+
+func TestCreateWorkOrderDeadline(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.DeadlineMinLeadHours = 0
+	config.AppConfig.DeadlineMaxHorizonDays = 0
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	now := time.Now()
+	tests := []struct {
+		name         string
+		deadline     time.Time
+		allowPast    bool
+		wantRejected bool
+	}{
+		{"past deadline", now.Add(-24 * time.Hour), false, true},
+		{"past the grace period", now.Add(-deadlineGracePeriod - time.Minute), false, true},
+		{"now, within the grace period", now, false, false},
+		{"future deadline", now.Add(24 * time.Hour), false, false},
+		{"past deadline allowed for imports", now.Add(-24 * time.Hour), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, nil)
+
+			request, err := json.Marshal(CreateWorkOrderRequest{
+				ProductName:        "Widget",
+				TargetQuantity:     10,
+				ProductionDeadline: tt.deadline,
+				OperatorID:         2,
+				AllowPastDeadline:  tt.allowPast,
+			})
+			if err != nil {
+				t.Fatalf("encoding request: %v", err)
+			}
+			status, body := testRequestBody(t, "/work-orders", CreateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPost, "/work-orders", string(request))
+
+			msg, _ := body["msg"].(string)
+			rejected := status == fiber.StatusBadRequest && strings.Contains(msg, "Production deadline must be in the future")
+			if rejected != tt.wantRejected {
+				t.Errorf("deadline rejected = %t, want %t (%d %v)", rejected, tt.wantRejected, status, body)
+			}
+			// A rejected deadline is answered before the operator is looked up
+			if queried := len(recorder.Statements()) > 0; queried == tt.wantRejected {
+				t.Errorf("database queried = %t for a rejected deadline = %t", queried, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestWorkOrderOfDeletedOperator(t *testing.T) {
+	deletedAt := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		deletedAt   driver.Value
+		wantDeleted bool
+	}{
+		{"current operator", nil, false},
+		{"former employee", deletedAt, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := storedRows(map[string]testTable{
+				"work_orders": {
+					columns: []string{"id", "plant_id", "operator_id", "status"},
+					rows:    [][]driver.Value{{int64(1), int64(1), int64(2), string(models.StatusInProgress)}},
+				},
+				"users": {
+					columns: []string{"id", "plant_id", "username", "role", "deleted_at"},
+					rows:    [][]driver.Value{{int64(2), int64(1), "operator", string(models.RoleOperator), tt.deletedAt}},
+				},
+			})
+			useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+				switch {
+				case strings.Contains(stmt.SQL, `"parent_id"`):
+					return dbtest.Rows{}
+				case tt.deletedAt != nil && strings.Contains(stmt.SQL, `"users"."deleted_at" IS NULL`):
+					// A soft-deleted user is invisible to scoped queries
+					return dbtest.Rows{}
+				}
+				return stored(stmt)
+			})
+
+			status, body := testRequest(t, "/work-orders/:id", GetWorkOrderByID, testUser{1, models.RoleProductionManager, 0}, fiber.MethodGet, "/work-orders/1")
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want %d (%v)", status, fiber.StatusOK, body)
+			}
+			workOrder, _ := body["work_order"].(map[string]interface{})
+			operator, _ := workOrder["operator"].(map[string]interface{})
+			if operator["username"] != "operator" {
+				t.Fatalf("operator = %v, want the assigned operator", workOrder["operator"])
+			}
+			if operator["deleted"] != tt.wantDeleted {
+				t.Errorf("operator deleted = %v, want %t", operator["deleted"], tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestStatusHandlersFollowTheTransitionMap(t *testing.T) {
+	managerOnly := map[models.WorkOrderStatus][]models.StatusTransition{
+		models.StatusPending:    {{To: models.StatusInProgress}},
+		models.StatusInProgress: {{To: models.StatusCompleted}, {To: models.StatusOnHold, Roles: []models.Role{models.RoleProductionManager}}},
+		models.StatusOnHold:     {{To: models.StatusInProgress, Roles: []models.Role{models.RoleProductionManager}}},
+	}
+
+	// statusChange is a request of a handler changing the status of work order 1
+	type statusChange struct {
+		route   string
+		handler fiber.Handler
+		method  string
+		target  string
+		body    string
+	}
+
+	tests := []struct {
+		name        string
+		transitions map[models.WorkOrderStatus][]models.StatusTransition
+		user        testUser
+	}{
+		{"default lifecycle for the assigned operator", models.StatusTransitions, testUser{2, models.RoleOperator, 0}},
+		{"default lifecycle for a manager", models.StatusTransitions, testUser{1, models.RoleProductionManager, 0}},
+		{"manager-only moves for the assigned operator", managerOnly, testUser{2, models.RoleOperator, 0}},
+		{"manager-only moves for a manager", managerOnly, testUser{1, models.RoleProductionManager, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := models.StatusTransitions
+			models.StatusTransitions = tt.transitions
+			t.Cleanup(func() { models.StatusTransitions = previous })
+
+			for _, from := range models.WorkOrderStatuses {
+				useScriptedDB(t, statusUpdateRows(from, 0))
+
+				_, body := testRequest(t, "/work-orders/:id/transitions", GetWorkOrderTransitions, tt.user, fiber.MethodGet, "/work-orders/1/transitions")
+				listed := map[models.WorkOrderStatus]bool{}
+				transitions, _ := body["transitions"].([]interface{})
+				for _, status := range transitions {
+					listed[models.WorkOrderStatus(status.(string))] = true
+				}
+
+				for _, to := range models.WorkOrderStatuses {
+					if to == from {
+						continue
+					}
+					if allowed := models.CanTransition(from, to, tt.user.role); listed[to] != allowed {
+						t.Errorf("%s -> %s listed = %t, but allowed = %t", from, to, listed[to], allowed)
+					}
+
+					// Every handler changing the status refuses exactly the moves the endpoint does not list
+					requests := []statusChange{
+						{"/work-orders/:id/status", UpdateWorkOrderStatus, fiber.MethodPut, "/work-orders/1/status", `{"status":"` + string(to) + `","quantity":100,"hold_reason":"line down","reason":"rush order"}`},
+						{"/work-orders/:id/logs", CreateWorkOrderLog, fiber.MethodPost, "/work-orders/1/logs", `{"note":"line down","status":"` + string(to) + `"}`},
+					}
+					if tt.user.role == models.RoleProductionManager {
+						requests = append(requests, statusChange{"/work-orders/:id", UpdateWorkOrder, fiber.MethodPut, "/work-orders/1", `{"status":"` + string(to) + `"}`})
+					}
+					for _, r := range requests {
+						useScriptedDB(t, statusUpdateRows(from, 0))
+						status, body := testRequestBody(t, r.route, r.handler, tt.user, r.method, r.target, r.body)
+						refused := status == fiber.StatusBadRequest && body["code"] == CodeInvalidStatusTransition
+						if refused == listed[to] {
+							t.Errorf("%s %s -> %s refused = %t, listed = %t (%d %v)", r.target, from, to, refused, listed[to], status, body)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// noteArg returns the string argument of the statement containing substr
+func noteArg(stmt dbtest.Statement, substr string) (string, bool) {
+	for _, arg := range stmt.Args {
+		if s, ok := arg.(string); ok && strings.Contains(s, substr) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+func TestUpdateWorkOrderStatusOverrideReason(t *testing.T) {
+	tests := []struct {
+		name         string
+		user         testUser
+		body         string
+		wantStatus   int
+		wantOverride string
+	}{
+		{"manager without a reason", testUser{1, models.RoleProductionManager, 0}, `{"status":"in_progress"}`, fiber.StatusBadRequest, ""},
+		{"manager with a blank reason", testUser{1, models.RoleProductionManager, 0}, `{"status":"in_progress","reason":"   "}`, fiber.StatusBadRequest, ""},
+		{"manager with a reason", testUser{1, models.RoleProductionManager, 0}, `{"status":"in_progress","reason":" operator out sick "}`, fiber.StatusOK, "Manager override: operator out sick"},
+		{"manager keeping the status", testUser{1, models.RoleProductionManager, 0}, `{"status":"pending"}`, fiber.StatusOK, ""},
+		{"assigned operator without a reason", testUser{2, models.RoleOperator, 0}, `{"status":"in_progress"}`, fiber.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, 0))
+
+			status, body := testRequestBody(t, "/work-orders/:id/status", UpdateWorkOrderStatus, tt.user, fiber.MethodPut, "/work-orders/1/status", tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if status == fiber.StatusBadRequest {
+				if updates := recorder.Find(`UPDATE "work_orders"`); len(updates) > 0 {
+					t.Errorf("status changed without a reason: %+v", updates)
+				}
+				return
+			}
+
+			// The reason is kept with the status history and the audit log of the change
+			for _, table := range []string{"work_order_status_histories", "audit_logs"} {
+				overridden := false
+				for _, insert := range recorder.Find(`INSERT INTO "` + table + `"`) {
+					note, ok := noteArg(insert, "Manager override")
+					overridden = overridden || ok
+					if ok && !strings.Contains(note, tt.wantOverride) {
+						t.Errorf("%s note %q, want it to contain %q", table, note, tt.wantOverride)
+					}
+				}
+				if overridden != (tt.wantOverride != "") {
+					t.Errorf("%s notes the override = %t, want %t", table, overridden, tt.wantOverride != "")
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateWorkOrderLeavesTheProducedQuantity(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantColumns []string
+	}{
+		{"renamed", `{"product_name":"Bracket"}`, []string{`"product_name"=`}},
+		{"new target", `{"target_quantity":150}`, []string{`"target_quantity"=`}},
+		{"quantity in the body", `{"quantity":999,"unit":"box"}`, []string{`"unit"=`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, 0))
+
+			status, body := testRequestBody(t, "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", tt.body)
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", status, body)
+			}
+
+			// A progress entry may have raised the quantity since the work order was read
+			updates := recorder.Find(`UPDATE "work_orders"`)
+			if len(updates) != 1 {
+				t.Fatalf("work order updated %d times, want once: %+v", len(updates), updates)
+			}
+			if strings.Contains(updates[0].SQL, `"quantity"=`) {
+				t.Errorf("update %q writes the produced quantity", updates[0].SQL)
+			}
+			for _, column := range tt.wantColumns {
+				if !strings.Contains(updates[0].SQL, column) {
+					t.Errorf("update %q does not write %s", updates[0].SQL, column)
+				}
+			}
+			if hasArgs(updates[0], int64(999)) {
+				t.Errorf("update %+v carries the quantity of the body", updates[0])
+			}
+		})
+	}
+}
+
+func TestCompletionBelowTheTargetOnEveryPath(t *testing.T) {
+	// statusRequest is a request completing work order 1, which has produced nothing of its 100
+	type statusRequest struct {
+		name    string
+		route   string
+		handler fiber.Handler
+		user    testUser
+		method  string
+		target  string
+		body    string
+	}
+	paths := []statusRequest{
+		{"status endpoint", "/work-orders/:id/status", UpdateWorkOrderStatus, testUser{2, models.RoleOperator, 0}, fiber.MethodPut, "/work-orders/1/status", `{"status":"completed"`},
+		{"work order update", "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"status":"completed"`},
+		{"log entry", "/work-orders/:id/logs", CreateWorkOrderLog, testUser{2, models.RoleOperator, 0}, fiber.MethodPost, "/work-orders/1/logs", `{"note":"shift over","status":"completed"`},
+	}
+
+	tests := []struct {
+		name          string
+		details       string
+		wantStatus    int
+		wantNote      string
+		wantBackorder bool
+	}{
+		{"without a disposition", ``, fiber.StatusBadRequest, "", false},
+		{"unknown disposition", `,"remaining_disposition":"scrapped"`, fiber.StatusBadRequest, "", false},
+		{"backorder", `,"remaining_disposition":"backorder"`, fiber.StatusOK, "Completed 0 of 100, remaining 100 backorder", true},
+		{"cancelled", `,"remaining_disposition":"cancelled","cancellation_reason":"order withdrawn"`, fiber.StatusOK, "Completed 0 of 100, remaining 100 cancelled: order withdrawn", false},
+	}
+
+	for _, path := range paths {
+		for _, tt := range tests {
+			t.Run(path.name+" "+tt.name, func(t *testing.T) {
+				recorder := useScriptedDB(t, statusUpdateRows(models.StatusInProgress, 0))
+
+				status, body := testRequestBody(t, path.route, path.handler, path.user, path.method, path.target, path.body+tt.details+`}`)
+				if status != tt.wantStatus {
+					t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+				}
+				if status != fiber.StatusOK {
+					if updates := recorder.Find(`UPDATE "work_orders"`); len(updates) > 0 {
+						t.Errorf("completed short of the target without a disposition: %+v", updates)
+					}
+					return
+				}
+
+				// The shortfall is kept with the status history of the completion
+				noted := false
+				for _, insert := range recorder.Find(`INSERT INTO "work_order_status_histories"`) {
+					if hasArgs(insert, string(models.StatusCompleted)) {
+						_, noted = noteArg(insert, tt.wantNote)
+					}
+				}
+				if !noted {
+					t.Errorf("status history does not note %q: %+v", tt.wantNote, recorder.Find(`INSERT INTO "work_order_status_histories"`))
+				}
+
+				backorders := recorder.Find(`INSERT INTO "work_orders"`)
+				if tt.wantBackorder != (len(backorders) == 1) {
+					t.Errorf("backorders created %d, want backorder %t", len(backorders), tt.wantBackorder)
+				}
+				if tt.wantBackorder && !hasArgs(backorders[0], int64(100), int64(1)) {
+					t.Errorf("backorder %+v is not of the remaining 100 of work order 1", backorders[0])
+				}
+			})
+		}
+	}
+}
+
+func TestStartingWorkChecksTheActiveOrderLimitOnEveryPath(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.MaxActiveOrdersPerOperator = 3
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	tests := []struct {
+		name         string
+		route        string
+		handler      fiber.Handler
+		user         testUser
+		method       string
+		target       string
+		body         string
+		activeOrders int64
+		wantStatus   int
+		wantOverride bool
+	}{
+		{"log entry below the limit", "/work-orders/:id/logs", CreateWorkOrderLog, testUser{2, models.RoleOperator, 0}, fiber.MethodPost, "/work-orders/1/logs", `{"note":"starting","status":"in_progress"}`, 2, fiber.StatusOK, false},
+		{"log entry at the limit", "/work-orders/:id/logs", CreateWorkOrderLog, testUser{2, models.RoleOperator, 0}, fiber.MethodPost, "/work-orders/1/logs", `{"note":"starting","status":"in_progress"}`, 3, fiber.StatusConflict, false},
+		{"log entry can not force", "/work-orders/:id/logs", CreateWorkOrderLog, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPost, "/work-orders/1/logs", `{"note":"starting","status":"in_progress","force":true}`, 3, fiber.StatusConflict, false},
+		{"work order update at the limit", "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"status":"in_progress"}`, 3, fiber.StatusConflict, false},
+		{"work order update forced", "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"status":"in_progress","force":true}`, 3, fiber.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, tt.activeOrders))
+
+			status, body := testRequestBody(t, tt.route, tt.handler, tt.user, tt.method, tt.target, tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if status == fiber.StatusConflict {
+				if body["active_orders"] != float64(tt.activeOrders) {
+					t.Errorf("response %v lacks the %d active orders", body, tt.activeOrders)
+				}
+				if updates := recorder.Find(`UPDATE "work_orders"`); len(updates) > 0 {
+					t.Errorf("work order started past the limit: %+v", updates)
+				}
+			}
+
+			overridden := false
+			for _, insert := range recorder.Find(`INSERT INTO "audit_logs"`) {
+				_, ok := noteArg(insert, "Active order limit overridden")
+				overridden = overridden || ok
+			}
+			if overridden != tt.wantOverride {
+				t.Errorf("override audit logged = %t, want %t", overridden, tt.wantOverride)
+			}
+		})
+	}
+}
+
+// completionRace is work order 1 of operator 2, in progress with its target produced, in a
+// database applying the status-locked update atomically. The first two lookups of the order
+// find it in progress and no update runs before both of them, as with two racing requests.
+type completionRace struct {
+	mu      sync.Mutex
+	lookups int
+	read    chan struct{} // closed by the second lookup
+	status  string
+}
+
+// statusLock matches the status condition of a locked update, such as status = $8
+var statusLock = regexp.MustCompile(`status = \$(\d+)`)
+
+// respond is the dbtest.Responder of the database
+func (r *completionRace) respond(stmt dbtest.Statement) dbtest.Rows {
+	switch {
+	case strings.HasPrefix(stmt.SQL, `SELECT * FROM "work_orders"`):
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		status := r.status
+		if r.lookups++; r.lookups <= 2 {
+			status = string(models.StatusInProgress)
+		}
+		if r.lookups == 2 {
+			close(r.read)
+		}
+		return dbtest.Rows{
+			Columns: []string{"id", "operator_id", "status", "quantity", "target_quantity", "work_order_number"},
+			Values:  [][]driver.Value{{int64(1), int64(2), status, int64(100), int64(100), "WO-20260301-001"}},
+		}
+	case strings.HasPrefix(stmt.SQL, `UPDATE "work_orders"`):
+		<-r.read
+		lock := statusLock.FindStringSubmatch(stmt.SQL)
+		if lock == nil {
+			return dbtest.Rows{RowsAffected: 1}
+		}
+		arg, _ := strconv.Atoi(lock[1])
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if stmt.Args[arg-1] != r.status {
+			return dbtest.Rows{}
+		}
+		r.status = string(models.StatusCompleted)
+		return dbtest.Rows{RowsAffected: 1}
+	}
+	return dbtest.Rows{}
+}
+
+func TestConcurrentCompletionsGoThroughOnce(t *testing.T) {
+	operator := testUser{2, models.RoleOperator, 0}
+	manager := testUser{1, models.RoleProductionManager, 0}
+
+	// completion is a request completing work order 1
+	type completion struct {
+		route   string
+		handler fiber.Handler
+		user    testUser
+		target  string
+	}
+	update := completion{"/work-orders/:id", UpdateWorkOrder, manager, "/work-orders/1"}
+	status := completion{"/work-orders/:id/status", UpdateWorkOrderStatus, operator, "/work-orders/1/status"}
+
+	tests := []struct {
+		name        string
+		completions [2]completion
+	}{
+		{"two work order updates", [2]completion{update, update}},
+		{"work order update and status update", [2]completion{update, status}},
+		{"two status updates", [2]completion{status, status}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &completionRace{read: make(chan struct{}), status: string(models.StatusInProgress)}
+			recorder := useScriptedDB(t, db.respond)
+
+			var wg sync.WaitGroup
+			responses := make(chan map[string]interface{}, len(tt.completions))
+			for _, c := range tt.completions {
+				wg.Add(1)
+				go func(c completion) {
+					defer wg.Done()
+					app := testApp(c.route, c.handler, c.user, fiber.MethodPut)
+					resp, err := app.Test(jsonRequest(fiber.MethodPut, c.target, `{"status":"completed"}`))
+					if err != nil {
+						t.Errorf("serving request: %v", err)
+						return
+					}
+					defer resp.Body.Close()
+					var body map[string]interface{}
+					if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+						t.Errorf("decoding response: %v", err)
+					}
+					body["status"] = resp.StatusCode
+					responses <- body
+				}(c)
+			}
+			wg.Wait()
+			close(responses)
+
+			completed, refused := 0, 0
+			for body := range responses {
+				switch {
+				case body["status"] == fiber.StatusOK:
+					completed++
+				case body["status"] == fiber.StatusConflict && body["code"] == CodeAlreadyCompleted && body["final_quantity"] == float64(100):
+					refused++
+				default:
+					t.Errorf("unexpected response %v", body)
+				}
+			}
+			if completed != 1 || refused != 1 {
+				t.Errorf("%d completed and %d refused, want one of each", completed, refused)
+			}
+			if histories := recorder.Find(`INSERT INTO "work_order_status_histories"`); len(histories) != 1 {
+				t.Errorf("completion recorded %d times, want once", len(histories))
+			}
+		})
+	}
+}
+
+func TestCompletionWaitsForProgressApprovalOnEveryPath(t *testing.T) {
+	paths := []struct {
+		name    string
+		route   string
+		handler fiber.Handler
+		user    testUser
+		method  string
+		target  string
+		body    string
+	}{
+		{"status endpoint", "/work-orders/:id/status", UpdateWorkOrderStatus, testUser{2, models.RoleOperator, 0}, fiber.MethodPut, "/work-orders/1/status", `{"status":"completed","quantity":100}`},
+		{"work order update", "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"status":"completed","remaining_disposition":"backorder"}`},
+		{"log entry", "/work-orders/:id/logs", CreateWorkOrderLog, testUser{2, models.RoleOperator, 0}, fiber.MethodPost, "/work-orders/1/logs", `{"note":"done","status":"completed","remaining_disposition":"backorder"}`},
+	}
+
+	tests := []struct {
+		name       string
+		flags      string
+		unapproved int64
+		wantStatus int
+	}{
+		{"approval off with unapproved progress", "", 2, fiber.StatusOK},
+		{"approval on with all progress approved", features.ProgressApproval, 0, fiber.StatusOK},
+		{"approval on with unapproved progress", features.ProgressApproval, 2, fiber.StatusConflict},
+	}
+
+	for _, path := range paths {
+		for _, tt := range tests {
+			t.Run(path.name+" "+tt.name, func(t *testing.T) {
+				previous := config.AppConfig
+				config.AppConfig.FeatureFlags = tt.flags
+				features.Invalidate()
+				t.Cleanup(func() {
+					config.AppConfig = previous
+					features.Invalidate()
+				})
+
+				stored := statusUpdateRows(models.StatusInProgress, 0)
+				recorder := useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+					if strings.HasPrefix(stmt.SQL, `SELECT count(*) FROM "work_order_progresses"`) && strings.Contains(stmt.SQL, "approved_at IS NULL") {
+						return dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{tt.unapproved}}}
+					}
+					return stored(stmt)
+				})
+
+				status, body := testRequestBody(t, path.route, path.handler, path.user, path.method, path.target, path.body)
+				if status != tt.wantStatus {
+					t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+				}
+				if status == fiber.StatusConflict {
+					if body["code"] != CodeProgressNotApproved {
+						t.Errorf("code = %v, want %s", body["code"], CodeProgressNotApproved)
+					}
+					if updates := recorder.Find(`UPDATE "work_orders"`); len(updates) > 0 {
+						t.Errorf("completed with unapproved progress: %+v", updates)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCancelledRemainderOnEveryPath(t *testing.T) {
+	paths := []struct {
+		name    string
+		route   string
+		handler fiber.Handler
+		user    testUser
+		method  string
+		target  string
+		body    string
+	}{
+		{"status endpoint", "/work-orders/:id/status", UpdateWorkOrderStatus, testUser{2, models.RoleOperator, 0}, fiber.MethodPut, "/work-orders/1/status", `{"status":"completed","quantity":60,"remaining_disposition":"cancelled"`},
+		{"work order update", "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"status":"completed","remaining_disposition":"cancelled"`},
+		{"log entry", "/work-orders/:id/logs", CreateWorkOrderLog, testUser{2, models.RoleOperator, 0}, fiber.MethodPost, "/work-orders/1/logs", `{"note":"done","status":"completed","remaining_disposition":"cancelled"`},
+	}
+
+	tests := []struct {
+		name        string
+		details     string
+		wantStatus  int
+		wantReason  string
+		wantRestock string
+	}{
+		{"missing reason", ``, fiber.StatusBadRequest, "", ""},
+		{"blank reason", `,"cancellation_reason":"  "`, fiber.StatusBadRequest, "", ""},
+		{"reason", `,"cancellation_reason":" customer cancelled "`, fiber.StatusOK, "customer cancelled", ""},
+		{"reason and restock note", `,"cancellation_reason":"customer cancelled","restock_note":"40 blanks back to stores"`, fiber.StatusOK, "customer cancelled", "40 blanks back to stores"},
+	}
+
+	for _, path := range paths {
+		for _, tt := range tests {
+			t.Run(path.name+" "+tt.name, func(t *testing.T) {
+				recorder := useScriptedDB(t, statusUpdateRows(models.StatusInProgress, 0))
+
+				status, body := testRequestBody(t, path.route, path.handler, path.user, path.method, path.target, path.body+tt.details+`}`)
+				if status != tt.wantStatus {
+					t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+				}
+				updates := recorder.Find(`UPDATE "work_orders"`)
+				if status != fiber.StatusOK {
+					if len(updates) > 0 {
+						t.Errorf("remainder cancelled without a reason: %+v", updates)
+					}
+					return
+				}
+
+				// The reason and restock note are stored on the order and noted in its status history
+				if len(updates) != 1 || !hasArgs(updates[0], string(models.DispositionCancelled), tt.wantReason) {
+					t.Fatalf("updates %+v do not store the cancellation reason %q", updates, tt.wantReason)
+				}
+				workOrder, _ := body["work_order"].(map[string]interface{})
+				if workOrder["cancellation_reason"] != tt.wantReason {
+					t.Errorf("cancellation_reason = %v, want %q", workOrder["cancellation_reason"], tt.wantReason)
+				}
+				histories := recorder.Find(`INSERT INTO "work_order_status_histories"`)
+				if len(histories) != 1 {
+					t.Fatalf("%d status history rows, want one", len(histories))
+				}
+				if _, ok := noteArg(histories[0], tt.wantReason); !ok {
+					t.Errorf("status history %+v does not note the reason", histories[0])
+				}
+				if tt.wantRestock == "" {
+					if workOrder["restock_note"] != nil {
+						t.Errorf("restock_note = %v, want none", workOrder["restock_note"])
+					}
+					return
+				}
+				if !hasArgs(updates[0], tt.wantRestock) || workOrder["restock_note"] != tt.wantRestock {
+					t.Errorf("restock note %q not stored: update %+v, work order %v", tt.wantRestock, updates[0], workOrder)
+				}
+				if _, ok := noteArg(histories[0], "Restock: "+tt.wantRestock); !ok {
+					t.Errorf("status history %+v does not note the restock", histories[0])
+				}
+			})
+		}
+	}
+}
+
+// nextOrderRows answers the pending work order query with the rows sorted the way
+// its ORDER BY clause asks for, limited to the first as the database would
+func nextOrderRows(rows [][]driver.Value) dbtest.Responder {
+	columns := []string{"id", "operator_id", "status", "priority", "production_deadline", "created_at"}
+	return func(stmt dbtest.Statement) dbtest.Rows {
+		if !strings.HasPrefix(stmt.SQL, `SELECT * FROM "work_orders"`) {
+			return dbtest.Rows{}
+		}
+		return dbtest.Rows{Columns: columns, Values: orderedPage(columns, rows, stmt.SQL)}
+	}
+}
+
+func TestGetNextWorkOrderPriorityBeforeDeadline(t *testing.T) {
+	now := time.Now()
+	overdue, soon, later := now.Add(-24*time.Hour), now.Add(24*time.Hour), now.Add(72*time.Hour)
+	created := now.Add(-7 * 24 * time.Hour)
+	order := func(id, priority int64, deadline, createdAt time.Time) []driver.Value {
+		return []driver.Value{id, int64(2), string(models.StatusPending), priority, deadline, createdAt}
+	}
+
+	tests := []struct {
+		name        string
+		rows        [][]driver.Value
+		wantID      float64
+		wantOverdue bool
+	}{
+		{"higher priority before an earlier deadline", [][]driver.Value{order(1, 0, soon, created), order(2, 5, later, created)}, 2, false},
+		{"higher priority before an overdue order", [][]driver.Value{order(1, 0, overdue, created), order(2, 1, later, created)}, 2, false},
+		{"equal priority falls back to the earliest deadline", [][]driver.Value{order(1, 3, later, created), order(2, 3, soon, created)}, 2, false},
+		{"overdue first within a priority", [][]driver.Value{order(1, 3, soon, created), order(2, 3, overdue, created), order(3, 0, overdue, created)}, 2, true},
+		{"equal deadlines fall back to the oldest order", [][]driver.Value{order(1, 3, soon, created.Add(time.Hour)), order(2, 3, soon, created)}, 2, false},
+		{"equal creation falls back to the lowest ID", [][]driver.Value{order(2, 3, soon, created), order(1, 3, soon, created)}, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, nextOrderRows(tt.rows))
+
+			status, body := testRequest(t, "/work-orders/next", GetNextWorkOrder, testUser{2, models.RoleOperator, 0}, fiber.MethodGet, "/work-orders/next")
+			if status != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200 (%v)", status, body)
+			}
+			workOrder, _ := body["work_order"].(map[string]interface{})
+			if workOrder["id"] != tt.wantID {
+				t.Errorf("next work order = %v, want %v", workOrder["id"], tt.wantID)
+			}
+			if body["overdue"] != tt.wantOverdue {
+				t.Errorf("overdue = %v, want %t", body["overdue"], tt.wantOverdue)
+			}
+			if queries := recorder.Find(`ORDER BY priority DESC, production_deadline ASC`); len(queries) != 1 {
+				t.Errorf("pending orders not ordered by priority before the deadline: %+v", recorder.Statements())
+			}
+		})
+	}
+}
+
+func TestWorkOrderPriorityRange(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.DeadlineMinLeadHours = 0
+	config.AppConfig.DeadlineMaxHorizonDays = 0
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	for _, priority := range []int{-1, models.MaxPriority + 1} {
+		t.Run("create with "+strconv.Itoa(priority), func(t *testing.T) {
+			recorder := useScriptedDB(t, nil)
+
+			request, err := json.Marshal(CreateWorkOrderRequest{
+				ProductName:        "Widget",
+				TargetQuantity:     10,
+				ProductionDeadline: time.Now().Add(24 * time.Hour),
+				OperatorID:         2,
+				Priority:           priority,
+			})
+			if err != nil {
+				t.Fatalf("encoding request: %v", err)
+			}
+			status, body := testRequestBody(t, "/work-orders", CreateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPost, "/work-orders", string(request))
+			if status != fiber.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (%v)", status, body)
+			}
+			if len(recorder.Find(`INSERT INTO "work_orders"`)) != 0 {
+				t.Errorf("work order with priority %d created", priority)
+			}
+		})
+
+		t.Run("update with "+strconv.Itoa(priority), func(t *testing.T) {
+			recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, 0))
+
+			status, body := testRequestBody(t, "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"priority":`+strconv.Itoa(priority)+`}`)
+			if status != fiber.StatusBadRequest {
+				t.Errorf("status = %d, want 400 (%v)", status, body)
+			}
+			if len(recorder.Find(`UPDATE "work_orders"`)) != 0 {
+				t.Errorf("work order updated to priority %d", priority)
+			}
+		})
+	}
+
+	t.Run("update within the range", func(t *testing.T) {
+		recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, 0))
+
+		status, body := testRequestBody(t, "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"priority":7}`)
+		if status != fiber.StatusOK {
+			t.Fatalf("status = %d, want 200 (%v)", status, body)
+		}
+		updates := recorder.Find(`UPDATE "work_orders"`)
+		if len(updates) != 1 || !strings.Contains(updates[0].SQL, `"priority"=`) || !hasArgs(updates[0], int64(7)) {
+			t.Errorf("priority 7 not written: %+v", updates)
+		}
+	})
+}
+
+func TestOperatorStatusUpdateOnlyReportsTheProducedQuantity(t *testing.T) {
+	operator := testUser{2, models.RoleOperator, 0}
+
+	t.Run("produced quantity", func(t *testing.T) {
+		recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, 0))
+
+		status, body := testRequestBody(t, "/work-orders/:id/status", UpdateWorkOrderStatus, operator, fiber.MethodPut, "/work-orders/1/status", `{"status":"in_progress","quantity":30}`)
+		if status != fiber.StatusOK {
+			t.Fatalf("status = %d, want 200 (%v)", status, body)
+		}
+		updates := recorder.Find(`UPDATE "work_orders"`)
+		if len(updates) != 1 {
+			t.Fatalf("work order updated %d times, want once: %+v", len(updates), updates)
+		}
+		if !strings.Contains(updates[0].SQL, `"quantity"=`) || !hasArgs(updates[0], int64(30)) {
+			t.Errorf("update %+v does not write the produced quantity 30", updates[0])
+		}
+		if strings.Contains(updates[0].SQL, `"target_quantity"=`) {
+			t.Errorf("update %q writes the target quantity", updates[0].SQL)
+		}
+		workOrder, _ := body["work_order"].(map[string]interface{})
+		if workOrder["quantity"] != float64(30) || workOrder["target_quantity"] != float64(100) {
+			t.Errorf("work order = %v, want quantity 30 against the unchanged target 100", workOrder)
+		}
+	})
+
+	fields := map[string]string{
+		"target_quantity":     `150`,
+		"unit":                `"kg"`,
+		"product_name":        `"Bracket"`,
+		"production_deadline": `"2030-01-31T00:00:00Z"`,
+		"priority":            `9`,
+		"operator_id":         `3`,
+		"plant_id":            `2`,
+		"parent_id":           `4`,
+	}
+	for field, value := range fields {
+		t.Run(field, func(t *testing.T) {
+			recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, 0))
+
+			payload := `{"status":"in_progress","quantity":30,"` + field + `":` + value + `}`
+			status, body := testRequestBody(t, "/work-orders/:id/status", UpdateWorkOrderStatus, operator, fiber.MethodPut, "/work-orders/1/status", payload)
+			if msg, _ := body["msg"].(string); status != fiber.StatusForbidden || !strings.Contains(msg, field) {
+				t.Errorf("status = %d, want 403 naming %s (%v)", status, field, body)
+			}
+			if updates := recorder.Find(`UPDATE "work_orders"`); len(updates) != 0 {
+				t.Errorf("work order updated: %+v", updates)
+			}
+		})
+	}
+}
+
+// operatorLookup answers the lookup of an assignable operator, which only finds the active operator 2
+func operatorLookup(stmt dbtest.Statement) (dbtest.Rows, bool) {
+	if !strings.HasPrefix(stmt.SQL, `SELECT * FROM "users" WHERE (id = $1 AND role = $2 AND active = $3)`) {
+		return dbtest.Rows{}, false
+	}
+	if stmt.Args[0] != int64(2) {
+		return dbtest.Rows{}, true
+	}
+	return dbtest.Rows{
+		Columns: []string{"id", "plant_id", "username", "role", "active"},
+		Values:  [][]driver.Value{{int64(2), int64(1), "operator", string(models.RoleOperator), true}},
+	}, true
+}
+
+func TestCreateWorkOrderDuplicateActiveOrder(t *testing.T) {
+	tests := []struct {
+		name          string
+		guard         bool
+		existing      bool
+		force         bool
+		wantStatus    int
+		wantChecked   bool
+		wantOverrides int
+	}{
+		{"guard off", false, true, false, fiber.StatusCreated, false, 0},
+		{"no active order", true, false, false, fiber.StatusCreated, true, 0},
+		{"duplicate blocked", true, true, false, fiber.StatusConflict, true, 0},
+		{"duplicate forced", true, true, true, fiber.StatusCreated, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.AppConfig
+			config.AppConfig.PreventDuplicateActiveOrders = tt.guard
+			config.AppConfig.MaxActiveOrdersPerOperator = 0
+			config.AppConfig.DeadlineMinLeadHours = 0
+			config.AppConfig.DeadlineMaxHorizonDays = 0
+			t.Cleanup(func() { config.AppConfig = previous })
+
+			recorder := useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+				if rows, ok := operatorLookup(stmt); ok {
+					return rows
+				}
+				switch {
+				case strings.Contains(stmt.SQL, "UPPER(product_name) = UPPER($3)") && tt.existing:
+					return dbtest.Rows{
+						Columns: []string{"id", "operator_id", "status", "product_name", "work_order_number"},
+						Values:  [][]driver.Value{{int64(1), int64(2), string(models.StatusInProgress), "Widget", "WO-20260301-001"}},
+					}
+				case strings.HasPrefix(stmt.SQL, `INSERT INTO "work_orders"`):
+					return dbtest.Rows{Columns: []string{"id"}, Values: [][]driver.Value{{int64(5)}}}
+				case strings.HasPrefix(stmt.SQL, `SELECT * FROM "users"`):
+					return dbtest.Rows{Columns: []string{"id", "plant_id", "username", "role"}, Values: [][]driver.Value{{int64(1), int64(1), "manager", string(models.RoleProductionManager)}}}
+				}
+				return dbtest.Rows{}
+			})
+
+			request, err := json.Marshal(CreateWorkOrderRequest{
+				ProductName:        "widget ",
+				TargetQuantity:     10,
+				ProductionDeadline: time.Now().Add(24 * time.Hour),
+				OperatorID:         2,
+				Force:              tt.force,
+			})
+			if err != nil {
+				t.Fatalf("encoding request: %v", err)
+			}
+			status, body := testRequestBody(t, "/work-orders", CreateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPost, "/work-orders", string(request))
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+
+			if lookups := recorder.Find(`FROM "users" WHERE (id = $1 AND role = $2 AND active = $3)`); len(lookups) != 1 {
+				t.Errorf("operator looked up %d times, want once", len(lookups))
+			}
+
+			checks := recorder.Find("UPPER(product_name) = UPPER($3)")
+			if checked := len(checks) > 0; checked != tt.wantChecked {
+				t.Fatalf("duplicate checked = %t, want %t", checked, tt.wantChecked)
+			}
+			// Only orders that are not completed count, whatever the case and spacing of the name
+			if tt.wantChecked && !hasArgs(checks[0], int64(2), string(models.StatusCompleted), "widget") {
+				t.Errorf("duplicate check %+v, want operator 2, not completed and the trimmed product name", checks[0])
+			}
+
+			inserts := recorder.Find(`INSERT INTO "work_orders"`)
+			if tt.wantStatus == fiber.StatusConflict {
+				if body["code"] != CodeDuplicateActiveOrder || body["existing_work_order_number"] != "WO-20260301-001" {
+					t.Errorf("conflict = %v, want code %s naming WO-20260301-001", body, CodeDuplicateActiveOrder)
+				}
+				if len(inserts) != 0 {
+					t.Errorf("duplicate work order created: %+v", inserts)
+				}
+			}
+
+			overrides := 0
+			for _, stmt := range recorder.Find(`INSERT INTO "audit_logs"`) {
+				if note, ok := noteArg(stmt, "Duplicate active order forced"); ok {
+					overrides++
+					if !strings.Contains(note, "WO-20260301-001") {
+						t.Errorf("override note %q does not name the existing work order", note)
+					}
+				}
+			}
+			if overrides != tt.wantOverrides {
+				t.Errorf("%d duplicate overrides logged, want %d", overrides, tt.wantOverrides)
+			}
+		})
+	}
+}
+
+func TestUpdateWorkOrderReassignment(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.MaxActiveOrdersPerOperator = 0
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	tests := []struct {
+		name       string
+		operatorID int
+		wantStatus int
+	}{
+		{"active operator", 3, fiber.StatusOK},
+		{"unknown user", 99, fiber.StatusBadRequest},
+		{"manager", 1, fiber.StatusBadRequest},
+		{"inactive operator", 4, fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := statusUpdateRows(models.StatusPending, 0)
+			recorder := useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+				// Only operator 3 is an active operator besides the assigned operator 2
+				if strings.HasPrefix(stmt.SQL, `SELECT * FROM "users" WHERE (id = $1 AND role = $2 AND active = $3)`) {
+					if stmt.Args[0] != int64(3) {
+						return dbtest.Rows{}
+					}
+					return dbtest.Rows{
+						Columns: []string{"id", "plant_id", "username", "role", "active"},
+						Values:  [][]driver.Value{{int64(3), int64(1), "night-shift", string(models.RoleOperator), true}},
+					}
+				}
+				return stored(stmt)
+			})
+
+			status, body := testRequestBody(t, "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"operator_id":`+strconv.Itoa(tt.operatorID)+`}`)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+
+			// The new operator must exist, be an operator and be active
+			lookups := recorder.Find(`FROM "users" WHERE (id = $1 AND role = $2 AND active = $3)`)
+			if len(lookups) != 1 || !hasArgs(lookups[0], int64(tt.operatorID), string(models.RoleOperator), true) {
+				t.Errorf("operator lookups %+v, want one of an active operator %d", lookups, tt.operatorID)
+			}
+
+			updates := recorder.Find(`UPDATE "work_orders"`)
+			var reassignments int
+			for _, stmt := range recorder.Find(`INSERT INTO "audit_logs"`) {
+				if note, ok := noteArg(stmt, "reassigned"); ok {
+					reassignments++
+					if !strings.Contains(note, "from operator #2 to night-shift (#3)") {
+						t.Errorf("reassignment note = %q", note)
+					}
+				}
+			}
+			if tt.wantStatus != fiber.StatusOK {
+				if body["code"] != CodeOperatorNotFound {
+					t.Errorf("code = %v, want %s", body["code"], CodeOperatorNotFound)
+				}
+				if len(updates) != 0 || reassignments != 0 {
+					t.Errorf("work order updated %d times with %d reassignments logged, want none", len(updates), reassignments)
+				}
+				return
+			}
+			if len(updates) != 1 || !hasArgs(updates[0], int64(3)) || reassignments != 1 {
+				t.Errorf("work order updated %d times with %d reassignments logged, want it assigned to operator 3 once: %+v", len(updates), reassignments, updates)
+			}
+		})
+	}
+}
+
+func TestUpdateWorkOrderTargetChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		target        int
+		progress      int64 // progress entries logged, 40 of the target of 100 are produced
+		block         bool
+		wantStatus    int
+		wantCode      string
+		wantWarning   bool
+		wantAuditNote string
+	}{
+		{"below the produced quantity", 30, 3, false, fiber.StatusBadRequest, CodeTargetBelowProduced, false, ""},
+		{"down to the produced quantity", 40, 3, false, fiber.StatusOK, "", true, "target quantity changed from 100 to 40 with 40 already produced in 3 progress entries"},
+		{"raised with progress", 150, 3, false, fiber.StatusOK, "", true, "target quantity changed from 100 to 150 with 40 already produced in 3 progress entries"},
+		{"raised without progress", 150, 0, false, fiber.StatusOK, "", false, ""},
+		{"raised with progress when blocked", 150, 3, true, fiber.StatusConflict, CodeTargetChangeBlocked, false, ""},
+		{"below the produced quantity when blocked", 30, 3, true, fiber.StatusBadRequest, CodeTargetBelowProduced, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.AppConfig
+			config.AppConfig.BlockTargetChangeWithProgress = tt.block
+			t.Cleanup(func() { config.AppConfig = previous })
+
+			stored := storedRows(map[string]testTable{
+				"users": {
+					columns: []string{"id", "plant_id", "username", "role"},
+					rows:    [][]driver.Value{{int64(1), int64(1), "manager", string(models.RoleProductionManager)}},
+				},
+				"work_orders": {
+					columns: []string{"id", "plant_id", "operator_id", "status", "quantity", "target_quantity", "work_order_number"},
+					rows:    [][]driver.Value{{int64(1), int64(1), int64(2), string(models.StatusInProgress), int64(40), int64(100), "WO-20260301-001"}},
+				},
+			})
+			recorder := useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+				switch {
+				case strings.HasPrefix(stmt.SQL, `SELECT count(*) FROM "work_order_progresses"`):
+					return dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{tt.progress}}}
+				case strings.HasPrefix(stmt.SQL, `UPDATE "work_orders"`):
+					return dbtest.Rows{RowsAffected: 1}
+				}
+				return stored(stmt)
+			})
+
+			status, body := testRequestBody(t, "/work-orders/:id", UpdateWorkOrder, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPut, "/work-orders/1", `{"target_quantity":`+strconv.Itoa(tt.target)+`}`)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+			if code, _ := body["code"].(string); code != tt.wantCode {
+				t.Errorf("code = %q, want %q", code, tt.wantCode)
+			}
+
+			updates := recorder.Find(`UPDATE "work_orders"`)
+			if (len(updates) > 0) != (status == fiber.StatusOK) {
+				t.Errorf("work order updated %d times with status %d", len(updates), status)
+			}
+
+			// Blocked and warned changes report the produced quantity against the old target
+			change, _ := body["target_change"].(map[string]interface{})
+			if tt.wantWarning || tt.wantStatus != fiber.StatusOK {
+				want := map[string]interface{}{"old_target": float64(100), "new_target": float64(tt.target), "produced": float64(40), "progress_entries": float64(tt.progress)}
+				if fmt.Sprint(change) != fmt.Sprint(want) {
+					t.Errorf("target_change = %v, want %v", change, want)
+				}
+			} else if change != nil {
+				t.Errorf("target_change = %v without progress", change)
+			}
+			warning, _ := body["warning"].(string)
+			if (warning != "") != tt.wantWarning || tt.wantWarning && !strings.Contains(warning, "after 40 of 100 were produced") {
+				t.Errorf("warning = %q, want one = %t", warning, tt.wantWarning)
+			}
+
+			if tt.wantAuditNote != "" {
+				var found bool
+				for _, stmt := range recorder.Find(`INSERT INTO "audit_logs"`) {
+					if _, ok := noteArg(stmt, tt.wantAuditNote); ok {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("no audit log noting %q", tt.wantAuditNote)
+				}
+			}
+		})
+	}
+}
+
+func TestDeadlineWindowBoundaries(t *testing.T) {
+	previous := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = previous })
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	earliest := now.Add(24*time.Hour - deadlineGracePeriod)
+	latest := now.AddDate(0, 0, 30)
+
+	tests := []struct {
+		name         string
+		minLeadHours int
+		maxDays      int
+		deadline     time.Time
+		want         bool
+	}{
+		{"before the minimum lead time", 24, 30, earliest.Add(-time.Second), false},
+		{"at the minimum lead time less the grace period", 24, 30, earliest, true},
+		{"at the minimum lead time", 24, 30, now.Add(24 * time.Hour), true},
+		{"at the maximum horizon", 24, 30, latest, true},
+		{"past the maximum horizon", 24, 30, latest.Add(time.Second), false},
+		{"far future without a horizon", 24, 0, now.AddDate(5, 0, 0), true},
+		{"soon without a lead time", 0, 30, now, true},
+		{"no window", 0, 0, now.AddDate(-1, 0, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AppConfig.DeadlineMinLeadHours = tt.minLeadHours
+			config.AppConfig.DeadlineMaxHorizonDays = tt.maxDays
+			if got := deadlineWindow(now).contains(tt.deadline); got != tt.want {
+				t.Errorf("deadline %s in the window = %t, want %t", tt.deadline, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeadlineWindowOnCreateAndUpdate(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.DeadlineMinLeadHours = 24
+	config.AppConfig.DeadlineMaxHorizonDays = 30
+	config.AppConfig.MaxActiveOrdersPerOperator = 0
+	config.AppConfig.PreventDuplicateActiveOrders = false
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	now := time.Now()
+	manager := testUser{1, models.RoleProductionManager, 0}
+	tests := []struct {
+		name          string
+		deadline      time.Time
+		override      bool
+		wantRejected  bool
+		wantOverrides int // audit logs of the deadline accepted outside the window
+	}{
+		{"within the window", now.Add(48 * time.Hour), false, false, 0},
+		{"within the window with the override", now.Add(48 * time.Hour), true, false, 0},
+		{"too soon", now.Add(2 * time.Hour), false, true, 0},
+		{"too far", now.AddDate(0, 0, 31), false, true, 0},
+		{"too soon with the override", now.Add(2 * time.Hour), true, false, 1},
+		{"too far with the override", now.AddDate(0, 0, 31), true, false, 1},
+	}
+
+	// overridesLogged counts the audit logs of deadlines accepted outside the window
+	overridesLogged := func(recorder *dbtest.Recorder) int {
+		count := 0
+		for _, stmt := range recorder.Find(`INSERT INTO "audit_logs"`) {
+			if _, ok := noteArg(stmt, "Deadline window overridden"); ok {
+				count++
+			}
+		}
+		return count
+	}
+
+	for _, tt := range tests {
+		checkRejection := func(t *testing.T, status int, body map[string]interface{}) {
+			t.Helper()
+			if rejected := status == fiber.StatusBadRequest; rejected != tt.wantRejected {
+				t.Fatalf("status = %d, want rejected = %t (%v)", status, tt.wantRejected, body)
+			}
+			if !tt.wantRejected {
+				return
+			}
+			window, _ := body["allowed_window"].(map[string]interface{})
+			if body["code"] != CodeDeadlineOutsideWindow || window["earliest"] == nil || window["latest"] == nil {
+				t.Errorf("rejection = %v, want code %s with the allowed window", body, CodeDeadlineOutsideWindow)
+			}
+		}
+
+		t.Run("create "+tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+				if rows, ok := operatorLookup(stmt); ok {
+					return rows
+				}
+				switch {
+				case strings.HasPrefix(stmt.SQL, `INSERT INTO "work_orders"`):
+					return dbtest.Rows{Columns: []string{"id"}, Values: [][]driver.Value{{int64(5)}}}
+				case strings.HasPrefix(stmt.SQL, `SELECT * FROM "users"`):
+					return dbtest.Rows{Columns: []string{"id", "plant_id", "username", "role"}, Values: [][]driver.Value{{int64(1), int64(1), "manager", string(models.RoleProductionManager)}}}
+				}
+				return dbtest.Rows{}
+			})
+
+			request, err := json.Marshal(CreateWorkOrderRequest{
+				ProductName:                "Widget",
+				TargetQuantity:             10,
+				ProductionDeadline:         tt.deadline,
+				OperatorID:                 2,
+				AllowDeadlineOutsideWindow: tt.override,
+			})
+			if err != nil {
+				t.Fatalf("encoding request: %v", err)
+			}
+			status, body := testRequestBody(t, "/work-orders", CreateWorkOrder, manager, fiber.MethodPost, "/work-orders", string(request))
+			checkRejection(t, status, body)
+			if overrides := overridesLogged(recorder); overrides != tt.wantOverrides {
+				t.Errorf("%d deadline overrides logged, want %d", overrides, tt.wantOverrides)
+			}
+		})
+
+		t.Run("update "+tt.name, func(t *testing.T) {
+			recorder := useScriptedDB(t, statusUpdateRows(models.StatusPending, 0))
+
+			payload, err := json.Marshal(map[string]interface{}{
+				"production_deadline":           tt.deadline,
+				"allow_deadline_outside_window": tt.override,
+			})
+			if err != nil {
+				t.Fatalf("encoding request: %v", err)
+			}
+			status, body := testRequestBody(t, "/work-orders/:id", UpdateWorkOrder, manager, fiber.MethodPut, "/work-orders/1", string(payload))
+			checkRejection(t, status, body)
+			if updates := recorder.Find(`UPDATE "work_orders"`); (len(updates) > 0) == tt.wantRejected {
+				t.Errorf("work order updated %d times, want rejected = %t", len(updates), tt.wantRejected)
+			}
+			if overrides := overridesLogged(recorder); overrides != tt.wantOverrides {
+				t.Errorf("%d deadline overrides logged, want %d", overrides, tt.wantOverrides)
+			}
+		})
+	}
+}
@@ -0,0 +1,450 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/events"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// defaultImportBatchSize is used when IMPORT_BATCH_SIZE is not a positive number
+const defaultImportBatchSize = 500
+
+// importColumns maps the accepted CSV header names to the work order field they fill
+var importColumns = map[string]string{
+	"product_name":        "product_name",
+	"product":             "product_name",
+	"quantity":            "quantity",
+	"target_quantity":     "target_quantity",
+	"target":              "target_quantity",
+	"unit":                "unit",
+	"uom":                 "unit",
+	"operator":            "operator",
+	"operator_id":         "operator",
+	"operator_username":   "operator",
+	"production_deadline": "production_deadline",
+	"deadline":            "production_deadline",
+}
+
+// ImportRowResult is the outcome of one CSV row of a work order import
+type ImportRowResult struct {
+	Row             int      `json:"row"` // line number in the CSV file, the header is line 1
+	WorkOrderID     uint     `json:"work_order_id,omitempty"`
+	WorkOrderNumber string   `json:"work_order_number,omitempty"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+// ImportWorkOrdersResponse represents the result of a work order import
+type ImportWorkOrdersResponse struct {
+	Error        bool              `json:"error"`
+	Msg          string            `json:"msg,omitempty"`
+	ValidateOnly bool              `json:"validate_only"`
+	Processed    int               `json:"processed"` // rows read from start_row on
+	Created      int               `json:"created"`
+	ResumeFrom   int               `json:"resume_from,omitempty"` // start_row that continues a partly created import
+	Rows         []ImportRowResult `json:"rows"`
+}
+
+// pendingImport is a validated row waiting for its batch to be created
+type pendingImport struct {
+	workOrder models.WorkOrder
+	result    int          // index in the response rows
+	event     events.Event // creation event, set once the work order is created
+}
+
+// @Summary Import work orders
+// @Description Create work orders from a CSV file with the columns product_name, quantity, target_quantity, operator (username or ID) and production_deadline (RFC 3339 or YYYY-MM-DD), and optionally unit (defaults to pcs). The file is read as a stream and created in batches of IMPORT_BATCH_SIZE rows, each batch in its own transaction. A batch with an invalid row is not created and stops the import; the batches before it stay and resume_from names the start_row to continue from once the file is fixed. (Production Manager only)
+// @Tags work-orders
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV file with a header row"
+// @Param validate_only query bool false "Only validate the rows without creating work orders"
+// @Param allow_past_deadline query bool false "Accept deadlines in the past"
+// @Param force query bool false "Override the operators' active order limit"
+// @Param start_row query int false "Skip the rows before this line of the file, the header is line 1"
+// @Success 200 {object} ImportWorkOrdersResponse "Validation result when validate_only is set"
+// @Success 201 {object} ImportWorkOrdersResponse
+// @Failure 400 {object} ImportWorkOrdersResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ImportWorkOrdersResponse
+// @Failure 500 {object} ImportWorkOrdersResponse
+// @Router /work-orders/import [post]
+func ImportWorkOrders(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+	validateOnly := c.QueryBool("validate_only", false)
+	allowPastDeadline := c.QueryBool("allow_past_deadline", false)
+	force := c.QueryBool("force", false)
+	startRow := c.QueryInt("start_row", 2)
+
+	batchSize := config.AppConfig.ImportBatchSize
+	if batchSize < 1 {
+		batchSize = defaultImportBatchSize
+	}
+	maxRows := config.AppConfig.ImportMaxRows
+
+	// Open the uploaded CSV file
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "CSV file is required in the file field",
+		})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error reading CSV file",
+		})
+	}
+	defer file.Close()
+
+	reader, err := newImportReader(file)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+			Error: true,
+			Msg:   err.Error(),
+		})
+	}
+
+	// Look up the operators once for all rows
+	var operators []models.User
+	if err := getDB(c).Where("role = ?", models.RoleOperator).Find(&operators).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching operators",
+		})
+	}
+	operatorsByID := make(map[uint]models.User, len(operators))
+	operatorsByName := make(map[string]models.User, len(operators))
+	for _, operator := range operators {
+		operatorsByID[operator.ID] = operator
+		operatorsByName[strings.ToLower(operator.Username)] = operator
+	}
+
+	response := ImportWorkOrdersResponse{
+		ValidateOnly: validateOnly,
+		Rows:         []ImportRowResult{},
+	}
+	products := map[string]bool{}
+	capacity := map[uint]bool{}
+	valid := true
+
+	// Rows are validated and created one batch at a time, only the work orders
+	// of the current batch and the per row results are kept in memory
+	batch := make([]pendingImport, 0, batchSize)
+	batchRows := 0
+	batchStart := 0
+	batchInvalidRow := 0
+	resumeFrom := 0
+	flush := func() error {
+		defer func() {
+			batch = batch[:0]
+			batchRows = 0
+			batchInvalidRow = 0
+		}()
+		if batchInvalidRow > 0 {
+			valid = false
+			if validateOnly {
+				return nil
+			}
+			resumeFrom = batchStart
+			msg := fmt.Sprintf("Row %d is invalid, no work orders were created", batchInvalidRow)
+			if response.Created > 0 {
+				msg = fmt.Sprintf("Row %d is invalid, the %d work orders before row %d were created, fix the file and resume with start_row=%d", batchInvalidRow, response.Created, batchStart, batchStart)
+			}
+			return fiber.NewError(fiber.StatusBadRequest, msg)
+		}
+		if validateOnly || len(batch) == 0 {
+			return nil
+		}
+
+		if err := createImportBatch(c, batch); err != nil {
+			log.Printf("Error importing work orders from %s at row %d: %v", fileHeader.Filename, batchStart, err)
+			resumeFrom = batchStart
+			// A concurrent create may have generated one of the same work order numbers
+			if database.IsUniqueViolation(err) {
+				return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Work order number already exists, resume with start_row=%d", batchStart))
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, fmt.Sprintf("Error importing work orders, resume with start_row=%d", batchStart))
+		}
+
+		// Report the generated numbers and run the usual follow-ups per work order
+		for _, pending := range batch {
+			response.Rows[pending.result].WorkOrderID = pending.workOrder.ID
+			response.Rows[pending.result].WorkOrderNumber = pending.workOrder.WorkOrderNumber
+			products[pending.workOrder.ProductName] = true
+			announceEvent(c, pending.event)
+		}
+		response.Created += len(batch)
+		log.Printf("Imported %d work orders from %s, %d rows read", response.Created, fileHeader.Filename, response.Processed)
+		return nil
+	}
+
+	var importErr error
+	for importErr == nil {
+		record, line, err := reader.Next()
+		if err == io.EOF {
+			importErr = flush()
+			break
+		}
+		if err != nil {
+			// Nothing of the current batch was created, or of the broken row when it starts a batch
+			resumeFrom = batchStart
+			var parseErr *csv.ParseError
+			if batchRows == 0 && errors.As(err, &parseErr) {
+				resumeFrom = parseErr.StartLine
+			}
+			importErr = fiber.NewError(fiber.StatusBadRequest, err.Error())
+			break
+		}
+		if line < startRow {
+			continue
+		}
+		if maxRows > 0 && response.Processed == maxRows {
+			if importErr = flush(); importErr == nil {
+				importErr = fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("CSV file has more than %d rows, resume with start_row=%d", maxRows, line))
+				resumeFrom = line
+			}
+			break
+		}
+		response.Processed++
+		if batchRows == 0 {
+			batchStart = line
+		}
+		batchRows++
+
+		workOrder, errs := parseImportRecord(record, operatorsByID, operatorsByName, allowPastDeadline)
+
+		// Check the operator's active order limit like CreateWorkOrder does
+		if len(errs) == 0 && !force {
+			atCapacity, checked := capacity[workOrder.OperatorID]
+			if !checked {
+				_, atCapacity, err = operatorAtCapacity(getDB(c), workOrder.OperatorID)
+				if err != nil {
+					resumeFrom = batchStart
+					importErr = fiber.NewError(fiber.StatusInternalServerError, "Error checking operator active orders")
+					break
+				}
+				capacity[workOrder.OperatorID] = atCapacity
+			}
+			if atCapacity {
+				errs = append(errs, "operator reached the maximum number of active work orders, use force to override")
+			}
+		}
+
+		response.Rows = append(response.Rows, ImportRowResult{Row: line, Errors: errs})
+		if len(errs) > 0 {
+			if batchInvalidRow == 0 {
+				batchInvalidRow = line
+			}
+		} else {
+			batch = append(batch, pendingImport{workOrder: workOrder, result: len(response.Rows) - 1})
+		}
+		if batchRows == batchSize {
+			importErr = flush()
+		}
+	}
+
+	for productName := range products {
+		refreshDailyCounter(getDB(c), productName, time.Now())
+	}
+	if response.Created > 0 {
+		if err := auditService.CreateLog(
+			getDB(c),
+			userID,
+			models.ActionCustom,
+			"WorkOrder",
+			0,
+			nil,
+			nil,
+			fmt.Sprintf("Imported %d work orders from %s", response.Created, fileHeader.Filename),
+		); err != nil {
+			log.Printf("Error creating audit log: %v", err)
+		}
+	}
+
+	if importErr != nil {
+		status := fiber.StatusInternalServerError
+		if e, ok := importErr.(*fiber.Error); ok {
+			status = e.Code
+		}
+		response.Error = true
+		response.Msg = importErr.Error()
+		if !validateOnly {
+			response.ResumeFrom = resumeFrom
+		}
+		return c.Status(status).JSON(response)
+	}
+	if response.Processed == 0 {
+		response.Error = true
+		response.Msg = "CSV file has no rows"
+		return c.Status(fiber.StatusBadRequest).JSON(response)
+	}
+	if !valid {
+		response.Error = true
+		response.Msg = "Some rows are invalid, no work orders were created"
+		return c.Status(fiber.StatusBadRequest).JSON(response)
+	}
+	if validateOnly {
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// importReader streams the rows of an import CSV file and maps them to work
+// order fields using the header row, without holding the whole file in memory
+type importReader struct {
+	reader *csv.Reader
+	fields []string
+}
+
+// newImportReader reads the header row and checks the required columns are present
+func newImportReader(r io.Reader) (*importReader, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV file: %v", err)
+	}
+
+	fields := make([]string, len(header))
+	present := map[string]bool{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		fields[i] = importColumns[name]
+		present[fields[i]] = true
+	}
+	for _, required := range []string{"product_name", "quantity", "target_quantity", "operator", "production_deadline"} {
+		if !present[required] {
+			return nil, fmt.Errorf("CSV header is missing the %s column", required)
+		}
+	}
+
+	return &importReader{reader: reader, fields: fields}, nil
+}
+
+// Next returns the next row and the line of the file it starts on, or io.EOF after the last row
+func (r *importReader) Next() (map[string]string, int, error) {
+	values, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, 0, io.EOF
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid CSV file: %w", err)
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	record := map[string]string{}
+	for i, value := range values {
+		if r.fields[i] != "" {
+			record[r.fields[i]] = strings.TrimSpace(value)
+		}
+	}
+	return record, line, nil
+}
+
+// createImportBatch creates a batch of imported work orders in one transaction, together
+// with the outbox jobs of their creation events. Numbers are generated against the
+// transaction so every row gets the next number after the previous one.
+func createImportBatch(c *fiber.Ctx, batch []pendingImport) error {
+	return getDB(c).Transaction(func(tx *gorm.DB) error {
+		for i := range batch {
+			workOrder := &batch[i].workOrder
+			workOrder.WorkOrderNumber = generateWorkOrderNumber(tx)
+			if err := tx.Create(workOrder).Error; err != nil {
+				return err
+			}
+			if err := createStatusHistory(tx, *workOrder, "Imported from CSV"); err != nil {
+				return err
+			}
+			batch[i].event = newEvent(c, events.WorkOrderCreated, *workOrder, "")
+			if err := enqueueEvent(tx, batch[i].event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// parseImportRecord validates a CSV row and builds the pending work order it describes
+func parseImportRecord(record map[string]string, operatorsByID map[uint]models.User, operatorsByName map[string]models.User, allowPastDeadline bool) (models.WorkOrder, []string) {
+	var errs []string
+	workOrder := models.WorkOrder{Status: models.StatusPending}
+
+	workOrder.ProductName = record["product_name"]
+	if workOrder.ProductName == "" {
+		errs = append(errs, "product_name is required")
+	}
+
+	quantity, err := strconv.Atoi(record["quantity"])
+	if err != nil || quantity < 0 {
+		errs = append(errs, "quantity must be a non-negative integer")
+	}
+	workOrder.Quantity = quantity
+
+	target, err := strconv.Atoi(record["target_quantity"])
+	if err != nil || target < 1 {
+		errs = append(errs, "target_quantity must be a positive integer")
+	}
+	workOrder.TargetQuantity = target
+
+	workOrder.Unit = workOrderUnit(record["unit"])
+	if len(workOrder.Unit) > 20 {
+		errs = append(errs, "unit must be at most 20 characters")
+	}
+
+	// The operator can be given by ID or username
+	operatorRef := record["operator"]
+	operator, found := operatorsByName[strings.ToLower(operatorRef)]
+	if id, err := strconv.ParseUint(operatorRef, 10, 64); err == nil {
+		operator, found = operatorsByID[uint(id)]
+	}
+	if !found {
+		errs = append(errs, fmt.Sprintf("operator %q not found", operatorRef))
+	}
+	workOrder.OperatorID = operator.ID
+
+	deadline, err := parseImportDeadline(record["production_deadline"])
+	if err != nil {
+		errs = append(errs, "production_deadline must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	} else if !allowPastDeadline && !deadline.After(time.Now().Add(-deadlineGracePeriod)) {
+		errs = append(errs, "production_deadline must be in the future, use allow_past_deadline to import past work orders")
+	}
+	workOrder.ProductionDeadline = deadline
+
+	return workOrder, errs
+}
+
+// parseImportDeadline parses an RFC 3339 timestamp, or a date meaning the end of that day
+func parseImportDeadline(value string) (time.Time, error) {
+	if deadline, err := time.Parse(time.RFC3339, value); err == nil {
+		return deadline, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return date.Add(24*time.Hour - time.Second), nil
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// generatedCSV is an import file of rows rows produced while it is read, counting
+// how much of it was read
+type generatedCSV struct {
+	rows     int
+	deadline string
+	next     int // rows produced, the header is row 0
+	pending  []byte
+	read     int
+}
+
+func (g *generatedCSV) Read(p []byte) (int, error) {
+	for len(g.pending) == 0 {
+		switch {
+		case g.next == 0:
+			g.pending = []byte("product_name,quantity,target_quantity,operator,production_deadline\n")
+		case g.next <= g.rows:
+			g.pending = []byte(fmt.Sprintf("Widget %d,0,10,operator,%s\n", g.next, g.deadline))
+		default:
+			return 0, io.EOF
+		}
+		g.next++
+	}
+	n := copy(p, g.pending)
+	g.pending = g.pending[n:]
+	g.read += n
+	return n, nil
+}
+
+func TestImportReaderStreamsTheFile(t *testing.T) {
+	file := &generatedCSV{rows: 1_000_000, deadline: "2030-01-31"}
+	reader, err := newImportReader(file)
+	if err != nil {
+		t.Fatalf("reading header: %v", err)
+	}
+
+	for i := 0; i < 250; i++ {
+		record, line, err := reader.Next()
+		if err != nil {
+			t.Fatalf("reading row %d: %v", i+2, err)
+		}
+		if line != i+2 || record["product_name"] != fmt.Sprintf("Widget %d", i+1) {
+			t.Fatalf("row %d = %v at line %d", i+2, record, line)
+		}
+	}
+
+	// A row is about 40 bytes, the reader only buffers ahead of the rows it returned
+	if file.read > 64<<10 {
+		t.Errorf("read %d bytes of the file for 250 rows", file.read)
+	}
+}
+
+// importedBatches records the transactions an import creates its work orders in
+type importedBatches struct {
+	mu      sync.Mutex
+	open    bool
+	current int
+	sizes   []int // work orders created per transaction that created any
+	outside int   // work orders created outside a transaction
+	nextID  int64
+}
+
+func (b *importedBatches) respond(stmt dbtest.Statement) dbtest.Rows {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case stmt.SQL == "BEGIN":
+		b.open, b.current = true, 0
+	case stmt.SQL == "COMMIT":
+		// Other transactions of the import, such as the daily counters, create no work orders
+		if b.current > 0 {
+			b.sizes = append(b.sizes, b.current)
+		}
+		b.open = false
+	case strings.HasPrefix(stmt.SQL, `INSERT INTO "work_orders"`):
+		if !b.open {
+			b.outside++
+		}
+		b.current++
+		b.nextID++
+		return dbtest.Rows{Columns: []string{"id"}, Values: [][]driver.Value{{b.nextID}}}
+	case strings.HasPrefix(stmt.SQL, `SELECT * FROM "users"`):
+		return dbtest.Rows{
+			Columns: []string{"id", "plant_id", "username", "role"},
+			Values:  [][]driver.Value{{int64(2), int64(1), "operator", string(models.RoleOperator)}},
+		}
+	}
+	return dbtest.Rows{}
+}
+
+func TestImportLargeFileInBatches(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.ImportBatchSize = 100
+	config.AppConfig.ImportMaxRows = 0
+	config.AppConfig.MaxActiveOrdersPerOperator = 0
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	const rows = 2050
+	batches := &importedBatches{}
+	recorder := useScriptedDB(t, batches.respond)
+
+	var upload bytes.Buffer
+	form := multipart.NewWriter(&upload)
+	part, err := form.CreateFormFile("file", "orders.csv")
+	if err != nil {
+		t.Fatalf("creating form file: %v", err)
+	}
+	deadline := time.Now().AddDate(0, 1, 0).Format(time.DateOnly)
+	if _, err := io.Copy(part, &generatedCSV{rows: rows, deadline: deadline}); err != nil {
+		t.Fatalf("writing form file: %v", err)
+	}
+	form.Close()
+
+	req := httptest.NewRequest(fiber.MethodPost, "/work-orders/import", &upload)
+	req.Header.Set(fiber.HeaderContentType, form.FormDataContentType())
+	resp, err := testApp("/work-orders/import", ImportWorkOrders, testUser{1, models.RoleProductionManager, 0}, fiber.MethodPost).Test(req, -1)
+	if err != nil {
+		t.Fatalf("serving import: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var response ImportWorkOrdersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusCreated || response.Created != rows || response.Processed != rows {
+		t.Fatalf("status = %d, created %d of %d rows: %s", resp.StatusCode, response.Created, response.Processed, response.Msg)
+	}
+
+	// Every batch is committed on its own, holding at most IMPORT_BATCH_SIZE work orders
+	if batches.outside != 0 {
+		t.Errorf("%d work orders created outside a batch transaction", batches.outside)
+	}
+	if len(batches.sizes) != 21 {
+		t.Errorf("created in %d transactions, want 21: %v", len(batches.sizes), batches.sizes)
+	}
+	for i, size := range batches.sizes {
+		want := 100
+		if i == len(batches.sizes)-1 {
+			want = rows % 100
+		}
+		if size != want {
+			t.Errorf("transaction %d created %d work orders, want %d", i+1, size, want)
+		}
+	}
+
+	// The operators are looked up once, not per row
+	if lookups := recorder.Find(`SELECT * FROM "users" WHERE role = $1`); len(lookups) != 1 {
+		t.Errorf("operators looked up %d times, want once", len(lookups))
+	}
+}
@@ -1,8 +1,16 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
 	"github.com/dawamr/work-order-system-go/database"
 	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/events"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -10,30 +18,76 @@ import (
 // CreateProgressRequest represents the create progress request body
 type CreateProgressRequest struct {
 	ProgressDesc     string `json:"progress_description" validate:"required"`
-	ProgressQuantity int    `json:"progress_quantity" validate:"required,min=0"`
+	ProgressQuantity int    `json:"progress_quantity" validate:"required,min=1"` // at least MIN_PROGRESS_QUANTITY
 }
 
 // ProgressResponse represents a progress entry response
 type ProgressResponse struct {
-	Error    bool                    `json:"error"`
-	Progress models.WorkOrderProgress `json:"progress"`
+	Error    bool        `json:"error"`
+	Progress ProgressDTO `json:"progress"`
 }
 
 // ProgressListResponse represents a list of progress entries
 type ProgressListResponse struct {
-	Error    bool                      `json:"error"`
-	Progress []models.WorkOrderProgress `json:"progress"`
+	Error    bool          `json:"error"`
+	Progress []ProgressDTO `json:"progress"`
+}
+
+// ProgressFeedEntry is a progress entry of the all-orders progress feed
+type ProgressFeedEntry struct {
+	ProgressDTO
+	WorkOrderNumber string `json:"work_order_number"`
+	ProductName     string `json:"product_name"`
+}
+
+// ProgressFeedResponse represents a page of the all-orders progress feed
+type ProgressFeedResponse struct {
+	Error      bool                `json:"error"`
+	Progress   []ProgressFeedEntry `json:"progress"`
+	Pagination Pagination          `json:"pagination"`
 }
 
 // StatusHistoryResponse represents a list of status history entries
 type StatusHistoryResponse struct {
-	Error   bool                          `json:"error"`
-	History []models.WorkOrderStatusHistory `json:"history"`
+	Error   bool               `json:"error"`
+	History []StatusHistoryDTO `json:"history"`
+}
+
+// ProgressLimitErrorResponse represents an error response when progress would exceed the target quantity
+type ProgressLimitErrorResponse struct {
+	Error     bool   `json:"error"`
+	Msg       string `json:"msg"`
+	Remaining int    `json:"remaining"` // quantity that can still be reported
+}
+
+// StatusPeriod is one uninterrupted stretch a work order spent in a status
+type StatusPeriod struct {
+	Status          models.WorkOrderStatus `json:"status"`
+	StartedAt       time.Time              `json:"started_at"`
+	EndedAt         *time.Time             `json:"ended_at"` // nil while the work order is still in the status
+	DurationSeconds int64                  `json:"duration_seconds"`
+}
+
+// StatusDuration is the total time a work order spent in a status
+type StatusDuration struct {
+	Status          models.WorkOrderStatus `json:"status"`
+	DurationSeconds int64                  `json:"duration_seconds"`
+	Duration        string                 `json:"duration"` // e.g. 26h3m0s
+}
+
+// StatusDurationsResponse represents the time a work order spent in each status
+type StatusDurationsResponse struct {
+	Error   bool             `json:"error"`
+	Periods []StatusPeriod   `json:"periods"`
+	Totals  []StatusDuration `json:"totals"` // in the order the statuses were first entered
 }
 
+// autoStartNote is the status history note of a work order started by its first progress entry
+const autoStartNote = "Started by the first progress entry"
+
 // CreateWorkOrderProgress creates a new progress entry for a work order
 // @Summary Create progress entry
-// @Description Create a new progress entry for a work order
+// @Description Create a new progress entry for a work order. The work order must be in progress; with AUTO_START_ON_PROGRESS a pending work order is moved to in_progress first, within the operator's active order limit.
 // @Tags progress
 // @Accept json
 // @Produce json
@@ -41,18 +95,25 @@ type StatusHistoryResponse struct {
 // @Param id path int true "Work order ID"
 // @Param request body CreateProgressRequest true "Progress details"
 // @Success 201 {object} ProgressResponse
-// @Failure 400 {object} ErrorResponse
+// @Failure 400 {object} ProgressLimitErrorResponse "Invalid request, progress below MIN_PROGRESS_QUANTITY or exceeding the target quantity"
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} CapacityErrorResponse "Auto-start would exceed MAX_ACTIVE_ORDERS_PER_OPERATOR"
 // @Router /work-orders/{id}/progress [post]
 func CreateWorkOrderProgress(c *fiber.Ctx) error {
 	// Get user ID and role from context
-	userID := c.Locals("user_id").(uint)
-	role := c.Locals("role").(models.Role)
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
 
 	// Get work order ID from URL
-	workOrderID := c.Params("id")
+	workOrderID, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
 
 	// Parse request body
 	var req CreateProgressRequest
@@ -65,7 +126,7 @@ func CreateWorkOrderProgress(c *fiber.Ctx) error {
 
 	// Get work order from database
 	var workOrder models.WorkOrder
-	result := database.DB.First(&workOrder, workOrderID)
+	result := getDB(c).First(&workOrder, workOrderID)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
@@ -87,33 +148,228 @@ func CreateWorkOrderProgress(c *fiber.Ctx) error {
 		})
 	}
 
-	// Check if work order is in progress
-	if workOrder.Status != models.StatusInProgress {
+	// Check if work order is in progress, with AUTO_START_ON_PROGRESS the first
+	// progress entry on a pending order starts it instead
+	autoStart := workOrder.Status == models.StatusPending && config.AppConfig.AutoStartOnProgress &&
+		models.CanTransition(models.StatusPending, models.StatusInProgress, role)
+	if workOrder.Status != models.StatusInProgress && !autoStart {
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Work order must be in progress to add progress updates",
 		})
 	}
 
+	// Empty or negative entries would flatten or lower the burndown
+	if req.ProgressQuantity < config.AppConfig.MinProgressQuantity {
+		return progressQuantityError(c, fmt.Sprintf("progress_quantity must be at least %d", config.AppConfig.MinProgressQuantity),
+			workOrder.TargetQuantity-workOrder.Quantity)
+	}
+
+	// Cumulative progress may not exceed the target quantity
+	if workOrder.Quantity+req.ProgressQuantity > workOrder.TargetQuantity {
+		return progressLimitError(c, workOrder.TargetQuantity-workOrder.Quantity)
+	}
+
+	// Starting work counts against the operator's active order limit
+	if autoStart {
+		activeOrders, atCapacity, err := operatorAtCapacity(getDB(c), workOrder.OperatorID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error checking operator active orders",
+			})
+		}
+		if atCapacity {
+			return capacityError(c, activeOrders)
+		}
+	}
+
 	// Create progress entry
 	progress := models.WorkOrderProgress{
 		WorkOrderID:      workOrder.ID,
 		ProgressDesc:     req.ProgressDesc,
 		ProgressQuantity: req.ProgressQuantity,
+		ReportedByID:     &userID,
 	}
 
-	// Save progress to database
-	if err := database.DB.Create(&progress).Error; err != nil {
+	// Save progress and accumulate the produced quantity in one transaction.
+	// The increment is done in SQL so concurrent progress entries never
+	// overwrite each other with a stale quantity, and only applies while
+	// the total stays within the target.
+	errOverTarget := errors.New("progress exceeds target quantity")
+	errStatusChanged := errors.New("work order status changed")
+	startedOrder := workOrder
+	startedOrder.Status = models.StatusInProgress
+	err := getDB(c).Transaction(func(tx *gorm.DB) error {
+		// Start the pending order, unless a concurrent request changed its status
+		if autoStart {
+			result := tx.Model(&models.WorkOrder{}).
+				Where("id = ? AND status = ?", workOrder.ID, models.StatusPending).
+				Update("status", models.StatusInProgress)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errStatusChanged
+			}
+			if err := createStatusHistory(tx, startedOrder, autoStartNote); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&progress).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.WorkOrder{}).
+			Where("id = ? AND quantity + ? <= target_quantity", workOrder.ID, req.ProgressQuantity).
+			Update("quantity", gorm.Expr("quantity + ?", req.ProgressQuantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOverTarget
+		}
+		return nil
+	})
+	if err == errOverTarget {
+		// A concurrent entry used up the remaining quantity
+		var current models.WorkOrder
+		if err := getDB(c).Select("quantity", "target_quantity").First(&current, workOrder.ID).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error fetching work order",
+			})
+		}
+		return progressLimitError(c, current.TargetQuantity-current.Quantity)
+	}
+	if err == errStatusChanged {
+		return statusConflictError(c, workOrder.ID)
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
 			Msg:   "Error creating progress entry",
 		})
 	}
+	refreshDailyCounter(getDB(c), workOrder.ProductName, progress.CreatedAt)
+
+	if autoStart {
+		if err := auditService.CreateLog(
+			getDB(c),
+			actorID(c),
+			models.ActionUpdate,
+			"WorkOrder",
+			workOrder.ID,
+			workOrder,
+			startedOrder,
+			statusChangeNote(workOrder.WorkOrderNumber, workOrder.Status, startedOrder.Status, autoStartNote),
+		); err != nil {
+			log.Printf("Error creating audit log: %v", err)
+		}
+		if err := publishEvent(c, events.StatusChanged, startedOrder, workOrder.Status); err != nil {
+			return eventError(c, err)
+		}
+	}
 
 	// Return progress
 	return c.Status(fiber.StatusCreated).JSON(ProgressResponse{
 		Error:    false,
-		Progress: progress,
+		Progress: toProgressDTO(progress),
+	})
+}
+
+// ApproveWorkOrderProgress signs off a progress entry of a work order
+// @Summary Approve work order progress
+// @Description Sign off a logged progress entry. With the progress_approval feature switched on, a work order can only be completed once all its progress is approved. Approving an approved entry keeps the first approval. (Production Manager only)
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Param progress_id path int true "Progress entry ID"
+// @Success 200 {object} ProgressResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /work-orders/{id}/progress/{progress_id}/approve [post]
+func ApproveWorkOrderProgress(c *fiber.Ctx) error {
+	userID, ok := getUserID(c)
+	if !ok {
+		return unauthorizedError(c)
+	}
+
+	id, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+	progressID, ok := idParam(c, "progress_id")
+	if !ok {
+		return invalidIDError(c, "progress_id")
+	}
+
+	// Look the work order up first so the entry is found within the request's plant
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	var progress models.WorkOrderProgress
+	result = getDB(c).Where("work_order_id = ?", workOrder.ID).First(&progress, progressID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Progress entry not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching progress entry",
+		})
+	}
+
+	if progress.ApprovedAt == nil {
+		now := time.Now()
+		progress.ApprovedByID = &userID
+		progress.ApprovedAt = &now
+		if err := getDB(c).Model(&progress).Updates(map[string]interface{}{
+			"approved_by_id": userID,
+			"approved_at":    now,
+		}).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error approving progress entry",
+			})
+		}
+
+		if err := auditService.CreateLog(
+			getDB(c),
+			userID,
+			models.ActionCustom,
+			"WorkOrder",
+			workOrder.ID,
+			nil,
+			nil,
+			fmt.Sprintf("Progress entry %d approved", progress.ID),
+		); err != nil {
+			log.Printf("Error creating audit log: %v", err)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ProgressResponse{
+		Error:    false,
+		Progress: toProgressDTO(progress),
 	})
 }
 
@@ -126,21 +382,28 @@ func CreateWorkOrderProgress(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Work order ID"
 // @Success 200 {object} ProgressListResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /work-orders/{id}/progress [get]
 func GetWorkOrderProgress(c *fiber.Ctx) error {
 	// Get user ID and role from context
-	userID := c.Locals("user_id").(uint)
-	role := c.Locals("role").(models.Role)
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
 
 	// Get work order ID from URL
-	workOrderID := c.Params("id")
+	workOrderID, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
 
 	// Get work order from database
 	var workOrder models.WorkOrder
-	result := database.DB.First(&workOrder, workOrderID)
+	result := getDB(c).First(&workOrder, workOrderID)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
@@ -164,7 +427,7 @@ func GetWorkOrderProgress(c *fiber.Ctx) error {
 
 	// Get progress entries
 	var progress []models.WorkOrderProgress
-	result = database.DB.Where("work_order_id = ?", workOrder.ID).Order("created_at DESC").Find(&progress)
+	result = preloadUnscoped(getDB(c), "ReportedBy").Where("work_order_id = ?", workOrder.ID).Order("created_at DESC, id DESC").Find(&progress)
 	if result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
@@ -175,7 +438,116 @@ func GetWorkOrderProgress(c *fiber.Ctx) error {
 	// Return progress entries
 	return c.Status(fiber.StatusOK).JSON(ProgressListResponse{
 		Error:    false,
-		Progress: progress,
+		Progress: toProgressDTOs(progress),
+	})
+}
+
+// GetProgressFeed gets the progress entries of all work orders
+// @Summary Get progress feed
+// @Description Get the progress entries of all work orders, newest first, with their work order number and product (Production Manager only)
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param operator_id query int false "Filter by the reporting operator; entries without a recorded reporter match the assigned operator"
+// @Param product_name query string false "Filter by product name (partial match)"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} ProgressFeedResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /progress [get]
+func GetProgressFeed(c *fiber.Ctx) error {
+	// Get query parameters
+	operatorID := c.QueryInt("operator_id", 0)
+	productName := c.Query("product_name")
+	page := c.QueryInt("page", 1)
+	limit := pageLimit(c)
+
+	// Calculate offset
+	offset := (page - 1) * limit
+
+	// Only progress of work orders that still exist, in the caller's plant
+	query := getDB(c).Model(&models.WorkOrderProgress{}).
+		Joins("JOIN work_orders ON work_orders.id = work_order_progresses.work_order_id AND work_orders.deleted_at IS NULL")
+	if plantID, ok := database.PlantFromContext(c.UserContext()); ok {
+		query = query.Where("work_orders.plant_id = ?", plantID)
+	}
+
+	// Apply filters if provided
+	if operatorID > 0 {
+		// Entries logged before the reporter was recorded count for the assigned operator
+		query = query.Where("COALESCE(work_order_progresses.reported_by_id, work_orders.operator_id) = ?", operatorID)
+	}
+	if productName != "" {
+		query = query.Where("UPPER(work_orders.product_name) LIKE ?", "%"+strings.ToUpper(productName)+"%")
+	}
+	query = applyDateRange(query, "work_order_progresses.created_at", c.Query("start_date"), c.Query("end_date"))
+
+	// Get total count
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching progress entries",
+		})
+	}
+
+	// Fetch progress entries with pagination
+	var progress []models.WorkOrderProgress
+	if err := preloadUnscoped(query, "ReportedBy").
+		Offset(offset).
+		Limit(limit).
+		Order("work_order_progresses.created_at DESC, work_order_progresses.id DESC").
+		Find(&progress).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching progress entries",
+		})
+	}
+
+	// Look up the work orders of the page
+	workOrderIDs := make([]uint, 0, len(progress))
+	for _, entry := range progress {
+		workOrderIDs = append(workOrderIDs, entry.WorkOrderID)
+	}
+	var workOrders []models.WorkOrder
+	if len(workOrderIDs) > 0 {
+		if err := getDB(c).Select("id", "work_order_number", "product_name").Find(&workOrders, workOrderIDs).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Error fetching work orders",
+			})
+		}
+	}
+	workOrdersByID := make(map[uint]models.WorkOrder, len(workOrders))
+	for _, workOrder := range workOrders {
+		workOrdersByID[workOrder.ID] = workOrder
+	}
+
+	entries := make([]ProgressFeedEntry, 0, len(progress))
+	for _, entry := range progress {
+		workOrder := workOrdersByID[entry.WorkOrderID]
+		entries = append(entries, ProgressFeedEntry{
+			ProgressDTO:     toProgressDTO(entry),
+			WorkOrderNumber: workOrder.WorkOrderNumber,
+			ProductName:     workOrder.ProductName,
+		})
+	}
+
+	// Return progress feed
+	return c.Status(fiber.StatusOK).JSON(ProgressFeedResponse{
+		Error:    false,
+		Progress: entries,
+		Pagination: Pagination{
+			Total: count,
+			Page:  page,
+			Limit: limit,
+			Pages: (count + int64(limit) - 1) / int64(limit),
+		},
 	})
 }
 
@@ -188,21 +560,28 @@ func GetWorkOrderProgress(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Work order ID"
 // @Success 200 {object} StatusHistoryResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /work-orders/{id}/history [get]
 func GetWorkOrderStatusHistory(c *fiber.Ctx) error {
 	// Get user ID and role from context
-	userID := c.Locals("user_id").(uint)
-	role := c.Locals("role").(models.Role)
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
 
 	// Get work order ID from URL
-	workOrderID := c.Params("id")
+	workOrderID, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
 
 	// Get work order from database
 	var workOrder models.WorkOrder
-	result := database.DB.First(&workOrder, workOrderID)
+	result := getDB(c).First(&workOrder, workOrderID)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
@@ -226,7 +605,7 @@ func GetWorkOrderStatusHistory(c *fiber.Ctx) error {
 
 	// Get status history
 	var history []models.WorkOrderStatusHistory
-	result = database.DB.Where("work_order_id = ?", workOrder.ID).Order("created_at ASC").Find(&history)
+	result = getDB(c).Where("work_order_id = ?", workOrder.ID).Order("created_at ASC, id ASC").Find(&history)
 	if result.Error != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 			Error: true,
@@ -237,6 +616,143 @@ func GetWorkOrderStatusHistory(c *fiber.Ctx) error {
 	// Return status history
 	return c.Status(fiber.StatusOK).JSON(StatusHistoryResponse{
 		Error:   false,
-		History: history,
+		History: toStatusHistoryDTOs(history),
 	})
 }
+
+// GetWorkOrderStatusDurations gets the time a work order spent in each status
+// @Summary Get work order status durations
+// @Description Get how long a work order spent in each status, computed from its status history. The current status counts up to now unless it is terminal.
+// @Tags progress
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Work order ID"
+// @Success 200 {object} StatusDurationsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /work-orders/{id}/status-durations [get]
+func GetWorkOrderStatusDurations(c *fiber.Ctx) error {
+	// Get user ID and role from context
+	userID, hasUser := getUserID(c)
+	role, hasRole := getRole(c)
+	if !hasUser || !hasRole {
+		return unauthorizedError(c)
+	}
+
+	// Get work order ID from URL
+	workOrderID, ok := idParam(c, "id")
+	if !ok {
+		return invalidIDError(c, "id")
+	}
+
+	// Get work order from database
+	var workOrder models.WorkOrder
+	result := getDB(c).First(&workOrder, workOrderID)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+				Error: true,
+				Msg:   "Work order not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching work order",
+		})
+	}
+
+	// Check if user is the assigned operator or a production manager
+	if role == models.RoleOperator && workOrder.OperatorID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "You are not assigned to this work order",
+		})
+	}
+
+	// Get status history
+	var history []models.WorkOrderStatusHistory
+	result = getDB(c).Where("work_order_id = ?", workOrder.ID).Order("created_at ASC, id ASC").Find(&history)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: true,
+			Msg:   "Error fetching status history",
+		})
+	}
+
+	periods, totals := statusDurations(history, time.Now())
+
+	return c.Status(fiber.StatusOK).JSON(StatusDurationsResponse{
+		Error:   false,
+		Periods: periods,
+		Totals:  totals,
+	})
+}
+
+// progressLimitError responds to progress that would take the produced quantity over the target
+func progressLimitError(c *fiber.Ctx, remaining int) error {
+	return progressQuantityError(c, "Progress exceeds the target quantity", remaining)
+}
+
+// progressQuantityError responds to a rejected progress quantity with the quantity that can still be reported
+func progressQuantityError(c *fiber.Ctx, msg string, remaining int) error {
+	if remaining < 0 {
+		remaining = 0
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(ProgressLimitErrorResponse{
+		Error:     true,
+		Msg:       fmt.Sprintf("%s, only %d remaining", msg, remaining),
+		Remaining: remaining,
+	})
+}
+
+// statusDurations turns a chronological status history into the periods spent in
+// each status and their totals. Each entry lasts until the next one; the last one
+// lasts until now unless its status is terminal, i.e. has no transitions out of it.
+func statusDurations(history []models.WorkOrderStatusHistory, now time.Time) ([]StatusPeriod, []StatusDuration) {
+	periods := []StatusPeriod{}
+	for i, entry := range history {
+		// Consecutive entries with the same status (e.g. quantity updates) extend the period
+		if len(periods) > 0 && periods[len(periods)-1].Status == entry.Status {
+			continue
+		}
+
+		period := StatusPeriod{Status: entry.Status, StartedAt: entry.CreatedAt}
+		end := now
+		for _, next := range history[i+1:] {
+			if next.Status != entry.Status {
+				endedAt := next.CreatedAt
+				period.EndedAt = &endedAt
+				end = endedAt
+				break
+			}
+		}
+		if period.EndedAt == nil && len(models.StatusTransitions[entry.Status]) == 0 {
+			// A terminal status does not accumulate time
+			endedAt := entry.CreatedAt
+			period.EndedAt = &endedAt
+			end = endedAt
+		}
+		period.DurationSeconds = int64(end.Sub(entry.CreatedAt).Seconds())
+		periods = append(periods, period)
+	}
+
+	totals := []StatusDuration{}
+	index := map[models.WorkOrderStatus]int{}
+	for _, period := range periods {
+		i, ok := index[period.Status]
+		if !ok {
+			i = len(totals)
+			index[period.Status] = i
+			totals = append(totals, StatusDuration{Status: period.Status})
+		}
+		totals[i].DurationSeconds += period.DurationSeconds
+	}
+	for i := range totals {
+		totals[i].Duration = (time.Duration(totals[i].DurationSeconds) * time.Second).String()
+	}
+
+	return periods, totals
+}
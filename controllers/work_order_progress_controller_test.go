@@ -0,0 +1,373 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// producedQuantity is work order 1 of operator 2 in a database applying the
+// conditional quantity increment atomically. Lookups of the order read the
+// quantity as read, as a request racing the others would, while the re-read
+// after a refused increment sees the current quantity.
+type producedQuantity struct {
+	mu       sync.Mutex
+	read     int64
+	quantity int64
+	target   int64
+}
+
+// respond is the dbtest.Responder of the database
+func (p *producedQuantity) respond(stmt dbtest.Statement) dbtest.Rows {
+	switch {
+	case strings.HasPrefix(stmt.SQL, `SELECT "quantity","target_quantity" FROM "work_orders"`):
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return dbtest.Rows{Columns: []string{"quantity", "target_quantity"}, Values: [][]driver.Value{{p.quantity, p.target}}}
+	case strings.HasPrefix(stmt.SQL, "SELECT") && strings.Contains(stmt.SQL, `FROM "work_orders"`):
+		return dbtest.Rows{
+			Columns: []string{"id", "operator_id", "status", "quantity", "target_quantity"},
+			Values:  [][]driver.Value{{int64(1), int64(2), string(models.StatusInProgress), p.read, p.target}},
+		}
+	case strings.HasPrefix(stmt.SQL, `INSERT INTO "work_order_progresses"`):
+		return dbtest.Rows{Columns: []string{"id"}, Values: [][]driver.Value{{int64(1)}}}
+	case strings.HasPrefix(stmt.SQL, `UPDATE "work_orders" SET "quantity"=quantity + $1`):
+		increment := stmt.Args[0].(int64)
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.quantity+increment > p.target {
+			return dbtest.Rows{}
+		}
+		p.quantity += increment
+		return dbtest.Rows{RowsAffected: 1}
+	}
+	return dbtest.Rows{}
+}
+
+func TestStatusDurations(t *testing.T) {
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	at := func(hours float64) time.Time { return start.Add(time.Duration(hours * float64(time.Hour))) }
+	entry := func(status models.WorkOrderStatus, hours float64) models.WorkOrderStatusHistory {
+		return models.WorkOrderStatusHistory{Status: status, CreatedAt: at(hours)}
+	}
+	ended := func(hours float64) *time.Time {
+		endedAt := at(hours)
+		return &endedAt
+	}
+	now := at(10)
+
+	tests := []struct {
+		name        string
+		history     []models.WorkOrderStatusHistory
+		wantPeriods []StatusPeriod
+		wantTotals  []StatusDuration
+	}{
+		{
+			name:        "no history",
+			wantPeriods: []StatusPeriod{},
+			wantTotals:  []StatusDuration{},
+		},
+		{
+			name:        "still pending lasts until now",
+			history:     []models.WorkOrderStatusHistory{entry(models.StatusPending, 0)},
+			wantPeriods: []StatusPeriod{{models.StatusPending, at(0), nil, 36000}},
+			wantTotals:  []StatusDuration{{models.StatusPending, 36000, "10h0m0s"}},
+		},
+		{
+			name: "completed order stops accumulating",
+			history: []models.WorkOrderStatusHistory{
+				entry(models.StatusPending, 0),
+				entry(models.StatusInProgress, 1),
+				entry(models.StatusCompleted, 4),
+			},
+			wantPeriods: []StatusPeriod{
+				{models.StatusPending, at(0), ended(1), 3600},
+				{models.StatusInProgress, at(1), ended(4), 10800},
+				{models.StatusCompleted, at(4), ended(4), 0},
+			},
+			wantTotals: []StatusDuration{
+				{models.StatusPending, 3600, "1h0m0s"},
+				{models.StatusInProgress, 10800, "3h0m0s"},
+				{models.StatusCompleted, 0, "0s"},
+			},
+		},
+		{
+			name: "quantity updates extend the period",
+			history: []models.WorkOrderStatusHistory{
+				entry(models.StatusInProgress, 0),
+				entry(models.StatusInProgress, 2),
+				entry(models.StatusInProgress, 3),
+				entry(models.StatusOnHold, 5),
+			},
+			wantPeriods: []StatusPeriod{
+				{models.StatusInProgress, at(0), ended(5), 18000},
+				{models.StatusOnHold, at(5), nil, 18000},
+			},
+			wantTotals: []StatusDuration{
+				{models.StatusInProgress, 18000, "5h0m0s"},
+				{models.StatusOnHold, 18000, "5h0m0s"},
+			},
+		},
+		{
+			name: "returning to a status adds to its total",
+			history: []models.WorkOrderStatusHistory{
+				entry(models.StatusInProgress, 0),
+				entry(models.StatusOnHold, 2),
+				entry(models.StatusInProgress, 2.5),
+				entry(models.StatusCompleted, 6),
+			},
+			wantPeriods: []StatusPeriod{
+				{models.StatusInProgress, at(0), ended(2), 7200},
+				{models.StatusOnHold, at(2), ended(2.5), 1800},
+				{models.StatusInProgress, at(2.5), ended(6), 12600},
+				{models.StatusCompleted, at(6), ended(6), 0},
+			},
+			wantTotals: []StatusDuration{
+				{models.StatusInProgress, 19800, "5h30m0s"},
+				{models.StatusOnHold, 1800, "30m0s"},
+				{models.StatusCompleted, 0, "0s"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods, totals := statusDurations(tt.history, now)
+			if !reflect.DeepEqual(periods, tt.wantPeriods) {
+				t.Errorf("periods = %+v, want %+v", periods, tt.wantPeriods)
+			}
+			if !reflect.DeepEqual(totals, tt.wantTotals) {
+				t.Errorf("totals = %+v, want %+v", totals, tt.wantTotals)
+			}
+		})
+	}
+}
+
+func TestCreateWorkOrderProgressConcurrently(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.MinProgressQuantity = 1
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	tests := []struct {
+		name         string
+		target       int64
+		requests     int
+		each         int
+		wantCreated  int
+		wantQuantity int64
+	}{
+		{"every entry fits the target", 100, 20, 5, 20, 100},
+		{"entries beyond the target are refused", 20, 10, 3, 6, 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &producedQuantity{target: tt.target}
+			recorder := useScriptedDB(t, db.respond)
+			app := testApp("/work-orders/:id/progress", CreateWorkOrderProgress, testUser{2, models.RoleOperator, 0}, fiber.MethodPost)
+
+			var wg sync.WaitGroup
+			statuses := make(chan int, tt.requests)
+			for i := 0; i < tt.requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					body := fmt.Sprintf(`{"progress_description":"batch","progress_quantity":%d}`, tt.each)
+					resp, err := app.Test(jsonRequest(fiber.MethodPost, "/work-orders/1/progress", body))
+					if err != nil {
+						t.Errorf("serving request: %v", err)
+						return
+					}
+					resp.Body.Close()
+					statuses <- resp.StatusCode
+				}()
+			}
+			wg.Wait()
+			close(statuses)
+
+			created := 0
+			for status := range statuses {
+				switch status {
+				case fiber.StatusCreated:
+					created++
+				case fiber.StatusBadRequest:
+				default:
+					t.Errorf("unexpected status %d", status)
+				}
+			}
+			if created != tt.wantCreated {
+				t.Errorf("%d entries created, want %d", created, tt.wantCreated)
+			}
+			if db.quantity != tt.wantQuantity {
+				t.Errorf("produced quantity %d, want %d", db.quantity, tt.wantQuantity)
+			}
+
+			// Progress and increment share a transaction, refused entries are rolled back
+			if rollbacks := len(recorder.Find("ROLLBACK")); rollbacks != tt.requests-tt.wantCreated {
+				t.Errorf("%d transactions rolled back, want %d", rollbacks, tt.requests-tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestCreateWorkOrderProgressTargetAndReporter(t *testing.T) {
+	previous := config.AppConfig
+	config.AppConfig.MinProgressQuantity = 1
+	t.Cleanup(func() { config.AppConfig = previous })
+
+	operator := testUser{2, models.RoleOperator, 0}
+	manager := testUser{1, models.RoleProductionManager, 0}
+
+	tests := []struct {
+		name          string
+		user          testUser
+		read          int64 // produced quantity the lookup of the order sees
+		quantity      int64 // produced quantity when the entry is stored
+		progress      int
+		wantStatus    int
+		wantRemaining int     // remaining quantity of a refused entry
+		wantReporter  float64 // reported_by_id of a created entry
+	}{
+		{"operator within the target", operator, 40, 40, 10, fiber.StatusCreated, 0, 2},
+		{"manager within the target", manager, 40, 40, 10, fiber.StatusCreated, 0, 1},
+		{"reaching the target exactly", operator, 95, 95, 5, fiber.StatusCreated, 0, 2},
+		{"over the target", operator, 95, 95, 6, fiber.StatusBadRequest, 5, 0},
+		{"manager over the target", manager, 95, 95, 10, fiber.StatusBadRequest, 5, 0},
+		{"target already reached", operator, 100, 100, 1, fiber.StatusBadRequest, 0, 0},
+		{"concurrent entry used up the target", operator, 40, 97, 10, fiber.StatusBadRequest, 3, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &producedQuantity{read: tt.read, quantity: tt.quantity, target: 100}
+			recorder := useScriptedDB(t, db.respond)
+
+			body := fmt.Sprintf(`{"progress_description":"batch","progress_quantity":%d}`, tt.progress)
+			status, resp := testRequestBody(t, "/work-orders/:id/progress", CreateWorkOrderProgress, tt.user, fiber.MethodPost, "/work-orders/1/progress", body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, resp)
+			}
+
+			if status == fiber.StatusBadRequest {
+				if resp["remaining"] != float64(tt.wantRemaining) {
+					t.Errorf("remaining = %v, want %d", resp["remaining"], tt.wantRemaining)
+				}
+				if db.quantity != tt.quantity {
+					t.Errorf("produced quantity changed to %d", db.quantity)
+				}
+				return
+			}
+
+			progress, _ := resp["progress"].(map[string]interface{})
+			if progress["reported_by_id"] != tt.wantReporter {
+				t.Errorf("reported_by_id = %v, want %v", progress["reported_by_id"], tt.wantReporter)
+			}
+			inserts := recorder.Find(`INSERT INTO "work_order_progresses"`)
+			if len(inserts) != 1 || !hasArgs(inserts[0], int64(tt.wantReporter)) {
+				t.Errorf("progress stored as %+v, want it reported by %v", inserts, tt.wantReporter)
+			}
+		})
+	}
+}
+
+func TestAutoStartOnProgress(t *testing.T) {
+	tests := []struct {
+		name          string
+		autoStart     bool
+		status        models.WorkOrderStatus
+		activeOrders  int64 // in progress orders of the operator, the limit is 1
+		statusChanged bool  // a concurrent request moved the order on after it was read
+		wantStatus    int
+		wantStarted   bool
+	}{
+		{"pending order without auto-start", false, models.StatusPending, 0, false, fiber.StatusBadRequest, false},
+		{"pending order with auto-start", true, models.StatusPending, 0, false, fiber.StatusCreated, true},
+		{"in progress order with auto-start", true, models.StatusInProgress, 1, false, fiber.StatusCreated, false},
+		{"in progress order without auto-start", false, models.StatusInProgress, 1, false, fiber.StatusCreated, false},
+		{"on hold order with auto-start", true, models.StatusOnHold, 0, false, fiber.StatusBadRequest, false},
+		{"operator at the active order limit", true, models.StatusPending, 1, false, fiber.StatusConflict, false},
+		{"status changed meanwhile", true, models.StatusPending, 0, true, fiber.StatusConflict, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := config.AppConfig
+			config.AppConfig.AutoStartOnProgress = tt.autoStart
+			config.AppConfig.MaxActiveOrdersPerOperator = 1
+			config.AppConfig.MinProgressQuantity = 1
+			t.Cleanup(func() { config.AppConfig = previous })
+
+			recorder := useScriptedDB(t, func(stmt dbtest.Statement) dbtest.Rows {
+				switch {
+				case strings.HasPrefix(stmt.SQL, "SELECT count(*)"):
+					return dbtest.Rows{Columns: []string{"count"}, Values: [][]driver.Value{{tt.activeOrders}}}
+				case strings.HasPrefix(stmt.SQL, `SELECT * FROM "work_orders"`):
+					return dbtest.Rows{
+						Columns: []string{"id", "operator_id", "status", "quantity", "target_quantity", "work_order_number"},
+						Values:  [][]driver.Value{{int64(1), int64(2), string(tt.status), int64(0), int64(100), "WO-20260301-001"}},
+					}
+				case strings.HasPrefix(stmt.SQL, `SELECT * FROM "users"`):
+					return dbtest.Rows{Columns: []string{"id", "plant_id", "username", "role"}, Values: [][]driver.Value{{int64(2), int64(1), "operator", string(models.RoleOperator)}}}
+				case strings.HasPrefix(stmt.SQL, `UPDATE "work_orders" SET "status"=$1`):
+					if tt.statusChanged {
+						return dbtest.Rows{}
+					}
+					return dbtest.Rows{RowsAffected: 1}
+				case strings.HasPrefix(stmt.SQL, `UPDATE "work_orders" SET "quantity"=quantity + $1`):
+					return dbtest.Rows{RowsAffected: 1}
+				case strings.HasPrefix(stmt.SQL, "INSERT INTO"):
+					return dbtest.Rows{Columns: []string{"id"}, Values: [][]driver.Value{{int64(1)}}}
+				}
+				return dbtest.Rows{}
+			})
+
+			status, body := testRequestBody(t, "/work-orders/:id/progress", CreateWorkOrderProgress, testUser{2, models.RoleOperator, 0}, fiber.MethodPost, "/work-orders/1/progress", `{"progress_description":"first batch","progress_quantity":10}`)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.wantStatus, body)
+			}
+
+			// The progress only stays when the order is, or was just, in progress
+			progress := recorder.Find(`INSERT INTO "work_order_progresses"`)
+			committed := len(recorder.Find("ROLLBACK")) == 0
+			if stored := len(progress) > 0 && committed; stored != (tt.wantStatus == fiber.StatusCreated) {
+				t.Errorf("progress stored = %t with status %d", stored, status)
+			}
+
+			starts := recorder.Find(`UPDATE "work_orders" SET "status"=$1`)
+			if started := len(starts) > 0 && committed; started != tt.wantStarted {
+				t.Fatalf("order started = %t, want %t", started, tt.wantStarted)
+			}
+			if !tt.wantStarted {
+				if len(recorder.Find(`INSERT INTO "work_order_status_histories"`)) > 0 && committed {
+					t.Errorf("status history written for an order that was not started")
+				}
+				return
+			}
+			if !hasArgs(starts[0], string(models.StatusInProgress), string(models.StatusPending)) {
+				t.Errorf("start %+v, want pending to in_progress", starts[0])
+			}
+			history := recorder.Find(`INSERT INTO "work_order_status_histories"`)
+			if len(history) != 1 || !hasArgs(history[0], string(models.StatusInProgress), autoStartNote) {
+				t.Errorf("status history %+v, want in_progress noted %q", history, autoStartNote)
+			}
+			var audited bool
+			for _, stmt := range recorder.Find(`INSERT INTO "audit_logs"`) {
+				if _, ok := noteArg(stmt, autoStartNote); ok {
+					audited = true
+				}
+			}
+			if !audited {
+				t.Errorf("auto-start not audit logged")
+			}
+		})
+	}
+}
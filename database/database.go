@@ -3,9 +3,11 @@ package database
 import (
 	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/dawamr/work-order-system-go/config"
-	"github.com/dawamr/work-order-system-go/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -27,61 +29,71 @@ func ConnectDB() {
 		config.AppConfig.DBName,
 	)
 
+	// Let Postgres cancel statements that run longer than the configured timeout
+	if config.AppConfig.DBStatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", config.AppConfig.DBStatementTimeout*1000)
+	}
+
 	// Connect to the database
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: newLogger(),
 	})
 
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	log.Println("Database connection established")
-}
-
-// MigrateDB performs database migration
-func MigrateDB() {
-	log.Println("Running database migrations...")
-
-	// Drop existing foreign key constraints if any
- 	// Auto migrate models
- 	err := DB.AutoMigrate(
- 		&models.User{},
- 		&models.WorkOrder{},
- 		&models.WorkOrderProgress{},
- 		&models.WorkOrderStatusHistory{},
- 		&models.AuditLog{},
- 	)
-
- 	if err != nil {
- 		log.Fatalf("Failed to migrate database: %v", err)
- 	}
-
- 	// Drop existing foreign key constraints if any
- 	DB.Exec(`ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS fk_audit_logs_user`)
+	// Isolate the data of each plant on requests scoped to one
+	if err := RegisterPlantScope(DB); err != nil {
+		log.Fatalf("Failed to register plant scoping: %v", err)
+	}
 
+	// Configure the connection pool
+	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+		log.Fatalf("Failed to get database connection pool: %v", err)
 	}
+	sqlDB.SetMaxOpenConns(config.AppConfig.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.AppConfig.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(config.AppConfig.DBConnMaxLifetime) * time.Second)
 
-	// Check if constraint exists before adding it
-	var constraintExists int64
-	DB.Raw(`
-		SELECT COUNT(1)
-		FROM information_schema.table_constraints
-		WHERE constraint_name = 'fk_audit_logs_user'
-		AND table_name = 'audit_logs'
-	`).Scan(&constraintExists)
+	log.Println("Database connection established")
+	log.Printf("Database pool: max open %d, max idle %d, max lifetime %ds, statement timeout %ds",
+		config.AppConfig.DBMaxOpenConns,
+		config.AppConfig.DBMaxIdleConns,
+		config.AppConfig.DBConnMaxLifetime,
+		config.AppConfig.DBStatementTimeout,
+	)
+}
 
-	// Only add constraint if it doesn't exist
-	if constraintExists == 0 {
-		DB.Exec(`ALTER TABLE audit_logs
-			ADD CONSTRAINT fk_audit_logs_user
-			FOREIGN KEY (user_id)
-			REFERENCES users(id)
-			ON DELETE RESTRICT
-			ON UPDATE CASCADE`)
+// newLogger returns the GORM logger configured by DB_LOG_LEVEL and DB_SLOW_QUERY_THRESHOLD
+func newLogger() logger.Interface {
+	var level logger.LogLevel
+	switch strings.ToLower(config.AppConfig.DBLogLevel) {
+	case "silent":
+		level = logger.Silent
+	case "error":
+		level = logger.Error
+	case "info":
+		level = logger.Info
+	case "warn":
+		level = logger.Warn
+	default:
+		log.Printf("Unknown DB_LOG_LEVEL %q, using warn", config.AppConfig.DBLogLevel)
+		level = logger.Warn
 	}
 
-	log.Println("Database migration completed")
+	return logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold:             time.Duration(config.AppConfig.DBSlowQueryThreshold) * time.Millisecond,
+		LogLevel:                  level,
+		IgnoreRecordNotFoundError: true,
+		Colorful:                  config.AppConfig.AppEnv == "development",
+	})
+}
+
+// MigrateDB performs database migration
+func MigrateDB() {
+	if err := Migrate(MigrateOptions{}); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
 }
@@ -0,0 +1,224 @@
+// Package dbtest opens GORM databases backed by a scripted SQL driver, for tests
+// that check the statements code runs and feed it rows without a database server.
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement is a statement run against the scripted database. Transactions are
+// recorded as the statements BEGIN, COMMIT and ROLLBACK.
+type Statement struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// Rows is the answer of the scripted database to a statement
+type Rows struct {
+	Columns      []string
+	Values       [][]driver.Value
+	RowsAffected int64 // reported for statements run with Exec
+	Err          error
+}
+
+// Responder answers the statements run against the scripted database, the zero
+// Rows is an empty result
+type Responder func(stmt Statement) Rows
+
+// Recorder keeps the statements run against a scripted database
+type Recorder struct {
+	mu         sync.Mutex
+	statements []Statement
+	respond    Responder
+}
+
+// Statements returns the statements run so far, in order
+func (r *Recorder) Statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.statements...)
+}
+
+// Find returns the statements run so far whose SQL contains substr
+func (r *Recorder) Find(substr string) []Statement {
+	var found []Statement
+	for _, stmt := range r.Statements() {
+		if strings.Contains(stmt.SQL, substr) {
+			found = append(found, stmt)
+		}
+	}
+	return found
+}
+
+// run records a statement and returns its answer
+func (r *Recorder) run(query string, args []driver.NamedValue) Rows {
+	stmt := Statement{SQL: query}
+	for _, arg := range args {
+		stmt.Args = append(stmt.Args, arg.Value)
+	}
+
+	r.mu.Lock()
+	r.statements = append(r.statements, stmt)
+	r.mu.Unlock()
+
+	if r.respond == nil {
+		return Rows{}
+	}
+	return r.respond(stmt)
+}
+
+// Open returns a GORM database speaking the Postgres dialect to a scripted driver
+// answering with respond, which may be nil, and the recorder of its statements
+func Open(t testing.TB, respond Responder) (*gorm.DB, *Recorder) {
+	t.Helper()
+	recorder := &Recorder{respond: respond}
+	sqlDB := sql.OpenDB(connector{recorder})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("opening scripted database: %v", err)
+	}
+	return db, recorder
+}
+
+// connector opens connections to the scripted database
+type connector struct {
+	recorder *Recorder
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{recorder: c.recorder}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return scriptedDriver{}
+}
+
+// scriptedDriver only exists to satisfy driver.Connector, connections are made by the connector
+type scriptedDriver struct{}
+
+func (scriptedDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("dbtest: open the database with dbtest.Open")
+}
+
+// conn is a connection to the scripted database
+type conn struct {
+	recorder *Recorder
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.recorder.run("BEGIN", nil)
+	return tx{recorder: c.recorder}, nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	answer := c.recorder.run(query, args)
+	if answer.Err != nil {
+		return nil, answer.Err
+	}
+	return &rows{columns: answer.Columns, values: answer.Values}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	answer := c.recorder.run(query, args)
+	if answer.Err != nil {
+		return nil, answer.Err
+	}
+	return driver.RowsAffected(answer.RowsAffected), nil
+}
+
+// stmt is a prepared statement, run like an unprepared one
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+// namedValues numbers positional arguments
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+// tx records the end of a transaction
+type tx struct {
+	recorder *Recorder
+}
+
+func (t tx) Commit() error {
+	t.recorder.run("COMMIT", nil)
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.recorder.run("ROLLBACK", nil)
+	return nil
+}
+
+// rows iterates over the scripted rows of a query
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unique violation", &pgconn.PgError{Code: uniqueViolation}, true},
+		{"wrapped unique violation", fmt.Errorf("creating user: %w", &pgconn.PgError{Code: uniqueViolation}), true},
+		{"other constraint", &pgconn.PgError{Code: "23503"}, false},
+		{"not a Postgres error", errors.New("duplicate key"), false},
+		{"no error", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUniqueViolation(tt.err); got != tt.want {
+				t.Errorf("IsUniqueViolation(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
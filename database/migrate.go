@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Models lists every model managed by the migrations
+var Models = []interface{}{
+	&models.User{},
+	&models.WorkOrder{},
+	&models.WorkOrderProgress{},
+	&models.WorkOrderStatusHistory{},
+	&models.AuditLog{},
+	&models.DailyProductionCounter{},
+	&models.NotificationPreference{},
+	&models.OverdueAcknowledgement{},
+	&models.FeatureFlag{},
+	&models.Tag{},
+	&models.OutboxJob{},
+}
+
+// MigrateOptions controls how Migrate applies the schema changes
+type MigrateOptions struct {
+	DryRun  bool // log the statements that would run without applying them
+	Verbose bool // log the result of the constraint checks
+}
+
+// Migrate brings the schema up to date with the models.
+// In dry-run mode the migration runs inside a transaction that is rolled back,
+// so the planned statements are computed against the real schema but never committed.
+func Migrate(opts MigrateOptions) error {
+	if !opts.DryRun {
+		log.Println("Running database migrations...")
+		if err := migrate(DB, opts); err != nil {
+			return err
+		}
+		log.Println("Database migration completed")
+		return nil
+	}
+
+	log.Println("Running database migrations in dry-run mode, no changes will be applied...")
+
+	recorder := &statementRecorder{}
+	tx := DB.Session(&gorm.Session{Logger: recorder}).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	err := migrate(tx, opts)
+	if rbErr := tx.Rollback().Error; rbErr != nil && err == nil {
+		err = rbErr
+	}
+	if err != nil {
+		return err
+	}
+
+	statements := recorder.changes()
+	if len(statements) == 0 {
+		log.Println("Schema is up to date, nothing to apply")
+		return nil
+	}
+
+	log.Printf("%d statement(s) would be executed:", len(statements))
+	for _, stmt := range statements {
+		log.Printf("  %s;", stmt)
+	}
+	return nil
+}
+
+// migrate runs the migration steps against db
+func migrate(db *gorm.DB, opts MigrateOptions) error {
+	// Auto migrate models
+	if err := db.AutoMigrate(Models...); err != nil {
+		return err
+	}
+
+	// Daily counters are unique per plant now, drop the old unique index without the plant
+	if err := db.Exec(`DROP INDEX IF EXISTS idx_daily_counter_date_product`).Error; err != nil {
+		return err
+	}
+
+	// Drop existing foreign key constraints if any
+	if err := db.Exec(`ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS fk_audit_logs_user`).Error; err != nil {
+		return err
+	}
+
+	// Check if constraint exists before adding it
+	var constraintExists int64
+	if err := db.Raw(`
+		SELECT COUNT(1)
+		FROM information_schema.table_constraints
+		WHERE constraint_name = 'fk_audit_logs_user'
+		AND table_name = 'audit_logs'
+	`).Scan(&constraintExists).Error; err != nil {
+		return err
+	}
+
+	if opts.Verbose {
+		log.Printf("Constraint fk_audit_logs_user exists: %t", constraintExists > 0)
+	}
+
+	// Only add constraint if it doesn't exist
+	if constraintExists == 0 {
+		if opts.Verbose {
+			log.Println("Adding constraint fk_audit_logs_user")
+		}
+		if err := db.Exec(`ALTER TABLE audit_logs
+			ADD CONSTRAINT fk_audit_logs_user
+			FOREIGN KEY (user_id)
+			REFERENCES users(id)
+			ON DELETE RESTRICT
+			ON UPDATE CASCADE`).Error; err != nil {
+			return err
+		}
+	}
+
+	// Backfill the actor username snapshot on audit logs written before it existed
+	if err := db.Exec(`UPDATE audit_logs SET user_name = users.username
+		FROM users
+		WHERE audit_logs.user_id = users.id
+		AND (audit_logs.user_name IS NULL OR audit_logs.user_name = '')`).Error; err != nil {
+		return err
+	}
+
+	// Audit logs written before they had a plant got the default one, move them to their actor's plant
+	return db.Exec(`UPDATE audit_logs SET plant_id = users.plant_id
+		FROM users
+		WHERE audit_logs.user_id = users.id
+		AND audit_logs.plant_id = 1 AND users.plant_id <> 1`).Error
+}
+
+// statementRecorder is a GORM logger that keeps every executed statement
+type statementRecorder struct {
+	statements []string
+}
+
+func (r *statementRecorder) LogMode(logger.LogLevel) logger.Interface { return r }
+
+func (r *statementRecorder) Info(context.Context, string, ...interface{}) {}
+
+func (r *statementRecorder) Warn(context.Context, string, ...interface{}) {}
+
+func (r *statementRecorder) Error(ctx context.Context, msg string, args ...interface{}) {
+	DB.Logger.Error(ctx, msg, args...)
+}
+
+func (r *statementRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, strings.TrimSpace(sql))
+}
+
+// changes returns the recorded statements that modify the schema or data,
+// skipping the catalog lookups the migrator makes while planning
+func (r *statementRecorder) changes() []string {
+	var changes []string
+	for _, stmt := range r.statements {
+		verb := strings.ToUpper(strings.SplitN(stmt, " ", 2)[0])
+		switch verb {
+		case "CREATE", "ALTER", "DROP", "COMMENT", "INSERT", "UPDATE", "DELETE":
+			changes = append(changes, stmt)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// plantKey is the context key of the plant the current request is scoped to
+type plantKey struct{}
+
+// WithPlant returns a context that scopes the queries run with it to a plant
+func WithPlant(ctx context.Context, plantID uint) context.Context {
+	return context.WithValue(ctx, plantKey{}, plantID)
+}
+
+// WithoutPlant returns a context whose queries see every plant, for lookups
+// that have to span plants such as the globally unique work order numbers
+func WithoutPlant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, plantKey{}, uint(0))
+}
+
+// PlantFromContext returns the plant the context is scoped to, if any
+func PlantFromContext(ctx context.Context) (uint, bool) {
+	plantID, ok := ctx.Value(plantKey{}).(uint)
+	return plantID, ok && plantID != 0
+}
+
+// RegisterPlantScope registers the callbacks that isolate plants. Queries,
+// updates and deletes of models with a PlantID field are restricted to the
+// plant of the statement's context, and creates fill in a missing PlantID.
+// Statements without a plant in their context (migrations, command line
+// tools, background jobs) are not restricted. Raw SQL and subqueries are
+// never scoped, so they have to filter on a scoped parent record themselves.
+func RegisterPlantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("plant:scope", scopeToPlant); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("plant:scope", scopeToPlant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("plant:scope", scopeToPlant); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("plant:scope", scopeToPlant); err != nil {
+		return err
+	}
+	return callbacks.Create().Before("gorm:create").Register("plant:assign", assignPlant)
+}
+
+// plantField returns the PlantID field of the statement's model and the plant of its context
+func plantField(db *gorm.DB) (*schema.Field, uint, bool) {
+	plantID, ok := PlantFromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil {
+		return nil, 0, false
+	}
+	field := db.Statement.Schema.LookUpField("PlantID")
+	return field, plantID, field != nil
+}
+
+// scopeToPlant restricts the statement to the plant of its context
+func scopeToPlant(db *gorm.DB) {
+	field, plantID, ok := plantField(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: plantID},
+	}})
+}
+
+// assignPlant sets the plant of the statement's context on created records without one
+func assignPlant(db *gorm.DB) {
+	field, plantID, ok := plantField(db)
+	if !ok {
+		return
+	}
+
+	assign := func(record reflect.Value) {
+		if _, zero := field.ValueOf(db.Statement.Context, record); zero {
+			if err := field.Set(db.Statement.Context, record, plantID); err != nil {
+				db.AddError(err)
+			}
+		}
+	}
+
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			assign(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		assign(value)
+	}
+}
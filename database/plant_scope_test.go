@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dryRunDB returns a database that builds the statements without connecting, with the plant scope registered
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost user=test dbname=test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("opening dry run database: %v", err)
+	}
+	if err := RegisterPlantScope(db); err != nil {
+		t.Fatalf("registering plant scope: %v", err)
+	}
+	return db
+}
+
+func TestScopeToPlant(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		run       func(db *gorm.DB) *gorm.DB
+		wantScope string
+	}{
+		{
+			name:      "audit log by id of another plant is not found",
+			ctx:       WithPlant(context.Background(), 2),
+			run:       func(db *gorm.DB) *gorm.DB { return db.First(&models.AuditLog{}, 7) },
+			wantScope: `"audit_logs"."plant_id" = 2`,
+		},
+		{
+			name: "audit log list only shows the plant",
+			ctx:  WithPlant(context.Background(), 3),
+			run: func(db *gorm.DB) *gorm.DB {
+				var logs []models.AuditLog
+				return db.Where("entity_type = ? AND entity_id = ?", "WorkOrder", 1).Find(&logs)
+			},
+			wantScope: `"audit_logs"."plant_id" = 3`,
+		},
+		{
+			name: "audit log count only counts the plant",
+			ctx:  WithPlant(context.Background(), 2),
+			run: func(db *gorm.DB) *gorm.DB {
+				var count int64
+				return db.Model(&models.AuditLog{}).Where("user_id = ?", 1).Count(&count)
+			},
+			wantScope: `"audit_logs"."plant_id" = 2`,
+		},
+		{
+			name:      "work order of another plant is not found",
+			ctx:       WithPlant(context.Background(), 2),
+			run:       func(db *gorm.DB) *gorm.DB { return db.First(&models.WorkOrder{}, 1) },
+			wantScope: `"work_orders"."plant_id" = 2`,
+		},
+		{
+			name: "updates are scoped",
+			ctx:  WithPlant(context.Background(), 2),
+			run: func(db *gorm.DB) *gorm.DB {
+				return db.Model(&models.WorkOrder{}).Where("id = ?", 1).Update("quantity", 5)
+			},
+			wantScope: `"work_orders"."plant_id" = 2`,
+		},
+		{
+			name:      "without a plant every plant is visible",
+			ctx:       context.Background(),
+			run:       func(db *gorm.DB) *gorm.DB { return db.First(&models.AuditLog{}, 7) },
+			wantScope: "",
+		},
+		{
+			name:      "WithoutPlant sees every plant",
+			ctx:       WithoutPlant(WithPlant(context.Background(), 2)),
+			run:       func(db *gorm.DB) *gorm.DB { return db.First(&models.AuditLog{}, 7) },
+			wantScope: "",
+		},
+		{
+			name:      "models without a plant are not scoped",
+			ctx:       WithPlant(context.Background(), 2),
+			run:       func(db *gorm.DB) *gorm.DB { return db.First(&models.WorkOrderProgress{}, 1) },
+			wantScope: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dryRunDB(t)
+			stmt := tt.run(db.WithContext(tt.ctx)).Statement
+			sql := db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+			if tt.wantScope == "" {
+				if strings.Contains(sql, "plant_id") {
+					t.Errorf("statement is scoped to a plant: %s", sql)
+				}
+				return
+			}
+			if !strings.Contains(sql, tt.wantScope) {
+				t.Errorf("statement lacks %s: %s", tt.wantScope, sql)
+			}
+		})
+	}
+}
+
+func TestAssignPlant(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		log  models.AuditLog
+		want uint
+	}{
+		{"missing plant takes the context's", WithPlant(context.Background(), 2), models.AuditLog{}, 2},
+		{"actor's plant is kept", WithPlant(context.Background(), 2), models.AuditLog{PlantID: 3}, 3},
+		{"without a plant in the context the column default is used", context.Background(), models.AuditLog{}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := tt.log
+			if err := dryRunDB(t).WithContext(tt.ctx).Create(&log).Error; err != nil {
+				t.Fatalf("creating audit log: %v", err)
+			}
+			if log.PlantID != tt.want {
+				t.Errorf("PlantID = %d, want %d", log.PlantID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// SchemaStatus describes whether the tables of the migrated models exist
+type SchemaStatus struct {
+	Migrated      bool            `json:"migrated"`
+	Tables        map[string]bool `json:"tables"`         // expected table name to whether it exists
+	MissingTables []string        `json:"missing_tables"` // expected tables that do not exist yet
+}
+
+// CheckSchema looks up the tables of every model in Models in information_schema
+func CheckSchema(db *gorm.DB) (SchemaStatus, error) {
+	expected := make([]string, 0, len(Models))
+	for _, model := range Models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return SchemaStatus{}, err
+		}
+		expected = append(expected, stmt.Schema.Table)
+	}
+
+	var existing []string
+	if err := db.Raw(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = current_schema()
+		AND table_name IN (?)
+	`, expected).Scan(&existing).Error; err != nil {
+		return SchemaStatus{}, err
+	}
+
+	status := SchemaStatus{
+		Tables:        make(map[string]bool, len(expected)),
+		MissingTables: []string{},
+	}
+	for _, table := range expected {
+		status.Tables[table] = false
+	}
+	for _, table := range existing {
+		status.Tables[table] = true
+	}
+	for table, exists := range status.Tables {
+		if !exists {
+			status.MissingTables = append(status.MissingTables, table)
+		}
+	}
+	sort.Strings(status.MissingTables)
+	status.Migrated = len(status.MissingTables) == 0
+
+	return status, nil
+}
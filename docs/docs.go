@@ -23,9 +23,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
+        "/admin/impersonate/{operator_id}": {
             "post": {
-                "description": "Authenticate user and return JWT token",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a short-lived token to see and act as an operator for support, valid for IMPERSONATION_TTL minutes. Requests made with it are served as the operator and answered with an X-Impersonated-By header naming the manager, who is recorded as the actor of their audit logs. Impersonation ends by discarding the token. (Production Manager only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -33,25 +38,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "users"
                 ],
-                "summary": "Login user",
+                "summary": "Impersonate operator",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "Operator ID",
+                        "name": "operator_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.LoginResponse"
+                            "$ref": "#/definitions/controllers.ImpersonationResponse"
                         }
                     },
                     "400": {
@@ -65,43 +68,15 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/register": {
-            "post": {
-                "description": "Register a new user and return JWT token",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Register new user",
-                "parameters": [
-                    {
-                        "description": "Registration details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.RegisterRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/controllers.RegisterResponse"
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -115,35 +90,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports/operators": {
+        "/admin/jobs": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get performance metrics for operators (Production Manager only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the background jobs of notifications and webhook deliveries of the plant, newest first. By default the dead-lettered ones, which failed on every attempt, with their last error. (Production Manager only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reports"
+                    "admin"
                 ],
-                "summary": "Get operator performance",
+                "summary": "Get outbox jobs",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "start_date",
+                        "description": "pending, running, done or dead (default)",
+                        "name": "status",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "end_date",
+                        "description": "Job kind, e.g. webhook or notification.assignment",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -151,7 +135,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.PerformanceResponse"
+                            "$ref": "#/definitions/controllers.OutboxJobListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "401": {
@@ -165,39 +155,42 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/reports/summary": {
+        "/admin/perf": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a summary report of work orders by status (Production Manager only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the routes with the slowest p95 response time recorded in memory by this instance since startup or the last reset, with their call count, mean, p50, p95 and maximum in milliseconds. Percentiles are estimated from a latency histogram. (Production Manager only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "reports"
+                    "admin"
                 ],
-                "summary": "Get work order summary",
+                "summary": "Get slowest routes",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Start date (YYYY-MM-DD)",
-                        "name": "start_date",
+                        "type": "integer",
+                        "description": "Number of routes (default: 20)",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "End date (YYYY-MM-DD)",
-                        "name": "end_date",
+                        "type": "boolean",
+                        "description": "Clear the recorded timings after reading them",
+                        "name": "reset",
                         "in": "query"
                     }
                 ],
@@ -205,7 +198,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.SummaryResponse"
+                            "$ref": "#/definitions/controllers.PerfResponse"
                         }
                     },
                     "401": {
@@ -223,14 +216,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/work-orders": {
+        "/audit-logs": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a paginated list of all work orders (Production Manager only)",
+                "description": "Get a paginated list of audit logs, newest first (Production Manager only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -238,13 +231,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
+                    "audit-logs"
                 ],
-                "summary": "Get all work orders",
+                "summary": "Get audit logs",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Page number (default: 1)",
+                        "description": "Page number (default: 1), ignored when after is given",
                         "name": "page",
                         "in": "query"
                     },
@@ -256,8 +249,26 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Filter by status (pending/in_progress/completed)",
-                        "name": "status",
+                        "description": "Cursor from next_cursor; pass an empty value to start a cursor scan",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by entity type (e.g. WorkOrder)",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by entity ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by action (create/update/delete/custom)",
+                        "name": "action",
                         "in": "query"
                     }
                 ],
@@ -265,7 +276,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.WorkOrderListResponse"
+                            "$ref": "#/definitions/controllers.AuditLogListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
                     "401": {
@@ -279,16 +296,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/audit-logs/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new work order (Production Manager only)",
+                "description": "Get an audit log entry with its old and new values aligned per field (Production Manager only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -296,25 +321,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
+                    "audit-logs"
                 ],
-                "summary": "Create work order",
+                "summary": "Get audit log entry",
                 "parameters": [
                     {
-                        "description": "Work order details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.CreateWorkOrderRequest"
-                        }
+                        "type": "integer",
+                        "description": "Audit log ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                            "$ref": "#/definitions/controllers.AuditLogDetailResponse"
                         }
                     },
                     "400": {
@@ -334,18 +357,25 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/work-orders/assigned": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get work orders assigned to the current operator",
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate user and return JWT token",
                 "consumes": [
                     "application/json"
                 ],
@@ -353,44 +383,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
+                    "auth"
                 ],
-                "summary": "Get assigned work orders",
+                "summary": "Login user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page (default: 10)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Filter by status (pending/in_progress/completed)",
-                        "name": "status",
-                        "in": "query"
+                        "description": "Login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.WorkOrderListResponse"
+                            "$ref": "#/definitions/controllers.LoginResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -398,14 +419,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/work-orders/{id}": {
+        "/auth/me": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get a work order by its ID",
+                "description": "Verify the token and return the current user and the token expiry",
                 "consumes": [
                     "application/json"
                 ],
@@ -413,23 +434,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
-                ],
-                "summary": "Get work order by ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Work order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "auth"
                 ],
+                "summary": "Get current user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                            "$ref": "#/definitions/controllers.MeResponse"
                         }
                     },
                     "401": {
@@ -438,27 +450,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/password": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a work order (Production Manager only)",
+                "description": "Change the password of the current user. Not allowed with an impersonation token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -466,24 +474,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
+                    "auth"
                 ],
-                "summary": "Update work order",
+                "summary": "Change password",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Work order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Work order update details",
+                        "description": "Current and new password",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.UpdateWorkOrderRequest"
+                            "$ref": "#/definitions/controllers.ChangePasswordRequest"
                         }
                     }
                 ],
@@ -491,13 +492,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                            "$ref": "#/definitions/controllers.ChangePasswordResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ErrorResponse"
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
                         }
                     },
                     "401": {
@@ -512,8 +513,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -521,14 +522,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/work-orders/{id}/history": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get the status history for a work order",
+        "/auth/register": {
+            "post": {
+                "description": "Register a new user and return JWT token",
                 "consumes": [
                     "application/json"
                 ],
@@ -536,39 +532,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "progress"
+                    "auth"
                 ],
-                "summary": "Get work order status history",
+                "summary": "Register new user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Work order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Registration details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RegisterRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/controllers.StatusHistoryResponse"
+                            "$ref": "#/definitions/controllers.RegisterResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
@@ -576,14 +574,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/work-orders/{id}/progress": {
+        "/calendar/next-business-day": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all progress entries for a work order",
+                "description": "Get the date that is N business days from today, skipping the configured weekend and holidays",
                 "consumes": [
                     "application/json"
                 ],
@@ -591,23 +589,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "progress"
+                    "calendar"
                 ],
-                "summary": "Get work order progress",
+                "summary": "Get next business day",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Work order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Number of business days to add (default: 1)",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ProgressListResponse"
+                            "$ref": "#/definitions/controllers.NextBusinessDayResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feature-flags": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the feature flags stored in the database and the ones enabled by default through FEATURE_FLAGS (Production Manager only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feature-flags"
+                ],
+                "summary": "Get feature flags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.FeatureFlagListResponse"
                         }
                     },
                     "401": {
@@ -622,21 +656,54 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/feature-flags/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the names of the switched on feature flags so clients can adapt",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feature-flags"
+                ],
+                "summary": "Get enabled features",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.EnabledFeaturesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feature-flags/{name}": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new progress entry for a work order",
+                "description": "Switch a feature flag on or off, creating it if needed. The flag overrides FEATURE_FLAGS and reaches other instances within 30 seconds. (Production Manager only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -644,32 +711,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "progress"
+                    "feature-flags"
                 ],
-                "summary": "Create progress entry",
+                "summary": "Set feature flag",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Work order ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Feature flag name, e.g. multi_operator",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Progress details",
+                        "description": "Feature flag state",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.CreateProgressRequest"
+                            "$ref": "#/definitions/controllers.SetFeatureFlagRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.ProgressResponse"
+                            "$ref": "#/definitions/controllers.FeatureFlagResponse"
                         }
                     },
                     "400": {
@@ -690,23 +757,114 @@ const docTemplate = `{
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a stored feature flag, it falls back to FEATURE_FLAGS (Production Manager only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feature-flags"
+                ],
+                "summary": "Delete feature flag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feature flag name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/work-orders/{id}/status": {
+        "/maintenance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get whether the read-only maintenance mode is on",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Get maintenance mode",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MaintenanceModeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a work order status (Operator only)",
+                "description": "Turn the read-only maintenance mode on or off. While it is on, write requests are answered with 503 and reads keep working. The mode is kept in memory, a restart falls back to MAINTENANCE_MODE. (Production Manager only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -714,24 +872,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "work-orders"
+                    "maintenance"
                 ],
-                "summary": "Update work order status",
+                "summary": "Set maintenance mode",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Work order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Status update details",
+                        "description": "Maintenance mode",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.UpdateWorkOrderStatusRequest"
+                            "$ref": "#/definitions/controllers.MaintenanceModeRequest"
                         }
                     }
                 ],
@@ -739,7 +890,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                            "$ref": "#/definitions/controllers.MaintenanceModeResponse"
                         }
                     },
                     "400": {
@@ -759,260 +910,6004 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/controllers.ErrorResponse"
                         }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/controllers.ErrorResponse"
-                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.CreateProgressRequest": {
-            "type": "object",
-            "required": [
-                "progress_description",
-                "progress_quantity"
-            ],
+        },
+        "/me/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the notification preferences of the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get my notification preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.NotificationPreferencesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Opt in or out of notifications for the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Update my notification preferences",
+                "parameters": [
+                    {
+                        "description": "Notification preferences",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateNotificationPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.NotificationPreferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/meta/enums": {
+            "get": {
+                "description": "Get the valid work order statuses, remaining dispositions, user roles and audit log action types, taken from the backend constants. Public, the values are not sensitive.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "Get enum values",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.EnumsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/meta/time": {
+            "get": {
+                "description": "Get the server's current UTC time, its timezone and offset, the token lifetime and the largest page size, so clients can align date pickers and countdowns with the backend. Public, the values are not sensitive.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "Get server time",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ServerTimeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/operators": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a paginated list of operators in the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operators"
+                ],
+                "summary": "Get all operators",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by username substring",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by active status",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OperatorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/operators/{id}/forecast": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get an operator's open work orders by deadline with the cumulative remaining quantity, flagging orders the operator is unlikely to finish in time at their historical throughput (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get operator capacity forecast",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Operator ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Quantity per day to forecast with instead of the historical throughput",
+                        "name": "throughput",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OperatorForecastResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/operators/{id}/report/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download an XLSX workbook with the operator's completed work orders in the date range, their quantities, lead times and on-time rate, and a month-by-month breakdown. Orders are dated by their completion, the range defaults to the current year. (Production Manager only)",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Export operator performance history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Operator ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date format: iso (default), raw, en-US or id-ID; falls back to Accept-Language",
+                        "name": "locale",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/progress": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the progress entries of all work orders, newest first, with their work order number and product (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress"
+                ],
+                "summary": "Get progress feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by the reporting operator; entries without a recorded reporter match the assigned operator",
+                        "name": "operator_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by product name (partial match)",
+                        "name": "product_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProgressFeedResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/aging": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the number of non-completed work orders by age since creation: 0-1d, 1-3d, 3-7d and 7+d. Operators only see their own orders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get work order aging",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AgingResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/compare": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the performance metrics of the listed operators side by side, keyed by operator ID: assigned, completed and quantity as in the performance report, the average lead time and the on-time rate of the completed orders. Orders are selected by their production deadline like the performance report, completions are taken from the status history. (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Compare operators",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated operator IDs, 2 to 5",
+                        "name": "operators",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OperatorComparisonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/daily": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the precomputed daily production counters per product (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get daily production",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by product name",
+                        "name": "product_name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DailyProductionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/dashboard": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a dashboard report of work orders by status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get work order Dashboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DashboardResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/kpis": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get total orders, completion rate, overdue count and active operators in one call. Operators only see their own orders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get dashboard KPIs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.KPIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/lead-time": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the average and the p50, p90 and p99 lead time in hours from creating a work order to completing it, taken from the status history. Orders are dated by their completion and can be grouped by product or operator. Completed orders without a completion in the status history are left out. (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get lead time percentiles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Completed on or after this day (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Completed on or before this day (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Group by product or operator",
+                        "name": "group_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LeadTimeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/notification-preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get how many users opted in to each notification (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get notification preference summary",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.NotificationPreferenceSummaryResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/operators/matrix": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get every operator with their work order counts per status and produced quantity in the date range, the overview behind the per-operator summaries (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get operator status matrix",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OperatorStatusMatrixResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/performance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get performance metrics for operators (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get operator performance",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PerformanceResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a summary report of work orders by status (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get work order summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum ordered (target) quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum ordered (target) quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/summary/compare": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the orders created, the orders completed, their quantity and on-time rate of the current week, month, quarter or year so far next to the same span of the previous period, e.g. the 1st to the 15th of this and of last month, with the change between them. Completions are taken from the status history. Results are cached for a minute. (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Compare summary with the previous period",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "week, month (default), quarter or year",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SummaryCompareResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/summary/product/{product_name}/orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the paginated work orders behind a product row of the work order summary, using the same date range (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get work orders of a summary product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product name",
+                        "name": "product_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum ordered (target) quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum ordered (target) quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit of the summary row, e.g. pcs",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (pending/in_progress/on_hold/completed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/summary/{operator_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a summary report of work orders by status for a specific operator (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get work order summary by operator",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operator ID",
+                        "name": "operator_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SummaryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/upcoming-load": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the number and total target quantity of non-completed work orders due in each of the next weeks, starting with the current ISO week. Weeks without orders are included with zero counts. (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Get upcoming load",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of weeks including the current one, at most 52 (default: UPCOMING_LOAD_WEEKS)",
+                        "name": "weeks",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpcomingLoadResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Search work orders by number or product name, operators by username and, for Production Managers, audit log notes. Each group returns at most 5 items with the total number of matches. Operators only find their own work orders.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Global search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search term",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the tags that can be put on work orders, sorted by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Get tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TagListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a tag operators and managers can put on work orders. Names are lowercased. (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Create tag",
+                "parameters": [
+                    {
+                        "description": "Tag",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a tag and take it off every work order carrying it (Production Manager only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Delete tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the details of a user, including the last login time (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/actions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a paginated list of the audit logs where the user is the actor, newest first, to review a person's changes (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user actions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by entity type (e.g. WorkOrder)",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by action (create/update/delete/custom)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuditLogListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reset-password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set a new password for an operator, or generate one when none is given. The generated password is only returned once. Other Production Managers' passwords can not be reset (403). (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reset user password",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New password",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResetPasswordResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a paginated list of all work orders (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Get all work orders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (pending/in_progress/on_hold/completed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by work order number (WO- prefix) or product name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on or after this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on or before this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by whether the assigned operator acknowledged the order",
+                        "name": "acknowledged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum ordered (target) quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum ordered (target) quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by work_order_number, production_deadline, created_at, target_quantity, quantity or status (default: WORK_ORDER_SORT_BY)",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order asc or desc (default: WORK_ORDER_SORT_ORDER)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated work order fields to return, e.g. id,work_order_number,status (default: all)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated tag names, e.g. rework,customer-x",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Match orders with any (default) or all of the tags",
+                        "name": "tags_match",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new work order (Production Manager only). With PREVENT_DUPLICATE_ACTIVE_ORDERS, an operator's second non-completed order for the same product is rejected with 409 unless force is set. The production deadline must fall within DEADLINE_MIN_LEAD_HOURS and DEADLINE_MAX_HORIZON_DAYS unless allow_deadline_outside_window is set, which is audit logged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Create work order",
+                "parameters": [
+                    {
+                        "description": "Work order details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateWorkOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or deadline outside the allowed window",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DeadlineWindowErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Work order number taken, operator at capacity or a duplicate active order",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DuplicateActiveOrderErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/acknowledge-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Acknowledge all unacknowledged, non-completed work orders assigned to an operator at once, e.g. at the start of a shift. Operators acknowledge their own orders, Production Managers name the operator with operator_id. The batch is recorded as one audit log entry.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Acknowledge all assigned work orders",
+                "parameters": [
+                    {
+                        "description": "Operator to acknowledge for (Production Manager only)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AcknowledgeAllRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AcknowledgeAllResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/assigned": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get work orders assigned to the current operator",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Get assigned work orders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (pending/in_progress/on_hold/completed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by work order number (WO- prefix) or product name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on or after this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on or before this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by whether the order was acknowledged",
+                        "name": "acknowledged",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum ordered (target) quantity",
+                        "name": "min_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum ordered (target) quantity",
+                        "name": "max_quantity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by work_order_number, production_deadline, created_at, target_quantity, quantity or status (default: WORK_ORDER_SORT_BY)",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order asc or desc (default: WORK_ORDER_SORT_ORDER)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated work order fields to return, e.g. id,work_order_number,status (default: all)",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated tag names, e.g. rework,customer-x",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Match orders with any (default) or all of the tags",
+                        "name": "tags_match",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/batch-get": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the work orders matching a list of IDs, at most 100 per request. Operators only get work orders assigned to them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Batch get work orders",
+                "parameters": [
+                    {
+                        "description": "Work order IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.BatchGetWorkOrdersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.BatchGetWorkOrdersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create work orders from a CSV file with the columns product_name, quantity, target_quantity, operator (username or ID) and production_deadline (RFC 3339 or YYYY-MM-DD), and optionally unit (defaults to pcs). The file is read as a stream and created in batches of IMPORT_BATCH_SIZE rows, each batch in its own transaction. A batch with an invalid row is not created and stops the import; the batches before it stay and resume_from names the start_row to continue from once the file is fixed. (Production Manager only)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Import work orders",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file with a header row",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate the rows without creating work orders",
+                        "name": "validate_only",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Accept deadlines in the past",
+                        "name": "allow_past_deadline",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Override the operators' active order limit",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Skip the rows before this line of the file, the header is line 1",
+                        "name": "start_row",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Validation result when validate_only is set",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ImportWorkOrdersResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ImportWorkOrdersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ImportWorkOrdersResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ImportWorkOrdersResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ImportWorkOrdersResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/inbox": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the work orders the current user should look at first. Operators get their pending and in-progress orders by deadline, Production Managers get open orders with unacknowledged or overdue ones first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Get work order inbox",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status (pending/in_progress/on_hold/completed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by work order number (WO- prefix) or product name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on or after this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Production deadline on or before this day (YYYY-MM-DD or RFC 3339)",
+                        "name": "deadline_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated work order fields to return, e.g. id,work_order_number,status (default: all)",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/next": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the pending work order the current operator should start next: the highest priority first, then the earliest production deadline, so overdue orders come before the others of their priority, then the oldest order. Answers 204 without a body when the operator has no pending orders. (Operator only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Get next work order",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.NextWorkOrderResponse"
+                        }
+                    },
+                    "204": {
+                        "description": "No pending work orders"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a work order by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Get work order by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated work order fields to return, e.g. id,work_order_number,status (default: all)",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a work order (Production Manager only). The target quantity can not go below the produced quantity. Changing the target of an order with logged progress is answered with a warning and the produced quantity, or rejected with 409 when BLOCK_TARGET_CHANGE_WITH_PROGRESS is set. A status change needs the same details as through the status endpoint. A changed production deadline must fall within DEADLINE_MIN_LEAD_HOURS and DEADLINE_MAX_HORIZON_DAYS unless allow_deadline_outside_window is set, which is audit logged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Update work order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Work order update details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateWorkOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateWorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or target below the produced quantity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TargetChangeErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Target change blocked by BLOCK_TARGET_CHANGE_WITH_PROGRESS, or the work order completed or changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.TargetChangeErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a work order (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Delete work order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/acknowledge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Confirm that the assigned operator has seen the work order (assigned Operator only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Acknowledge work order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/audit-report": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the tamper-evident lifecycle report of a work order: its audit logs, status history and progress entries. The checksum is the SHA-256 of the report as canonical JSON (object keys sorted, no whitespace, no HTML escaping); when AUDIT_REPORT_SIGNING_KEY is set, the signature is the HMAC-SHA256 of the same bytes. With format=pdf the report is downloaded as a PDF with the checksum and signature printed. (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "audit-logs"
+                ],
+                "summary": "Get work order audit report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (default) or pdf",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date format of the PDF: iso (default), raw, en-US or id-ID; falls back to Accept-Language",
+                        "name": "locale",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AuditReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/burndown": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get how the produced quantity approached the target over the work order's life: a point at creation, one per progress entry and one at the production deadline, each with the cumulative progress quantity and the ideal quantity of a straight line from zero at creation to the target at the deadline",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress"
+                ],
+                "summary": "Get work order burndown",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.BurndownResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/field-history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the chronological changes of a single work order field across its audit log (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit-logs"
+                ],
+                "summary": "Get work order field history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field name as stored in the audit log (e.g. status)",
+                        "name": "field",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.FieldHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the status history for a work order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress"
+                ],
+                "summary": "Get work order status history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.StatusHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a paginated list of the audit log entries of a work order, newest first. Operators only see the logs of work orders assigned to them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Get work order logs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a note to the work order log, optionally changing its status with the same details as the status endpoint (assigned Operator or Production Manager)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Create work order log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Log note and optional status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateWorkOrderLogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderLogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Work order completed or changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AlreadyCompletedErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/progress": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all progress entries for a work order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress"
+                ],
+                "summary": "Get work order progress",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProgressListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new progress entry for a work order. The work order must be in progress; with AUTO_START_ON_PROGRESS a pending work order is moved to in_progress first, within the operator's active order limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress"
+                ],
+                "summary": "Create progress entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Progress details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CreateProgressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProgressResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, progress below MIN_PROGRESS_QUANTITY or exceeding the target quantity",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProgressLimitErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Auto-start would exceed MAX_ACTIVE_ORDERS_PER_OPERATOR",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CapacityErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/progress/{progress_id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sign off a logged progress entry. With the progress_approval feature switched on, a work order can only be completed once all its progress is approved. Approving an approved entry keeps the first approval. (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress"
+                ],
+                "summary": "Approve work order progress",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Progress entry ID",
+                        "name": "progress_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ProgressResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/snooze-overdue": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Acknowledge that a work order is overdue and stop its overdue reminders for the given number of hours, at most 30 days (Production Manager only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Snooze overdue reminders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Snooze duration",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SnoozeOverdueRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update a work order status and produced quantity (assigned Operator, or Production Manager with a reason). Operators setting order fields such as target_quantity or product_name get 403.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Update work order status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status update details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateWorkOrderStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Work order already completed or changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.AlreadyCompletedErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/status-durations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get how long a work order spent in each status, computed from its status history. The current status counts up to now unless it is terminal.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "progress"
+                ],
+                "summary": "Get work order status durations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.StatusDurationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/tags": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Put existing tags on a work order, tags it already carries are kept. Operators can only tag the work orders assigned to them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Add tags to work order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag names",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/tags/{tag_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Take a tag off a work order. Operators can only untag the work orders assigned to them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Remove tag from work order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tag_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.WorkOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/work-orders/{id}/transitions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the statuses the current user may move a work order to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "work-orders"
+                ],
+                "summary": "Get work order status transitions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Work order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.StatusTransitionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.AcknowledgeAllRequest": {
+            "type": "object",
+            "properties": {
+                "operator_id": {
+                    "description": "required for Production Managers, operators always acknowledge their own orders",
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.AcknowledgeAllResponse": {
+            "type": "object",
+            "properties": {
+                "acknowledged": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "work_order_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "controllers.AgingBucket": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "description": "0-1d, 1-3d, 3-7d or 7+d",
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.AgingResponse": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "description": "youngest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.AgingBucket"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.AlreadyCompletedErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "final_quantity": {
+                    "description": "quantity recorded by the completion that went through",
+                    "type": "integer"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AuditLogDTO": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/models.ActionType"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "entity_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "new_values": {
+                    "type": "object"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "old_values": {
+                    "type": "object"
+                },
+                "user": {
+                    "description": "only set when the user is loaded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controllers.UserDTO"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.AuditLogDetailResponse": {
+            "type": "object",
+            "properties": {
+                "audit_log": {
+                    "$ref": "#/definitions/controllers.AuditLogDTO"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.FieldChange"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.AuditLogListResponse": {
+            "type": "object",
+            "properties": {
+                "audit_logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.AuditLogDTO"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "description": "pass as ?after= to get the next page, empty on the last page",
+                    "type": "string"
+                },
+                "pagination": {
+                    "description": "only set for offset paging",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controllers.Pagination"
+                        }
+                    ]
+                }
+            }
+        },
+        "controllers.AuditLogSearchGroup": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.AuditLogDTO"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.AuditReport": {
+            "type": "object",
+            "properties": {
+                "audit_logs": {
+                    "description": "chronological",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.AuditLogDTO"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "generated_by": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "description": "chronological",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ProgressDTO"
+                    }
+                },
+                "status_history": {
+                    "description": "chronological",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.StatusHistoryDTO"
+                    }
+                },
+                "work_order": {
+                    "$ref": "#/definitions/controllers.WorkOrderDTO"
+                }
+            }
+        },
+        "controllers.AuditReportResponse": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "description": "hex SHA-256 of the report as canonical JSON",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "report": {
+                    "$ref": "#/definitions/controllers.AuditReport"
+                },
+                "signature": {
+                    "description": "hex HMAC-SHA256 of the same bytes, when a signing key is configured",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.BatchGetWorkOrdersRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "controllers.BatchGetWorkOrdersResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "missing_ids": {
+                    "description": "requested IDs that were not found or are not visible to the user",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "work_orders": {
+                    "description": "in the order the IDs were requested",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.WorkOrderDTO"
+                    }
+                }
+            }
+        },
+        "controllers.BurndownPoint": {
+            "type": "object",
+            "properties": {
+                "ideal": {
+                    "description": "quantity due by then on a straight line from creation to the deadline",
+                    "type": "number"
+                },
+                "produced": {
+                    "description": "cumulative progress quantity",
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.BurndownResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "points": {
+                    "description": "chronological",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.BurndownPoint"
+                    }
+                },
+                "production_deadline": {
+                    "type": "string"
+                },
+                "target_quantity": {
+                    "type": "integer"
+                },
+                "work_order_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.CapacityErrorResponse": {
+            "type": "object",
+            "properties": {
+                "active_orders": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ChangePasswordResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "fresh token without the password change restriction",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.CreateProgressRequest": {
+            "type": "object",
+            "required": [
+                "progress_description",
+                "progress_quantity"
+            ],
+            "properties": {
+                "progress_description": {
+                    "type": "string"
+                },
+                "progress_quantity": {
+                    "description": "at least MIN_PROGRESS_QUANTITY",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "controllers.CreateTagRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.CreateWorkOrderLogRequest": {
+            "type": "object",
+            "required": [
+                "note"
+            ],
+            "properties": {
+                "backorder_deadline": {
+                    "description": "BackorderDeadline is the deadline of the spawned backorder, defaults to the original deadline",
+                    "type": "string"
+                },
+                "cancellation_reason": {
+                    "description": "CancellationReason is required when the remaining quantity is cancelled",
+                    "type": "string"
+                },
+                "hold_reason": {
+                    "description": "required when moving to on_hold",
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "remaining_disposition": {
+                    "description": "RemainingDisposition is required when completing below the target quantity",
+                    "enum": [
+                        "cancelled",
+                        "backorder"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RemainingDisposition"
+                        }
+                    ]
+                },
+                "restock_note": {
+                    "description": "RestockNote records the materials of a cancelled remainder returned to stock",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkOrderStatus"
+                }
+            }
+        },
+        "controllers.CreateWorkOrderRequest": {
+            "type": "object",
+            "required": [
+                "operator_id",
+                "product_name",
+                "production_deadline",
+                "quantity",
+                "target_quantity"
+            ],
+            "properties": {
+                "allow_deadline_outside_window": {
+                    "description": "AllowDeadlineOutsideWindow accepts a deadline outside DEADLINE_MIN_LEAD_HOURS..DEADLINE_MAX_HORIZON_DAYS, audit logged",
+                    "type": "boolean"
+                },
+                "allow_past_deadline": {
+                    "description": "accept a deadline in the past, e.g. for data imports",
+                    "type": "boolean"
+                },
+                "force": {
+                    "description": "override the operator's active order limit and the duplicate active order check",
+                    "type": "boolean"
+                },
+                "operator_id": {
+                    "type": "integer"
+                },
+                "priority": {
+                    "description": "higher is started first, 0 by default",
+                    "type": "integer",
+                    "maximum": 9,
+                    "minimum": 0
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "production_deadline": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "target_quantity": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "unit": {
+                    "description": "unit of measure of the quantities, defaults to pcs",
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "controllers.DailyProductionResponse": {
+            "type": "object",
+            "properties": {
+                "counters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DailyProductionCounter"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.DashboardResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "summary": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.WorkOrderDashboard"
+                    }
+                }
+            }
+        },
+        "controllers.DeadlineWindow": {
+            "type": "object",
+            "properties": {
+                "earliest": {
+                    "description": "nil without a minimum lead time",
+                    "type": "string"
+                },
+                "latest": {
+                    "description": "nil without a maximum horizon",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.DeadlineWindowErrorResponse": {
+            "type": "object",
+            "properties": {
+                "allowed_window": {
+                    "$ref": "#/definitions/controllers.DeadlineWindow"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.DuplicateActiveOrderErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "existing_work_order_number": {
+                    "type": "string"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.EnabledFeaturesResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.EnumsResponse": {
+            "type": "object",
+            "properties": {
+                "action_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ActionType"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "remaining_dispositions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RemainingDisposition"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Role"
+                    }
+                },
+                "work_order_statuses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WorkOrderStatus"
+                    }
+                }
+            }
+        },
+        "controllers.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "machine readable error code, set for errors clients handle specifically",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "msg": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.FeatureFlagListResponse": {
+            "type": "object",
+            "properties": {
+                "defaults": {
+                    "description": "enabled through FEATURE_FLAGS unless stored as disabled",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "flags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FeatureFlag"
+                    }
+                }
+            }
+        },
+        "controllers.FeatureFlagResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "flag": {
+                    "$ref": "#/definitions/models.FeatureFlag"
+                }
+            }
+        },
+        "controllers.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {
+                    "description": "null when the field was removed",
+                    "type": "object"
+                },
+                "old": {
+                    "description": "null when the field had no previous value",
+                    "type": "object"
+                }
+            }
+        },
+        "controllers.FieldHistoryEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/models.ActionType"
+                },
+                "audit_log_id": {
+                    "type": "integer"
+                },
+                "changed_at": {
+                    "type": "string"
+                },
+                "new": {
+                    "type": "object"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "old": {
+                    "type": "object"
+                },
+                "user_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.FieldHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.FieldHistoryEntry"
+                    }
+                }
+            }
+        },
+        "controllers.ForecastOrder": {
+            "type": "object",
+            "properties": {
+                "at_risk": {
+                    "description": "the projected completion is after the deadline",
+                    "type": "boolean"
+                },
+                "cumulative_remaining": {
+                    "description": "remaining quantity of this and all earlier-deadline orders",
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "production_deadline": {
+                    "type": "string"
+                },
+                "projected_completion": {
+                    "description": "nil when the operator has no throughput",
+                    "type": "string"
+                },
+                "remaining_quantity": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkOrderStatus"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                },
+                "work_order_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "operator": {
+                    "$ref": "#/definitions/controllers.UserDTO"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ImportRowResult": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "row": {
+                    "description": "line number in the CSV file, the header is line 1",
+                    "type": "integer"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                },
+                "work_order_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ImportWorkOrdersResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "processed": {
+                    "description": "rows read from start_row on",
+                    "type": "integer"
+                },
+                "resume_from": {
+                    "description": "start_row that continues a partly created import",
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ImportRowResult"
+                    }
+                },
+                "validate_only": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.KPIResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "kpis": {
+                    "$ref": "#/definitions/controllers.WorkOrderKPIs"
+                }
+            }
+        },
+        "controllers.LeadTimeResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "group_by": {
+                    "type": "string"
+                },
+                "groups": {
+                    "description": "empty without group_by",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.LeadTimeStats"
+                    }
+                },
+                "overall": {
+                    "$ref": "#/definitions/controllers.LeadTimeStats"
+                }
+            }
+        },
+        "controllers.LeadTimeStats": {
+            "type": "object",
+            "properties": {
+                "average_hours": {
+                    "type": "number"
+                },
+                "group": {
+                    "description": "product name or operator username, empty for all orders",
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "p50_hours": {
+                    "type": "number"
+                },
+                "p90_hours": {
+                    "type": "number"
+                },
+                "p99_hours": {
+                    "type": "number"
+                }
+            }
+        },
+        "controllers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "type": "integer"
+                        },
+                        "must_change_password": {
+                            "type": "boolean"
+                        },
+                        "role": {
+                            "$ref": "#/definitions/models.Role"
+                        },
+                        "username": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "controllers.MaintenanceModeRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.MaintenanceModeResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "maintenance": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.MatchOffset": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.MeResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "type": "integer"
+                        },
+                        "role": {
+                            "$ref": "#/definitions/models.Role"
+                        },
+                        "username": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "controllers.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.NextBusinessDayResponse": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.NextWorkOrderResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "overdue": {
+                    "description": "past its production deadline",
+                    "type": "boolean"
+                },
+                "work_order": {
+                    "$ref": "#/definitions/controllers.WorkOrderDTO"
+                }
+            }
+        },
+        "controllers.NotificationPreferenceDTO": {
+            "type": "object",
+            "properties": {
+                "email_on_assignment": {
+                    "type": "boolean"
+                },
+                "email_on_overdue": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.NotificationPreferenceSummary": {
+            "type": "object",
+            "properties": {
+                "email_on_assignment": {
+                    "type": "integer"
+                },
+                "email_on_overdue": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.NotificationPreferenceSummaryResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "summary": {
+                    "$ref": "#/definitions/controllers.NotificationPreferenceSummary"
+                }
+            }
+        },
+        "controllers.NotificationPreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "preferences": {
+                    "$ref": "#/definitions/controllers.NotificationPreferenceDTO"
+                }
+            }
+        },
+        "controllers.OperatorComparison": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "type": "integer"
+                },
+                "average_lead_time_hours": {
+                    "description": "from creation to completion of the completed orders",
+                    "type": "number"
+                },
+                "completed": {
+                    "type": "integer"
+                },
+                "flagged": {
+                    "type": "boolean"
+                },
+                "in_progress": {
+                    "type": "integer"
+                },
+                "on_time_rate": {
+                    "description": "percentage of completed orders finished by their deadline",
+                    "type": "integer"
+                },
+                "operator_id": {
+                    "type": "integer"
+                },
+                "throughput_per_day": {
+                    "description": "completed quantity per day in the range",
+                    "type": "number"
+                },
+                "total_quantity": {
+                    "type": "integer"
+                },
+                "trailing_throughput_per_day": {
+                    "description": "same metric over the preceding period of equal length",
+                    "type": "number"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OperatorComparisonResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "operator_ids": {
+                    "description": "in the requested order",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "operators": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/controllers.OperatorComparison"
+                    }
+                }
+            }
+        },
+        "controllers.OperatorForecastResponse": {
+            "type": "object",
+            "properties": {
+                "at_risk_orders": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "lookback_days": {
+                    "description": "days the historical throughput was measured over, 0 when overridden",
+                    "type": "integer"
+                },
+                "operator_id": {
+                    "type": "integer"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ForecastOrder"
+                    }
+                },
+                "throughput_per_day": {
+                    "type": "number"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OperatorPerformance": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "type": "integer"
+                },
+                "completed": {
+                    "type": "integer"
+                },
+                "flagged": {
+                    "type": "boolean"
+                },
+                "in_progress": {
+                    "type": "integer"
+                },
+                "operator_id": {
+                    "type": "integer"
+                },
+                "throughput_per_day": {
+                    "description": "completed quantity per day in the range",
+                    "type": "number"
+                },
+                "total_quantity": {
+                    "type": "integer"
+                },
+                "trailing_throughput_per_day": {
+                    "description": "same metric over the preceding period of equal length",
+                    "type": "number"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OperatorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "operators": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.UserDTO"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/controllers.Pagination"
+                }
+            }
+        },
+        "controllers.OperatorSearchGroup": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.UserDTO"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.OperatorStatusMatrixResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "operators": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.OperatorStatusMatrixRow"
+                    }
+                }
+            }
+        },
+        "controllers.OperatorStatusMatrixRow": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer"
+                },
+                "in_progress": {
+                    "type": "integer"
+                },
+                "on_hold": {
+                    "type": "integer"
+                },
+                "operator_id": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "produced_quantity": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.OutboxJobListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OutboxJob"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/controllers.Pagination"
+                }
+            }
+        },
+        "controllers.Pagination": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "pages": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.PerfResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "PERF_PROFILER, nothing is recorded when false",
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "routes": {
+                    "description": "slowest p95 first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/middleware.RouteTiming"
+                    }
+                },
+                "since": {
+                    "description": "startup or the last reset",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.PerformanceResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "performances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.OperatorPerformance"
+                    }
+                }
+            }
+        },
+        "controllers.PeriodChange": {
+            "type": "object",
+            "properties": {
+                "completed_quantity": {
+                    "description": "percent",
+                    "type": "number"
+                },
+                "on_time_rate": {
+                    "description": "percentage points",
+                    "type": "number"
+                },
+                "orders_completed": {
+                    "description": "percent",
+                    "type": "number"
+                },
+                "orders_created": {
+                    "description": "percent",
+                    "type": "number"
+                }
+            }
+        },
+        "controllers.PeriodMetrics": {
+            "type": "object",
+            "properties": {
+                "completed_quantity": {
+                    "type": "integer"
+                },
+                "end": {
+                    "description": "exclusive",
+                    "type": "string"
+                },
+                "on_time_rate": {
+                    "description": "percentage of the completed orders finished by their deadline",
+                    "type": "number"
+                },
+                "orders_completed": {
+                    "type": "integer"
+                },
+                "orders_created": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ProgressDTO": {
+            "type": "object",
+            "properties": {
+                "approved_at": {
+                    "type": "string"
+                },
+                "approved_by_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "progress_desc": {
+                    "type": "string"
+                },
+                "progress_quantity": {
+                    "type": "integer"
+                },
+                "reported_by": {
+                    "description": "only set when the reporter is loaded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controllers.UserDTO"
+                        }
+                    ]
+                },
+                "reported_by_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.ProgressFeedEntry": {
+            "type": "object",
+            "properties": {
+                "approved_at": {
+                    "type": "string"
+                },
+                "approved_by_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "progress_desc": {
+                    "type": "string"
+                },
+                "progress_quantity": {
+                    "type": "integer"
+                },
+                "reported_by": {
+                    "description": "only set when the reporter is loaded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controllers.UserDTO"
+                        }
+                    ]
+                },
+                "reported_by_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                },
+                "work_order_number": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ProgressFeedResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "pagination": {
+                    "$ref": "#/definitions/controllers.Pagination"
+                },
+                "progress": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ProgressFeedEntry"
+                    }
+                }
+            }
+        },
+        "controllers.ProgressLimitErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "msg": {
+                    "type": "string"
+                },
+                "remaining": {
+                    "description": "quantity that can still be reported",
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.ProgressListResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "progress": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ProgressDTO"
+                    }
+                }
+            }
+        },
+        "controllers.ProgressResponse": {
+            "type": "object",
             "properties": {
-                "progress_description": {
-                    "type": "string"
+                "error": {
+                    "type": "boolean"
                 },
-                "progress_quantity": {
-                    "type": "integer",
-                    "minimum": 0
+                "progress": {
+                    "$ref": "#/definitions/controllers.ProgressDTO"
                 }
             }
         },
-        "controllers.CreateWorkOrderRequest": {
+        "controllers.RegisterRequest": {
             "type": "object",
             "required": [
-                "operator_id",
-                "product_name",
-                "production_deadline",
-                "quantity"
+                "password",
+                "role",
+                "username"
             ],
             "properties": {
-                "operator_id": {
+                "password": {
+                    "description": "checked against the password policy",
+                    "type": "string"
+                },
+                "role": {
+                    "enum": [
+                        "production_manager",
+                        "operator"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    ]
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 3
+                }
+            }
+        },
+        "controllers.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "user": {
+                    "type": "object",
+                    "properties": {
+                        "id": {
+                            "type": "integer"
+                        },
+                        "role": {
+                            "$ref": "#/definitions/models.Role"
+                        },
+                        "username": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "controllers.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "must_change_password": {
+                    "description": "defaults to true",
+                    "type": "boolean"
+                },
+                "password": {
+                    "description": "optional, a random password is generated when empty",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ResetPasswordResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "must_change_password": {
+                    "type": "boolean"
+                },
+                "password": {
+                    "description": "only returned when the password was generated",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.SearchMatch": {
+            "type": "object",
+            "properties": {
+                "matched_field": {
+                    "description": "product_name or work_order_number",
+                    "type": "string"
+                },
+                "offsets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.MatchOffset"
+                    }
+                },
+                "work_order_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "controllers.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "audit_logs": {
+                    "description": "only for Production Managers",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controllers.AuditLogSearchGroup"
+                        }
+                    ]
                 },
-                "product_name": {
+                "error": {
+                    "type": "boolean"
+                },
+                "operators": {
+                    "$ref": "#/definitions/controllers.OperatorSearchGroup"
+                },
+                "query": {
                     "type": "string"
                 },
-                "production_deadline": {
+                "work_orders": {
+                    "$ref": "#/definitions/controllers.WorkOrderSearchGroup"
+                }
+            }
+        },
+        "controllers.ServerTimeResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "max_page_size": {
+                    "description": "largest accepted limit of the paginated lists",
+                    "type": "integer"
+                },
+                "server_time": {
+                    "description": "current time in UTC",
                     "type": "string"
                 },
-                "quantity": {
+                "timezone": {
+                    "description": "APP_TIMEZONE, or the system timezone when unset",
+                    "type": "string"
+                },
+                "token_expires_in_hours": {
+                    "type": "integer"
+                },
+                "utc_offset_seconds": {
+                    "description": "current offset of the timezone",
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.SetFeatureFlagRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.SnoozeOverdueRequest": {
+            "type": "object",
+            "required": [
+                "duration_hours"
+            ],
+            "properties": {
+                "duration_hours": {
                     "type": "integer",
                     "minimum": 1
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.StatusDuration": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "e.g. 26h3m0s",
+                    "type": "string"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkOrderStatus"
+                }
+            }
+        },
+        "controllers.StatusDurationsResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.StatusPeriod"
+                    }
+                },
+                "totals": {
+                    "description": "in the order the statuses were first entered",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.StatusDuration"
+                    }
+                }
+            }
+        },
+        "controllers.StatusHistoryDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkOrderStatus"
+                },
+                "work_order_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.StatusHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.StatusHistoryDTO"
+                    }
+                }
+            }
+        },
+        "controllers.StatusPeriod": {
+            "type": "object",
+            "properties": {
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "ended_at": {
+                    "description": "nil while the work order is still in the status",
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkOrderStatus"
+                }
+            }
+        },
+        "controllers.StatusTransitionsResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkOrderStatus"
+                },
+                "transitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WorkOrderStatus"
+                    }
+                }
+            }
+        },
+        "controllers.SummaryCompareResponse": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "$ref": "#/definitions/controllers.PeriodChange"
+                },
+                "current": {
+                    "$ref": "#/definitions/controllers.PeriodMetrics"
+                },
+                "error": {
+                    "type": "boolean"
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "previous": {
+                    "$ref": "#/definitions/controllers.PeriodMetrics"
                 }
             }
         },
-        "controllers.ErrorResponse": {
+        "controllers.SummaryResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "boolean"
                 },
-                "msg": {
-                    "type": "string"
+                "summary": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.WorkOrderSummary"
+                    }
                 }
             }
         },
-        "controllers.LoginRequest": {
+        "controllers.TagDTO": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
-                    "type": "string"
+                "id": {
+                    "type": "integer"
                 },
-                "username": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "controllers.LoginResponse": {
+        "controllers.TagListResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "boolean"
                 },
-                "token": {
-                    "type": "string"
-                },
-                "user": {
-                    "type": "object",
-                    "properties": {
-                        "id": {
-                            "type": "integer"
-                        },
-                        "role": {
-                            "$ref": "#/definitions/models.Role"
-                        },
-                        "username": {
-                            "type": "string"
-                        }
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.TagDTO"
                     }
                 }
             }
         },
-        "controllers.OperatorPerformance": {
+        "controllers.TagResponse": {
             "type": "object",
             "properties": {
-                "assigned": {
-                    "type": "integer"
+                "error": {
+                    "type": "boolean"
                 },
-                "completed": {
+                "tag": {
+                    "$ref": "#/definitions/controllers.TagDTO"
+                }
+            }
+        },
+        "controllers.TargetChange": {
+            "type": "object",
+            "properties": {
+                "new_target": {
                     "type": "integer"
                 },
-                "in_progress": {
+                "old_target": {
                     "type": "integer"
                 },
-                "operator_id": {
+                "produced": {
+                    "description": "produced quantity the new target applies to",
                     "type": "integer"
                 },
-                "total_quantity": {
+                "progress_entries": {
+                    "description": "progress entries logged against the old target",
                     "type": "integer"
+                }
+            }
+        },
+        "controllers.TargetChangeErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
                 },
-                "username": {
+                "error": {
+                    "type": "boolean"
+                },
+                "msg": {
                     "type": "string"
+                },
+                "target_change": {
+                    "$ref": "#/definitions/controllers.TargetChange"
                 }
             }
         },
-        "controllers.PerformanceResponse": {
+        "controllers.UpcomingLoadResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "boolean"
                 },
-                "performances": {
+                "weeks": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/controllers.OperatorPerformance"
+                        "$ref": "#/definitions/controllers.WeeklyLoad"
                     }
                 }
             }
         },
-        "controllers.ProgressListResponse": {
+        "controllers.UpdateNotificationPreferencesRequest": {
             "type": "object",
             "properties": {
-                "error": {
+                "email_on_assignment": {
                     "type": "boolean"
                 },
-                "progress": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.WorkOrderProgress"
-                    }
+                "email_on_overdue": {
+                    "type": "boolean"
                 }
             }
         },
-        "controllers.ProgressResponse": {
+        "controllers.UpdateWorkOrderRequest": {
+            "type": "object",
+            "properties": {
+                "allow_deadline_outside_window": {
+                    "description": "AllowDeadlineOutsideWindow accepts a deadline outside DEADLINE_MIN_LEAD_HOURS..DEADLINE_MAX_HORIZON_DAYS, audit logged",
+                    "type": "boolean"
+                },
+                "backorder_deadline": {
+                    "description": "BackorderDeadline is the deadline of the spawned backorder, defaults to the original deadline",
+                    "type": "string"
+                },
+                "cancellation_reason": {
+                    "description": "CancellationReason is required when the remaining quantity is cancelled",
+                    "type": "string"
+                },
+                "force": {
+                    "description": "override the operator's active order limit",
+                    "type": "boolean"
+                },
+                "hold_reason": {
+                    "description": "required when moving to on_hold",
+                    "type": "string"
+                },
+                "operator_id": {
+                    "type": "integer"
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 9,
+                    "minimum": 0
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "production_deadline": {
+                    "type": "string"
+                },
+                "remaining_disposition": {
+                    "description": "RemainingDisposition is required when completing below the target quantity",
+                    "enum": [
+                        "cancelled",
+                        "backorder"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RemainingDisposition"
+                        }
+                    ]
+                },
+                "restock_note": {
+                    "description": "RestockNote records the materials of a cancelled remainder returned to stock",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.WorkOrderStatus"
+                },
+                "target_quantity": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "unit": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "controllers.UpdateWorkOrderResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "boolean"
                 },
-                "progress": {
-                    "$ref": "#/definitions/models.WorkOrderProgress"
+                "target_change": {
+                    "$ref": "#/definitions/controllers.TargetChange"
+                },
+                "warning": {
+                    "type": "string"
+                },
+                "work_order": {
+                    "$ref": "#/definitions/controllers.WorkOrderDTO"
                 }
             }
         },
-        "controllers.RegisterRequest": {
+        "controllers.UpdateWorkOrderStatusRequest": {
             "type": "object",
             "required": [
-                "password",
-                "role",
-                "username"
+                "status"
             ],
             "properties": {
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "backorder_deadline": {
+                    "description": "BackorderDeadline is the deadline of the spawned backorder, defaults to the original deadline",
+                    "type": "string"
                 },
-                "role": {
+                "cancellation_reason": {
+                    "description": "CancellationReason is required when the remaining quantity is cancelled",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "force": {
+                    "description": "Force lets a Production Manager override the operator's active order limit",
+                    "type": "boolean"
+                },
+                "hold_reason": {
+                    "description": "required when moving to on_hold",
+                    "type": "string"
+                },
+                "quantity": {
+                    "description": "Quantity is the produced quantity; the target quantity can only be changed by a Production Manager through PUT /work-orders/{id}",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "reason": {
+                    "description": "Reason is required when a Production Manager changes the status on behalf of the assigned operator",
+                    "type": "string"
+                },
+                "remaining_disposition": {
+                    "description": "RemainingDisposition is required when completing below the target quantity",
                     "enum": [
-                        "production_manager",
-                        "operator"
+                        "cancelled",
+                        "backorder"
                     ],
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.Role"
+                            "$ref": "#/definitions/models.RemainingDisposition"
                         }
                     ]
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 50,
-                    "minLength": 3
+                "restock_note": {
+                    "description": "RestockNote records the materials of a cancelled remainder returned to stock",
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "in_progress",
+                        "on_hold",
+                        "completed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.WorkOrderStatus"
+                        }
+                    ]
                 }
             }
         },
-        "controllers.RegisterResponse": {
+        "controllers.UserDTO": {
             "type": "object",
             "properties": {
-                "error": {
+                "active": {
                     "type": "boolean"
                 },
-                "token": {
+                "created_at": {
                     "type": "string"
                 },
-                "user": {
-                    "type": "object",
-                    "properties": {
-                        "id": {
-                            "type": "integer"
-                        },
-                        "role": {
-                            "$ref": "#/definitions/models.Role"
-                        },
-                        "username": {
-                            "type": "string"
-                        }
-                    }
+                "deleted": {
+                    "description": "the user was soft-deleted, e.g. a former employee still assigned to orders",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_login_at": {
+                    "type": "string"
+                },
+                "plant_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/models.Role"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "controllers.StatusHistoryResponse": {
+        "controllers.UserResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "boolean"
                 },
-                "history": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.WorkOrderStatusHistory"
-                    }
+                "user": {
+                    "$ref": "#/definitions/controllers.UserDTO"
                 }
             }
         },
-        "controllers.SummaryResponse": {
+        "controllers.ValidationErrorResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "boolean"
                 },
-                "summary": {
+                "errors": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/controllers.WorkOrderSummary"
+                        "$ref": "#/definitions/validator.FieldError"
                     }
+                },
+                "msg": {
+                    "type": "string"
                 }
             }
         },
-        "controllers.UpdateWorkOrderRequest": {
+        "controllers.WeeklyLoad": {
+            "type": "object",
+            "properties": {
+                "orders": {
+                    "type": "integer"
+                },
+                "total_target_quantity": {
+                    "type": "integer"
+                },
+                "week": {
+                    "description": "ISO week, e.g. 2026-W42",
+                    "type": "string"
+                },
+                "week_start": {
+                    "description": "Monday, YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.WorkOrderDTO": {
             "type": "object",
             "properties": {
+                "acknowledged": {
+                    "type": "boolean"
+                },
+                "acknowledged_at": {
+                    "type": "string"
+                },
+                "cancellation_reason": {
+                    "type": "string"
+                },
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.WorkOrderDTO"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hold_reason": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "operator": {
+                    "description": "only set when the operator is loaded",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/controllers.UserDTO"
+                        }
+                    ]
+                },
                 "operator_id": {
                     "type": "integer"
                 },
+                "overdue_snoozed_until": {
+                    "description": "overdue reminders are snoozed until then",
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "integer"
+                },
+                "plant_id": {
+                    "type": "integer"
+                },
+                "priority": {
+                    "type": "integer"
+                },
                 "product_name": {
                     "type": "string"
                 },
@@ -1020,35 +6915,68 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "quantity": {
-                    "type": "integer",
-                    "minimum": 1
+                    "type": "integer"
+                },
+                "remaining_disposition": {
+                    "$ref": "#/definitions/models.RemainingDisposition"
+                },
+                "restock_note": {
+                    "type": "string"
                 },
                 "status": {
                     "$ref": "#/definitions/models.WorkOrderStatus"
+                },
+                "tags": {
+                    "description": "only set when the order has tags and they are loaded",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.TagDTO"
+                    }
+                },
+                "target_quantity": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "work_order_number": {
+                    "type": "string"
                 }
             }
         },
-        "controllers.UpdateWorkOrderStatusRequest": {
+        "controllers.WorkOrderDashboard": {
             "type": "object",
-            "required": [
-                "status"
-            ],
             "properties": {
-                "quantity": {
-                    "type": "integer",
-                    "minimum": 0
+                "count": {
+                    "type": "integer"
                 },
                 "status": {
-                    "enum": [
-                        "pending",
-                        "in_progress",
-                        "completed"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.WorkOrderStatus"
-                        }
-                    ]
+                    "$ref": "#/definitions/models.WorkOrderStatus"
+                }
+            }
+        },
+        "controllers.WorkOrderKPIs": {
+            "type": "object",
+            "properties": {
+                "active_operators": {
+                    "description": "operators holding in-progress orders",
+                    "type": "integer"
+                },
+                "completed_orders": {
+                    "type": "integer"
+                },
+                "completion_rate": {
+                    "description": "percentage of completed orders",
+                    "type": "integer"
+                },
+                "overdue_orders": {
+                    "type": "integer"
+                },
+                "total_orders": {
+                    "type": "integer"
                 }
             }
         },
@@ -1058,6 +6986,13 @@ const docTemplate = `{
                 "error": {
                     "type": "boolean"
                 },
+                "matches": {
+                    "description": "only populated when a search term is given",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.SearchMatch"
+                    }
+                },
                 "pagination": {
                     "type": "object",
                     "properties": {
@@ -1078,8 +7013,39 @@ const docTemplate = `{
                 "work_orders": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.WorkOrder"
+                        "$ref": "#/definitions/controllers.WorkOrderDTO"
+                    }
+                }
+            }
+        },
+        "controllers.WorkOrderLogResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "work_order": {
+                    "$ref": "#/definitions/controllers.WorkOrderDTO"
+                }
+            }
+        },
+        "controllers.WorkOrderLogsResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "boolean"
+                },
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.AuditLogDTO"
                     }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/controllers.Pagination"
                 }
             }
         },
@@ -1090,155 +7056,305 @@ const docTemplate = `{
                     "type": "boolean"
                 },
                 "work_order": {
-                    "$ref": "#/definitions/models.WorkOrder"
+                    "$ref": "#/definitions/controllers.WorkOrderDTO"
+                }
+            }
+        },
+        "controllers.WorkOrderSearchGroup": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.WorkOrderDTO"
+                    }
+                },
+                "total": {
+                    "description": "all matches, more than the returned items when capped",
+                    "type": "integer"
                 }
             }
         },
         "controllers.WorkOrderSummary": {
             "type": "object",
             "properties": {
+                "achieved_qty": {
+                    "type": "integer"
+                },
+                "achievement": {
+                    "type": "integer"
+                },
+                "cancelled": {
+                    "type": "integer"
+                },
+                "cancelled_qty": {
+                    "type": "integer"
+                },
                 "completed": {
                     "type": "integer"
                 },
-                "count": {
+                "in_progress": {
                     "type": "integer"
                 },
-                "quantity": {
+                "mixed_units": {
+                    "description": "set on the total row when products use different units, its quantities are then left at zero",
+                    "type": "boolean"
+                },
+                "on_hold": {
                     "type": "integer"
                 },
-                "status": {
-                    "$ref": "#/definitions/models.WorkOrderStatus"
+                "partially_cancelled": {
+                    "description": "Completed orders whose remaining quantity was cancelled, the cancelled quantity and how many noted a restock",
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "percentage": {
+                    "type": "integer"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "restocked": {
+                    "type": "integer"
+                },
+                "target_qty": {
+                    "type": "integer"
+                },
+                "total_wo": {
+                    "type": "integer"
+                },
+                "unit": {
+                    "description": "unit of the quantities, empty on a total row with mixed units",
+                    "type": "string"
+                },
+                "work_order_number": {
+                    "type": "string"
                 }
             }
         },
-        "models.Role": {
+        "controllers.WorkOrderTagsRequest": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "description": "names of existing tags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "middleware.RouteTiming": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "max_ms": {
+                    "type": "number"
+                },
+                "mean_ms": {
+                    "type": "number"
+                },
+                "p50_ms": {
+                    "description": "estimated from the histogram, see perfBuckets",
+                    "type": "number"
+                },
+                "p95_ms": {
+                    "description": "estimated from the histogram, see perfBuckets",
+                    "type": "number"
+                },
+                "route": {
+                    "description": "method and registered path, e.g. GET /api/work-orders/:id",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ActionType": {
             "type": "string",
             "enum": [
-                "production_manager",
-                "operator"
+                "create",
+                "update",
+                "delete",
+                "custom"
             ],
             "x-enum-varnames": [
-                "RoleProductionManager",
-                "RoleOperator"
+                "ActionCreate",
+                "ActionUpdate",
+                "ActionDelete",
+                "ActionCustom"
             ]
         },
-        "models.User": {
+        "models.DailyProductionCounter": {
             "type": "object",
             "properties": {
+                "completed": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "date": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "role": {
-                    "$ref": "#/definitions/models.Role"
+                "in_progress": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "on_hold": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "plant_id": {
+                    "type": "integer"
+                },
+                "produced_quantity": {
+                    "type": "integer"
+                },
+                "product_name": {
                     "type": "string"
                 },
-                "username": {
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.WorkOrder": {
+        "models.FeatureFlag": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "operator": {
-                    "$ref": "#/definitions/models.User"
-                },
-                "operator_id": {
-                    "type": "integer"
-                },
-                "product_name": {
+                "description": {
                     "type": "string"
                 },
-                "production_deadline": {
-                    "type": "string"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "quantity": {
+                "id": {
                     "type": "integer"
                 },
-                "status": {
-                    "$ref": "#/definitions/models.WorkOrderStatus"
-                },
-                "updated_at": {
+                "name": {
                     "type": "string"
                 },
-                "work_order_number": {
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.WorkOrderProgress": {
+        "models.OutboxJob": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "progress_description": {
+                "kind": {
                     "type": "string"
                 },
-                "progress_quantity": {
-                    "type": "integer"
+                "last_error": {
+                    "type": "string"
                 },
-                "updated_at": {
+                "locked_at": {
+                    "description": "when a worker claimed the job",
                     "type": "string"
                 },
-                "work_order": {
-                    "$ref": "#/definitions/models.WorkOrder"
+                "max_attempts": {
+                    "type": "integer"
                 },
-                "work_order_id": {
+                "payload": {
+                    "description": "JSON input of the kind's handler",
+                    "type": "string"
+                },
+                "plant_id": {
                     "type": "integer"
+                },
+                "run_at": {
+                    "description": "earliest time of the next attempt",
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.OutboxJobStatus"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
+        "models.OutboxJobStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "done",
+                "dead"
+            ],
+            "x-enum-varnames": [
+                "JobPending",
+                "JobRunning",
+                "JobDone",
+                "JobDead"
+            ]
+        },
+        "models.RemainingDisposition": {
+            "type": "string",
+            "enum": [
+                "cancelled",
+                "backorder"
+            ],
+            "x-enum-varnames": [
+                "DispositionCancelled",
+                "DispositionBackorder"
+            ]
+        },
+        "models.Role": {
+            "type": "string",
+            "enum": [
+                "production_manager",
+                "operator"
+            ],
+            "x-enum-varnames": [
+                "RoleProductionManager",
+                "RoleOperator"
+            ]
+        },
         "models.WorkOrderStatus": {
             "type": "string",
             "enum": [
                 "pending",
                 "in_progress",
-                "completed"
+                "completed",
+                "on_hold"
             ],
             "x-enum-varnames": [
                 "StatusPending",
                 "StatusInProgress",
-                "StatusCompleted"
+                "StatusCompleted",
+                "StatusOnHold"
             ]
         },
-        "models.WorkOrderStatusHistory": {
+        "validator.FieldError": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "field": {
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "quantity": {
-                    "type": "integer"
-                },
-                "status": {
-                    "$ref": "#/definitions/models.WorkOrderStatus"
-                },
-                "updated_at": {
+                "msg": {
                     "type": "string"
                 },
-                "work_order": {
-                    "$ref": "#/definitions/models.WorkOrder"
-                },
-                "work_order_id": {
-                    "type": "integer"
+                "rule": {
+                    "type": "string"
                 }
             }
         }
@@ -1257,7 +7373,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/api",
+	BasePath:         "/api/v1",
 	Schemes:          []string{"http", "https"},
 	Title:            "Work Order System API",
 	Description:      "This is the API documentation for the Work Order System",
@@ -1,14 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/controllers"
 	"github.com/dawamr/work-order-system-go/database"
 	_ "github.com/dawamr/work-order-system-go/docs" // Import generated Swagger docs
+	"github.com/dawamr/work-order-system-go/middleware"
 	"github.com/dawamr/work-order-system-go/routes"
+	"github.com/dawamr/work-order-system-go/services"
+	"github.com/dawamr/work-order-system-go/utils/events"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
@@ -27,7 +36,7 @@ import (
 // @license.url https://opensource.org/licenses/MIT
 
 // @host localhost:8080
-// @BasePath /api
+// @BasePath /api/v1
 // @schemes http https
 
 // @securityDefinitions.apikey BearerAuth
@@ -43,8 +52,45 @@ func main() {
 	database.ConnectDB()
 	database.MigrateDB()
 
+	// Start read-only if requested, managers can toggle the mode at runtime
+	middleware.SetMaintenanceMode(config.AppConfig.MaintenanceMode)
+
+	// Side effects are stored as outbox jobs and executed with retries by the worker
+	outbox := &services.OutboxService{
+		DB:          database.DB,
+		MaxAttempts: config.AppConfig.OutboxMaxAttempts,
+		Backoff:     time.Duration(config.AppConfig.OutboxRetryBackoff) * time.Second,
+	}
+
+	// The handlers store the outbox jobs of the notification and webhook subscribers
+	// with every domain event, in the transaction of the change; the worker delivers them
+	notifications := &services.NotificationSubscriber{
+		Notifications: &services.NotificationService{Sender: services.LogSender{}},
+		Outbox:        outbox,
+	}
+	outbox.Register(services.JobAssignmentNotification, notifications.Deliver)
+	subscribers := []services.EventSubscriber{notifications}
+	if config.AppConfig.WebhookURL != "" {
+		webhook := services.NewWebhookSubscriber(config.AppConfig.WebhookURL, 10*time.Second, outbox)
+		outbox.Register(services.JobWebhook, webhook.Deliver)
+		subscribers = append(subscribers, webhook)
+	}
+	controllers.SetEventSubscribers(subscribers...)
+
+	// In-memory subscribers hear of the domain events on the bus once they are committed
+	bus := events.NewBus(config.AppConfig.EventBufferSize)
+	controllers.SetEventBus(bus)
+
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		outbox.Run(workerCtx, time.Duration(config.AppConfig.OutboxPollInterval)*time.Second, config.AppConfig.OutboxBatchSize)
+	}()
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
+		BodyLimit: config.AppConfig.MaxBodySize * 1024 * 1024,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 
@@ -66,24 +112,40 @@ func main() {
 		AllowOrigins: "*",
 		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
 		AllowMethods: "GET, POST, PUT, DELETE",
+		// Lets browser clients show that an impersonation token is in use
+		ExposeHeaders: "X-Impersonated-By",
 	}))
+	app.Use(middleware.Compress(compress.Level(config.AppConfig.CompressLevel), config.AppConfig.CompressMinSize))
+	app.Use(middleware.Envelope())
+	if config.AppConfig.PerfProfiler {
+		app.Use(middleware.Perf())
+	}
+	if config.AppConfig.RequestTimeout > 0 {
+		app.Use(middleware.Timeout(time.Duration(config.AppConfig.RequestTimeout) * time.Second))
+	}
 
-	// Health check endpoint (for hosting platform verification)
+	// Health check endpoint (for hosting platform verification), a liveness check
+	// that does not depend on the database
 	app.Get("/kaithheathcheck", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
-			"status": "ok",
-			"message": "Application is running",
-			"service": "Work Order System API",
+			"status":      "ok",
+			"message":     "Application is running",
+			"service":     "Work Order System API",
+			"maintenance": middleware.MaintenanceMode(),
 		})
 	})
 	app.Get("/kaithhealth", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
-			"status": "ok",
-			"message": "Application is running",
-			"service": "Work Order System API",
+			"status":      "ok",
+			"message":     "Application is running",
+			"service":     "Work Order System API",
+			"maintenance": middleware.MaintenanceMode(),
 		})
 	})
 
+	// Readiness check, 503 until the database is reachable and migrated
+	app.Get("/readyz", controllers.GetReadiness)
+
 	// Swagger UI route
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
@@ -96,7 +158,37 @@ func main() {
 		port = "8080"
 	}
 
+	// On SIGINT or SIGTERM stop accepting connections and let in-flight requests finish
+	shutdownTimeout := time.Duration(config.AppConfig.ShutdownTimeout) * time.Second
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+		<-quit
+		log.Println("Shutting down server")
+		if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			log.Printf("Error shutting down server: %v", err)
+		}
+	}()
+
 	// Start the server
 	log.Printf("Starting server on port %s", port)
-	log.Fatal(app.Listen(":" + port))
+	if err := app.Listen(":" + port); err != nil {
+		log.Fatal(err)
+	}
+
+	// Handle the events queued by the last requests before exiting
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := bus.Close(ctx); err != nil {
+		log.Printf("Error draining queued events: %v", err)
+	}
+
+	// Let the worker finish its current job, unfinished jobs are claimed again after a restart
+	stopWorker()
+	select {
+	case <-workerDone:
+	case <-ctx.Done():
+		log.Println("Outbox worker still busy, its jobs are retried after a restart")
+	}
+	log.Println("Server stopped")
 }
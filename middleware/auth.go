@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
 	"github.com/dawamr/work-order-system-go/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
@@ -13,9 +15,12 @@ import (
 
 // JWTClaims represents the claims in the JWT token
 type JWTClaims struct {
-	UserID   uint        `json:"user_id"`
-	Username string      `json:"username"`
-	Role     models.Role `json:"role"`
+	UserID             uint        `json:"user_id"`
+	Username           string      `json:"username"`
+	Role               models.Role `json:"role"`
+	MustChangePassword bool        `json:"must_change_password,omitempty"` // restricts the token to changing the password
+	PlantID            uint        `json:"plant_id"`
+	ImpersonatedBy     uint        `json:"impersonated_by,omitempty"` // Production Manager acting as the user
 	jwt.RegisteredClaims
 }
 
@@ -26,14 +31,20 @@ func GenerateToken(user *models.User) (string, error) {
 
 	// Create claims
 	claims := JWTClaims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:             user.ID,
+		Username:           user.Username,
+		Role:               user.Role,
+		MustChangePassword: user.MustChangePassword,
+		PlantID:            user.PlantID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    config.AppConfig.JWTIssuer,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if config.AppConfig.JWTAudience != "" {
+		claims.Audience = jwt.ClaimStrings{config.AppConfig.JWTAudience}
+	}
 
 	// Create token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -47,6 +58,37 @@ func GenerateToken(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
+// GenerateImpersonationToken generates a short-lived JWT token letting a Production
+// Manager act as an operator. Requests are served as the operator while audit logs
+// record the manager.
+func GenerateImpersonationToken(operator *models.User, managerID uint) (string, time.Time, error) {
+	expirationTime := time.Now().Add(time.Minute * time.Duration(config.AppConfig.ImpersonationTTL))
+
+	claims := JWTClaims{
+		UserID:         operator.ID,
+		Username:       operator.Username,
+		Role:           operator.Role,
+		PlantID:        operator.PlantID,
+		ImpersonatedBy: managerID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    config.AppConfig.JWTIssuer,
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	if config.AppConfig.JWTAudience != "" {
+		claims.Audience = jwt.ClaimStrings{config.AppConfig.JWTAudience}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(config.AppConfig.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expirationTime, nil
+}
+
 // Protected is a middleware that verifies JWT tokens
 func Protected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -95,11 +137,69 @@ func Protected() fiber.Handler {
 			})
 		}
 
+		// Reject tokens minted for another service sharing the secret
+		if issuer := config.AppConfig.JWTIssuer; issuer != "" && !claims.VerifyIssuer(issuer, true) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": true,
+				"msg":   "Invalid token issuer",
+			})
+		}
+		if audience := config.AppConfig.JWTAudience; audience != "" && !claims.VerifyAudience(audience, true) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": true,
+				"msg":   "Invalid token audience",
+			})
+		}
+
 		// Set user information in context
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
 		c.Locals("role", claims.Role)
+		c.Locals("must_change_password", claims.MustChangePassword)
+		c.Locals("plant_id", claims.PlantID)
+		if claims.ExpiresAt != nil {
+			c.Locals("token_expires_at", claims.ExpiresAt.Time)
+		}
+
+		// Tell the client whose session is acting as the user
+		if claims.ImpersonatedBy != 0 {
+			c.Locals("impersonated_by", claims.ImpersonatedBy)
+			c.Set("X-Impersonated-By", strconv.FormatUint(uint64(claims.ImpersonatedBy), 10))
+		}
+
+		return c.Next()
+	}
+}
+
+// PasswordChangeGate is a middleware that rejects requests from users who must change their password first.
+// Routes that let the user change the password must be registered outside of it.
+func PasswordChangeGate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if mustChange, _ := c.Locals("must_change_password").(bool); mustChange {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": true,
+				"msg":   "Password change required, use PUT /auth/password",
+				"code":  "password_change_required",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// PlantScope is a middleware that scopes every query of the request to the caller's plant.
+// It must run after Protected, which reads the plant from the token.
+func PlantScope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		plantID, _ := c.Locals("plant_id").(uint)
+		if plantID == 0 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": true,
+				"msg":   "Token has no plant, please log in again",
+			})
+		}
 
+		c.SetUserContext(database.WithPlant(c.UserContext(), plantID))
 		return c.Next()
 	}
 }
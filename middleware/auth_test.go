@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// operator is the user the tokens of the tests are issued to
+var operator = models.User{ID: 2, Username: "operator", Role: models.RoleOperator, PlantID: 1}
+
+// useTestConfig signs and checks the tokens of the test with a test secret
+func useTestConfig(t *testing.T) {
+	t.Helper()
+	previous := config.AppConfig
+	config.AppConfig.JWTSecret = "test-secret"
+	config.AppConfig.JWTIssuer = ""
+	config.AppConfig.JWTAudience = ""
+	config.AppConfig.TokenExpiresIn = 1
+	config.AppConfig.ImpersonationTTL = 15
+	t.Cleanup(func() { config.AppConfig = previous })
+}
+
+// protectedLocals is what a handler behind Protected sees of the request
+type protectedLocals struct {
+	userID         uint
+	impersonatedBy uint
+}
+
+// serveProtected serves one request with the authorization header through Protected
+func serveProtected(t *testing.T, authorization string) (int, string, protectedLocals) {
+	t.Helper()
+	var locals protectedLocals
+	app := fiber.New()
+	app.Get("/", Protected(), func(c *fiber.Ctx) error {
+		locals.userID, _ = c.Locals("user_id").(uint)
+		locals.impersonatedBy, _ = c.Locals("impersonated_by").(uint)
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("serving request: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, resp.Header.Get("X-Impersonated-By"), locals
+}
+
+func TestProtectedImpersonation(t *testing.T) {
+	useTestConfig(t)
+	own, err := GenerateToken(&operator)
+	if err != nil {
+		t.Fatalf("generating token: %v", err)
+	}
+	impersonation, _, err := GenerateImpersonationToken(&operator, 1)
+	if err != nil {
+		t.Fatalf("generating impersonation token: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantHeader    string
+		wantLocals    protectedLocals
+	}{
+		{"missing header", "", fiber.StatusUnauthorized, "", protectedLocals{}},
+		{"not a bearer token", "Basic " + own, fiber.StatusUnauthorized, "", protectedLocals{}},
+		{"invalid token", "Bearer not-a-token", fiber.StatusUnauthorized, "", protectedLocals{}},
+		{"own token", "Bearer " + own, fiber.StatusOK, "", protectedLocals{userID: 2}},
+		{"impersonation token", "Bearer " + impersonation, fiber.StatusOK, "1", protectedLocals{userID: 2, impersonatedBy: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, header, locals := serveProtected(t, tt.authorization)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if header != tt.wantHeader {
+				t.Errorf("X-Impersonated-By = %q, want %q", header, tt.wantHeader)
+			}
+			if locals != tt.wantLocals {
+				t.Errorf("locals = %+v, want %+v", locals, tt.wantLocals)
+			}
+		})
+	}
+}
+
+// tokenFor issues a token for the operator as a service with the issuer and audience would
+func tokenFor(t *testing.T, issuer, audience string) string {
+	t.Helper()
+	config.AppConfig.JWTIssuer = issuer
+	config.AppConfig.JWTAudience = audience
+	token, err := GenerateToken(&operator)
+	if err != nil {
+		t.Fatalf("generating token: %v", err)
+	}
+	return token
+}
+
+func TestProtectedIssuerAndAudience(t *testing.T) {
+	tests := []struct {
+		name          string
+		tokenIssuer   string
+		tokenAudience string
+		issuer        string
+		audience      string
+		wantStatus    int
+	}{
+		{"checks disabled by default", "other-service", "other-api", "", "", fiber.StatusOK},
+		{"matching issuer and audience", "work-order-system", "work-order-api", "work-order-system", "work-order-api", fiber.StatusOK},
+		{"wrong issuer", "other-service", "work-order-api", "work-order-system", "work-order-api", fiber.StatusUnauthorized},
+		{"wrong audience", "work-order-system", "other-api", "work-order-system", "work-order-api", fiber.StatusUnauthorized},
+		{"token issued before the issuer was set", "", "", "work-order-system", "", fiber.StatusUnauthorized},
+		{"token issued before the audience was set", "", "", "", "work-order-api", fiber.StatusUnauthorized},
+		{"audience check only", "other-service", "work-order-api", "", "work-order-api", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			token := tokenFor(t, tt.tokenIssuer, tt.tokenAudience)
+			config.AppConfig.JWTIssuer = tt.issuer
+			config.AppConfig.JWTAudience = tt.audience
+
+			if status, _, _ := serveProtected(t, "Bearer "+token); status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPasswordChangeGate(t *testing.T) {
+	useTestConfig(t)
+
+	tests := []struct {
+		name       string
+		mustChange bool
+		wantStatus int
+	}{
+		{"password reset by a manager", true, fiber.StatusForbidden},
+		{"password changed", false, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := operator
+			user.MustChangePassword = tt.mustChange
+			token, err := GenerateToken(&user)
+			if err != nil {
+				t.Fatalf("generating token: %v", err)
+			}
+
+			app := fiber.New()
+			app.Get("/work-orders", Protected(), PasswordChangeGate(), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+			req := httptest.NewRequest(fiber.MethodGet, "/work-orders", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("serving request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			var body map[string]interface{}
+			if tt.mustChange {
+				if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+					t.Fatalf("decoding response: %v", err)
+				}
+				if body["code"] != "password_change_required" {
+					t.Errorf("code = %v, want password_change_required", body["code"])
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewaresWithoutAuthContext(t *testing.T) {
+	useTestConfig(t)
+
+	tests := []struct {
+		name       string
+		middleware fiber.Handler
+		wantStatus int
+	}{
+		{"missing token", Protected(), fiber.StatusUnauthorized},
+		{"role check mounted without Protected", RoleAuthorization(models.RoleProductionManager), fiber.StatusUnauthorized},
+		{"plant scope mounted without Protected", PlantScope(), fiber.StatusUnauthorized},
+		{"password change gate mounted without Protected", PasswordChangeGate(), fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/reports/dashboard", tt.middleware, func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/reports/dashboard", nil))
+			if err != nil {
+				t.Fatalf("serving request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/valyala/fasthttp"
+)
+
+// uncompressedContentTypes are already-compressed binary formats that are not worth compressing again
+var uncompressedContentTypes = []string{
+	"application/pdf",
+	"application/vnd.openxmlformats-officedocument",
+	"application/zip",
+	"application/gzip",
+	"application/octet-stream",
+}
+
+// Compress is a middleware that compresses responses with brotli or gzip,
+// depending on the Accept-Encoding request header. Responses smaller than
+// minSize bytes and binary documents such as PDF or XLSX are sent as is.
+func Compress(level compress.Level, minSize int) fiber.Handler {
+	var compressor fasthttp.RequestHandler
+	noop := func(c *fasthttp.RequestCtx) {}
+
+	switch level {
+	case compress.LevelDefault:
+		compressor = fasthttp.CompressHandlerBrotliLevel(noop, fasthttp.CompressBrotliDefaultCompression, fasthttp.CompressDefaultCompression)
+	case compress.LevelBestSpeed:
+		compressor = fasthttp.CompressHandlerBrotliLevel(noop, fasthttp.CompressBrotliBestSpeed, fasthttp.CompressBestSpeed)
+	case compress.LevelBestCompression:
+		compressor = fasthttp.CompressHandlerBrotliLevel(noop, fasthttp.CompressBrotliBestCompression, fasthttp.CompressBestCompression)
+	default:
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		if !resp.IsBodyStream() && len(resp.Body()) < minSize {
+			return nil
+		}
+
+		contentType := string(resp.Header.ContentType())
+		for _, excluded := range uncompressedContentTypes {
+			if strings.HasPrefix(contentType, excluded) {
+				return nil
+			}
+		}
+
+		compressor(c.Context())
+		return nil
+	}
+}
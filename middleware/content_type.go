@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireJSON is a middleware that answers POST, PUT and PATCH requests whose
+// body is not sent as application/json with 415, so a form post or a missing
+// Content-Type is not parsed into an empty request. Requests without a body pass.
+func RequireJSON() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+		if len(c.Body()) == 0 {
+			return c.Next()
+		}
+
+		// Ignore parameters such as charset=utf-8
+		mediaType, _, _ := strings.Cut(string(c.Request().Header.ContentType()), ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), fiber.MIMEApplicationJSON) {
+			return c.Next()
+		}
+
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": true,
+			"msg":   "Content-Type must be application/json",
+		})
+	}
+}
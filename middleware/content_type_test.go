@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequireJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"json", fiber.MethodPost, "application/json", `{"username":"operator"}`, fiber.StatusOK},
+		{"json with a charset", fiber.MethodPost, "application/json; charset=utf-8", `{"username":"operator"}`, fiber.StatusOK},
+		{"json in upper case", fiber.MethodPut, "Application/JSON", `{"username":"operator"}`, fiber.StatusOK},
+		{"form post", fiber.MethodPost, "application/x-www-form-urlencoded", "username=operator&password=secret", fiber.StatusUnsupportedMediaType},
+		{"multipart form", fiber.MethodPost, "multipart/form-data; boundary=x", "--x--", fiber.StatusUnsupportedMediaType},
+		{"plain text put", fiber.MethodPut, "text/plain", "operator", fiber.StatusUnsupportedMediaType},
+		{"patch without a content type", fiber.MethodPatch, "", `{"username":"operator"}`, fiber.StatusUnsupportedMediaType},
+		{"post without a body", fiber.MethodPost, "", "", fiber.StatusOK},
+		{"form data on a get", fiber.MethodGet, "application/x-www-form-urlencoded", "username=operator", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Add(tt.method, "/auth/login", RequireJSON(), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/auth/login", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("serving request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// EnvelopeV2 is the envelope version selected with ?envelope=v2 or the EnvelopeV2MediaType Accept header
+const EnvelopeV2 = "v2"
+
+// EnvelopeV2MediaType is the Accept header value selecting the v2 envelope
+const EnvelopeV2MediaType = "application/vnd.work-order.v2+json"
+
+// envelopeMetaFields are the legacy fields describing a list rather than being
+// its data, moved to meta so the data keeps one shape with or without them
+var envelopeMetaFields = []string{"pagination", "next_cursor", "matches"}
+
+// envelope is the standardized response shape for generic API clients
+type envelope struct {
+	Success bool                       `json:"success"`
+	Data    json.RawMessage            `json:"data,omitempty"`
+	Error   *envelopeError             `json:"error,omitempty"`
+	Meta    map[string]json.RawMessage `json:"meta,omitempty"`
+}
+
+// envelopeError is the error of a failed request in the v2 envelope
+type envelopeError struct {
+	Code    string                     `json:"code"`
+	Message string                     `json:"message"`
+	Details map[string]json.RawMessage `json:"details,omitempty"` // other fields of the legacy error, e.g. remaining
+}
+
+// Envelope is a middleware that rewrites the legacy JSON responses into the v2
+// envelope when the client asks for it, leaving the legacy shape the default.
+// Errors returned by handlers are rendered by the app's error handler first so
+// they are wrapped as well.
+func Envelope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !wantsEnvelopeV2(c) {
+			return c.Next()
+		}
+
+		if err := c.Next(); err != nil {
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+
+		resp := c.Response()
+		if resp.IsBodyStream() || !strings.HasPrefix(string(resp.Header.ContentType()), fiber.MIMEApplicationJSON) {
+			return nil
+		}
+		if body, ok := toEnvelope(resp.Body(), resp.StatusCode()); ok {
+			resp.SetBodyRaw(body)
+		}
+		return nil
+	}
+}
+
+// wantsEnvelopeV2 reports whether the request selects the v2 envelope
+func wantsEnvelopeV2(c *fiber.Ctx) bool {
+	return c.Query("envelope") == EnvelopeV2 || strings.Contains(c.Get(fiber.HeaderAccept), EnvelopeV2MediaType)
+}
+
+// toEnvelope maps a legacy response body, an object with an error flag, into the
+// v2 envelope. The legacy msg and code become error.message and error.code, the
+// pagination, next_cursor and matches go to meta, and the other fields become data:
+// the value itself when there is one field, e.g. work_order, or an object of them otherwise.
+// Bodies of another shape are reported as not mapped.
+func toEnvelope(body []byte, status int) ([]byte, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, false
+	}
+	var failed bool
+	if err := json.Unmarshal(fields["error"], &failed); err != nil {
+		return nil, false
+	}
+	delete(fields, "error")
+
+	if failed {
+		var code, message string
+		_ = json.Unmarshal(fields["code"], &code)
+		_ = json.Unmarshal(fields["msg"], &message)
+		delete(fields, "code")
+		delete(fields, "msg")
+		if code == "" {
+			// Without a specific code the HTTP status names the error, e.g. not_found
+			code = strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+		}
+		out := envelope{Error: &envelopeError{Code: code, Message: message}}
+		if len(fields) > 0 {
+			out.Error.Details = fields
+		}
+		return marshalEnvelope(out)
+	}
+
+	out := envelope{Success: true}
+	for _, name := range envelopeMetaFields {
+		if value, ok := fields[name]; ok {
+			if out.Meta == nil {
+				out.Meta = map[string]json.RawMessage{}
+			}
+			out.Meta[name] = value
+			delete(fields, name)
+		}
+	}
+	switch len(fields) {
+	case 0:
+	case 1:
+		for _, value := range fields {
+			out.Data = value
+		}
+	default:
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, false
+		}
+		out.Data = data
+	}
+	return marshalEnvelope(out)
+}
+
+// marshalEnvelope encodes an envelope
+func marshalEnvelope(out envelope) ([]byte, bool) {
+	body, err := json.Marshal(out)
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maintenanceRetryAfter is the Retry-After value in seconds sent while writes are blocked
+const maintenanceRetryAfter = "60"
+
+// maintenanceMode is set from MAINTENANCE_MODE at startup and toggled by managers at runtime
+var maintenanceMode atomic.Bool
+
+// SetMaintenanceMode turns the read-only maintenance mode on or off
+func SetMaintenanceMode(enabled bool) {
+	maintenanceMode.Store(enabled)
+}
+
+// MaintenanceMode reports whether the read-only maintenance mode is on
+func MaintenanceMode() bool {
+	return maintenanceMode.Load()
+}
+
+// Maintenance is a middleware that answers write requests with 503 while
+// the maintenance mode is on. GET, HEAD and OPTIONS requests are still served.
+func Maintenance() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !MaintenanceMode() {
+			return c.Next()
+		}
+
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		c.Set(fiber.HeaderRetryAfter, maintenanceRetryAfter)
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": true,
+			"msg":   "The system is in maintenance mode and read-only, please try again later",
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMaintenance(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		method     string
+		wantStatus int
+	}{
+		{"GET in maintenance mode", true, fiber.MethodGet, fiber.StatusOK},
+		{"HEAD in maintenance mode", true, fiber.MethodHead, fiber.StatusOK},
+		{"POST in maintenance mode", true, fiber.MethodPost, fiber.StatusServiceUnavailable},
+		{"PUT in maintenance mode", true, fiber.MethodPut, fiber.StatusServiceUnavailable},
+		{"DELETE in maintenance mode", true, fiber.MethodDelete, fiber.StatusServiceUnavailable},
+		{"GET", false, fiber.MethodGet, fiber.StatusOK},
+		{"POST", false, fiber.MethodPost, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := MaintenanceMode()
+			SetMaintenanceMode(tt.enabled)
+			t.Cleanup(func() { SetMaintenanceMode(previous) })
+
+			handled := false
+			app := fiber.New()
+			app.Use(Maintenance())
+			app.All("/work-orders", func(c *fiber.Ctx) error {
+				handled = true
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, "/work-orders", nil))
+			if err != nil {
+				t.Fatalf("serving request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			blocked := tt.wantStatus == fiber.StatusServiceUnavailable
+			if handled == blocked {
+				t.Errorf("handler reached = %t with the request blocked = %t", handled, blocked)
+			}
+			if retryAfter := resp.Header.Get(fiber.HeaderRetryAfter); (retryAfter != "") != blocked {
+				t.Errorf("Retry-After = %q with the request blocked = %t", retryAfter, blocked)
+			}
+		})
+	}
+}
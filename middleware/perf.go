@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// perfBuckets are the upper bounds of the latency histogram buckets, the last
+// bucket holds everything slower. Recording a request is a handful of atomic adds,
+// percentiles are estimated from the bucket counts when the report is read.
+var perfBuckets = []time.Duration{
+	time.Millisecond, 2 * time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2 * time.Second, 5 * time.Second,
+	10 * time.Second, 30 * time.Second, time.Minute,
+}
+
+// routeStats are the timings of one route, updated without locks
+type routeStats struct {
+	count   atomic.Int64
+	totalNs atomic.Int64
+	maxNs   atomic.Int64
+	buckets [16]atomic.Int64 // one per perfBuckets bound and one for slower requests
+}
+
+// RouteTiming is the timing summary of one route
+type RouteTiming struct {
+	Route  string  `json:"route"` // method and registered path, e.g. GET /api/work-orders/:id
+	Count  int64   `json:"count"`
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"` // estimated from the histogram, see perfBuckets
+	P95Ms  float64 `json:"p95_ms"` // estimated from the histogram, see perfBuckets
+	MaxMs  float64 `json:"max_ms"`
+}
+
+// perfStats holds the timings per route since startup or the last reset
+var perfStats = struct {
+	routes sync.Map // route -> *routeStats
+	mu     sync.RWMutex
+	since  time.Time
+}{since: time.Now()}
+
+// Perf is a middleware recording how long every route takes, for the slowest
+// routes report. Requests that matched no route are not recorded.
+func Perf() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+		elapsed := time.Since(start)
+
+		// Unmatched requests come back as fiber's 404 or 405 error, handlers answer with a status instead
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && (fiberErr.Code == fiber.StatusNotFound || fiberErr.Code == fiber.StatusMethodNotAllowed) {
+			return err
+		}
+		recordTiming(c.Method()+" "+c.Route().Path, elapsed)
+		return err
+	}
+}
+
+// recordTiming adds a request duration to the route's statistics
+func recordTiming(route string, elapsed time.Duration) {
+	value, ok := perfStats.routes.Load(route)
+	if !ok {
+		value, _ = perfStats.routes.LoadOrStore(route, &routeStats{})
+	}
+	stats := value.(*routeStats)
+
+	ns := elapsed.Nanoseconds()
+	stats.count.Add(1)
+	stats.totalNs.Add(ns)
+	for {
+		current := stats.maxNs.Load()
+		if ns <= current || stats.maxNs.CompareAndSwap(current, ns) {
+			break
+		}
+	}
+	bucket := sort.Search(len(perfBuckets), func(i int) bool { return elapsed <= perfBuckets[i] })
+	stats.buckets[bucket].Add(1)
+}
+
+// RouteTimings returns the timings of every route recorded since the returned
+// time, slowest p95 first. With reset the recorded timings are cleared.
+func RouteTimings(reset bool) ([]RouteTiming, time.Time) {
+	perfStats.mu.RLock()
+	since := perfStats.since
+	perfStats.mu.RUnlock()
+
+	timings := []RouteTiming{}
+	perfStats.routes.Range(func(key, value interface{}) bool {
+		if reset {
+			perfStats.routes.Delete(key)
+		}
+		if timing, ok := summarizeRoute(key.(string), value.(*routeStats)); ok {
+			timings = append(timings, timing)
+		}
+		return true
+	})
+
+	if reset {
+		perfStats.mu.Lock()
+		perfStats.since = time.Now()
+		perfStats.mu.Unlock()
+	}
+
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].P95Ms != timings[j].P95Ms {
+			return timings[i].P95Ms > timings[j].P95Ms
+		}
+		return timings[i].MeanMs > timings[j].MeanMs
+	})
+	return timings, since
+}
+
+// summarizeRoute turns a route's statistics into its timing summary
+func summarizeRoute(route string, stats *routeStats) (RouteTiming, bool) {
+	counts := make([]int64, len(stats.buckets))
+	var count int64
+	for i := range counts {
+		counts[i] = stats.buckets[i].Load()
+		count += counts[i]
+	}
+	if count == 0 {
+		return RouteTiming{}, false
+	}
+
+	return RouteTiming{
+		Route:  route,
+		Count:  count,
+		MeanMs: roundMs(time.Duration(stats.totalNs.Load() / stats.count.Load())),
+		P50Ms:  roundMs(bucketPercentile(counts, count, 0.50, stats)),
+		P95Ms:  roundMs(bucketPercentile(counts, count, 0.95, stats)),
+		MaxMs:  roundMs(time.Duration(stats.maxNs.Load())),
+	}, true
+}
+
+// bucketPercentile estimates the percentile by interpolating within the bucket
+// holding it, bounded by the slowest request seen
+func bucketPercentile(counts []int64, total int64, percentile float64, stats *routeStats) time.Duration {
+	max := time.Duration(stats.maxNs.Load())
+	rank := percentile * float64(total)
+	var seen int64
+	var lower time.Duration
+	for i, n := range counts {
+		upper := max
+		if i < len(perfBuckets) && perfBuckets[i] < max {
+			upper = perfBuckets[i]
+		}
+		if n > 0 && float64(seen+n) >= rank {
+			position := (rank - float64(seen)) / float64(n)
+			return lower + time.Duration(float64(upper-lower)*position)
+		}
+		seen += n
+		if i < len(perfBuckets) {
+			lower = perfBuckets[i]
+		}
+	}
+	return max
+}
+
+// roundMs converts a duration to milliseconds with two decimals
+func roundMs(d time.Duration) float64 {
+	return float64(d.Microseconds()/10) / 100
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Timeout is a middleware that attaches a deadline to the request context.
+// Handlers pass c.UserContext() to GORM, so in-flight queries are cancelled
+// once the deadline passes and the request is answered with 503.
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": true,
+				"msg":   "Request timed out, please try again later",
+			})
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TransactionKey is the c.Locals key of the request transaction started by Transaction
+const TransactionKey = "tx"
+
+// afterCommitKey is the c.Locals key of the functions waiting for the request transaction to commit
+const afterCommitKey = "after_commit"
+
+// Transaction is an opt-in middleware that runs the handler in one database
+// transaction. The transaction is stored in c.Locals(TransactionKey), where the
+// controllers' getDB picks it up. It is committed when the handler answers
+// with a 2xx or 3xx status and rolled back when it returns an error, answers
+// with 4xx or 5xx, or panics. Register it after PlantScope and Timeout so the
+// transaction carries the request's plant and deadline. Functions registered
+// with AfterCommit run after a successful commit.
+func Transaction() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tx := database.DB.WithContext(c.UserContext()).Begin()
+		if tx.Error != nil {
+			log.Printf("Error starting request transaction: %v", tx.Error)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": true,
+				"msg":   "Error starting database transaction",
+			})
+		}
+		c.Locals(TransactionKey, tx)
+
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+				panic(r)
+			}
+		}()
+
+		if err := c.Next(); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if c.Response().StatusCode() >= fiber.StatusBadRequest {
+			tx.Rollback()
+			return nil
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			log.Printf("Error committing request transaction: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": true,
+				"msg":   "Error saving changes",
+			})
+		}
+		if pending, ok := c.Locals(afterCommitKey).([]func()); ok {
+			for _, fn := range pending {
+				fn()
+			}
+		}
+		return nil
+	}
+}
+
+// AfterCommit runs fn once the request transaction is committed, and never if it is
+// rolled back. Without a request transaction fn runs right away.
+func AfterCommit(c *fiber.Ctx, fn func()) {
+	if _, ok := RequestTransaction(c); !ok {
+		fn()
+		return
+	}
+	pending, _ := c.Locals(afterCommitKey).([]func())
+	c.Locals(afterCommitKey, append(pending, fn))
+}
+
+// RequestTransaction returns the transaction started by Transaction for the request, if any
+func RequestTransaction(c *fiber.Ctx) (*gorm.DB, bool) {
+	tx, ok := c.Locals(TransactionKey).(*gorm.DB)
+	return tx, ok
+}
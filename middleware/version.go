@@ -0,0 +1,11 @@
+package middleware
+
+import "github.com/gofiber/fiber/v2"
+
+// APIVersion is a middleware that adds the X-API-Version header to every response
+func APIVersion(version string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("X-API-Version", version)
+		return c.Next()
+	}
+}
@@ -20,18 +20,23 @@ const (
 	ActionCustom ActionType = "custom"
 )
 
+// ActionTypes lists every audit log action type
+var ActionTypes = []ActionType{ActionCreate, ActionUpdate, ActionDelete, ActionCustom}
+
 // AuditLog represents a log entry for model changes
 type AuditLog struct {
 	ID         uint           `gorm:"primaryKey" json:"id"`
-	UserID     uint          `gorm:"not null" json:"user_id"`
-	User       User          `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;foreignKey:UserID;references:ID" json:"user"`
-	Action     ActionType    `gorm:"size:20;not null" json:"action"`
-	EntityID   uint         `gorm:"not null" json:"entity_id"`
-	EntityType string       `gorm:"size:50;not null" json:"entity_type"`
-	OldValues  JSON         `gorm:"type:jsonb" json:"old_values,omitempty"`
-	NewValues  JSON         `gorm:"type:jsonb" json:"new_values,omitempty"`
-	Note       string       `gorm:"type:text" json:"note,omitempty"`
-	CreatedAt  time.Time    `json:"created_at"`
+	UserID     uint           `gorm:"not null" json:"user_id"`
+	User       User           `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;foreignKey:UserID;references:ID" json:"user"`
+	UserName   string         `gorm:"size:50" json:"user_name"`                 // actor's username at the time of the action
+	PlantID    uint           `gorm:"not null;default:1;index" json:"plant_id"` // plant of the actor, scopes the log like the data it describes
+	Action     ActionType     `gorm:"size:20;not null" json:"action"`
+	EntityID   uint           `gorm:"not null" json:"entity_id"`
+	EntityType string         `gorm:"size:50;not null" json:"entity_type"`
+	OldValues  JSON           `gorm:"type:jsonb" json:"old_values,omitempty"`
+	NewValues  JSON           `gorm:"type:jsonb" json:"new_values,omitempty"`
+	Note       string         `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
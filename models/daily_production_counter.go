@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// DailyProductionCounter holds the precomputed production figures of one product on one day in one plant.
+// Status counts are the number of status changes into each status recorded that day and
+// ProducedQuantity is the sum of the progress quantities reported that day.
+type DailyProductionCounter struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	PlantID          uint      `gorm:"not null;default:1;uniqueIndex:idx_daily_counter_plant_date_product" json:"plant_id"`
+	Date             time.Time `gorm:"type:date;not null;uniqueIndex:idx_daily_counter_plant_date_product" json:"date"`
+	ProductName      string    `gorm:"size:100;not null;uniqueIndex:idx_daily_counter_plant_date_product" json:"product_name"`
+	Pending          int64     `gorm:"not null;default:0" json:"pending"`
+	InProgress       int64     `gorm:"not null;default:0" json:"in_progress"`
+	OnHold           int64     `gorm:"not null;default:0" json:"on_hold"`
+	Completed        int64     `gorm:"not null;default:0" json:"completed"`
+	ProducedQuantity int64     `gorm:"not null;default:0" json:"produced_quantity"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// FeatureFlag switches a gradually rolled out behavior on or off for all plants.
+// Flags without a row fall back to FEATURE_FLAGS.
+type FeatureFlag struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:50;uniqueIndex;not null" json:"name"`
+	Enabled     bool      `gorm:"not null;default:false" json:"enabled"`
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationPreference holds which notifications a user wants to receive
+type NotificationPreference struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	UserID            uint      `gorm:"not null;uniqueIndex" json:"user_id"`
+	EmailOnAssignment bool      `gorm:"not null;default:true" json:"email_on_assignment"`
+	EmailOnOverdue    bool      `gorm:"not null;default:false" json:"email_on_overdue"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// DefaultNotificationPreference returns the preferences of a user who never changed them
+func DefaultNotificationPreference(userID uint) NotificationPreference {
+	return NotificationPreference{
+		UserID:            userID,
+		EmailOnAssignment: true,
+		EmailOnOverdue:    false,
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxJobStatus represents where an outbox job is in its lifecycle
+type OutboxJobStatus string
+
+const (
+	// JobPending is a job waiting for its run_at to be claimed by the worker
+	JobPending OutboxJobStatus = "pending"
+	// JobRunning is a job claimed by a worker
+	JobRunning OutboxJobStatus = "running"
+	// JobDone is a job that was executed successfully
+	JobDone OutboxJobStatus = "done"
+	// JobDead is a job that failed on every attempt and is no longer retried
+	JobDead OutboxJobStatus = "dead"
+)
+
+// OutboxJobStatuses lists every outbox job status
+var OutboxJobStatuses = []OutboxJobStatus{JobPending, JobRunning, JobDone, JobDead}
+
+// OutboxJob is a side effect such as a notification or a webhook delivery, stored
+// so it survives a crash and is retried until it succeeds or runs out of attempts
+type OutboxJob struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	PlantID     uint            `gorm:"not null;default:1;index" json:"plant_id"`
+	Kind        string          `gorm:"size:50;not null" json:"kind"`
+	Payload     string          `gorm:"type:text;not null" json:"payload"` // JSON input of the kind's handler
+	Status      OutboxJobStatus `gorm:"size:20;not null;default:'pending';index:idx_outbox_jobs_claim,priority:1" json:"status"`
+	RunAt       time.Time       `gorm:"not null;index:idx_outbox_jobs_claim,priority:2" json:"run_at"` // earliest time of the next attempt
+	Attempts    int             `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int             `gorm:"not null" json:"max_attempts"`
+	LastError   string          `gorm:"type:text" json:"last_error,omitempty"`
+	LockedAt    *time.Time      `json:"locked_at,omitempty"` // when a worker claimed the job
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// OverdueAcknowledgement records a manager snoozing the overdue reminders of a work order.
+// The latest snooze is also kept on WorkOrder.OverdueSnoozedUntil.
+type OverdueAcknowledgement struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	WorkOrderID      uint      `gorm:"not null;index" json:"work_order_id"`
+	AcknowledgedByID uint      `gorm:"not null" json:"acknowledged_by_id"`
+	Until            time.Time `gorm:"not null" json:"until"`
+	Note             string    `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
@@ -0,0 +1,58 @@
+package models
+
+// StatusTransition is a status a work order may move to, optionally
+// restricted to a set of roles
+type StatusTransition struct {
+	To    WorkOrderStatus
+	Roles []Role // empty means every role may make the move
+}
+
+// StatusTransitions lists, per current status, the statuses a work order may
+// move to. Every status change is validated against this map, so extending the
+// work order lifecycle only requires adding entries here.
+var StatusTransitions = map[WorkOrderStatus][]StatusTransition{
+	StatusPending: {
+		{To: StatusInProgress},
+	},
+	StatusInProgress: {
+		{To: StatusCompleted},
+		{To: StatusOnHold},
+	},
+	StatusOnHold: {
+		{To: StatusInProgress},
+	},
+}
+
+// allows reports whether the transition may be made by role
+func (t StatusTransition) allows(role Role) bool {
+	if len(t.Roles) == 0 {
+		return true
+	}
+	for _, r := range t.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransition reports whether role may move a work order from one status to another
+func CanTransition(from, to WorkOrderStatus, role Role) bool {
+	for _, transition := range StatusTransitions[from] {
+		if transition.To == to && transition.allows(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions returns the statuses role may move a work order to from its current status
+func AllowedTransitions(from WorkOrderStatus, role Role) []WorkOrderStatus {
+	statuses := []WorkOrderStatus{}
+	for _, transition := range StatusTransitions[from] {
+		if transition.allows(role) {
+			statuses = append(statuses, transition.To)
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// useTransitions replaces StatusTransitions for the duration of the test
+func useTransitions(t *testing.T, transitions map[WorkOrderStatus][]StatusTransition) {
+	t.Helper()
+	previous := StatusTransitions
+	StatusTransitions = transitions
+	t.Cleanup(func() { StatusTransitions = previous })
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		from WorkOrderStatus
+		to   WorkOrderStatus
+		role Role
+		want bool
+	}{
+		{"start a pending order", StatusPending, StatusInProgress, RoleOperator, true},
+		{"complete an order in progress", StatusInProgress, StatusCompleted, RoleOperator, true},
+		{"hold an order in progress", StatusInProgress, StatusOnHold, RoleProductionManager, true},
+		{"resume an order on hold", StatusOnHold, StatusInProgress, RoleOperator, true},
+		{"complete a pending order", StatusPending, StatusCompleted, RoleOperator, false},
+		{"hold a pending order", StatusPending, StatusOnHold, RoleProductionManager, false},
+		{"complete an order on hold", StatusOnHold, StatusCompleted, RoleProductionManager, false},
+		{"reopen a completed order", StatusCompleted, StatusInProgress, RoleProductionManager, false},
+		{"move back to pending", StatusInProgress, StatusPending, RoleProductionManager, false},
+		{"unknown status", WorkOrderStatus("cancelled"), StatusInProgress, RoleOperator, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to, tt.role); got != tt.want {
+				t.Errorf("CanTransition(%s, %s, %s) = %t, want %t", tt.from, tt.to, tt.role, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransitionsFollowTheMap(t *testing.T) {
+	restricted := map[WorkOrderStatus][]StatusTransition{
+		StatusPending:    {{To: StatusInProgress}},
+		StatusInProgress: {{To: StatusCompleted}, {To: StatusOnHold, Roles: []Role{RoleProductionManager}}},
+		StatusOnHold:     {{To: StatusInProgress, Roles: []Role{RoleProductionManager}}},
+		StatusCompleted:  {{To: StatusInProgress, Roles: []Role{RoleProductionManager}}},
+	}
+
+	tests := []struct {
+		name        string
+		transitions map[WorkOrderStatus][]StatusTransition
+		role        Role
+		want        map[WorkOrderStatus][]WorkOrderStatus
+	}{
+		{
+			name:        "default lifecycle",
+			transitions: StatusTransitions,
+			role:        RoleOperator,
+			want: map[WorkOrderStatus][]WorkOrderStatus{
+				StatusPending:    {StatusInProgress},
+				StatusInProgress: {StatusCompleted, StatusOnHold},
+				StatusOnHold:     {StatusInProgress},
+				StatusCompleted:  {},
+			},
+		},
+		{
+			name:        "restricted moves of an operator",
+			transitions: restricted,
+			role:        RoleOperator,
+			want: map[WorkOrderStatus][]WorkOrderStatus{
+				StatusPending:    {StatusInProgress},
+				StatusInProgress: {StatusCompleted},
+				StatusOnHold:     {},
+				StatusCompleted:  {},
+			},
+		},
+		{
+			name:        "restricted moves of a manager",
+			transitions: restricted,
+			role:        RoleProductionManager,
+			want: map[WorkOrderStatus][]WorkOrderStatus{
+				StatusPending:    {StatusInProgress},
+				StatusInProgress: {StatusCompleted, StatusOnHold},
+				StatusOnHold:     {StatusInProgress},
+				StatusCompleted:  {StatusInProgress},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTransitions(t, tt.transitions)
+
+			for _, from := range WorkOrderStatuses {
+				allowed := AllowedTransitions(from, tt.role)
+				if !reflect.DeepEqual(allowed, tt.want[from]) {
+					t.Errorf("AllowedTransitions(%s, %s) = %v, want %v", from, tt.role, allowed, tt.want[from])
+				}
+				// CanTransition agrees with the listed transitions for every pair of statuses
+				for _, to := range WorkOrderStatuses {
+					listed := false
+					for _, status := range allowed {
+						listed = listed || status == to
+					}
+					if can := CanTransition(from, to, tt.role); can != listed {
+						t.Errorf("CanTransition(%s, %s, %s) = %t, but listed = %t", from, to, tt.role, can, listed)
+					}
+				}
+			}
+		})
+	}
+}
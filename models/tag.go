@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag is a free label put on work orders beyond product and status, e.g. rework or customer-x.
+// Production Managers maintain the tags of their plant, operators apply the existing ones.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PlantID   uint      `gorm:"not null;default:1;uniqueIndex:idx_tag_plant_name" json:"plant_id"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex:idx_tag_plant_name" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,7 +1,6 @@
 package models
 
 import (
-	"log"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,15 +17,22 @@ const (
 	RoleOperator Role = "operator"
 )
 
+// Roles lists every user role
+var Roles = []Role{RoleProductionManager, RoleOperator}
+
 // User represents a user in the system
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Username  string         `gorm:"size:50;uniqueIndex;not null" json:"username"`
-	Password  string         `gorm:"size:100;not null" json:"-"` // Password is not exposed in JSON
-	Role      Role           `gorm:"size:20;not null;index" json:"role"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	Username           string         `gorm:"size:50;uniqueIndex;not null" json:"username"`
+	Password           string         `gorm:"size:100;not null" json:"-"` // Password is not exposed in JSON
+	Role               Role           `gorm:"size:20;not null;index" json:"role"`
+	Active             bool           `gorm:"not null;default:true" json:"active"`
+	MustChangePassword bool           `gorm:"not null;default:false" json:"must_change_password"` // set by a password reset
+	LastLoginAt        *time.Time     `json:"last_login_at"`
+	PlantID            uint           `gorm:"not null;default:1;index" json:"plant_id"` // plant the user works at, scopes all their data
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // BeforeSave is a GORM hook that hashes the password before saving
@@ -46,7 +52,5 @@ func (u *User) BeforeSave(tx *gorm.DB) error {
 
 // CheckPassword compares the provided password with the stored hash
 func (u *User) CheckPassword(password string) error {
-	log.Println(u.Password)
-	log.Println(password)
 	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("Secret-Pass1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hashing password: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"right password", "Secret-Pass1", false},
+		{"wrong password", "Wrong-Pass2", true},
+		{"empty password", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged bytes.Buffer
+			previous := log.Writer()
+			log.SetOutput(&logged)
+			t.Cleanup(func() { log.SetOutput(previous) })
+
+			user := User{Password: string(hash)}
+			if err := user.CheckPassword(tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("CheckPassword(%q) = %v, want error %t", tt.password, err, tt.wantErr)
+			}
+
+			// Neither the hash nor the attempted password may reach the logs
+			for _, secret := range []string{string(hash), "Secret-Pass1", "Wrong-Pass2"} {
+				if strings.Contains(logged.String(), secret) {
+					t.Errorf("log %q contains %q", logged.String(), secret)
+				}
+			}
+		})
+	}
+}
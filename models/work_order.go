@@ -16,22 +16,59 @@ const (
 	StatusInProgress WorkOrderStatus = "in_progress"
 	// StatusCompleted represents a completed work order
 	StatusCompleted WorkOrderStatus = "completed"
+	// StatusOnHold represents a work order paused while in progress (e.g. missing materials)
+	StatusOnHold WorkOrderStatus = "on_hold"
 )
 
+// WorkOrderStatuses lists every work order status in lifecycle order
+var WorkOrderStatuses = []WorkOrderStatus{StatusPending, StatusInProgress, StatusOnHold, StatusCompleted}
+
+// DefaultUnit is the unit of measure of work orders created without one
+const DefaultUnit = "pcs"
+
+// MaxPriority is the highest work order priority, 0 is the default
+const MaxPriority = 9
+
+// RemainingDisposition describes what happens to the unproduced quantity
+// of a work order completed below its target
+type RemainingDisposition string
+
+const (
+	// DispositionCancelled means the remaining quantity is cancelled
+	DispositionCancelled RemainingDisposition = "cancelled"
+	// DispositionBackorder means the remaining quantity moves to a follow-up work order
+	DispositionBackorder RemainingDisposition = "backorder"
+)
+
+// RemainingDispositions lists every remaining quantity disposition
+var RemainingDispositions = []RemainingDisposition{DispositionCancelled, DispositionBackorder}
+
 // WorkOrder represents a work order in the system
 type WorkOrder struct {
-	ID                 uint            `gorm:"primaryKey" json:"id"`
-	WorkOrderNumber    string          `gorm:"size:20;uniqueIndex;not null" json:"work_order_number"`
-	ProductName        string          `gorm:"size:100;not null" json:"product_name"`
-	Quantity           int             `gorm:"not null;default:0" json:"quantity"`
-	TargetQuantity     int             `gorm:"not null;default:0" json:"target_quantity"`
-	ProductionDeadline time.Time       `json:"production_deadline"`
-	Status             WorkOrderStatus `gorm:"size:20;not null;default:'pending'" json:"status"`
-	OperatorID         uint            `json:"operator_id"`
-	Operator           User            `gorm:"foreignKey:OperatorID" json:"operator"`
-	CreatedAt          time.Time       `json:"created_at"`
-	UpdatedAt          time.Time       `json:"updated_at"`
-	DeletedAt          gorm.DeletedAt  `gorm:"index" json:"-"`
+	ID                   uint                 `gorm:"primaryKey" json:"id"`
+	WorkOrderNumber      string               `gorm:"size:20;uniqueIndex;not null" json:"work_order_number"`
+	ProductName          string               `gorm:"size:100;not null" json:"product_name"`
+	Quantity             int                  `gorm:"not null;default:0" json:"quantity"`
+	TargetQuantity       int                  `gorm:"not null;default:0" json:"target_quantity"`
+	Unit                 string               `gorm:"size:20;not null;default:'pcs'" json:"unit"` // unit of measure of the quantities, e.g. pcs or kg
+	ProductionDeadline   time.Time            `json:"production_deadline"`
+	Status               WorkOrderStatus      `gorm:"size:20;not null;default:'pending'" json:"status"`
+	Priority             int                  `gorm:"not null;default:0" json:"priority"` // 0 to MaxPriority, higher is started first
+	HoldReason           string               `gorm:"type:text" json:"hold_reason,omitempty"`
+	RemainingDisposition RemainingDisposition `gorm:"size:20" json:"remaining_disposition,omitempty"` // set when completed below target
+	CancellationReason   *string              `gorm:"type:text" json:"cancellation_reason,omitempty"` // why the remaining quantity was cancelled
+	RestockNote          *string              `gorm:"type:text" json:"restock_note,omitempty"`        // materials of the cancelled remainder returned to stock
+	OperatorID           uint                 `json:"operator_id"`
+	Operator             User                 `gorm:"foreignKey:OperatorID" json:"operator"`
+	AcknowledgedAt       *time.Time           `json:"acknowledged_at"`                  // set once by the assigned operator
+	OverdueSnoozedUntil  *time.Time           `json:"overdue_snoozed_until"`            // overdue reminders are skipped until then
+	ParentID             *uint                `gorm:"index" json:"parent_id,omitempty"` // backorder source work order
+	PlantID              uint                 `gorm:"not null;default:1;index" json:"plant_id"`
+	Children             []WorkOrder          `gorm:"foreignKey:ParentID" json:"children,omitempty"`
+	Tags                 []Tag                `gorm:"many2many:work_order_tags" json:"tags,omitempty"`
+	CreatedAt            time.Time            `json:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt       `gorm:"index" json:"-"`
 }
 
 // WorkOrderProgress represents progress updates for a work order
@@ -40,6 +77,10 @@ type WorkOrderProgress struct {
 	WorkOrderID      uint           `gorm:"not null" json:"work_order_id"`
 	ProgressDesc     string         `gorm:"size:500;not null" json:"progress_desc"`
 	ProgressQuantity int            `json:"progress_quantity"`
+	ReportedByID     *uint          `gorm:"index" json:"reported_by_id"` // user who logged the progress, nil for entries logged before it was recorded
+	ReportedBy       *User          `gorm:"foreignKey:ReportedByID" json:"reported_by,omitempty"`
+	ApprovedByID     *uint          `gorm:"index" json:"approved_by_id"` // manager who signed off the progress, nil until approved
+	ApprovedAt       *time.Time     `json:"approved_at"`
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
@@ -52,7 +93,28 @@ type WorkOrderStatusHistory struct {
 	WorkOrder   WorkOrder       `gorm:"foreignKey:WorkOrderID" json:"work_order"`
 	Status      WorkOrderStatus `gorm:"size:20;not null" json:"status"`
 	Quantity    int             `json:"quantity"`
+	Note        string          `gorm:"type:text" json:"note,omitempty"`
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"-"`
 }
+
+// OverdueStatuses are the statuses in which a work order counts as overdue
+// once its production deadline has passed. On-hold orders are paused and
+// completed orders are done, so neither is considered overdue.
+var OverdueStatuses = []WorkOrderStatus{StatusPending, StatusInProgress}
+
+// ScopeOverdue is a GORM scope that selects work orders past their production deadline
+func ScopeOverdue(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("production_deadline < ? AND status IN ?", now, OverdueStatuses)
+	}
+}
+
+// ScopeOverdueNotSnoozed is a GORM scope that selects overdue work orders whose reminders are not snoozed
+func ScopeOverdueNotSnoozed(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(ScopeOverdue(now)).
+			Where("overdue_snoozed_until IS NULL OR overdue_snoozed_until <= ?", now)
+	}
+}
@@ -9,49 +9,146 @@ import (
 
 // SetupRoutes sets up all the routes for the application
 func SetupRoutes(app *fiber.App) {
+	// Versioned API. A future v2 gets its own group and setup function
+	// registered before the unversioned alias below.
+	v1 := app.Group("/api/v1", middleware.APIVersion("v1"))
+	setupV1Routes(v1)
+
+	// Unversioned alias of v1, kept temporarily for existing clients
+	legacy := app.Group("/api", middleware.APIVersion("v1"))
+	setupV1Routes(legacy)
+}
+
+// setupV1Routes sets up the v1 routes on the given router
+func setupV1Routes(router fiber.Router) {
 	// Public routes
-	auth := app.Group("/api/auth")
+	auth := router.Group("/auth", middleware.RequireJSON())
 	auth.Post("/login", controllers.Login)
-	auth.Post("/register", controllers.Register)
+	auth.Post("/register", middleware.Maintenance(), controllers.Register)
+	auth.Put("/password", middleware.Protected(), middleware.Maintenance(), controllers.ChangePassword)
+	auth.Get("/me", middleware.Protected(), controllers.Me)
+
+	// Enum values for client dropdowns and the server clock, public since they are not sensitive
+	router.Get("/meta/enums", controllers.GetEnums)
+	router.Get("/meta/time", controllers.GetServerTime)
+
+	// Maintenance mode toggle (Production Manager only), registered before the protected
+	// group so it stays writable while the maintenance mode blocks every other write
+	maintenance := router.Group("/maintenance", middleware.Protected(), middleware.PasswordChangeGate(), middleware.RoleAuthorization(models.RoleProductionManager))
+	maintenance.Get("/", controllers.GetMaintenanceMode)
+	maintenance.Put("/", controllers.SetMaintenanceMode)
 
 	// Protected routes
-	api := app.Group("/api", middleware.Protected())
+	api := router.Group("", middleware.Protected(), middleware.PasswordChangeGate(), middleware.PlantScope(), middleware.Maintenance())
+
+	// Current user routes
+	me := api.Group("/me")
+	me.Get("/notifications", controllers.GetMyNotificationPreferences)
+	me.Put("/notifications", controllers.UpdateMyNotificationPreferences)
 
 	// Api for list all operators
 	operators := api.Group("/operators")
 	operators.Get("/", controllers.GetOperators)
+	operators.Get("/:id/forecast", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetOperatorForecast)
+	operators.Get("/:id/report/export", middleware.RoleAuthorization(models.RoleProductionManager), controllers.ExportOperatorReport)
+
+	// User management routes (Production Manager only)
+	users := api.Group("/users", middleware.RoleAuthorization(models.RoleProductionManager))
+	users.Get("/:id", controllers.GetUserByID)
+	users.Get("/:id/actions", controllers.GetUserActions)
+	users.Post("/:id/reset-password", controllers.ResetUserPassword)
+
+	// Work order import, registered before the work order group since it uploads a
+	// multipart file while every other work order write takes a JSON body
+	api.Post("/work-orders/import", middleware.RoleAuthorization(models.RoleProductionManager), controllers.ImportWorkOrders)
 
 	// Work Order routes
-	workOrders := api.Group("/work-orders")
+	workOrders := api.Group("/work-orders", middleware.RequireJSON())
 
 	// Definisikan route statis terlebih dahulu
 	workOrders.Get("/assigned", middleware.RoleAuthorization(models.RoleOperator), controllers.GetAssignedWorkOrders)
+	workOrders.Get("/inbox", controllers.GetWorkOrderInbox)
+	workOrders.Get("/next", middleware.RoleAuthorization(models.RoleOperator), controllers.GetNextWorkOrder)
+	workOrders.Post("/batch-get", controllers.BatchGetWorkOrders)
 
 	// Kemudian definisikan route dengan parameter
 	workOrders.Get("/:id", controllers.GetWorkOrderByID)
 	workOrders.Get("/:id/progress", controllers.GetWorkOrderProgress)
+	workOrders.Get("/:id/transitions", controllers.GetWorkOrderTransitions)
+	workOrders.Get("/:id/status-durations", controllers.GetWorkOrderStatusDurations)
+	workOrders.Get("/:id/burndown", controllers.GetWorkOrderBurndown)
+	workOrders.Get("/:id/field-history", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetWorkOrderFieldHistory)
+	workOrders.Get("/:id/audit-report", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetWorkOrderAuditReport)
 
 	// Routes for Production Manager only
-	workOrders.Post("/", middleware.RoleAuthorization(models.RoleProductionManager), controllers.CreateWorkOrder)
+	workOrders.Post("/", middleware.RoleAuthorization(models.RoleProductionManager), middleware.Transaction(), controllers.CreateWorkOrder)
 	workOrders.Get("/", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetWorkOrders)
-	workOrders.Put("/:id", middleware.RoleAuthorization(models.RoleProductionManager), controllers.UpdateWorkOrder)
+	workOrders.Put("/:id", middleware.RoleAuthorization(models.RoleProductionManager), middleware.Transaction(), controllers.UpdateWorkOrder)
 	workOrders.Delete("/:id", middleware.RoleAuthorization(models.RoleProductionManager), controllers.DeleteWorkOrder)
+	workOrders.Post("/:id/snooze-overdue", middleware.RoleAuthorization(models.RoleProductionManager), controllers.SnoozeOverdue)
 	// Work order logs
 	workOrders.Get("/:id/logs", controllers.GetWorkOrderLogs)
-	workOrders.Post("/:id/logs", controllers.CreateWorkOrderLog)
+	workOrders.Post("/:id/logs", middleware.Transaction(), controllers.CreateWorkOrderLog)
 
 	// Routes for Operator only
-	workOrders.Put("/:id/status", controllers.UpdateWorkOrderStatus)
-	workOrders.Post("/:id/progress", controllers.CreateWorkOrderProgress)
+	workOrders.Put("/:id/status", middleware.Transaction(), controllers.UpdateWorkOrderStatus)
+	workOrders.Post("/:id/progress", middleware.Transaction(), controllers.CreateWorkOrderProgress)
+	workOrders.Post("/:id/progress/:progress_id/approve", middleware.RoleAuthorization(models.RoleProductionManager), controllers.ApproveWorkOrderProgress)
+	workOrders.Post("/:id/acknowledge", controllers.AcknowledgeWorkOrder)
+	workOrders.Post("/acknowledge-all", middleware.Transaction(), controllers.AcknowledgeAllWorkOrders)
+
+	// Work order tags, operators only tag their own orders
+	workOrders.Post("/:id/tags", controllers.AddWorkOrderTags)
+	workOrders.Delete("/:id/tags/:tag_id", controllers.RemoveWorkOrderTag)
+
+	// Tag vocabulary, readable by every user and kept by Production Managers
+	tags := api.Group("/tags")
+	tags.Get("/", controllers.GetTags)
+	tags.Post("/", middleware.RoleAuthorization(models.RoleProductionManager), controllers.CreateTag)
+	tags.Delete("/:id", middleware.RoleAuthorization(models.RoleProductionManager), middleware.Transaction(), controllers.DeleteTag)
+
+	// Progress feed across all work orders (Production Manager only)
+	api.Get("/progress", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetProgressFeed)
 
 	// Report routes (Production Manager only)
 	reports := api.Group("/reports")
 	reports.Get("/dashboard", controllers.GetWorkOrderDashboard)
+	reports.Get("/kpis", controllers.GetWorkOrderKPIs)
+	reports.Get("/aging", controllers.GetWorkOrderAging)
+	reports.Get("/notification-preferences", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetNotificationPreferenceSummary)
+	reports.Get("/daily", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetDailyProduction)
 	reports.Get("/performance", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetOperatorPerformance)
+	reports.Get("/compare", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetOperatorComparison)
+	reports.Get("/lead-time", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetLeadTimePercentiles)
+	reports.Get("/upcoming-load", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetUpcomingLoad)
+	reports.Get("/operators/matrix", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetOperatorStatusMatrix)
 	reports.Get("/summary", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetWorkOrderSummary)
+	reports.Get("/summary/compare", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetSummaryComparison)
+	reports.Get("/summary/product/:product_name/orders", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetSummaryProductWorkOrders)
 	reports.Get("/summary/:operator_id", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetWorkOrderSummaryByOperator)
 
+	// Administration (Production Manager only)
+	admin := api.Group("/admin", middleware.RoleAuthorization(models.RoleProductionManager))
+	admin.Get("/jobs", controllers.GetOutboxJobs)
+	admin.Get("/perf", controllers.GetPerfStats)
+	admin.Post("/impersonate/:operator_id", controllers.ImpersonateOperator)
+
+	// Feature flags, the enabled ones for every user and management for Production Managers
+	featureFlags := api.Group("/feature-flags")
+	featureFlags.Get("/me", controllers.GetEnabledFeatures)
+	featureFlags.Get("/", middleware.RoleAuthorization(models.RoleProductionManager), controllers.GetFeatureFlags)
+	featureFlags.Put("/:name", middleware.RoleAuthorization(models.RoleProductionManager), controllers.SetFeatureFlag)
+	featureFlags.Delete("/:name", middleware.RoleAuthorization(models.RoleProductionManager), controllers.DeleteFeatureFlag)
+
+	// Global search
+	api.Get("/search", controllers.Search)
+
+	// Calendar routes
+	calendar := api.Group("/calendar")
+	calendar.Get("/next-business-day", controllers.GetNextBusinessDay)
+
 	// Audit log routes (Production Manager only)
 	auditLogs := api.Group("/audit-logs", middleware.RoleAuthorization(models.RoleProductionManager))
 	auditLogs.Get("/", controllers.GetAuditLogs)
+	auditLogs.Get("/:id", controllers.GetAuditLogByID)
 }
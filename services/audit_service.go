@@ -7,7 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/dawamr/work-order-system-go/database"
 	"github.com/dawamr/work-order-system-go/models"
 	"gorm.io/gorm"
 )
@@ -15,13 +14,14 @@ import (
 // AuditLogService handles audit logging operations
 type AuditLogService struct{}
 
-// CreateLog creates a new audit log entry
-func (s *AuditLogService) CreateLog(userID uint, action models.ActionType, entityType string, entityID uint, oldValues, newValues interface{}, note string) error {
+// CreateLog creates a new audit log entry with db, so it is written in the caller's
+// transaction and belongs to the plant of the actor
+func (s *AuditLogService) CreateLog(db *gorm.DB, userID uint, action models.ActionType, entityType string, entityID uint, oldValues, newValues interface{}, note string) error {
 	var oldValuesJSON, newValuesJSON models.JSON
 
 	// Get user data
 	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
+	if err := db.First(&user, userID).Error; err != nil {
 		return fmt.Errorf("error fetching user data: %v", err)
 	}
 
@@ -67,7 +67,9 @@ func (s *AuditLogService) CreateLog(userID uint, action models.ActionType, entit
 
 	log := models.AuditLog{
 		UserID:     userID,
-		User:       user,  // Include complete user data
+		User:       user,          // Include complete user data
+		UserName:   user.Username, // Snapshot so renames don't rewrite history
+		PlantID:    user.PlantID,
 		Action:     action,
 		EntityType: entityType,
 		EntityID:   entityID,
@@ -76,13 +78,61 @@ func (s *AuditLogService) CreateLog(userID uint, action models.ActionType, entit
 		Note:       note,
 	}
 
-	if err := database.DB.Create(&log).Error; err != nil {
+	if err := db.Create(&log).Error; err != nil {
 		return fmt.Errorf("error creating audit log: %v", err)
 	}
 
 	return nil
 }
 
+// CreateAccessLog records that a user read entityType, storing the request parameters as new values
+func (s *AuditLogService) CreateAccessLog(db *gorm.DB, userID uint, entityType string, params map[string]string, note string) error {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return fmt.Errorf("error fetching user data: %v", err)
+	}
+
+	var paramsJSON models.JSON
+	if len(params) > 0 {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("error marshaling request parameters: %v", err)
+		}
+		paramsJSON = models.JSON(data)
+	}
+
+	log := models.AuditLog{
+		UserID:     userID,
+		UserName:   user.Username,
+		PlantID:    user.PlantID,
+		Action:     models.ActionCustom,
+		EntityType: entityType,
+		NewValues:  paramsJSON,
+		Note:       note,
+	}
+
+	if err := db.Create(&log).Error; err != nil {
+		return fmt.Errorf("error creating audit log: %v", err)
+	}
+
+	return nil
+}
+
+// PurgeOlderThan hard-deletes the audit logs created before cutoff and returns how many were
+// removed. In dry-run mode it only counts them.
+func (s *AuditLogService) PurgeOlderThan(db *gorm.DB, cutoff time.Time, dryRun bool) (int64, error) {
+	query := db.Unscoped().Model(&models.AuditLog{}).Where("created_at < ?", cutoff)
+
+	if dryRun {
+		var count int64
+		err := query.Count(&count).Error
+		return count, err
+	}
+
+	result := query.Delete(&models.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
 // GetChangedFields compares old and new structs and returns changed fields
 func (s *AuditLogService) GetChangedFields(old, new interface{}) map[string]interface{} {
 	changes := make(map[string]interface{})
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DailyCounterService maintains the daily production counters table
+type DailyCounterService struct{}
+
+// dailyStatusCount is one row of the per-day status change aggregate
+type dailyStatusCount struct {
+	PlantID     uint
+	Day         time.Time
+	ProductName string
+	Status      models.WorkOrderStatus
+	Count       int64
+}
+
+// dailyQuantity is one row of the per-day produced quantity aggregate
+type dailyQuantity struct {
+	PlantID     uint
+	Day         time.Time
+	ProductName string
+	Quantity    int64
+}
+
+// RecomputeDay recomputes the counters of a product for the day containing at from the
+// status history and progress tables, one per plant. Recomputing the whole day instead of
+// incrementing keeps the counter correct when backdated events arrive late.
+func (s *DailyCounterService) RecomputeDay(db *gorm.DB, productName string, at time.Time) error {
+	day := at.Format(time.DateOnly)
+
+	var statusCounts []dailyStatusCount
+	if err := s.statusCounts(db).
+		Where("work_orders.product_name = ? AND DATE(work_order_status_histories.created_at) = ?", productName, day).
+		Scan(&statusCounts).Error; err != nil {
+		return err
+	}
+
+	var quantities []dailyQuantity
+	if err := s.quantities(db).
+		Where("work_orders.product_name = ? AND DATE(work_order_progresses.created_at) = ?", productName, day).
+		Scan(&quantities).Error; err != nil {
+		return err
+	}
+
+	counters := mergeDailyCounters(statusCounts, quantities)
+
+	// Drop the stale counters of plants where nothing happened that day anymore
+	date, _ := time.Parse(time.DateOnly, day)
+	stale := db.Where("date = ? AND product_name = ?", date, productName)
+	if len(counters) > 0 {
+		plantIDs := make([]uint, 0, len(counters))
+		for _, counter := range counters {
+			plantIDs = append(plantIDs, counter.PlantID)
+		}
+		stale = stale.Where("plant_id NOT IN ?", plantIDs)
+	}
+	if err := stale.Delete(&models.DailyProductionCounter{}).Error; err != nil {
+		return err
+	}
+	if len(counters) == 0 {
+		return nil
+	}
+
+	return s.upsert(db, counters)
+}
+
+// Rebuild recomputes the whole daily production counters table from source data
+func (s *DailyCounterService) Rebuild(db *gorm.DB) (int, error) {
+	var count int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var statusCounts []dailyStatusCount
+		if err := s.statusCounts(tx).Scan(&statusCounts).Error; err != nil {
+			return err
+		}
+
+		var quantities []dailyQuantity
+		if err := s.quantities(tx).Scan(&quantities).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).
+			Delete(&models.DailyProductionCounter{}).Error; err != nil {
+			return err
+		}
+
+		counters := mergeDailyCounters(statusCounts, quantities)
+		count = len(counters)
+		if count == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&counters, 500).Error
+	})
+	return count, err
+}
+
+// statusCounts builds the status changes per plant, day and product aggregate query
+func (s *DailyCounterService) statusCounts(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.WorkOrderStatusHistory{}).
+		Select("work_orders.plant_id, DATE(work_order_status_histories.created_at) AS day, work_orders.product_name, work_order_status_histories.status, COUNT(*) AS count").
+		Joins("JOIN work_orders ON work_orders.id = work_order_status_histories.work_order_id").
+		Group("work_orders.plant_id, day, work_orders.product_name, work_order_status_histories.status")
+}
+
+// quantities builds the produced quantity per plant, day and product aggregate query
+func (s *DailyCounterService) quantities(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.WorkOrderProgress{}).
+		Select("work_orders.plant_id, DATE(work_order_progresses.created_at) AS day, work_orders.product_name, COALESCE(SUM(work_order_progresses.progress_quantity), 0) AS quantity").
+		Joins("JOIN work_orders ON work_orders.id = work_order_progresses.work_order_id").
+		Group("work_orders.plant_id, day, work_orders.product_name")
+}
+
+// upsert inserts the counters or overwrites the existing counters of the same plant, day and product
+func (s *DailyCounterService) upsert(db *gorm.DB, counters []models.DailyProductionCounter) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "plant_id"}, {Name: "date"}, {Name: "product_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"pending", "in_progress", "on_hold", "completed", "produced_quantity", "updated_at"}),
+	}).Create(&counters).Error
+}
+
+// mergeDailyCounters combines both aggregates into one counter per plant, day and product
+func mergeDailyCounters(statusCounts []dailyStatusCount, quantities []dailyQuantity) []models.DailyProductionCounter {
+	type key struct {
+		plant   uint
+		day     string
+		product string
+	}
+
+	index := map[key]int{}
+	counters := []models.DailyProductionCounter{}
+	counterFor := func(plant uint, day time.Time, product string) *models.DailyProductionCounter {
+		k := key{plant, day.Format(time.DateOnly), product}
+		i, ok := index[k]
+		if !ok {
+			date, _ := time.Parse(time.DateOnly, k.day)
+			counters = append(counters, models.DailyProductionCounter{PlantID: plant, Date: date, ProductName: product})
+			i = len(counters) - 1
+			index[k] = i
+		}
+		return &counters[i]
+	}
+
+	for _, row := range statusCounts {
+		counter := counterFor(row.PlantID, row.Day, row.ProductName)
+		switch row.Status {
+		case models.StatusPending:
+			counter.Pending += row.Count
+		case models.StatusInProgress:
+			counter.InProgress += row.Count
+		case models.StatusOnHold:
+			counter.OnHold += row.Count
+		case models.StatusCompleted:
+			counter.Completed += row.Count
+		}
+	}
+	for _, row := range quantities {
+		counter := counterFor(row.PlantID, row.Day, row.ProductName)
+		counter.ProducedQuantity += row.Quantity
+	}
+
+	return counters
+}
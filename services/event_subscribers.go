@@ -0,0 +1,92 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"github.com/dawamr/work-order-system-go/utils/events"
+	"gorm.io/gorm"
+)
+
+// Outbox job kinds of the event subscribers
+const (
+	JobAssignmentNotification = "notification.assignment"
+	JobWebhook                = "webhook"
+)
+
+// EventSubscriber stores the outbox jobs a domain event calls for. The controllers
+// call Enqueue in the transaction of the change the event describes, so a job
+// exists exactly when the change is committed, and the outbox worker delivers it.
+type EventSubscriber interface {
+	Enqueue(db *gorm.DB, event events.Event) error
+}
+
+// assignmentJob is the payload of an assignment notification job
+type assignmentJob struct {
+	WorkOrderID uint `json:"work_order_id"`
+}
+
+// NotificationSubscriber tells operators about the work orders assigned to them
+type NotificationSubscriber struct {
+	Notifications *NotificationService
+	Outbox        *OutboxService
+}
+
+// Enqueue queues the assignment notification of a created or reassigned work order in the outbox
+func (s *NotificationSubscriber) Enqueue(db *gorm.DB, event events.Event) error {
+	if event.Type != events.WorkOrderCreated && event.Type != events.WorkOrderReassigned {
+		return nil
+	}
+	return s.Outbox.Enqueue(db, JobAssignmentNotification, event.WorkOrder.PlantID, assignmentJob{WorkOrderID: event.WorkOrder.ID})
+}
+
+// Deliver is the outbox handler sending an assignment notification. The work order
+// is read again so a notification retried later names its current operator.
+func (s *NotificationSubscriber) Deliver(db *gorm.DB, payload []byte) error {
+	var job assignmentJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+
+	var workOrder models.WorkOrder
+	if err := db.First(&workOrder, job.WorkOrderID).Error; err != nil {
+		return fmt.Errorf("error fetching work order %d: %v", job.WorkOrderID, err)
+	}
+	_, err := s.Notifications.NotifyAssignment(db, workOrder)
+	return err
+}
+
+// WebhookSubscriber posts every event as JSON to an external URL
+type WebhookSubscriber struct {
+	URL    string
+	Client *http.Client
+	Outbox *OutboxService
+}
+
+// NewWebhookSubscriber returns a webhook subscriber giving up on a delivery after the timeout
+func NewWebhookSubscriber(url string, timeout time.Duration, outbox *OutboxService) *WebhookSubscriber {
+	return &WebhookSubscriber{URL: url, Client: &http.Client{Timeout: timeout}, Outbox: outbox}
+}
+
+// Enqueue queues the delivery of the event in the outbox
+func (s *WebhookSubscriber) Enqueue(db *gorm.DB, event events.Event) error {
+	return s.Outbox.Enqueue(db, JobWebhook, event.WorkOrder.PlantID, event)
+}
+
+// Deliver is the outbox handler posting the queued event, it expects a 2xx answer
+func (s *WebhookSubscriber) Deliver(_ *gorm.DB, payload []byte) error {
+	resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
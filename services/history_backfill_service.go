@@ -0,0 +1,102 @@
+package services
+
+import (
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"gorm.io/gorm"
+)
+
+// HistoryBackfillService repairs work orders whose status changes were never recorded in the status history
+type HistoryBackfillService struct{}
+
+// BackfilledWorkOrder is a work order that got a synthesized status history
+type BackfilledWorkOrder struct {
+	WorkOrderID     uint
+	WorkOrderNumber string
+	Status          models.WorkOrderStatus
+	Rows            int // history rows created
+}
+
+// MismatchedWorkOrder is a work order whose current status differs from its latest history row
+type MismatchedWorkOrder struct {
+	WorkOrderID     uint
+	WorkOrderNumber string
+	Status          models.WorkOrderStatus // current status of the work order
+	HistoryStatus   models.WorkOrderStatus // status of the latest history row
+	HistoryAt       time.Time
+}
+
+// HistoryBackfillReport describes what a backfill did, or would do in dry-run mode
+type HistoryBackfillReport struct {
+	Backfilled []BackfilledWorkOrder
+	Mismatched []MismatchedWorkOrder
+}
+
+// Backfill synthesizes a best-effort status history for work orders that have none: a pending
+// row at creation and, when the order has moved on, a row with its current status at its last
+// update. Work orders whose latest history row contradicts their current status are only
+// reported, since the real sequence of changes cannot be recovered. In dry-run mode nothing is written.
+func (s *HistoryBackfillService) Backfill(db *gorm.DB, dryRun bool) (HistoryBackfillReport, error) {
+	var report HistoryBackfillReport
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var workOrders []models.WorkOrder
+		if err := tx.
+			Where("NOT EXISTS (SELECT 1 FROM work_order_status_histories h WHERE h.work_order_id = work_orders.id AND h.deleted_at IS NULL)").
+			Order("id ASC").
+			Find(&workOrders).Error; err != nil {
+			return err
+		}
+
+		for _, workOrder := range workOrders {
+			rows := []models.WorkOrderStatusHistory{{
+				WorkOrderID: workOrder.ID,
+				Status:      models.StatusPending,
+				Quantity:    0,
+				Note:        "Backfilled from work order creation",
+				CreatedAt:   workOrder.CreatedAt,
+			}}
+			if workOrder.Status != models.StatusPending {
+				rows = append(rows, models.WorkOrderStatusHistory{
+					WorkOrderID: workOrder.ID,
+					Status:      workOrder.Status,
+					Quantity:    workOrder.Quantity,
+					Note:        "Backfilled from current status",
+					CreatedAt:   workOrder.UpdatedAt,
+				})
+			}
+
+			if !dryRun {
+				if err := tx.Create(&rows).Error; err != nil {
+					return err
+				}
+			}
+
+			report.Backfilled = append(report.Backfilled, BackfilledWorkOrder{
+				WorkOrderID:     workOrder.ID,
+				WorkOrderNumber: workOrder.WorkOrderNumber,
+				Status:          workOrder.Status,
+				Rows:            len(rows),
+			})
+		}
+
+		// Compare every work order with its latest history row
+		return tx.Raw(`
+			SELECT work_orders.id AS work_order_id, work_orders.work_order_number, work_orders.status,
+				latest.status AS history_status, latest.created_at AS history_at
+			FROM work_orders
+			JOIN (
+				SELECT DISTINCT ON (work_order_id) work_order_id, status, created_at
+				FROM work_order_status_histories
+				WHERE deleted_at IS NULL
+				ORDER BY work_order_id, created_at DESC, id DESC
+			) latest ON latest.work_order_id = work_orders.id
+			WHERE work_orders.deleted_at IS NULL
+			AND latest.status <> work_orders.status
+			ORDER BY work_orders.id
+		`).Scan(&report.Mismatched).Error
+	})
+
+	return report, err
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"gorm.io/gorm"
+)
+
+// NotificationType identifies a kind of notification a user can opt in or out of
+type NotificationType string
+
+const (
+	NotificationAssignment NotificationType = "assignment"
+	NotificationOverdue    NotificationType = "overdue"
+)
+
+// NotificationSender delivers a notification to a user
+type NotificationSender interface {
+	Send(user models.User, subject, body string) error
+}
+
+// LogSender is a NotificationSender that writes notifications to the application log
+type LogSender struct{}
+
+// Send logs the notification
+func (LogSender) Send(user models.User, subject, body string) error {
+	log.Printf("Notification to %s: %s - %s", user.Username, subject, body)
+	return nil
+}
+
+// NotificationService sends notifications according to the user's preferences
+type NotificationService struct {
+	Sender NotificationSender
+}
+
+// Preferences returns the user's notification preferences, or the defaults when none are stored
+func (s *NotificationService) Preferences(db *gorm.DB, userID uint) (models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+	err := db.Where("user_id = ?", userID).First(&preference).Error
+	if err == gorm.ErrRecordNotFound {
+		return models.DefaultNotificationPreference(userID), nil
+	}
+	return preference, err
+}
+
+// SavePreferences stores the user's notification preferences
+func (s *NotificationService) SavePreferences(db *gorm.DB, preference *models.NotificationPreference) error {
+	columns := []string{"email_on_assignment", "email_on_overdue"}
+	if preference.ID != 0 {
+		return db.Model(preference).Select(columns).Updates(preference).Error
+	}
+
+	wanted := *preference
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(preference).Error; err != nil {
+			return err
+		}
+		// Create replaces false values with the column defaults, write the requested values
+		preference.EmailOnAssignment = wanted.EmailOnAssignment
+		preference.EmailOnOverdue = wanted.EmailOnOverdue
+		return tx.Model(preference).Select(columns).Updates(preference).Error
+	})
+}
+
+// Enabled reports whether the preference opts in to the notification type
+func Enabled(preference models.NotificationPreference, notification NotificationType) bool {
+	switch notification {
+	case NotificationAssignment:
+		return preference.EmailOnAssignment
+	case NotificationOverdue:
+		return preference.EmailOnOverdue
+	default:
+		return false
+	}
+}
+
+// Notify sends a notification to the user unless they opted out of its type.
+// It reports whether the notification was sent.
+func (s *NotificationService) Notify(db *gorm.DB, userID uint, notification NotificationType, subject, body string) (bool, error) {
+	preference, err := s.Preferences(db, userID)
+	if err != nil {
+		return false, fmt.Errorf("error fetching notification preferences: %v", err)
+	}
+	if !Enabled(preference, notification) {
+		return false, nil
+	}
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return false, fmt.Errorf("error fetching user data: %v", err)
+	}
+
+	if err := s.Sender.Send(user, subject, body); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NotifyAssignment tells the operator a work order was assigned to them
+func (s *NotificationService) NotifyAssignment(db *gorm.DB, workOrder models.WorkOrder) (bool, error) {
+	return s.Notify(db, workOrder.OperatorID, NotificationAssignment,
+		fmt.Sprintf("Work order %s assigned to you", workOrder.WorkOrderNumber),
+		fmt.Sprintf("%d x %s, due %s", workOrder.TargetQuantity, workOrder.ProductName, workOrder.ProductionDeadline.Format("2006-01-02 15:04")),
+	)
+}
+
+// NotifyOverdue tells the operator a work order passed its production deadline
+func (s *NotificationService) NotifyOverdue(db *gorm.DB, workOrder models.WorkOrder) (bool, error) {
+	return s.Notify(db, workOrder.OperatorID, NotificationOverdue,
+		fmt.Sprintf("Work order %s is overdue", workOrder.WorkOrderNumber),
+		fmt.Sprintf("The production deadline was %s", workOrder.ProductionDeadline.Format("2006-01-02 15:04")),
+	)
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/models"
+)
+
+// recordingSender keeps the subjects of the notifications it was asked to send
+type recordingSender struct {
+	sent []string
+}
+
+func (s *recordingSender) Send(_ models.User, subject, _ string) error {
+	s.sent = append(s.sent, subject)
+	return nil
+}
+
+// storedPreference answers with the preference row of user 2, none when preference is nil
+func storedPreference(preference []driver.Value) dbtest.Responder {
+	return func(stmt dbtest.Statement) dbtest.Rows {
+		switch {
+		case strings.Contains(stmt.SQL, `FROM "notification_preferences"`) && preference != nil:
+			return dbtest.Rows{
+				Columns: []string{"id", "user_id", "email_on_assignment", "email_on_overdue"},
+				Values:  [][]driver.Value{preference},
+			}
+		case strings.Contains(stmt.SQL, `FROM "users"`):
+			return dbtest.Rows{Columns: []string{"id", "username"}, Values: [][]driver.Value{{int64(2), "operator"}}}
+		}
+		return dbtest.Rows{}
+	}
+}
+
+func TestNotifyHonoursPreferences(t *testing.T) {
+	tests := []struct {
+		name         string
+		preference   []driver.Value
+		notification NotificationType
+		wantSent     bool
+	}{
+		{"assignment by default", nil, NotificationAssignment, true},
+		{"no overdue emails by default", nil, NotificationOverdue, false},
+		{"assignment switched off", []driver.Value{int64(1), int64(2), false, true}, NotificationAssignment, false},
+		{"overdue switched on", []driver.Value{int64(1), int64(2), false, true}, NotificationOverdue, true},
+		{"unknown notification type", []driver.Value{int64(1), int64(2), true, true}, NotificationType("digest"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := dbtest.Open(t, storedPreference(tt.preference))
+			sender := &recordingSender{}
+			notifications := &NotificationService{Sender: sender}
+
+			sent, err := notifications.Notify(db, 2, tt.notification, "Work order WO-1", "body")
+			if err != nil {
+				t.Fatalf("notifying: %v", err)
+			}
+			if sent != tt.wantSent || (len(sender.sent) == 1) != tt.wantSent {
+				t.Errorf("sent = %t with %v delivered, want %t", sent, sender.sent, tt.wantSent)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// outboxLease is how long a claimed job may run before another worker claims it
+// again, e.g. because the instance executing it crashed
+const outboxLease = 5 * time.Minute
+
+// outboxMaxBackoff caps the delay between two attempts of a job
+const outboxMaxBackoff = time.Hour
+
+// JobHandler executes the payload of an outbox job, an error schedules a retry
+type JobHandler func(db *gorm.DB, payload []byte) error
+
+// OutboxService stores side effects as outbox jobs and executes them with retries.
+// A job is retried with exponential backoff until it succeeds or has used
+// MaxAttempts attempts, it is then dead-lettered for a manager to inspect.
+type OutboxService struct {
+	DB          *gorm.DB
+	MaxAttempts int           // attempts of new jobs
+	Backoff     time.Duration // delay before the first retry, doubled for every further one
+	handlers    map[string]JobHandler
+}
+
+// Register sets the handler executing the jobs of a kind
+func (s *OutboxService) Register(kind string, handler JobHandler) {
+	if s.handlers == nil {
+		s.handlers = map[string]JobHandler{}
+	}
+	s.handlers[kind] = handler
+}
+
+// Enqueue stores a job of the given kind for the plant, to be executed by the worker
+func (s *OutboxService) Enqueue(db *gorm.DB, kind string, plantID uint, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	maxAttempts := s.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return db.Create(&models.OutboxJob{
+		PlantID:     plantID,
+		Kind:        kind,
+		Payload:     string(body),
+		Status:      models.JobPending,
+		RunAt:       time.Now(),
+		MaxAttempts: maxAttempts,
+	}).Error
+}
+
+// Run executes the due jobs every interval, batchSize at a time, until the context is done
+func (s *OutboxService) Run(ctx context.Context, interval time.Duration, batchSize int) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// Keep going while full batches are due, so a backlog drains quickly
+		for ctx.Err() == nil {
+			processed, err := s.RunOnce(time.Now(), batchSize)
+			if err != nil {
+				log.Printf("Error running outbox jobs: %v", err)
+				break
+			}
+			if processed < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce claims up to batchSize due jobs and executes them, returning how many were claimed
+func (s *OutboxService) RunOnce(now time.Time, batchSize int) (int, error) {
+	jobs, err := s.claim(now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		s.execute(job)
+	}
+	return len(jobs), nil
+}
+
+// claim locks the due jobs, skipping the ones other workers are claiming, and marks
+// them running. Running jobs whose lease expired are claimed again. Every claim
+// counts as an attempt, so a job crashing its worker is still dead-lettered eventually.
+func (s *OutboxService) claim(now time.Time, batchSize int) ([]models.OutboxJob, error) {
+	var jobs []models.OutboxJob
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+				models.JobPending, now, models.JobRunning, now.Add(-outboxLease)).
+			Order("run_at ASC, id ASC").
+			Limit(batchSize).
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(jobs))
+		for i := range jobs {
+			ids = append(ids, jobs[i].ID)
+			jobs[i].Status = models.JobRunning
+			jobs[i].Attempts++
+			jobs[i].LockedAt = &now
+		}
+		return tx.Model(&models.OutboxJob{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":    models.JobRunning,
+			"attempts":  gorm.Expr("attempts + 1"),
+			"locked_at": now,
+		}).Error
+	})
+	return jobs, err
+}
+
+// execute runs a claimed job and records its outcome
+func (s *OutboxService) execute(job models.OutboxJob) {
+	err := s.handle(job)
+	if err == nil {
+		s.finish(job, map[string]interface{}{"status": models.JobDone, "locked_at": nil, "last_error": ""})
+		return
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		log.Printf("Outbox job %d (%s) failed attempt %d of %d, dead-lettered: %v", job.ID, job.Kind, job.Attempts, job.MaxAttempts, err)
+		s.finish(job, map[string]interface{}{"status": models.JobDead, "locked_at": nil, "last_error": err.Error()})
+		return
+	}
+
+	retryAt := time.Now().Add(s.backoff(job.Attempts))
+	log.Printf("Outbox job %d (%s) failed attempt %d of %d, retrying at %s: %v",
+		job.ID, job.Kind, job.Attempts, job.MaxAttempts, retryAt.Format(time.RFC3339), err)
+	s.finish(job, map[string]interface{}{"status": models.JobPending, "run_at": retryAt, "locked_at": nil, "last_error": err.Error()})
+}
+
+// handle runs the job's handler, turning a panic into an error
+func (s *OutboxService) handle(job models.OutboxJob) (err error) {
+	handler, ok := s.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler registered for job kind %s", job.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(s.DB, []byte(job.Payload))
+}
+
+// finish stores the outcome of a job, as long as no other worker claimed it meanwhile
+func (s *OutboxService) finish(job models.OutboxJob, columns map[string]interface{}) {
+	if err := s.DB.Model(&models.OutboxJob{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, models.JobRunning, job.Attempts).
+		Updates(columns).Error; err != nil {
+		log.Printf("Error saving outcome of outbox job %d: %v", job.ID, err)
+	}
+}
+
+// backoff is the delay after the given failed attempt: Backoff, doubled for every
+// further attempt and capped at outboxMaxBackoff
+func (s *OutboxService) backoff(attempts int) time.Duration {
+	delay := s.Backoff
+	if delay <= 0 {
+		delay = time.Second
+	}
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxBackoff {
+		delay = outboxMaxBackoff
+	}
+	return delay
+}
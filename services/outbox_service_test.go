@@ -0,0 +1,169 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/database/dbtest"
+	"github.com/dawamr/work-order-system-go/models"
+	"gorm.io/gorm"
+)
+
+// outboxJobColumns are the columns of the outbox job rows answered by jobRow
+var outboxJobColumns = []string{"id", "plant_id", "kind", "payload", "status", "run_at", "attempts", "max_attempts", "last_error", "locked_at", "created_at", "updated_at"}
+
+// jobRow is an outbox job row as stored in the database
+func jobRow(id int64, status models.OutboxJobStatus, attempts int64, lockedAt interface{}) []driver.Value {
+	created := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	return []driver.Value{id, int64(1), "test", `{"n":1}`, string(status), created, attempts, int64(3), "", lockedAt, created, created}
+}
+
+// claimedJobs answers the claim query with rows and every update with one affected row
+func claimedJobs(rows ...[]driver.Value) dbtest.Responder {
+	return func(stmt dbtest.Statement) dbtest.Rows {
+		switch {
+		case strings.Contains(stmt.SQL, "FOR UPDATE SKIP LOCKED"):
+			return dbtest.Rows{Columns: outboxJobColumns, Values: rows}
+		case strings.HasPrefix(stmt.SQL, "UPDATE"):
+			return dbtest.Rows{RowsAffected: int64(len(rows))}
+		}
+		return dbtest.Rows{}
+	}
+}
+
+// hasArgs reports whether the statement was run with every one of the values
+func hasArgs(stmt dbtest.Statement, values ...driver.Value) bool {
+	for _, value := range values {
+		found := false
+		for _, arg := range stmt.Args {
+			if arg == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOutboxRedeliversJobOfCrashedWorker(t *testing.T) {
+	claimedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// Worker A claims the job and crashes before executing it
+	dbA, recorderA := dbtest.Open(t, claimedJobs(jobRow(1, models.JobPending, 0, nil)))
+	workerA := &OutboxService{DB: dbA}
+	jobs, err := workerA.claim(claimedAt, 10)
+	if err != nil {
+		t.Fatalf("claiming as worker A: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].Attempts != 1 || jobs[0].Status != models.JobRunning {
+		t.Fatalf("worker A claimed %+v, want job 1 running on attempt 1", jobs)
+	}
+	if updates := recorderA.Find(`UPDATE "outbox_jobs" SET "attempts"=attempts + 1`); len(updates) != 1 {
+		t.Fatalf("worker A did not count its claim as an attempt: %v", recorderA.Statements())
+	}
+
+	// Worker B polls after the lease expired and finds the job still running
+	redeliveredAt := claimedAt.Add(outboxLease + time.Minute)
+	dbB, recorderB := dbtest.Open(t, claimedJobs(jobRow(1, models.JobRunning, 1, claimedAt)))
+	workerB := &OutboxService{DB: dbB}
+	var delivered []string
+	workerB.Register("test", func(_ *gorm.DB, payload []byte) error {
+		delivered = append(delivered, string(payload))
+		return nil
+	})
+
+	processed, err := workerB.RunOnce(redeliveredAt, 10)
+	if err != nil {
+		t.Fatalf("running worker B: %v", err)
+	}
+	if processed != 1 || len(delivered) != 1 || delivered[0] != `{"n":1}` {
+		t.Fatalf("worker B processed %d jobs and delivered %v, want the job redelivered once", processed, delivered)
+	}
+
+	claims := recorderB.Find("FOR UPDATE SKIP LOCKED")
+	if len(claims) != 1 || !hasArgs(claims[0], string(models.JobRunning), redeliveredAt.Add(-outboxLease)) {
+		t.Fatalf("claim of worker B does not pick up jobs whose lease expired: %+v", claims)
+	}
+
+	// The outcome is only stored for worker B's attempt, a late answer of worker A is ignored
+	finishes := recorderB.Find("AND attempts = $")
+	if len(finishes) != 1 || !hasArgs(finishes[0], string(models.JobDone), int64(2)) {
+		t.Fatalf("worker B did not finish attempt 2: %+v", recorderB.Statements())
+	}
+	workerA.finish(jobs[0], map[string]interface{}{"status": models.JobDone})
+	staleFinishes := recorderA.Find("AND attempts = $")
+	if len(staleFinishes) != 1 || !hasArgs(staleFinishes[0], int64(1)) {
+		t.Fatalf("late finish of worker A is not limited to its own attempt: %+v", staleFinishes)
+	}
+}
+
+func TestOutboxExecuteRetriesAndDeadLetters(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempts   int64
+		handler    JobHandler
+		wantStatus models.OutboxJobStatus
+		wantError  string
+	}{
+		{"success", 1, func(*gorm.DB, []byte) error { return nil }, models.JobDone, ""},
+		{"failure is retried", 1, func(*gorm.DB, []byte) error { return errors.New("receiver down") }, models.JobPending, "receiver down"},
+		{"panic is retried", 2, func(*gorm.DB, []byte) error { panic("boom") }, models.JobPending, "handler panicked: boom"},
+		{"last attempt is dead-lettered", 3, func(*gorm.DB, []byte) error { return errors.New("receiver down") }, models.JobDead, "receiver down"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := dbtest.Open(t, claimedJobs(jobRow(1, models.JobPending, tt.attempts-1, nil)))
+			outbox := &OutboxService{DB: db, Backoff: time.Minute}
+			outbox.Register("test", tt.handler)
+
+			if _, err := outbox.RunOnce(time.Now(), 1); err != nil {
+				t.Fatalf("running outbox: %v", err)
+			}
+			finishes := recorder.Find("AND attempts = $")
+			if len(finishes) != 1 {
+				t.Fatalf("outcome not stored: %+v", recorder.Statements())
+			}
+			if !hasArgs(finishes[0], string(tt.wantStatus), tt.wantError) {
+				t.Errorf("outcome %+v, want status %s with error %q", finishes[0], tt.wantStatus, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  time.Duration
+		attempts int
+		want     time.Duration
+	}{
+		{"first retry", 30 * time.Second, 1, 30 * time.Second},
+		{"doubled per attempt", 30 * time.Second, 3, 2 * time.Minute},
+		{"capped at the maximum", 30 * time.Second, 20, outboxMaxBackoff},
+		{"backoff above the maximum", 2 * time.Hour, 1, outboxMaxBackoff},
+		{"default of a second", 0, 2, 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := &OutboxService{Backoff: tt.backoff}
+			if got := outbox.backoff(tt.attempts); got != tt.want {
+				t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboxHandleUnknownKind(t *testing.T) {
+	outbox := &OutboxService{}
+	if err := outbox.handle(models.OutboxJob{Kind: "missing"}); err == nil {
+		t.Error("job of a kind without handler succeeded")
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+	"gorm.io/gorm"
+)
+
+// OverdueReminderService reminds operators of their overdue work orders
+type OverdueReminderService struct {
+	Notifications *NotificationService
+}
+
+// OverdueReminderReport summarizes a reminder run
+type OverdueReminderReport struct {
+	Overdue  int // overdue work orders
+	Snoozed  int // overdue work orders skipped because a manager snoozed them
+	Notified int // reminders sent, operators who opted out are not counted
+}
+
+// Remind notifies the operators of every overdue work order that is not snoozed.
+// In dry-run mode the orders are only counted.
+func (s *OverdueReminderService) Remind(db *gorm.DB, now time.Time, dryRun bool) (OverdueReminderReport, error) {
+	var report OverdueReminderReport
+
+	var overdue int64
+	if err := db.Model(&models.WorkOrder{}).Scopes(models.ScopeOverdue(now)).Count(&overdue).Error; err != nil {
+		return report, err
+	}
+
+	var workOrders []models.WorkOrder
+	if err := db.Scopes(models.ScopeOverdueNotSnoozed(now)).Order("production_deadline ASC, id ASC").Find(&workOrders).Error; err != nil {
+		return report, err
+	}
+
+	report.Overdue = int(overdue)
+	report.Snoozed = report.Overdue - len(workOrders)
+	if dryRun {
+		return report, nil
+	}
+
+	// One failing notification must not stop the others
+	for _, workOrder := range workOrders {
+		sent, err := s.Notifications.NotifyOverdue(db, workOrder)
+		if err != nil {
+			log.Printf("Error sending overdue reminder for work order %s: %v", workOrder.WorkOrderNumber, err)
+			continue
+		}
+		if sent {
+			report.Notified++
+		}
+	}
+	return report, nil
+}
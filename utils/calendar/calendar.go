@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+)
+
+// Calendar knows which days are working days
+type Calendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[string]bool // keyed by YYYY-MM-DD
+}
+
+// New creates a calendar with the given weekend days and holidays
+func New(weekend []time.Weekday, holidays []time.Time) *Calendar {
+	cal := &Calendar{
+		weekend:  map[time.Weekday]bool{},
+		holidays: map[string]bool{},
+	}
+	for _, day := range weekend {
+		cal.weekend[day] = true
+	}
+	for _, holiday := range holidays {
+		cal.holidays[holiday.Format(time.DateOnly)] = true
+	}
+	return cal
+}
+
+// Default returns the business calendar configured through BUSINESS_WEEKEND and BUSINESS_HOLIDAYS
+func Default() (*Calendar, error) {
+	weekend, err := ParseWeekdays(config.AppConfig.BusinessWeekend)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := ParseDates(config.AppConfig.BusinessHolidays)
+	if err != nil {
+		return nil, err
+	}
+	return New(weekend, holidays), nil
+}
+
+// IsBusinessDay reports whether t falls on a working day
+func (cal *Calendar) IsBusinessDay(t time.Time) bool {
+	return !cal.weekend[t.Weekday()] && !cal.holidays[t.Format(time.DateOnly)]
+}
+
+// AddBusinessDays moves t forward by days working days, keeping the time of day.
+// With days = 0 it returns t itself when t is a working day, or the next working day otherwise.
+func (cal *Calendar) AddBusinessDays(t time.Time, days int) time.Time {
+	// A week without working days would loop forever
+	if len(cal.weekend) >= 7 {
+		return t
+	}
+
+	for !cal.IsBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	for days > 0 {
+		t = t.AddDate(0, 0, 1)
+		if cal.IsBusinessDay(t) {
+			days--
+		}
+	}
+	return t
+}
+
+// ParseWeekdays parses a comma separated list of weekday names such as "saturday,sunday"
+func ParseWeekdays(value string) ([]time.Weekday, error) {
+	weekdays := []time.Weekday{}
+	for _, name := range splitList(value) {
+		found := false
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(name, day.String()) || strings.EqualFold(name, day.String()[:3]) {
+				weekdays = append(weekdays, day)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid weekday %q", name)
+		}
+	}
+	return weekdays, nil
+}
+
+// ParseDates parses a comma separated list of YYYY-MM-DD dates
+func ParseDates(value string) ([]time.Time, error) {
+	dates := []time.Time{}
+	for _, item := range splitList(value) {
+		date, err := time.Parse(time.DateOnly, item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", item)
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+// splitList splits a comma separated list and drops empty items
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,98 @@
+package calendar
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// day returns the date at 9:30 UTC, the time of day AddBusinessDays keeps
+func day(date string) time.Time {
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		panic(err)
+	}
+	return t.Add(9*time.Hour + 30*time.Minute)
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	// 2026-03-06 is a Friday, the Tuesday after it a holiday
+	cal := New([]time.Weekday{time.Saturday, time.Sunday}, []time.Time{day("2026-03-10")})
+
+	tests := []struct {
+		name  string
+		cal   *Calendar
+		start time.Time
+		days  int
+		want  time.Time
+	}{
+		{"within the week", cal, day("2026-03-03"), 2, day("2026-03-05")},
+		{"crossing a weekend", cal, day("2026-03-06"), 1, day("2026-03-09")},
+		{"crossing a weekend and a holiday", cal, day("2026-03-06"), 2, day("2026-03-11")},
+		{"starting on a weekend", cal, day("2026-03-07"), 1, day("2026-03-11")},
+		{"zero days on a working day", cal, day("2026-03-05"), 0, day("2026-03-05")},
+		{"zero days on a weekend", cal, day("2026-03-08"), 0, day("2026-03-09")},
+		{"zero days on a holiday", cal, day("2026-03-10"), 0, day("2026-03-11")},
+		{"no working days at all", New([]time.Weekday{0, 1, 2, 3, 4, 5, 6}, nil), day("2026-03-05"), 3, day("2026-03-05")},
+		{"without weekend", New(nil, nil), day("2026-03-06"), 2, day("2026-03-08")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cal.AddBusinessDays(tt.start, tt.days); !got.Equal(tt.want) {
+				t.Errorf("AddBusinessDays(%s, %d) = %s, want %s", tt.start.Format(time.DateTime), tt.days, got.Format(time.DateTime), tt.want.Format(time.DateTime))
+			}
+		})
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []time.Weekday
+		wantErr bool
+	}{
+		{"full names", "saturday,sunday", []time.Weekday{time.Saturday, time.Sunday}, false},
+		{"short names in any case", " Fri , SAT ", []time.Weekday{time.Friday, time.Saturday}, false},
+		{"empty", "", []time.Weekday{}, false},
+		{"unknown day", "saturday,someday", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseWeekdays(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWeekdays(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseWeekdays(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"holidays", "2026-01-01, 2026-08-17,", 2, false},
+		{"empty", "", 0, false},
+		{"not a date", "2026-01-01,17/08/2026", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDates(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDates(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ParseDates(%q) returned %d dates, want %d", tt.value, len(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+)
+
+// Type identifies a kind of domain event
+type Type string
+
+const (
+	// WorkOrderCreated is published for every new work order, including imported ones
+	WorkOrderCreated Type = "work_order.created"
+	// WorkOrderUpdated is published when a Production Manager edits a work order
+	WorkOrderUpdated Type = "work_order.updated"
+	// WorkOrderReassigned is published when a work order moves to another operator
+	WorkOrderReassigned Type = "work_order.reassigned"
+	// StatusChanged is published when a work order moves to another status
+	StatusChanged Type = "work_order.status_changed"
+)
+
+// Event is a domain event, published once by the controllers and delivered to every subscriber
+type Event struct {
+	Type       Type                   `json:"type"`
+	WorkOrder  models.WorkOrder       `json:"work_order"`
+	OldStatus  models.WorkOrderStatus `json:"old_status,omitempty"` // set on status changes
+	ActorID    uint                   `json:"actor_id"`             // user whose request caused the event
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// Handler consumes the events delivered to a subscriber
+type Handler func(Event)
+
+// subscriber is a handler with its own queue, so subscribers consume independently
+type subscriber struct {
+	name    string
+	types   map[Type]bool // empty means every type
+	queue   chan Event
+	handler Handler
+}
+
+// Bus is an in-memory publish/subscribe bus. Publishing never blocks the caller:
+// every subscriber has a buffered queue drained by its own workers, and an event
+// is dropped for a subscriber whose queue is full.
+type Bus struct {
+	mu          sync.RWMutex
+	bufferSize  int
+	subscribers []*subscriber
+	closed      bool
+	workers     sync.WaitGroup
+}
+
+// NewBus returns a bus whose subscribers queue up to bufferSize events each
+func NewBus(bufferSize int) *Bus {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &Bus{bufferSize: bufferSize}
+}
+
+// Subscribe registers a handler run by the given number of workers for the listed
+// event types, or for every type when none are listed. Events of one subscriber are
+// handled in publish order only when it has a single worker.
+func (b *Bus) Subscribe(name string, workers int, handler Handler, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		log.Printf("Event subscriber %s not registered, the event bus is closed", name)
+		return
+	}
+
+	s := &subscriber{
+		name:    name,
+		types:   map[Type]bool{},
+		queue:   make(chan Event, b.bufferSize),
+		handler: handler,
+	}
+	for _, t := range types {
+		s.types[t] = true
+	}
+	b.subscribers = append(b.subscribers, s)
+
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		b.workers.Add(1)
+		go b.work(s)
+	}
+}
+
+// Publish queues the event for every subscriber of its type
+func (b *Bus) Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	// The read lock keeps Close from closing the queues while sending
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		log.Printf("Event %s of work order %d dropped, the event bus is closed", event.Type, event.WorkOrder.ID)
+		return
+	}
+
+	for _, s := range b.subscribers {
+		if len(s.types) > 0 && !s.types[event.Type] {
+			continue
+		}
+		select {
+		case s.queue <- event:
+		default:
+			log.Printf("Event %s of work order %d dropped for %s, its queue is full", event.Type, event.WorkOrder.ID, s.name)
+		}
+	}
+}
+
+// Close stops accepting events and waits until the subscribers handled the
+// queued ones, or until the context is done
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, s := range b.subscribers {
+			close(s.queue)
+		}
+	}
+	b.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		b.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work handles the subscriber's events until its queue is closed and empty
+func (b *Bus) work(s *subscriber) {
+	defer b.workers.Done()
+	for event := range s.queue {
+		handle(s, event)
+	}
+}
+
+// handle runs the handler, a panicking handler only loses the one event
+func handle(s *subscriber, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event subscriber %s panicked on %s of work order %d: %v", s.name, event.Type, event.WorkOrder.ID, r)
+		}
+	}()
+	s.handler(event)
+}
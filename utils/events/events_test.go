@@ -0,0 +1,211 @@
+package events
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/models"
+)
+
+// recorder collects the events a subscriber handled
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// handle is the Handler of the subscriber
+func (r *recorder) handle(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// workOrders returns the ids of the work orders of the handled events
+func (r *recorder) workOrders() []uint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := []uint{}
+	for _, event := range r.events {
+		ids = append(ids, event.WorkOrder.ID)
+	}
+	return ids
+}
+
+// closeBus drains the bus, failing the test when the subscribers do not finish
+func closeBus(t *testing.T, bus *Bus) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bus.Close(ctx); err != nil {
+		t.Fatalf("closing bus: %v", err)
+	}
+}
+
+// event is an event of the type for work order id
+func event(eventType Type, id uint) Event {
+	return Event{Type: eventType, WorkOrder: models.WorkOrder{ID: id}}
+}
+
+func TestBusDeliversToSubscribersOfTheType(t *testing.T) {
+	published := []Event{
+		event(WorkOrderCreated, 1),
+		event(StatusChanged, 2),
+		event(WorkOrderReassigned, 3),
+		event(StatusChanged, 4),
+	}
+
+	tests := []struct {
+		name  string
+		types []Type
+		want  []uint
+	}{
+		{"every type", nil, []uint{1, 2, 3, 4}},
+		{"one type", []Type{StatusChanged}, []uint{2, 4}},
+		{"several types", []Type{WorkOrderCreated, WorkOrderReassigned}, []uint{1, 3}},
+		{"type never published", []Type{WorkOrderUpdated}, []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus(10)
+			var r recorder
+			bus.Subscribe("test", 1, r.handle, tt.types...)
+			for _, e := range published {
+				bus.Publish(e)
+			}
+			closeBus(t, bus)
+
+			// A single worker handles the events in publish order
+			if got := r.workOrders(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("handled work orders %v, want %v", got, tt.want)
+			}
+			for _, e := range r.events {
+				if e.OccurredAt.IsZero() {
+					t.Errorf("event %s of work order %d has no time", e.Type, e.WorkOrder.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestBusDropsEventsOfAFullQueue(t *testing.T) {
+	tests := []struct {
+		name        string
+		bufferSize  int
+		published   int
+		wantHandled int // the event the subscriber was busy with and a full queue
+	}{
+		{"queue holds every event", 10, 5, 5},
+		{"full queue drops events", 2, 5, 3},
+		{"buffer of at least one", 0, 3, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus(tt.bufferSize)
+			started, release := make(chan struct{}), make(chan struct{})
+			var once sync.Once
+			var r recorder
+			bus.Subscribe("blocked", 1, func(e Event) {
+				once.Do(func() { close(started) })
+				<-release
+				r.handle(e)
+			})
+
+			bus.Publish(event(WorkOrderCreated, 1))
+			<-started
+			for id := uint(2); id <= uint(tt.published); id++ {
+				// Publishing does not wait for the busy subscriber
+				bus.Publish(event(WorkOrderCreated, id))
+			}
+			close(release)
+			closeBus(t, bus)
+
+			if got := len(r.workOrders()); got != tt.wantHandled {
+				t.Errorf("handled %d events, want %d", got, tt.wantHandled)
+			}
+		})
+	}
+}
+
+func TestBusRecoversFromPanickingSubscriber(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{"single worker", 1},
+		{"several workers", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus(10)
+			var panicking, other recorder
+			bus.Subscribe("panicking", tt.workers, func(e Event) {
+				panicking.handle(e)
+				panic("subscriber failed")
+			})
+			bus.Subscribe("other", 1, other.handle)
+
+			for id := uint(1); id <= 5; id++ {
+				bus.Publish(event(StatusChanged, id))
+			}
+			closeBus(t, bus)
+
+			// A panic only loses the one event, the workers and other subscribers go on
+			if got := len(panicking.workOrders()); got != 5 {
+				t.Errorf("panicking subscriber handled %d events, want 5", got)
+			}
+			if got := other.workOrders(); !reflect.DeepEqual(got, []uint{1, 2, 3, 4, 5}) {
+				t.Errorf("other subscriber handled work orders %v, want [1 2 3 4 5]", got)
+			}
+		})
+	}
+}
+
+func TestBusClosed(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(bus *Bus, r *recorder)
+	}{
+		{"publish after close", func(bus *Bus, r *recorder) { bus.Publish(event(WorkOrderCreated, 1)) }},
+		{"subscribe after close", func(bus *Bus, r *recorder) { bus.Subscribe("late", 1, r.handle) }},
+		{"close twice", func(bus *Bus, r *recorder) {
+			if err := bus.Close(context.Background()); err != nil {
+				panic(err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus(10)
+			var r recorder
+			bus.Subscribe("test", 1, r.handle)
+			closeBus(t, bus)
+
+			tt.action(bus, &r)
+			bus.Publish(event(StatusChanged, 2))
+			if got := r.workOrders(); len(got) > 0 {
+				t.Errorf("closed bus delivered work orders %v", got)
+			}
+		})
+	}
+}
+
+func TestBusCloseTimesOut(t *testing.T) {
+	bus := NewBus(10)
+	release := make(chan struct{})
+	defer close(release)
+	bus.Subscribe("stuck", 1, func(Event) { <-release })
+	bus.Publish(event(WorkOrderCreated, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Close() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
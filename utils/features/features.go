@@ -0,0 +1,106 @@
+package features
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dawamr/work-order-system-go/config"
+	"github.com/dawamr/work-order-system-go/database"
+	"github.com/dawamr/work-order-system-go/models"
+)
+
+// ProgressApproval requires a Production Manager to approve every progress
+// entry of a work order before it can be completed
+const ProgressApproval = "progress_approval"
+
+// refreshInterval is how long the flags are cached, so changes made through
+// another instance are picked up without a restart
+const refreshInterval = 30 * time.Second
+
+var (
+	mu       sync.RWMutex
+	flags    map[string]bool
+	loadedAt time.Time
+)
+
+// Enabled reports whether the named feature is switched on. Flags stored in
+// the database take precedence over the ones enabled through FEATURE_FLAGS,
+// unknown flags are off.
+func Enabled(name string) bool {
+	return snapshot()[name]
+}
+
+// EnabledNames returns the names of all switched on features, sorted
+func EnabledNames() []string {
+	names := []string{}
+	for name, enabled := range snapshot() {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Invalidate drops the cached flags, the next lookup reloads them
+func Invalidate() {
+	mu.Lock()
+	defer mu.Unlock()
+	loadedAt = time.Time{}
+}
+
+// snapshot returns the cached flags, reloading them once they are stale
+func snapshot() map[string]bool {
+	mu.RLock()
+	current, fresh := flags, time.Since(loadedAt) < refreshInterval
+	mu.RUnlock()
+	if fresh {
+		return current
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if time.Since(loadedAt) < refreshInterval {
+		return flags
+	}
+
+	loaded, err := load()
+	if err != nil {
+		// Keep serving the previous flags, retry with the next lookup
+		log.Printf("Error loading feature flags: %v", err)
+		if flags == nil {
+			return Defaults()
+		}
+		return flags
+	}
+	flags, loadedAt = loaded, time.Now()
+	return flags
+}
+
+// load reads the FEATURE_FLAGS defaults and overrides them with the stored flags
+func load() (map[string]bool, error) {
+	loaded := Defaults()
+
+	var stored []models.FeatureFlag
+	if err := database.DB.Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	for _, flag := range stored {
+		loaded[flag.Name] = flag.Enabled
+	}
+	return loaded, nil
+}
+
+// Defaults returns the features enabled through the comma separated FEATURE_FLAGS
+func Defaults() map[string]bool {
+	defaults := map[string]bool{}
+	for _, name := range strings.Split(config.AppConfig.FeatureFlags, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			defaults[name] = true
+		}
+	}
+	return defaults
+}
@@ -0,0 +1,126 @@
+package locale
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale describes how exports format dates and numbers
+type Locale struct {
+	Name           string
+	DateLayout     string
+	DateTimeLayout string
+	DecimalSep     string
+	ThousandsSep   string
+	raw            bool
+}
+
+var (
+	// ISO formats dates as YYYY-MM-DD and numbers without grouping, the default
+	ISO = Locale{Name: "iso", DateLayout: time.DateOnly, DateTimeLayout: "2006-01-02 15:04:05", DecimalSep: "."}
+	// Raw keeps machine formats (RFC 3339 timestamps, unrounded numbers) for programmatic consumers
+	Raw = Locale{Name: "raw", DateLayout: time.RFC3339, DateTimeLayout: time.RFC3339, DecimalSep: ".", raw: true}
+	// EnglishUS formats for US spreadsheets
+	EnglishUS = Locale{Name: "en-US", DateLayout: "01/02/2006", DateTimeLayout: "01/02/2006 03:04 PM", DecimalSep: ".", ThousandsSep: ","}
+	// Indonesian formats for Indonesian spreadsheets
+	Indonesian = Locale{Name: "id-ID", DateLayout: "02/01/2006", DateTimeLayout: "02/01/2006 15.04", DecimalSep: ",", ThousandsSep: "."}
+)
+
+// locales maps lowercase locale tags, with and without region, to their locale
+var locales = map[string]Locale{
+	"iso":   ISO,
+	"raw":   Raw,
+	"en":    EnglishUS,
+	"en-us": EnglishUS,
+	"id":    Indonesian,
+	"id-id": Indonesian,
+}
+
+// Parse returns the locale for a tag such as id-ID, en_US or raw
+func Parse(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if l, ok := locales[tag]; ok {
+		return l, true
+	}
+	// Fall back to the language without the region, e.g. id-XX to id
+	if language, _, found := strings.Cut(tag, "-"); found {
+		l, ok := locales[language]
+		return l, ok
+	}
+	return Locale{}, false
+}
+
+// FromRequest picks the locale from an explicit locale parameter, then from the
+// languages of an Accept-Language header in order of preference, defaulting to ISO
+func FromRequest(param, acceptLanguage string) Locale {
+	if param != "" {
+		if l, ok := Parse(param); ok {
+			return l
+		}
+		return ISO
+	}
+
+	best, bestQuality := ISO, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if l, ok := Parse(tag); ok && quality > bestQuality {
+			best, bestQuality = l, quality
+		}
+	}
+	return best
+}
+
+// Date formats the date part of t
+func (l Locale) Date(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+// DateTime formats t with its time of day
+func (l Locale) DateTime(t time.Time) string {
+	return t.Format(l.DateTimeLayout)
+}
+
+// Int formats n with the locale's thousands separator
+func (l Locale) Int(n int64) string {
+	return l.group(strconv.FormatInt(n, 10))
+}
+
+// Float formats v with the given number of decimals, or unrounded for the raw locale
+func (l Locale) Float(v float64, decimals int) string {
+	if l.raw {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	formatted := strconv.FormatFloat(v, 'f', decimals, 64)
+	whole, fraction, hasFraction := strings.Cut(formatted, ".")
+	whole = l.group(whole)
+	if !hasFraction {
+		return whole
+	}
+	return whole + l.DecimalSep + fraction
+}
+
+// group inserts the thousands separator into a string of digits with an optional sign
+func (l Locale) group(digits string) string {
+	if l.ThousandsSep == "" {
+		return digits
+	}
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(l.ThousandsSep)
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
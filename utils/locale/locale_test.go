@@ -0,0 +1,101 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   string
+		wantOK bool
+	}{
+		{"iso", "iso", true},
+		{"RAW", "raw", true},
+		{"id-ID", "id-ID", true},
+		{"id_ID", "id-ID", true},
+		{" en-us ", "en-US", true},
+		{"en-GB", "en-US", true},
+		{"id", "id-ID", true},
+		{"fr-FR", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			l, ok := Parse(tt.tag)
+			if ok != tt.wantOK || l.Name != tt.want {
+				t.Errorf("Parse(%q) = %q, %t, want %q, %t", tt.tag, l.Name, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		param          string
+		acceptLanguage string
+		want           string
+	}{
+		{"default", "", "", "iso"},
+		{"parameter", "id-ID", "en-US", "id-ID"},
+		{"raw parameter", "raw", "id-ID", "raw"},
+		{"unknown parameter falls back to ISO", "fr-FR", "id-ID", "iso"},
+		{"header", "", "id-ID", "id-ID"},
+		{"first supported language of the header", "", "fr-FR, en-US;q=0.8", "en-US"},
+		{"highest quality wins", "", "en-US;q=0.5, id;q=0.9", "id-ID"},
+		{"equal quality keeps the first", "", "en-US, id-ID", "en-US"},
+		{"refused language", "", "id-ID;q=0", "iso"},
+		{"unsupported header", "", "fr-FR, de", "iso"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromRequest(tt.param, tt.acceptLanguage); got.Name != tt.want {
+				t.Errorf("FromRequest(%q, %q) = %q, want %q", tt.param, tt.acceptLanguage, got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	at := time.Date(2026, 3, 2, 14, 5, 9, 0, time.UTC)
+
+	tests := []struct {
+		locale       Locale
+		wantDate     string
+		wantDateTime string
+		wantInt      string
+		wantNegative string
+		wantFloat    string
+		wantRounded  string
+	}{
+		{ISO, "2026-03-02", "2026-03-02 14:05:09", "1234567", "-1234", "1234567.89", "0.33"},
+		{Raw, "2026-03-02T14:05:09Z", "2026-03-02T14:05:09Z", "1234567", "-1234", "1234567.891", "0.3333333333333333"},
+		{EnglishUS, "03/02/2026", "03/02/2026 02:05 PM", "1,234,567", "-1,234", "1,234,567.89", "0.33"},
+		{Indonesian, "02/03/2026", "02/03/2026 14.05", "1.234.567", "-1.234", "1.234.567,89", "0,33"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale.Name, func(t *testing.T) {
+			checks := []struct {
+				name, got, want string
+			}{
+				{"Date", tt.locale.Date(at), tt.wantDate},
+				{"DateTime", tt.locale.DateTime(at), tt.wantDateTime},
+				{"Int", tt.locale.Int(1234567), tt.wantInt},
+				{"negative Int", tt.locale.Int(-1234), tt.wantNegative},
+				{"Float", tt.locale.Float(1234567.891, 2), tt.wantFloat},
+				{"rounded Float", tt.locale.Float(1.0/3, 2), tt.wantRounded},
+				{"Float without decimals", tt.locale.Float(999, 0), "999"},
+			}
+			for _, check := range checks {
+				if check.got != check.want {
+					t.Errorf("%s = %q, want %q", check.name, check.got, check.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page layout in points
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 50
+	fontSize     = 10
+	lineHeight   = 13
+	linesPerPage = (pageHeight - 2*margin) / lineHeight
+	maxLineChars = 95
+)
+
+// Document is a minimal PDF document of plain text lines in Helvetica, broken
+// into A4 pages. Characters outside printable ASCII are written as '?'.
+type Document struct {
+	lines []string
+}
+
+// AddLine appends a line of text, wrapping it at word boundaries when it is too wide for the page
+func (d *Document) AddLine(text string) {
+	for len(text) > maxLineChars {
+		cut := strings.LastIndex(text[:maxLineChars], " ")
+		if cut <= 0 {
+			cut = maxLineChars
+		}
+		d.lines = append(d.lines, text[:cut])
+		text = "  " + strings.TrimLeft(text[cut:], " ")
+	}
+	d.lines = append(d.lines, text)
+}
+
+// Write writes the document as a PDF file
+func (d *Document) Write(out io.Writer) error {
+	pages := d.pages()
+
+	// Objects 1 to 3 are the catalog, the page tree and the font, followed by a
+	// page and its content stream per page
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // page tree, filled in once the page objects are numbered
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, 0, len(pages))
+	for _, page := range pages {
+		pageObject := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObject))
+		stream := contentStream(page)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, pageObject+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := b.WriteTo(out)
+	return err
+}
+
+// pages splits the lines into pages, an empty document still has one blank page
+func (d *Document) pages() [][]string {
+	var pages [][]string
+	for start := 0; start < len(d.lines); start += linesPerPage {
+		end := start + linesPerPage
+		if end > len(d.lines) {
+			end = len(d.lines)
+		}
+		pages = append(pages, d.lines[start:end])
+	}
+	if len(pages) == 0 {
+		pages = append(pages, nil)
+	}
+	return pages
+}
+
+// contentStream draws the lines of a page from the top left margin down
+func contentStream(lines []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin-fontSize)
+	for _, line := range lines {
+		fmt.Fprintf(&b, "(%s) Tj T*\n", escape(line))
+	}
+	b.WriteString("ET")
+	return b.String()
+}
+
+// escape makes a line safe inside a PDF string literal
+func escape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
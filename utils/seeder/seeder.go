@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"math/rand"
@@ -10,6 +11,7 @@ import (
 	"github.com/dawamr/work-order-system-go/database"
 	"github.com/dawamr/work-order-system-go/models"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -54,7 +56,26 @@ var progressDescriptions = []string{
 	"Menyerahkan produk ke bagian QA",
 }
 
+// Seed untuk random generator, gunakan nilai yang sama untuk menghasilkan data yang identik
+var seed = flag.Int64("seed", 0, "Seed untuk random generator (0 = acak berdasarkan waktu)")
+
+// Mode upsert menambah data yang belum ada tanpa menghapus data lama, untuk mengisi ulang staging
+var upsert = flag.Bool("upsert", false, "Buat pengguna yang belum ada dan tambah work order sampai -work-orders tanpa menghapus data")
+
+// Jumlah work order yang dibuat, atau jumlah total yang dituju dalam mode upsert
+var workOrderTarget = flag.Int("work-orders", WorkOrderCount, "Jumlah work order yang dibuat (total yang dituju dengan -upsert)")
+
 func main() {
+	flag.Parse()
+
+	// Inisialisasi random generator
+	seedValue := *seed
+	if seedValue == 0 {
+		seedValue = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seedValue))
+	fmt.Printf("Using random seed %d\n", seedValue)
+
 	// Load configuration
 	config.LoadConfig()
 
@@ -65,17 +86,22 @@ func main() {
 	database.MigrateDB()
 
 	// Seed data
-	seedUsers()
-	seedWorkOrders()
+	if *upsert {
+		upsertUsers()
+		topUpWorkOrders(rng, *workOrderTarget)
+	} else {
+		seedUsers()
+		seedWorkOrders(rng, *workOrderTarget)
+	}
 
 	fmt.Println("Seeding completed successfully!")
 }
 
 // Menghasilkan tanggal acak dalam rentang yang ditentukan
-func randomDate(start, end time.Time) time.Time {
+func randomDate(rng *rand.Rand, start, end time.Time) time.Time {
 	delta := end.Unix() - start.Unix()
-	sec := rand.Int63n(delta) + start.Unix()
-	return time.Unix(sec, 0)
+	sec := rng.Int63n(delta) + start.Unix()
+	return time.Unix(sec, 0).UTC()
 }
 
 // Menghasilkan password hash
@@ -115,8 +141,39 @@ func seedUsers() {
 	fmt.Printf("Created %d Operators\n", UserCount)
 }
 
+// Seed data pengguna tanpa menghapus: hanya pengguna yang belum ada (berdasarkan username) yang dibuat
+func upsertUsers() {
+	fmt.Println("Upserting users...")
+
+	users := []models.User{{
+		Username: "manager",
+		Password: "password",
+		Role:     models.RoleProductionManager,
+	}}
+	for i := 1; i <= UserCount; i++ {
+		users = append(users, models.User{
+			Username: fmt.Sprintf("operator%d", i),
+			Password: "password",
+			Role:     models.RoleOperator,
+		})
+	}
+
+	created := 0
+	for _, user := range users {
+		var existing models.User
+		result := database.DB.Where(models.User{Username: user.Username}).
+			Attrs(models.User{Password: user.Password, Role: user.Role}).
+			FirstOrCreate(&existing)
+		if result.Error != nil {
+			log.Fatalf("Failed to upsert user %s: %v", user.Username, result.Error)
+		}
+		created += int(result.RowsAffected)
+	}
+	fmt.Printf("Created %d missing users, %d already existed\n", created, len(users)-created)
+}
+
 // Seed data work order
-func seedWorkOrders() {
+func seedWorkOrders(rng *rand.Rand, count int) {
 	fmt.Println("Seeding work orders...")
 
 	// Hapus data work order yang ada
@@ -133,41 +190,8 @@ func seedWorkOrders() {
 	}
 
 	// Buat work orders
-	for i := 1; i <= WorkOrderCount; i++ {
-		// Pilih operator secara acak
-		operator := operators[rand.Intn(len(operators))]
-
-		// Tentukan tanggal pembuatan dan deadline
-		createdAt := randomDate(startDate, endDate)
-		productionDeadline := createdAt.Add(time.Hour * 24 * time.Duration(rand.Intn(14)+1)) // 1-14 hari setelah dibuat
-
-		// Tentukan status secara acak
-		statusOptions := []models.WorkOrderStatus{
-			models.StatusPending,
-			models.StatusInProgress,
-			models.StatusCompleted,
-		}
-		status := statusOptions[rand.Intn(len(statusOptions))]
-
-		// Buat work order number
-		workOrderNumber := fmt.Sprintf("WO-%s-%03d", createdAt.Format("20060102"), i%999+1)
-
-		// Pilih nama produk secara acak
-		productName := productNames[rand.Intn(len(productNames))]
-
-		// Buat work order
-		targetQuantity := rand.Intn(100) + 1 // 1-100
-		workOrder := models.WorkOrder{
-			WorkOrderNumber:    workOrderNumber,
-			ProductName:        productName,
-			TargetQuantity:     targetQuantity,
-			Quantity:           rand.Intn(targetQuantity),
-			ProductionDeadline: productionDeadline,
-			Status:             status,
-			OperatorID:         operator.ID,
-			CreatedAt:          createdAt,
-			UpdatedAt:          createdAt,
-		}
+	for i := 1; i <= count; i++ {
+		workOrder := randomWorkOrder(rng, operators, i)
 
 		// Simpan work order
 		result := database.DB.Create(&workOrder)
@@ -175,20 +199,111 @@ func seedWorkOrders() {
 			log.Fatalf("Failed to create work order: %v", result.Error)
 		}
 
-		// Buat riwayat status
-		seedWorkOrderStatusHistory(workOrder)
+		seedWorkOrderDetails(rng, workOrder)
+	}
+
+	fmt.Printf("Created %d Work Orders\n", count)
+}
+
+// Tambah work order sampai jumlahnya mencapai target tanpa menghapus data yang ada.
+// Nomor work order yang sudah dipakai dilewati lewat ON CONFLICT DO NOTHING.
+func topUpWorkOrders(rng *rand.Rand, target int) {
+	fmt.Println("Topping up work orders...")
+
+	var existing int64
+	database.DB.Model(&models.WorkOrder{}).Count(&existing)
+	missing := target - int(existing)
+	if missing <= 0 {
+		fmt.Printf("Already %d Work Orders, nothing to add\n", existing)
+		return
+	}
+
+	// Dapatkan semua operator
+	var operators []models.User
+	database.DB.Where("role = ?", models.RoleOperator).Find(&operators)
+
+	if len(operators) == 0 {
+		log.Fatal("No operators found. Please seed users first.")
+	}
+
+	created := 0
+	for i := int(existing) + 1; created < missing; i++ {
+		// Batasi percobaan agar tidak berputar terus saat nomor work order habis
+		if i > int(existing)+missing*10 {
+			log.Fatalf("Could not find free work order numbers, created %d of %d", created, missing)
+		}
 
-		// Jika status in progress atau completed, buat progress entries
-		if status == models.StatusInProgress || status == models.StatusCompleted {
-			seedWorkOrderProgress(workOrder)
+		workOrder := randomWorkOrder(rng, operators, i)
+		result := database.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "work_order_number"}},
+			DoNothing: true,
+		}).Create(&workOrder)
+		if result.Error != nil {
+			log.Fatalf("Failed to create work order: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// Nomor sudah dipakai, coba nomor berikutnya
+			continue
 		}
+
+		seedWorkOrderDetails(rng, workOrder)
+		created++
+	}
+
+	fmt.Printf("Created %d Work Orders, %d in total\n", created, int(existing)+created)
+}
+
+// Buat work order acak dengan urutan i untuk nomor work order
+func randomWorkOrder(rng *rand.Rand, operators []models.User, i int) models.WorkOrder {
+	// Pilih operator secara acak
+	operator := operators[rng.Intn(len(operators))]
+
+	// Tentukan tanggal pembuatan dan deadline
+	createdAt := randomDate(rng, startDate, endDate)
+	productionDeadline := createdAt.Add(time.Hour * 24 * time.Duration(rng.Intn(14)+1)) // 1-14 hari setelah dibuat
+
+	// Tentukan status secara acak
+	statusOptions := []models.WorkOrderStatus{
+		models.StatusPending,
+		models.StatusInProgress,
+		models.StatusCompleted,
 	}
+	status := statusOptions[rng.Intn(len(statusOptions))]
+
+	// Buat work order number
+	workOrderNumber := fmt.Sprintf("WO-%s-%03d", createdAt.Format("20060102"), i%999+1)
+
+	// Pilih nama produk secara acak
+	productName := productNames[rng.Intn(len(productNames))]
+
+	// Buat work order
+	targetQuantity := rng.Intn(100) + 1 // 1-100
+	return models.WorkOrder{
+		WorkOrderNumber:    workOrderNumber,
+		ProductName:        productName,
+		TargetQuantity:     targetQuantity,
+		Quantity:           rng.Intn(targetQuantity),
+		ProductionDeadline: productionDeadline,
+		Status:             status,
+		OperatorID:         operator.ID,
+		CreatedAt:          createdAt,
+		UpdatedAt:          createdAt,
+	}
+}
 
-	fmt.Printf("Created %d Work Orders\n", WorkOrderCount)
+// Buat riwayat status dan progress untuk work order yang baru disimpan
+func seedWorkOrderDetails(rng *rand.Rand, workOrder models.WorkOrder) {
+	// Buat riwayat status
+	seedWorkOrderStatusHistory(rng, workOrder)
+
+	// Jika status in progress atau completed, buat progress entries
+	if workOrder.Status == models.StatusInProgress || workOrder.Status == models.StatusCompleted {
+		seedWorkOrderProgress(rng, workOrder)
+	}
 }
 
 // Seed data riwayat status work order
-func seedWorkOrderStatusHistory(workOrder models.WorkOrder) {
+func seedWorkOrderStatusHistory(rng *rand.Rand, workOrder models.WorkOrder) {
 	// Selalu buat status awal "pending"
 	pendingHistory := models.WorkOrderStatusHistory{
 		WorkOrderID: workOrder.ID,
@@ -201,7 +316,7 @@ func seedWorkOrderStatusHistory(workOrder models.WorkOrder) {
 
 	// Jika status in progress atau completed, tambahkan riwayat in progress
 	if workOrder.Status == models.StatusInProgress || workOrder.Status == models.StatusCompleted {
-		inProgressDate := workOrder.CreatedAt.Add(time.Hour * 24 * time.Duration(rand.Intn(3)+1)) // 1-3 hari setelah dibuat
+		inProgressDate := workOrder.CreatedAt.Add(time.Hour * 24 * time.Duration(rng.Intn(3)+1)) // 1-3 hari setelah dibuat
 		inProgressHistory := models.WorkOrderStatusHistory{
 			WorkOrderID: workOrder.ID,
 			Status:      models.StatusInProgress,
@@ -214,7 +329,7 @@ func seedWorkOrderStatusHistory(workOrder models.WorkOrder) {
 
 	// Jika status completed, tambahkan riwayat completed
 	if workOrder.Status == models.StatusCompleted {
-		completedDate := workOrder.CreatedAt.Add(time.Hour * 24 * time.Duration(rand.Intn(5)+4)) // 4-8 hari setelah dibuat
+		completedDate := workOrder.CreatedAt.Add(time.Hour * 24 * time.Duration(rng.Intn(5)+4)) // 4-8 hari setelah dibuat
 		completedHistory := models.WorkOrderStatusHistory{
 			WorkOrderID: workOrder.ID,
 			Status:      models.StatusCompleted,
@@ -227,13 +342,13 @@ func seedWorkOrderStatusHistory(workOrder models.WorkOrder) {
 }
 
 // Seed data progress work order
-func seedWorkOrderProgress(workOrder models.WorkOrder) {
+func seedWorkOrderProgress(rng *rand.Rand, workOrder models.WorkOrder) {
 	// Tentukan jumlah entri progress (1-5)
-	progressEntries := rand.Intn(5) + 1
+	progressEntries := rng.Intn(5) + 1
 
 	for i := 0; i < progressEntries; i++ {
 		// Tentukan tanggal progress
-		progressDate := workOrder.CreatedAt.Add(time.Hour * 24 * time.Duration(rand.Intn(5)+1)) // 1-5 hari setelah dibuat
+		progressDate := workOrder.CreatedAt.Add(time.Hour * 24 * time.Duration(rng.Intn(5)+1)) // 1-5 hari setelah dibuat
 
 		// Jika status completed, pastikan tanggal progress sebelum tanggal completed
 		if workOrder.Status == models.StatusCompleted {
@@ -247,13 +362,13 @@ func seedWorkOrderProgress(workOrder models.WorkOrder) {
 		}
 
 		// Pilih deskripsi progress secara acak
-		progressDesc := progressDescriptions[rand.Intn(len(progressDescriptions))]
+		progressDesc := progressDescriptions[rng.Intn(len(progressDescriptions))]
 
 		// Buat progress entry
 		progress := models.WorkOrderProgress{
 			WorkOrderID:      workOrder.ID,
 			ProgressDesc:     progressDesc,
-			ProgressQuantity: rand.Intn(workOrder.Quantity + 1),    // 0 sampai quantity
+			ProgressQuantity: rng.Intn(workOrder.Quantity + 1),    // 0 sampai quantity
 			CreatedAt:        progressDate,
 			UpdatedAt:        progressDate,
 		}
@@ -261,8 +376,3 @@ func seedWorkOrderProgress(workOrder models.WorkOrder) {
 		database.DB.Create(&progress)
 	}
 }
-
-// Inisialisasi random seed
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
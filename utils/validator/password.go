@@ -0,0 +1,144 @@
+package validator
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dawamr/work-order-system-go/config"
+)
+
+// FieldError describes a single validation rule that a request field failed
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Msg   string `json:"msg"`
+}
+
+// PasswordPolicy holds the rules a password must satisfy
+type PasswordPolicy struct {
+	MinLength     int
+	RequireDigit  bool
+	RequireUpper  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy returns the password policy configured through environment variables
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:     config.AppConfig.PasswordMinLength,
+		RequireDigit:  config.AppConfig.PasswordRequireDigit,
+		RequireUpper:  config.AppConfig.PasswordRequireUpper,
+		RequireSymbol: config.AppConfig.PasswordRequireSymbol,
+	}
+}
+
+// Validate checks the password against the policy and returns one error per failed rule
+func (p PasswordPolicy) Validate(field, password string) []FieldError {
+	errs := []FieldError{}
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		errs = append(errs, FieldError{
+			Field: field,
+			Rule:  "min_length",
+			Msg:   fmt.Sprintf("Password must be at least %d characters long", p.MinLength),
+		})
+	}
+
+	var hasDigit, hasUpper, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireDigit && !hasDigit {
+		errs = append(errs, FieldError{
+			Field: field,
+			Rule:  "require_digit",
+			Msg:   "Password must contain at least one digit",
+		})
+	}
+	if p.RequireUpper && !hasUpper {
+		errs = append(errs, FieldError{
+			Field: field,
+			Rule:  "require_upper",
+			Msg:   "Password must contain at least one uppercase letter",
+		})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		errs = append(errs, FieldError{
+			Field: field,
+			Rule:  "require_symbol",
+			Msg:   "Password must contain at least one symbol",
+		})
+	}
+
+	return errs
+}
+
+// generatedPasswordLength is the minimum length of generated passwords
+const generatedPasswordLength = 12
+
+// Character sets used to generate passwords
+const (
+	lowerChars  = "abcdefghijkmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	digitChars  = "23456789"
+	symbolChars = "!@#$%^&*-_=+?"
+)
+
+// Generate returns a random password that satisfies the policy
+func (p PasswordPolicy) Generate() (string, error) {
+	length := p.MinLength
+	if length < generatedPasswordLength {
+		length = generatedPasswordLength
+	}
+
+	// Always include one character of every class so any policy is met
+	sets := []string{lowerChars, upperChars, digitChars, symbolChars}
+	all := lowerChars + upperChars + digitChars + symbolChars
+
+	password := make([]byte, 0, length)
+	for _, set := range sets {
+		char, err := randomChar(set)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, char)
+	}
+	for len(password) < length {
+		char, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, char)
+	}
+
+	// Shuffle so the required classes are not always at the start
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
+
+// randomChar returns a random character of set
+func randomChar(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireDigit: true, RequireUpper: true, RequireSymbol: true}
+
+	tests := []struct {
+		name      string
+		policy    PasswordPolicy
+		password  string
+		wantRules []string
+	}{
+		{"meets every rule", strict, "Secure-pass1", nil},
+		{"too short", strict, "Sh0rt-pw", []string{"min_length"}},
+		{"length counts characters, not bytes", PasswordPolicy{MinLength: 4}, "äöüß", nil},
+		{"missing digit", strict, "Secure-password", []string{"require_digit"}},
+		{"missing uppercase letter", strict, "secure-pass1", []string{"require_upper"}},
+		{"missing symbol", strict, "Securepass1", []string{"require_symbol"}},
+		{"every rule failed in order", strict, "abc", []string{"min_length", "require_digit", "require_upper", "require_symbol"}},
+		{"rules not required are skipped", PasswordPolicy{MinLength: 6}, "lowercase", nil},
+		{"empty password", PasswordPolicy{MinLength: 1, RequireDigit: true}, "", []string{"min_length", "require_digit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.policy.Validate("new_password", tt.password)
+			var rules []string
+			for _, err := range errs {
+				if err.Field != "new_password" || err.Msg == "" {
+					t.Errorf("error %+v lacks the field or message", err)
+				}
+				rules = append(rules, err.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Errorf("failed rules %v, want %v", rules, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyGenerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     PasswordPolicy
+		wantLength int
+	}{
+		{"no rules", PasswordPolicy{}, generatedPasswordLength},
+		{"every rule", PasswordPolicy{MinLength: 8, RequireDigit: true, RequireUpper: true, RequireSymbol: true}, generatedPasswordLength},
+		{"longer minimum length", PasswordPolicy{MinLength: 20, RequireDigit: true, RequireUpper: true, RequireSymbol: true}, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				password, err := tt.policy.Generate()
+				if err != nil {
+					t.Fatalf("generating password: %v", err)
+				}
+				if len(password) != tt.wantLength {
+					t.Errorf("generated %q of length %d, want %d", password, len(password), tt.wantLength)
+				}
+				if errs := tt.policy.Validate("password", password); len(errs) > 0 {
+					t.Errorf("generated %q fails the policy: %+v", password, errs)
+				}
+			}
+		})
+	}
+}